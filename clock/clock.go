@@ -0,0 +1,89 @@
+// Package clock abstracts over the passage of time, so that protocol code
+// can be driven either by the wall clock or by a virtual clock under the
+// control of a test.
+package clock
+
+import (
+	"time"
+)
+
+// Clock tells the time and creates timers.
+//
+// All its functions should be thread-safe.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+	// After waits for duration d to elapse and then sends the current time on
+	// the returned channel, like time.After.
+	After(d time.Duration) <-chan time.Time
+	// NewTimer creates a Timer that sends the current time on its channel after
+	// at least duration d, like time.NewTimer.
+	NewTimer(d time.Duration) Timer
+}
+
+// Timer mirrors the API of *time.Timer.
+type Timer interface {
+	// C returns the channel on which the time is delivered.
+	C() <-chan time.Time
+	// Stop prevents the Timer from firing. It returns true if the call stops
+	// the timer, false if the timer has already expired or been stopped.
+	Stop() bool
+	// Reset changes the timer to expire after duration d. It returns true if
+	// the timer had been active, false if the timer had expired or been
+	// stopped. Like *time.Timer, Reset should only be invoked on stopped or
+	// expired timers with drained channels.
+	Reset(d time.Duration) bool
+}
+
+// Real returns a Clock backed by the time package.
+func Real() Clock {
+	return realClock{}
+}
+
+// OrReal returns c, or Real() if c is nil.
+func OrReal(c Clock) Clock {
+	if c == nil {
+		return Real()
+	}
+	return c
+}
+
+// Until returns the duration until t according to c.
+func Until(c Clock, t time.Time) time.Duration {
+	return t.Sub(c.Now())
+}
+
+// Since returns the time elapsed since t according to c.
+func Since(c Clock, t time.Time) time.Duration {
+	return c.Now().Sub(t)
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+func (realClock) NewTimer(d time.Duration) Timer {
+	return realTimer{time.NewTimer(d)}
+}
+
+type realTimer struct {
+	timer *time.Timer
+}
+
+func (t realTimer) C() <-chan time.Time {
+	return t.timer.C
+}
+
+func (t realTimer) Stop() bool {
+	return t.timer.Stop()
+}
+
+func (t realTimer) Reset(d time.Duration) bool {
+	return t.timer.Reset(d)
+}
@@ -0,0 +1,219 @@
+package clock
+
+import (
+	"container/heap"
+	"sync"
+	"time"
+)
+
+// VirtualClock is a Clock whose time only moves when one of its Advance*
+// methods is called. Timers fire synchronously, in deadline order, from
+// within those methods. Combined with a deterministic network (see
+// networking/simnet) this allows simulating long stretches of protocol
+// execution in a short amount of wall-clock time.
+//
+// Note that VirtualClock only controls the timers created through it.
+// Goroutines woken up by a timer still run concurrently with the caller of
+// Advance*. Tests that need to wait for the protocol to react to a timer
+// should do so explicitly, e.g. by yielding with runtime.Gosched or by
+// waiting on an observable effect, before advancing further.
+type VirtualClock struct {
+	mu     sync.Mutex
+	now    time.Time
+	timers virtualTimerHeap
+	seq    uint64
+}
+
+var _ Clock = (*VirtualClock)(nil)
+
+// NewVirtualClock returns a VirtualClock whose current time is start.
+func NewVirtualClock(start time.Time) *VirtualClock {
+	return &VirtualClock{now: start}
+}
+
+func (c *VirtualClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *VirtualClock) After(d time.Duration) <-chan time.Time {
+	return c.NewTimer(d).C()
+}
+
+func (c *VirtualClock) NewTimer(d time.Duration) Timer {
+	t := &virtualTimer{c, make(chan time.Time, 1), false, time.Time{}, 0}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.schedule(t, d)
+	return t
+}
+
+// Advance moves the clock forward by d, firing all timers whose deadline is
+// reached along the way.
+func (c *VirtualClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	target := c.now.Add(d)
+	c.mu.Unlock()
+	c.AdvanceTo(target)
+}
+
+// AdvanceTo moves the clock forward to t, firing all timers whose deadline is
+// at or before t. If t is in the past, the clock is not changed.
+func (c *VirtualClock) AdvanceTo(t time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for {
+		next, ok := c.peek()
+		if !ok || next.After(t) {
+			break
+		}
+		c.fireNext()
+	}
+	if t.After(c.now) {
+		c.now = t
+	}
+}
+
+// AdvanceToNext moves the clock forward to the earliest pending timer
+// deadline and fires all timers with that deadline. It returns false if there
+// was no pending timer, in which case the clock is left unchanged.
+func (c *VirtualClock) AdvanceToNext() (time.Time, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	next, ok := c.peek()
+	if !ok {
+		return c.now, false
+	}
+	for {
+		deadline, ok := c.peek()
+		if !ok || deadline.After(next) {
+			break
+		}
+		c.fireNext()
+	}
+	return c.now, true
+}
+
+// PendingTimers returns the number of timers that have not fired or been
+// stopped yet.
+func (c *VirtualClock) PendingTimers() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	count := 0
+	for _, entry := range c.timers {
+		if entry.current() {
+			count++
+		}
+	}
+	return count
+}
+
+// schedule must be called with c.mu held.
+func (c *VirtualClock) schedule(t *virtualTimer, d time.Duration) {
+	t.active = true
+	t.deadline = c.now.Add(d)
+	t.generation++
+	if d <= 0 {
+		t.fire(c.now)
+		return
+	}
+	c.seq++
+	heap.Push(&c.timers, virtualTimerEntry{t.deadline, c.seq, t, t.generation})
+}
+
+// peek returns the deadline of the earliest current timer. It discards stale
+// heap entries along the way. Must be called with c.mu held.
+func (c *VirtualClock) peek() (time.Time, bool) {
+	for c.timers.Len() > 0 {
+		if c.timers[0].current() {
+			return c.timers[0].deadline, true
+		}
+		heap.Pop(&c.timers)
+	}
+	return time.Time{}, false
+}
+
+// fireNext must be called with c.mu held and after peek returned true.
+func (c *VirtualClock) fireNext() {
+	entry := heap.Pop(&c.timers).(virtualTimerEntry)
+	if entry.deadline.After(c.now) {
+		c.now = entry.deadline
+	}
+	entry.timer.fire(c.now)
+}
+
+type virtualTimer struct {
+	clock      *VirtualClock
+	ch         chan time.Time
+	active     bool
+	deadline   time.Time
+	generation uint64
+}
+
+// fire must be called with clock.mu held.
+func (t *virtualTimer) fire(now time.Time) {
+	t.active = false
+	// like the runtime, never block when delivering the tick
+	select {
+	case t.ch <- now:
+	default:
+	}
+}
+
+func (t *virtualTimer) C() <-chan time.Time {
+	return t.ch
+}
+
+func (t *virtualTimer) Stop() bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+	wasActive := t.active
+	t.active = false
+	return wasActive
+}
+
+func (t *virtualTimer) Reset(d time.Duration) bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+	wasActive := t.active
+	t.clock.schedule(t, d)
+	return wasActive
+}
+
+type virtualTimerEntry struct {
+	deadline   time.Time
+	seq        uint64
+	timer      *virtualTimer
+	generation uint64
+}
+
+// current returns whether the entry still describes the pending expiry of its
+// timer, i.e. the timer was neither stopped nor reset since.
+func (e virtualTimerEntry) current() bool {
+	return e.timer.active && e.timer.generation == e.generation
+}
+
+// virtualTimerHeap implements heap.Interface, ordered by deadline and then by
+// creation order.
+type virtualTimerHeap []virtualTimerEntry
+
+func (h virtualTimerHeap) Len() int { return len(h) }
+
+func (h virtualTimerHeap) Less(i, j int) bool {
+	if h[i].deadline.Equal(h[j].deadline) {
+		return h[i].seq < h[j].seq
+	}
+	return h[i].deadline.Before(h[j].deadline)
+}
+
+func (h virtualTimerHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+
+func (h *virtualTimerHeap) Push(x interface{}) { *h = append(*h, x.(virtualTimerEntry)) }
+
+func (h *virtualTimerHeap) Pop() interface{} {
+	old := *h
+	item := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return item
+}
@@ -0,0 +1,180 @@
+package clock_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/smartcontractkit/libocr/clock"
+)
+
+var start = time.Unix(1000, 0)
+
+// fired returns the time sent on the timer's channel, if any.
+func fired(timer clock.Timer) (time.Time, bool) {
+	select {
+	case t := <-timer.C():
+		return t, true
+	default:
+		return time.Time{}, false
+	}
+}
+
+func expectFired(t *testing.T, name string, timer clock.Timer, expected time.Time) {
+	t.Helper()
+	if actual, ok := fired(timer); !ok || !actual.Equal(expected) {
+		t.Errorf("%s: expected to fire at %v, got %v (fired: %v)", name, expected, actual, ok)
+	}
+}
+
+func expectNotFired(t *testing.T, name string, timer clock.Timer) {
+	t.Helper()
+	if actual, ok := fired(timer); ok {
+		t.Errorf("%s: unexpectedly fired at %v", name, actual)
+	}
+}
+
+func TestVirtualClockAdvance(t *testing.T) {
+	c := clock.NewVirtualClock(start)
+	t1 := c.NewTimer(time.Second)
+	t2 := c.NewTimer(3 * time.Second)
+	after := c.After(2 * time.Second)
+
+	c.Advance(999 * time.Millisecond)
+	if !c.Now().Equal(start.Add(999 * time.Millisecond)) {
+		t.Fatalf("unexpected time %v", c.Now())
+	}
+	expectNotFired(t, "t1", t1)
+
+	// Timers receive their deadline, not the time the clock advanced to
+	c.Advance(1500 * time.Millisecond)
+	if !c.Now().Equal(start.Add(2499 * time.Millisecond)) {
+		t.Fatalf("unexpected time %v", c.Now())
+	}
+	expectFired(t, "t1", t1, start.Add(time.Second))
+	select {
+	case actual := <-after:
+		if !actual.Equal(start.Add(2 * time.Second)) {
+			t.Errorf("After: expected to fire at %v, got %v", start.Add(2*time.Second), actual)
+		}
+	default:
+		t.Errorf("After: did not fire")
+	}
+	expectNotFired(t, "t2", t2)
+	if pending := c.PendingTimers(); pending != 1 {
+		t.Errorf("expected 1 pending timer, got %v", pending)
+	}
+
+	// Zero and negative durations fire immediately
+	for _, d := range []time.Duration{0, -time.Second} {
+		expectFired(t, d.String(), c.NewTimer(d), c.Now())
+	}
+}
+
+func TestVirtualClockAdvanceTo(t *testing.T) {
+	c := clock.NewVirtualClock(start)
+	timer := c.NewTimer(time.Second)
+
+	// The deadline is inclusive
+	c.AdvanceTo(start.Add(time.Second))
+	expectFired(t, "timer", timer, start.Add(time.Second))
+
+	// Moving backwards is a no-op
+	c.AdvanceTo(start)
+	if !c.Now().Equal(start.Add(time.Second)) {
+		t.Fatalf("clock moved backwards to %v", c.Now())
+	}
+}
+
+func TestVirtualClockAdvanceToNextOrdering(t *testing.T) {
+	c := clock.NewVirtualClock(start)
+	timers := []clock.Timer{
+		c.NewTimer(3 * time.Second),
+		c.NewTimer(time.Second),
+		c.NewTimer(2 * time.Second),
+		c.NewTimer(time.Second),
+	}
+
+	for _, step := range []struct {
+		now   time.Duration
+		fired []int
+	}{
+		// Timers with the same deadline fire together
+		{time.Second, []int{1, 3}},
+		{2 * time.Second, []int{2}},
+		{3 * time.Second, []int{0}},
+	} {
+		now, ok := c.AdvanceToNext()
+		if !ok || !now.Equal(start.Add(step.now)) || !c.Now().Equal(now) {
+			t.Fatalf("expected to advance to %v, got %v, %v", start.Add(step.now), now, ok)
+		}
+		isFired := map[int]bool{}
+		for _, i := range step.fired {
+			isFired[i] = true
+		}
+		for i, timer := range timers {
+			name := step.now.String()
+			if isFired[i] {
+				expectFired(t, name, timer, now)
+			} else {
+				expectNotFired(t, name, timer)
+			}
+		}
+	}
+
+	now, ok := c.AdvanceToNext()
+	if ok || !now.Equal(start.Add(3*time.Second)) {
+		t.Fatalf("expected no pending timer, got %v, %v", now, ok)
+	}
+}
+
+func TestVirtualClockStopReset(t *testing.T) {
+	c := clock.NewVirtualClock(start)
+	stopped := c.NewTimer(time.Second)
+	reset := c.NewTimer(time.Second)
+	restarted := c.NewTimer(time.Second)
+	if pending := c.PendingTimers(); pending != 3 {
+		t.Fatalf("expected 3 pending timers, got %v", pending)
+	}
+
+	if !stopped.Stop() {
+		t.Errorf("expected Stop of active timer to return true")
+	}
+	if stopped.Stop() {
+		t.Errorf("expected Stop of stopped timer to return false")
+	}
+	if !reset.Reset(3 * time.Second) {
+		t.Errorf("expected Reset of active timer to return true")
+	}
+	restarted.Stop()
+	if restarted.Reset(2 * time.Second) {
+		t.Errorf("expected Reset of stopped timer to return false")
+	}
+	if pending := c.PendingTimers(); pending != 2 {
+		t.Fatalf("expected 2 pending timers, got %v", pending)
+	}
+
+	// Neither the stopped timer nor the old deadline of the reset ones count
+	// as the next deadline
+	if now, ok := c.AdvanceToNext(); !ok || !now.Equal(start.Add(2*time.Second)) {
+		t.Fatalf("expected to advance to %v, got %v, %v", start.Add(2*time.Second), now, ok)
+	}
+	expectNotFired(t, "stopped", stopped)
+	expectNotFired(t, "reset", reset)
+	expectFired(t, "restarted", restarted, start.Add(2*time.Second))
+
+	c.Advance(time.Second)
+	expectFired(t, "reset", reset, start.Add(3*time.Second))
+	if reset.Stop() {
+		t.Errorf("expected Stop of expired timer to return false")
+	}
+	if reset.Reset(time.Second) {
+		t.Errorf("expected Reset of expired timer to return false")
+	}
+	c.Advance(time.Second)
+	expectFired(t, "reset again", reset, start.Add(4*time.Second))
+
+	expectNotFired(t, "stopped", stopped)
+	if pending := c.PendingTimers(); pending != 0 {
+		t.Errorf("expected no pending timers, got %v", pending)
+	}
+}
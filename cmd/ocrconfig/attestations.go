@@ -0,0 +1,44 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/smartcontractkit/libocr/offchainreporting2plus/chains/evmutil"
+	"github.com/smartcontractkit/libocr/offchainreporting2plus/confighelper"
+	"github.com/smartcontractkit/libocr/offchainreporting2plus/identityattestation"
+)
+
+// All protocols supported by ocrconfig use EVM onchain keys. Verification
+// doesn't need a secret key.
+var onchainVerifier identityattestation.OnchainVerifier = &evmutil.EVMOnchainKeyring{}
+
+// readAttestations reads a JSON or YAML list of identity attestations.
+func readAttestations(path string) ([]identityattestation.Attestation, error) {
+	data, err := readFileOrStdin(path)
+	if err != nil {
+		return nil, err
+	}
+	var attestations []identityattestation.Attestation
+	if err := decodeJSONOrYAML(data, &attestations); err != nil {
+		return nil, fmt.Errorf("could not parse %s: %w", path, err)
+	}
+	return attestations, nil
+}
+
+// verifyInputAttestations checks that every oracle in input has a valid
+// attestation in the file at path.
+func verifyInputAttestations(input inputFile, path string) error {
+	attestations, err := readAttestations(path)
+	if err != nil {
+		return err
+	}
+	oracles := []confighelper.OracleIdentityExtra{}
+	for i, o := range input.Oracles {
+		oracle, err := o.toOracleIdentityExtra()
+		if err != nil {
+			return fmt.Errorf("oracle %v: %w", i, err)
+		}
+		oracles = append(oracles, oracle)
+	}
+	return identityattestation.VerifyIdentities(oracles, attestations, onchainVerifier)
+}
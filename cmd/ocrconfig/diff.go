@@ -0,0 +1,80 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/smartcontractkit/libocr/offchainreporting2/reportingplugin/median"
+	"github.com/smartcontractkit/libocr/offchainreporting2plus/confighelper"
+	"github.com/smartcontractkit/libocr/offchainreporting2plus/configinspector"
+	"github.com/smartcontractkit/libocr/offchainreporting2plus/ocr3confighelper"
+)
+
+// medianOffchainConfigDecoder decodes the ReportingPluginConfig of the median
+// reporting plugin.
+func medianOffchainConfigDecoder(b []byte) (interface{}, error) {
+	return median.DecodeOffchainConfig(b)
+}
+
+func runDiff(args []string) error {
+	flags := flag.NewFlagSet("diff", flag.ContinueOnError)
+	format := flags.String("format", "text", "output format, text, json or yaml")
+	event := flags.Bool("event", false, "OLD and NEW contain raw ConfigSet logs in JSON, as returned by eth_getLogs")
+	plugin := flags.String("plugin", "", "decode the reporting plugin config of this plugin, only median is supported")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: ocrconfig diff [-format text|json|yaml] [-event] [-plugin median] OLD NEW")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 2 {
+		flags.Usage()
+		return fmt.Errorf("expected exactly two input files")
+	}
+
+	var opts confighelper.DiffOptions
+	switch *plugin {
+	case "":
+	case "median":
+		opts.ReportingPluginConfigDecoder = medianOffchainConfigDecoder
+	default:
+		return fmt.Errorf("unknown plugin %q, expected median", *plugin)
+	}
+
+	old, err := readContractConfig(flags.Arg(0), *event)
+	if err != nil {
+		return err
+	}
+	new, err := readContractConfig(flags.Arg(1), *event)
+	if err != nil {
+		return err
+	}
+	if old.OffchainConfigVersion != new.OffchainConfigVersion {
+		return fmt.Errorf("cannot diff configs with different OffchainConfigVersions %v and %v", old.OffchainConfigVersion, new.OffchainConfigVersion)
+	}
+	inspection, err := configinspector.Inspect(old)
+	if err != nil {
+		return err
+	}
+
+	var diff confighelper.ConfigDiff
+	switch inspection.Protocol {
+	case configinspector.ProtocolOCR2:
+		diff, err = confighelper.DiffContractConfigs(old, new, opts)
+	case configinspector.ProtocolOCR3:
+		diff, err = ocr3confighelper.DiffContractConfigs(old, new, opts)
+	default:
+		return fmt.Errorf("unsupported protocol %q", inspection.Protocol)
+	}
+	if err != nil {
+		return err
+	}
+
+	if *format == "text" {
+		_, err := fmt.Print(diff)
+		return err
+	}
+	return writeOutput(os.Stdout, *format, diff)
+}
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+	"os"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/smartcontractkit/libocr/offchainreporting2plus/chains/evmutil"
+	"github.com/smartcontractkit/libocr/offchainreporting2plus/configbuilder"
+	"github.com/smartcontractkit/libocr/offchainreporting2plus/confighelper"
+	"github.com/smartcontractkit/libocr/offchainreporting2plus/types"
+)
+
+// setConfigOutput holds the arguments for the contract's setConfig function,
+// encoded so that they can be passed to ABI tooling as is, together with the
+// digest the contract will compute for the config and the manifest recording
+// how the config was built.
+type setConfigOutput struct {
+	Signers               []hexBytes             `json:"signers"`
+	Transmitters          []string               `json:"transmitters"`
+	F                     uint8                  `json:"f"`
+	OnchainConfig         hexBytes               `json:"onchainConfig"`
+	OffchainConfigVersion uint64                 `json:"offchainConfigVersion"`
+	OffchainConfig        hexBytes               `json:"offchainConfig"`
+	ConfigDigest          string                 `json:"configDigest"`
+	Manifest              configbuilder.Manifest `json:"manifest"`
+}
+
+// readSeed reads a hex-encoded configbuilder.Seed from path.
+func readSeed(path string) (configbuilder.Seed, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return configbuilder.Seed{}, err
+	}
+	var seed hexBytes
+	if err := seed.UnmarshalText(bytes.TrimSpace(data)); err != nil {
+		return configbuilder.Seed{}, fmt.Errorf("could not parse seed: %w", err)
+	}
+	if len(seed) != len(configbuilder.Seed{}) {
+		return configbuilder.Seed{}, fmt.Errorf("seed must be %v bytes, got %v", len(configbuilder.Seed{}), len(seed))
+	}
+	return configbuilder.Seed(seed), nil
+}
+
+func generate(input inputFile, source configbuilder.SharedSecretSource) (setConfigOutput, error) {
+	if !common.IsHexAddress(input.ContractAddress) {
+		return setConfigOutput{}, fmt.Errorf("contractAddress %q is not a valid address", input.ContractAddress)
+	}
+	if input.ConfigCount == 0 {
+		return setConfigOutput{}, fmt.Errorf("configCount must be positive")
+	}
+
+	oracles := []confighelper.OracleIdentityExtra{}
+	for i, o := range input.Oracles {
+		oracle, err := o.toOracleIdentityExtra()
+		if err != nil {
+			return setConfigOutput{}, fmt.Errorf("oracle %v: %w", i, err)
+		}
+		oracles = append(oracles, oracle)
+	}
+
+	opts := configbuilder.Options{
+		"",
+		input.ConfigCount,
+		evmutil.EVMOffchainConfigDigester{input.ChainID, common.HexToAddress(input.ContractAddress)},
+		oracles,
+		input.F,
+		time.Duration(input.DeltaProgress),
+		time.Duration(input.DeltaResend),
+		0,
+		time.Duration(input.DeltaRound),
+		time.Duration(input.DeltaGrace),
+		0,
+		time.Duration(input.DeltaStage),
+		input.RMax,
+		input.S,
+		input.ReportingPluginConfig,
+		input.OnchainConfig,
+		time.Duration(input.MaxDurationQuery),
+		time.Duration(input.MaxDurationObservation),
+		0,
+		0,
+		0,
+		time.Duration(input.MaxDurationShouldTransmitAcceptedReport),
+	}
+	// Fields that don't apply to the protocol stay zero, as Build requires
+	switch input.Protocol {
+	case protocolOCR2:
+		opts.Protocol = configbuilder.ProtocolOCR2
+		opts.MaxDurationReport = time.Duration(input.MaxDurationReport)
+		opts.MaxDurationShouldAcceptFinalizedReport = time.Duration(input.MaxDurationShouldAcceptFinalizedReport)
+	case protocolOCR3:
+		opts.Protocol = configbuilder.ProtocolOCR3
+		opts.DeltaInitial = time.Duration(input.DeltaInitial)
+		opts.DeltaCertifiedCommitRequest = time.Duration(input.DeltaCertifiedCommitRequest)
+		opts.MaxDurationShouldAcceptAttestedReport = time.Duration(input.MaxDurationShouldAcceptAttestedReport)
+	case protocolMercury:
+		if input.RMax > 255 {
+			return setConfigOutput{}, fmt.Errorf("rMax must fit into a uint8 for mercury, got %v", input.RMax)
+		}
+		if len(input.FeedID) != 32 {
+			return setConfigOutput{}, fmt.Errorf("feedID must be 32 bytes for mercury, got %v", len(input.FeedID))
+		}
+		// Mercury is OCR3 with only the observation phase bounded in time
+		opts.Protocol = configbuilder.ProtocolOCR3
+		opts.DeltaInitial = time.Duration(input.DeltaInitial)
+		opts.DeltaCertifiedCommitRequest = time.Duration(input.DeltaCertifiedCommitRequest)
+		opts.MaxDurationQuery = 0
+		opts.MaxDurationShouldTransmitAcceptedReport = 0
+		var feedID [32]byte
+		copy(feedID[:], input.FeedID)
+		opts.Digester = evmutil.MercuryOffchainConfigDigester{feedID, new(big.Int).SetUint64(input.ChainID), common.HexToAddress(input.ContractAddress)}
+	default:
+		return setConfigOutput{}, fmt.Errorf("unknown protocol %q, expected one of %q, %q, %q", input.Protocol, protocolOCR2, protocolOCR3, protocolMercury)
+	}
+
+	// Build runs the same checks oracles run when they pick up the config,
+	// so that we never output a config that oracles would reject.
+	contractConfig, manifest, err := configbuilder.Build(opts, source)
+	if err != nil {
+		return setConfigOutput{}, err
+	}
+
+	output := setConfigOutput{
+		nil,
+		nil,
+		contractConfig.F,
+		contractConfig.OnchainConfig,
+		contractConfig.OffchainConfigVersion,
+		contractConfig.OffchainConfig,
+		contractConfig.ConfigDigest.Hex(),
+		manifest,
+	}
+	for _, signer := range contractConfig.Signers {
+		output.Signers = append(output.Signers, hexBytes(signer))
+	}
+	for _, transmitter := range contractConfig.Transmitters {
+		if input.Protocol == protocolMercury {
+			// ABI tooling expects bytes32 values with 0x prefix
+			output.Transmitters = append(output.Transmitters, "0x"+string(transmitter))
+		} else {
+			output.Transmitters = append(output.Transmitters, string(transmitter))
+		}
+	}
+	return output, nil
+}
+
+func (o inputOracle) toOracleIdentityExtra() (confighelper.OracleIdentityExtra, error) {
+	var offchainPublicKey types.OffchainPublicKey
+	if len(o.OffchainPublicKey) != len(offchainPublicKey) {
+		return confighelper.OracleIdentityExtra{}, fmt.Errorf("offchainPublicKey must be %v bytes, got %v", len(offchainPublicKey), len(o.OffchainPublicKey))
+	}
+	copy(offchainPublicKey[:], o.OffchainPublicKey)
+
+	var configEncryptionPublicKey types.ConfigEncryptionPublicKey
+	if len(o.ConfigEncryptionPublicKey) != len(configEncryptionPublicKey) {
+		return confighelper.OracleIdentityExtra{}, fmt.Errorf("configEncryptionPublicKey must be %v bytes, got %v", len(configEncryptionPublicKey), len(o.ConfigEncryptionPublicKey))
+	}
+	copy(configEncryptionPublicKey[:], o.ConfigEncryptionPublicKey)
+
+	if len(o.OnchainPublicKey) == 0 {
+		return confighelper.OracleIdentityExtra{}, fmt.Errorf("onchainPublicKey must not be empty")
+	}
+	if o.PeerID == "" {
+		return confighelper.OracleIdentityExtra{}, fmt.Errorf("peerID must not be empty")
+	}
+	if o.TransmitAccount == "" {
+		return confighelper.OracleIdentityExtra{}, fmt.Errorf("transmitAccount must not be empty")
+	}
+
+	return confighelper.OracleIdentityExtra{
+		confighelper.OracleIdentity{
+			offchainPublicKey,
+			types.OnchainPublicKey(o.OnchainPublicKey),
+			o.PeerID,
+			types.Account(o.TransmitAccount),
+		},
+		configEncryptionPublicKey,
+	}, nil
+}
@@ -0,0 +1,365 @@
+package main
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/hex"
+	"encoding/json"
+	"math/big"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/smartcontractkit/libocr/offchainreporting2plus/chains/evmutil"
+	"github.com/smartcontractkit/libocr/offchainreporting2plus/configbuilder"
+	"github.com/smartcontractkit/libocr/offchainreporting2plus/confighelper"
+	"github.com/smartcontractkit/libocr/offchainreporting2plus/ocr3confighelper"
+	"github.com/smartcontractkit/libocr/offchainreporting2plus/types"
+	ragetypes "github.com/smartcontractkit/libocr/ragep2p/types"
+)
+
+const testContractAddress = "0x1234567890123456789012345678901234567890"
+
+var testSeed = configbuilder.Seed{0x5e, 0xed}
+
+func testInput(protocol string, n int) inputFile {
+	input := inputFile{
+		protocol,
+		1337,
+		testContractAddress,
+		nil,
+		2,
+		nil,
+		1,
+		duration(8 * time.Second),
+		duration(5 * time.Second),
+		duration(3 * time.Second),
+		duration(5 * time.Second),
+		duration(500 * time.Millisecond),
+		duration(time.Second),
+		duration(5 * time.Second),
+		3,
+		[]int{1, 1, 1, 1},
+		hexBytes{0x01, 0x02},
+		hexBytes{0x03, 0x04},
+		0,
+		duration(time.Second),
+		duration(time.Second),
+		duration(time.Second),
+		duration(time.Second),
+		duration(time.Second),
+	}
+	if protocol == protocolMercury {
+		input.FeedID = bytes.Repeat([]byte{0xfe}, 32)
+		input.ReportingPluginConfig = nil
+		input.OnchainConfig = nil
+	}
+	for i := 0; i < n; i++ {
+		sk := ed25519.NewKeyFromSeed(bytes.Repeat([]byte{byte(i + 1)}, ed25519.SeedSize))
+		peerID, err := ragetypes.PeerIDFromPrivateKey(sk)
+		if err != nil {
+			panic(err)
+		}
+		transmitAccount := common.BytesToAddress(bytes.Repeat([]byte{byte(0x20 + i)}, 20)).Hex()
+		if protocol == protocolMercury {
+			transmitAccount = hex.EncodeToString(bytes.Repeat([]byte{byte(0x20 + i)}, 32))
+		}
+		input.Oracles = append(input.Oracles, inputOracle{
+			hexBytes(sk.Public().(ed25519.PublicKey)),
+			bytes.Repeat([]byte{byte(0x10 + i)}, 20),
+			peerID.String(),
+			transmitAccount,
+			bytes.Repeat([]byte{byte(0x30 + i)}, 32),
+		})
+	}
+	return input
+}
+
+// contractConfigFromOutput reconstructs the contract config the contract
+// would emit after setConfig was called with output.
+func contractConfigFromOutput(t *testing.T, input inputFile, output setConfigOutput) types.ContractConfig {
+	t.Helper()
+	digest, err := types.BytesToConfigDigest(common.FromHex(output.ConfigDigest))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cc := types.ContractConfig{
+		digest,
+		input.ConfigCount,
+		nil,
+		nil,
+		output.F,
+		output.OnchainConfig,
+		output.OffchainConfigVersion,
+		output.OffchainConfig,
+	}
+	for _, signer := range output.Signers {
+		cc.Signers = append(cc.Signers, types.OnchainPublicKey(signer))
+	}
+	for _, transmitter := range output.Transmitters {
+		if input.Protocol == protocolMercury {
+			transmitter = strings.TrimPrefix(transmitter, "0x")
+		}
+		cc.Transmitters = append(cc.Transmitters, types.Account(transmitter))
+	}
+	return cc
+}
+
+func TestGenerate(t *testing.T) {
+	for _, protocol := range []string{protocolOCR2, protocolOCR3, protocolMercury} {
+		t.Run(protocol, func(t *testing.T) {
+			input := testInput(protocol, 4)
+			output, err := generate(input, testSeed)
+			if err != nil {
+				t.Fatal(err)
+			}
+			cc := contractConfigFromOutput(t, input, output)
+
+			again, err := generate(input, testSeed)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(output, again) {
+				t.Fatalf("generate is not deterministic\nfirst:  %+v\nsecond: %+v", output, again)
+			}
+
+			var digester types.OffchainConfigDigester = evmutil.EVMOffchainConfigDigester{input.ChainID, common.HexToAddress(testContractAddress)}
+			var expectedPrefix = types.ConfigDigestPrefixEVM
+			if protocol == protocolMercury {
+				var feedID [32]byte
+				copy(feedID[:], input.FeedID)
+				digester = evmutil.MercuryOffchainConfigDigester{feedID, new(big.Int).SetUint64(input.ChainID), common.HexToAddress(testContractAddress)}
+				expectedPrefix = types.ConfigDigestPrefixMercuryV02
+				for _, transmitter := range output.Transmitters {
+					if !strings.HasPrefix(transmitter, "0x") || len(transmitter) != 2+64 {
+						t.Fatalf("mercury transmitter %q is not a 0x-prefixed bytes32", transmitter)
+					}
+				}
+			}
+			expectedDigest, err := digester.ConfigDigest(cc)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if cc.ConfigDigest != expectedDigest || !expectedPrefix.IsPrefixOf(cc.ConfigDigest) {
+				t.Fatalf("output digest %v, expected %v", cc.ConfigDigest, expectedDigest)
+			}
+
+			// The manifest describes exactly the output
+			if err := configbuilder.VerifyManifest(output.Manifest, digester, testSeed); err != nil {
+				t.Fatal(err)
+			}
+			if err := configbuilder.VerifyManifest(output.Manifest, digester, configbuilder.Seed{1}); err == nil {
+				t.Fatalf("expected manifest verification to fail with a different seed")
+			}
+			manifestConfig, err := output.Manifest.ContractConfig()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(manifestConfig, cc) {
+				t.Fatalf("manifest output differs from setConfig arguments\nmanifest: %+v\noutput:   %+v", manifestConfig, cc)
+			}
+
+			// Oracles must accept the config and see what we put in
+			var identities []confighelper.OracleIdentity
+			var f int
+			var rMax uint64
+			if protocol == protocolOCR2 {
+				pc, err := confighelper.PublicConfigFromContractConfig(false, cc)
+				if err != nil {
+					t.Fatal(err)
+				}
+				identities, f, rMax = pc.OracleIdentities, pc.F, uint64(pc.RMax)
+				if pc.DeltaRound != time.Duration(input.DeltaRound) || !bytes.Equal(pc.ReportingPluginConfig, input.ReportingPluginConfig) {
+					t.Fatalf("unexpected public config %+v", pc)
+				}
+			} else {
+				pc, err := ocr3confighelper.PublicConfigFromContractConfig(false, cc)
+				if err != nil {
+					t.Fatal(err)
+				}
+				identities, f, rMax = pc.OracleIdentities, pc.F, pc.RMax
+				if pc.DeltaInitial != time.Duration(input.DeltaInitial) {
+					t.Fatalf("unexpected public config %+v", pc)
+				}
+			}
+			if f != input.F || rMax != input.RMax || len(identities) != len(input.Oracles) {
+				t.Fatalf("unexpected f %v, rMax %v or oracle count %v", f, rMax, len(identities))
+			}
+			for i, identity := range identities {
+				if identity.PeerID != input.Oracles[i].PeerID || !bytes.Equal(identity.OffchainPublicKey[:], input.Oracles[i].OffchainPublicKey) {
+					t.Fatalf("oracle %v has unexpected identity %+v", i, identity)
+				}
+			}
+		})
+	}
+}
+
+func TestGenerateRejectsInvalidInput(t *testing.T) {
+	for name, test := range map[string]struct {
+		protocol string
+		modify   func(*inputFile)
+	}{
+		"unknown protocol":     {"ocr1", func(*inputFile) {}},
+		"bad contract address": {protocolOCR2, func(i *inputFile) { i.ContractAddress = "0x1234" }},
+		"zero config count":    {protocolOCR2, func(i *inputFile) { i.ConfigCount = 0 }},
+		"rMax too large":       {protocolOCR2, func(i *inputFile) { i.RMax = 256 }},
+		"short feed id":        {protocolMercury, func(i *inputFile) { i.FeedID = i.FeedID[:31] }},
+		"short offchain key":   {protocolOCR3, func(i *inputFile) { i.Oracles[0].OffchainPublicKey = i.Oracles[0].OffchainPublicKey[:31] }},
+		"missing peer id":      {protocolOCR3, func(i *inputFile) { i.Oracles[1].PeerID = "" }},
+		"too few oracles":      {protocolOCR3, func(i *inputFile) { i.Oracles = i.Oracles[:3] }},
+		"mercury address transmitter": {protocolMercury, func(i *inputFile) {
+			i.Oracles[0].TransmitAccount = common.BytesToAddress([]byte{1}).Hex()
+		}},
+	} {
+		input := testInput(test.protocol, 4)
+		test.modify(&input)
+		if _, err := generate(input, testSeed); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+func TestReadSeed(t *testing.T) {
+	dir := t.TempDir()
+	for _, test := range []struct {
+		data          string
+		expectedError bool
+	}{
+		{"5eed" + strings.Repeat("00", 30) + "\n", false},
+		{"0x5eed" + strings.Repeat("00", 30), false},
+		{"5eed" + strings.Repeat("00", 29), true},
+		{"5eed" + strings.Repeat("00", 31), true},
+		{"not hex", true},
+	} {
+		path := filepath.Join(dir, "seed")
+		if err := os.WriteFile(path, []byte(test.data), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		seed, err := readSeed(path)
+		if test.expectedError {
+			if err == nil {
+				t.Errorf("%q: expected error", test.data)
+			}
+			continue
+		}
+		if err != nil || seed != testSeed {
+			t.Errorf("%q: expected seed %x, got %x, %v", test.data, testSeed, seed, err)
+		}
+	}
+	if _, err := readSeed(filepath.Join(dir, "missing")); err == nil {
+		t.Errorf("expected error for missing seed file")
+	}
+}
+
+func TestInspectGenerateOutput(t *testing.T) {
+	input := testInput(protocolOCR3, 4)
+	output, err := generate(input, testSeed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := writeOutput(&buf, "yaml", output); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "output.yaml")
+	if err := os.WriteFile(path, buf.Bytes(), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	// configCount comes from the manifest
+	cc, err := readContractConfig(path, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := contractConfigFromOutput(t, input, output); !reflect.DeepEqual(cc, expected) {
+		t.Fatalf("read config differs from generated config\nread:      %+v\ngenerated: %+v", cc, expected)
+	}
+}
+
+func TestReadInput(t *testing.T) {
+	input := testInput(protocolOCR3, 4)
+	dir := t.TempDir()
+
+	var jsonBuf, yamlBuf bytes.Buffer
+	if err := writeOutput(&jsonBuf, "json", input); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeOutput(&yamlBuf, "yaml", input); err != nil {
+		t.Fatal(err)
+	}
+	// hex values without 0x prefix are accepted, too
+	noPrefix := strings.ReplaceAll(jsonBuf.String(), `"0x`, `"`)
+
+	for name, data := range map[string]string{
+		"json":      jsonBuf.String(),
+		"yaml":      yamlBuf.String(),
+		"no prefix": noPrefix,
+	} {
+		path := filepath.Join(dir, strings.ReplaceAll(name, " ", "-"))
+		if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		decoded, err := readInput(path)
+		if err != nil {
+			t.Fatalf("%s: %v\n%s", name, err, data)
+		}
+		if name == "no prefix" {
+			// the contract address is a string, not hexBytes
+			decoded.ContractAddress = input.ContractAddress
+			for i := range decoded.Oracles {
+				decoded.Oracles[i].TransmitAccount = input.Oracles[i].TransmitAccount
+			}
+		}
+		if !reflect.DeepEqual(decoded, input) {
+			t.Fatalf("%s: decoded input differs\nbefore: %+v\nafter:  %+v", name, input, decoded)
+		}
+	}
+
+	for name, data := range map[string]string{
+		"unknown field":    strings.Replace(jsonBuf.String(), `"protocol"`, `"protocl"`, 1),
+		"invalid duration": strings.Replace(jsonBuf.String(), `"deltaRound": "5s"`, `"deltaRound": "5"`, 1),
+		"invalid hex":      strings.Replace(jsonBuf.String(), `"onchainConfig": "0x0304"`, `"onchainConfig": "0xzz"`, 1),
+	} {
+		if data == jsonBuf.String() {
+			t.Fatalf("%s: replacement didn't apply", name)
+		}
+		path := filepath.Join(dir, "invalid")
+		if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := readInput(path); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+func TestWriteOutputYAMLMatchesJSON(t *testing.T) {
+	output, err := generate(testInput(protocolOCR2, 4), testSeed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var jsonBuf, yamlBuf bytes.Buffer
+	if err := writeOutput(&jsonBuf, "json", output); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeOutput(&yamlBuf, "yaml", output); err != nil {
+		t.Fatal(err)
+	}
+	var fromJSON, fromYAML map[string]interface{}
+	if err := json.Unmarshal(jsonBuf.Bytes(), &fromJSON); err != nil {
+		t.Fatal(err)
+	}
+	if err := decodeJSONOrYAML(yamlBuf.Bytes(), &fromYAML); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(fromJSON, fromYAML) {
+		t.Fatalf("YAML output differs from JSON output\njson: %s\nyaml: %s", jsonBuf.String(), yamlBuf.String())
+	}
+	if err := writeOutput(&yamlBuf, "xml", output); err == nil {
+		t.Fatalf("expected error for unknown format")
+	}
+}
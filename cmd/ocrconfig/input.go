@@ -0,0 +1,153 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/smartcontractkit/libocr/offchainreporting2plus/configbuilder"
+)
+
+// inputFile is the format of the files read by ocrconfig. Fields that only
+// apply to some protocols are ignored for the others.
+type inputFile struct {
+	// One of "ocr2", "ocr3", "mercury"
+	Protocol string `json:"protocol"`
+
+	// Identify the contract the config is for. Used for computing the config
+	// digest.
+	ChainID         uint64   `json:"chainID"`
+	ContractAddress string   `json:"contractAddress"`
+	FeedID          hexBytes `json:"feedID"` // mercury only
+
+	// The configCount the contract will have after setConfig has been
+	// called with the generated arguments, i.e. one more than the current
+	// configCount. Used for computing the config digest.
+	ConfigCount uint64 `json:"configCount"`
+
+	Oracles []inputOracle `json:"oracles"`
+	F       int           `json:"f"`
+
+	DeltaProgress               duration `json:"deltaProgress"`
+	DeltaResend                 duration `json:"deltaResend"`
+	DeltaInitial                duration `json:"deltaInitial"` // ocr3 & mercury only
+	DeltaRound                  duration `json:"deltaRound"`
+	DeltaGrace                  duration `json:"deltaGrace"`
+	DeltaCertifiedCommitRequest duration `json:"deltaCertifiedCommitRequest"` // ocr3 & mercury only
+	DeltaStage                  duration `json:"deltaStage"`
+	RMax                        uint64   `json:"rMax"`
+	S                           []int    `json:"s"`
+
+	ReportingPluginConfig hexBytes `json:"reportingPluginConfig"`
+	OnchainConfig         hexBytes `json:"onchainConfig"`
+
+	MaxDurationQuery                        duration `json:"maxDurationQuery"`
+	MaxDurationObservation                  duration `json:"maxDurationObservation"`
+	MaxDurationReport                       duration `json:"maxDurationReport"`                      // ocr2 only
+	MaxDurationShouldAcceptFinalizedReport  duration `json:"maxDurationShouldAcceptFinalizedReport"` // ocr2 only
+	MaxDurationShouldAcceptAttestedReport   duration `json:"maxDurationShouldAcceptAttestedReport"`  // ocr3 only
+	MaxDurationShouldTransmitAcceptedReport duration `json:"maxDurationShouldTransmitAcceptedReport"`
+}
+
+type inputOracle struct {
+	OffchainPublicKey         hexBytes `json:"offchainPublicKey"`
+	OnchainPublicKey          hexBytes `json:"onchainPublicKey"`
+	PeerID                    string   `json:"peerID"`
+	TransmitAccount           string   `json:"transmitAccount"`
+	ConfigEncryptionPublicKey hexBytes `json:"configEncryptionPublicKey"`
+}
+
+const (
+	protocolOCR2    = "ocr2"
+	protocolOCR3    = "ocr3"
+	protocolMercury = "mercury"
+)
+
+// hexBytes is a byte string that is hex-encoded in input and output files.
+// The 0x prefix is optional in input. Input and output files share the
+// encoding of manifests and inspections.
+type hexBytes = configbuilder.HexBytes
+
+// duration is a time.Duration that is written as a string such as "1.5s" in
+// input and output files.
+type duration = configbuilder.Duration
+
+// readInput reads an inputFile from path, or from stdin if path is "-". JSON
+// and YAML are both accepted. Unknown fields are rejected to catch typos.
+func readInput(path string) (inputFile, error) {
+	data, err := readFileOrStdin(path)
+	if err != nil {
+		return inputFile{}, err
+	}
+	var input inputFile
+	if err := decodeJSONOrYAML(data, &input); err != nil {
+		return inputFile{}, fmt.Errorf("could not parse %s: %w", path, err)
+	}
+	return input, nil
+}
+
+func readFileOrStdin(path string) ([]byte, error) {
+	if path == "-" {
+		return io.ReadAll(os.Stdin)
+	}
+	return os.ReadFile(path)
+}
+
+// decodeJSONOrYAML decodes data into v using v's JSON field names and
+// unmarshalers. Since JSON is (for our purposes) a subset of YAML, data is
+// parsed as YAML and then re-encoded as JSON.
+func decodeJSONOrYAML(data []byte, v interface{}) error {
+	var raw interface{}
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	normalized, err := normalizeYAML(raw)
+	if err != nil {
+		return err
+	}
+	jsonData, err := json.Marshal(normalized)
+	if err != nil {
+		return err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(jsonData))
+	decoder.DisallowUnknownFields()
+	return decoder.Decode(v)
+}
+
+// normalizeYAML converts the map[interface{}]interface{} values produced by
+// the yaml package into map[string]interface{} values that encoding/json can
+// handle.
+func normalizeYAML(v interface{}) (interface{}, error) {
+	switch v := v.(type) {
+	case map[interface{}]interface{}:
+		result := make(map[string]interface{}, len(v))
+		for key, value := range v {
+			keyString, ok := key.(string)
+			if !ok {
+				return nil, fmt.Errorf("map key %v is not a string", key)
+			}
+			normalized, err := normalizeYAML(value)
+			if err != nil {
+				return nil, err
+			}
+			result[keyString] = normalized
+		}
+		return result, nil
+	case []interface{}:
+		result := make([]interface{}, len(v))
+		for i, value := range v {
+			normalized, err := normalizeYAML(value)
+			if err != nil {
+				return nil, err
+			}
+			result[i] = normalized
+		}
+		return result, nil
+	default:
+		return v, nil
+	}
+}
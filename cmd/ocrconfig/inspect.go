@@ -0,0 +1,146 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	gethtypes "github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/smartcontractkit/libocr/commontypes"
+	"github.com/smartcontractkit/libocr/offchainreporting2plus/chains/evmutil"
+	"github.com/smartcontractkit/libocr/offchainreporting2plus/configbuilder"
+	"github.com/smartcontractkit/libocr/offchainreporting2plus/configinspector"
+	"github.com/smartcontractkit/libocr/offchainreporting2plus/offchainkeyring"
+	"github.com/smartcontractkit/libocr/offchainreporting2plus/types"
+)
+
+// contractConfigFile is the format in which inspect reads a
+// types.ContractConfig. It matches the output of generate, with an optional
+// configCount. The manifest is optional, too, and only consulted for the
+// configCount.
+type contractConfigFile struct {
+	ConfigDigest          hexBytes                `json:"configDigest"`
+	ConfigCount           uint64                  `json:"configCount"`
+	Signers               []hexBytes              `json:"signers"`
+	Transmitters          []string                `json:"transmitters"`
+	F                     uint8                   `json:"f"`
+	OnchainConfig         hexBytes                `json:"onchainConfig"`
+	OffchainConfigVersion uint64                  `json:"offchainConfigVersion"`
+	OffchainConfig        hexBytes                `json:"offchainConfig"`
+	Manifest              *configbuilder.Manifest `json:"manifest"`
+}
+
+func (c contractConfigFile) toContractConfig() (types.ContractConfig, error) {
+	var configDigest types.ConfigDigest
+	if len(c.ConfigDigest) != 0 {
+		var err error
+		configDigest, err = types.BytesToConfigDigest(c.ConfigDigest)
+		if err != nil {
+			return types.ContractConfig{}, err
+		}
+	}
+	signers := []types.OnchainPublicKey{}
+	for _, signer := range c.Signers {
+		signers = append(signers, types.OnchainPublicKey(signer))
+	}
+	transmitters := []types.Account{}
+	for _, transmitter := range c.Transmitters {
+		transmitters = append(transmitters, types.Account(transmitter))
+	}
+	configCount := c.ConfigCount
+	if configCount == 0 && c.Manifest != nil {
+		configCount = c.Manifest.Inputs.ConfigCount
+	}
+	return types.ContractConfig{
+		configDigest,
+		configCount,
+		signers,
+		transmitters,
+		c.F,
+		c.OnchainConfig,
+		c.OffchainConfigVersion,
+		c.OffchainConfig,
+	}, nil
+}
+
+// readContractConfig reads a contractConfigFile from path, or a raw ConfigSet
+// log if event is set.
+func readContractConfig(path string, event bool) (types.ContractConfig, error) {
+	data, err := readFileOrStdin(path)
+	if err != nil {
+		return types.ContractConfig{}, err
+	}
+	if event {
+		var log gethtypes.Log
+		if err := json.Unmarshal(data, &log); err != nil {
+			return types.ContractConfig{}, fmt.Errorf("could not parse log: %w", err)
+		}
+		return evmutil.ContractConfigFromConfigSetLog(log)
+	}
+	var ccf contractConfigFile
+	if err := decodeJSONOrYAML(data, &ccf); err != nil {
+		return types.ContractConfig{}, fmt.Errorf("could not parse %s: %w", path, err)
+	}
+	return ccf.toContractConfig()
+}
+
+func runInspect(args []string) error {
+	flags := flag.NewFlagSet("inspect", flag.ContinueOnError)
+	format := flags.String("format", "json", "output format, json or yaml")
+	event := flags.Bool("event", false, "FILE contains a raw ConfigSet log in JSON, as returned by eth_getLogs")
+	keystorePath := flags.String("keystore", "", "offchain keyring keystore; if set, check that the oracle can decrypt the shared secret")
+	passphrasePath := flags.String("passphrase-file", "", "file containing the keystore passphrase")
+	oracleID := flags.Int("oracle-id", -1, "index of the keystore's oracle in the config; required with -keystore")
+	attestationsPath := flags.String("attestations", "", "file with identity attestations; if set, verify each oracle's attestation")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: ocrconfig inspect [-format json|yaml] [-event] [-keystore FILE -passphrase-file FILE -oracle-id N] [-attestations FILE] FILE")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return fmt.Errorf("expected exactly one input file")
+	}
+
+	cc, err := readContractConfig(flags.Arg(0), *event)
+	if err != nil {
+		return err
+	}
+
+	var inspection *configinspector.Inspection
+	if *keystorePath != "" {
+		if *oracleID < 0 || *oracleID > 255 {
+			return fmt.Errorf("-oracle-id must be set to a value between 0 and 255 when using -keystore")
+		}
+		passphrase, err := os.ReadFile(*passphrasePath)
+		if err != nil {
+			return fmt.Errorf("could not read passphrase: %w", err)
+		}
+		keyring, err := offchainkeyring.ReadFile(*keystorePath, strings.TrimRight(string(passphrase), "\r\n"))
+		if err != nil {
+			return err
+		}
+		inspection, err = configinspector.InspectWithKeyring(cc, commontypes.OracleID(*oracleID), keyring)
+		if err != nil {
+			return err
+		}
+	} else {
+		inspection, err = configinspector.Inspect(cc)
+		if err != nil {
+			return err
+		}
+	}
+	if *attestationsPath != "" {
+		attestations, err := readAttestations(*attestationsPath)
+		if err != nil {
+			return err
+		}
+		inspection.VerifyAttestations(attestations, onchainVerifier)
+	}
+	return writeOutput(os.Stdout, *format, inspection)
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	gethtypes "github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/smartcontractkit/libocr/offchainreporting2plus/chains/evmutil"
+	"github.com/smartcontractkit/libocr/offchainreporting2plus/configinspector"
+)
+
+// The fixtures in testdata were produced as follows:
+//   - ocr2.json and ocr3.json from the setConfig arguments in the output of
+//     generate for testInput(protocol, 4), with the median onchain config for
+//     OCR2, plus the input's configCount. They predate generate's -seed flag
+//     and have no manifest.
+//   - configset-log.json by calling setConfig with the arguments in ocr2.json
+//     on an OCR2Aggregator deployed on a simulated backend (chain ID 1337) that
+//     already had one config, and fetching the resulting log with
+//     eth_getLogs.
+
+func TestInspectFixtures(t *testing.T) {
+	for _, test := range []struct {
+		path             string
+		expectedProtocol string
+	}{
+		{"testdata/ocr2.json", configinspector.ProtocolOCR2},
+		{"testdata/ocr3.json", configinspector.ProtocolOCR3},
+	} {
+		cc, err := readContractConfig(test.path, false)
+		if err != nil {
+			t.Fatalf("%s: %v", test.path, err)
+		}
+		digester := evmutil.EVMOffchainConfigDigester{1337, common.HexToAddress(testContractAddress)}
+		if digest, err := digester.ConfigDigest(cc); err != nil || digest != cc.ConfigDigest {
+			t.Errorf("%s: config digest %v does not match computed digest %v, %v", test.path, cc.ConfigDigest, digest, err)
+		}
+
+		inspection, err := configinspector.Inspect(cc)
+		if err != nil {
+			t.Fatalf("%s: %v", test.path, err)
+		}
+		if inspection.Protocol != test.expectedProtocol || inspection.ConfigCount != 2 || inspection.N != 4 || inspection.F != 1 || inspection.ValidationError != "" {
+			t.Errorf("%s: unexpected inspection %+v", test.path, inspection)
+		}
+		input := testInput(test.expectedProtocol, 4)
+		for i, identity := range inspection.OracleIdentities {
+			if identity.PeerID != input.Oracles[i].PeerID || string(identity.TransmitAccount) != input.Oracles[i].TransmitAccount {
+				t.Errorf("%s: oracle %v has unexpected identity %+v", test.path, i, identity)
+			}
+		}
+	}
+}
+
+func TestInspectConfigSetLogFixture(t *testing.T) {
+	fromLog, err := readContractConfig("testdata/configset-log.json", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fromFile, err := readContractConfig("testdata/ocr2.json", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The contract computed the digest for its own address
+	data, err := os.ReadFile("testdata/configset-log.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var log gethtypes.Log
+	if err := json.Unmarshal(data, &log); err != nil {
+		t.Fatal(err)
+	}
+	digester := evmutil.EVMOffchainConfigDigester{1337, log.Address}
+	if digest, err := digester.ConfigDigest(fromLog); err != nil || digest != fromLog.ConfigDigest {
+		t.Fatalf("config digest %v from log does not match computed digest %v, %v", fromLog.ConfigDigest, digest, err)
+	}
+
+	fromLog.ConfigDigest = fromFile.ConfigDigest
+	if !reflect.DeepEqual(fromLog, fromFile) {
+		t.Fatalf("config from log differs from config passed to setConfig\nlog:  %+v\nfile: %+v", fromLog, fromFile)
+	}
+	inspection, err := configinspector.Inspect(fromLog)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if inspection.Protocol != configinspector.ProtocolOCR2 || inspection.ValidationError != "" {
+		t.Fatalf("unexpected inspection %+v", inspection)
+	}
+}
+
+func TestReadContractConfigRejectsInvalidInput(t *testing.T) {
+	logData, err := os.ReadFile("testdata/configset-log.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	configData, err := os.ReadFile("testdata/ocr3.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A log emitted by the contract, but not a ConfigSet log
+	otherTopic := strings.Replace(string(logData), "0x1591690b8638f5fb2dbec82ac741805ac5da8b45dc5263f4875b0496fdce4e05", "0x"+strings.Repeat("00", 32), 1)
+	malformedData := strings.Replace(string(logData), `"data": "0x`, `"data": "0x00`, 1)
+	shortDigest := strings.Replace(string(configData), `"configDigest": "0x00`, `"configDigest": "0x`, 1)
+	for _, data := range []string{otherTopic, malformedData} {
+		if data == string(logData) {
+			t.Fatalf("replacement didn't apply")
+		}
+	}
+	if shortDigest == string(configData) {
+		t.Fatalf("replacement didn't apply")
+	}
+
+	dir := t.TempDir()
+	for _, test := range []struct {
+		name  string
+		data  string
+		event bool
+	}{
+		{"other event", otherTopic, true},
+		{"malformed data", malformedData, true},
+		{"config file as log", string(configData), true},
+		{"log as config file", string(logData), false},
+		{"short config digest", shortDigest, false},
+	} {
+		path := filepath.Join(dir, "invalid")
+		if err := os.WriteFile(path, []byte(test.data), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if cc, err := readContractConfig(path, test.event); err == nil {
+			t.Errorf("%s: expected error, got %+v", test.name, cc)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/smartcontractkit/libocr/offchainreporting2plus/configlint"
+	"github.com/smartcontractkit/libocr/offchainreporting2plus/ocr3types"
+)
+
+func runLint(args []string) error {
+	flags := flag.NewFlagSet("lint", flag.ContinueOnError)
+	format := flags.String("format", "json", "output format, json or yaml")
+	event := flags.Bool("event", false, "FILE contains a raw ConfigSet log in JSON, as returned by eth_getLogs")
+	failOn := flags.String("fail-on", "error", "exit with non-zero status if there is a finding of this severity or higher, info, warning or error")
+	epochStartLatency := flags.Duration("epoch-start-latency", configlint.DefaultExpectedEpochStartLatency, "expected latency until followers receive a new leader's first message (OCR3)")
+	maxLeaderTenure := flags.Duration("max-leader-tenure", configlint.DefaultMaxLeaderTenure, "upper bound on RMax*DeltaRound")
+	maxBytesRate := flags.Float64("max-bytes-rate", configlint.DefaultMaxBytesRatePerOracle, "upper bound on the estimated bytes per second received from each oracle (OCR3)")
+	var pluginLimits ocr3types.ReportingPluginLimits
+	flags.IntVar(&pluginLimits.MaxQueryLength, "max-query-length", 0, "reporting plugin limit, enables bandwidth estimation (OCR3)")
+	flags.IntVar(&pluginLimits.MaxObservationLength, "max-observation-length", 0, "reporting plugin limit, enables bandwidth estimation (OCR3)")
+	flags.IntVar(&pluginLimits.MaxOutcomeLength, "max-outcome-length", 0, "reporting plugin limit, enables bandwidth estimation (OCR3)")
+	flags.IntVar(&pluginLimits.MaxReportLength, "max-report-length", 0, "reporting plugin limit, enables bandwidth estimation (OCR3)")
+	flags.IntVar(&pluginLimits.MaxReportCount, "max-report-count", 0, "reporting plugin limit, enables bandwidth estimation (OCR3)")
+	maxSignatureLength := flags.Int("max-signature-length", 0, "maximum length of an onchain signature, used for bandwidth estimation (OCR3)")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: ocrconfig lint [-format json|yaml] [-event] [-fail-on info|warning|error] [OPTIONS] FILE")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return fmt.Errorf("expected exactly one input file")
+	}
+
+	var threshold configlint.Severity
+	switch *failOn {
+	case "info":
+		threshold = configlint.SeverityInfo
+	case "warning":
+		threshold = configlint.SeverityWarning
+	case "error":
+		threshold = configlint.SeverityError
+	default:
+		return fmt.Errorf("unknown severity %q for -fail-on, expected info, warning or error", *failOn)
+	}
+
+	cc, err := readContractConfig(flags.Arg(0), *event)
+	if err != nil {
+		return err
+	}
+
+	opts := configlint.Options{
+		*epochStartLatency,
+		*maxLeaderTenure,
+		nil,
+		*maxSignatureLength,
+		*maxBytesRate,
+	}
+	if pluginLimits != (ocr3types.ReportingPluginLimits{}) {
+		opts.OCR3ReportingPluginLimits = &pluginLimits
+	}
+	findings := configlint.Lint(cc, opts)
+	if findings == nil {
+		findings = []configlint.Finding{}
+	}
+	if err := writeOutput(os.Stdout, *format, findings); err != nil {
+		return err
+	}
+	if max := configlint.MaxSeverity(findings); max >= threshold {
+		return fmt.Errorf("found %v finding(s), highest severity is %v", len(findings), max)
+	}
+	return nil
+}
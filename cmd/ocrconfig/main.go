@@ -0,0 +1,206 @@
+// Command ocrconfig generates the arguments for the setConfig function of
+// OCR2, OCR3 and Mercury contracts on EVM chains.
+//
+// Usage:
+//
+//	ocrconfig generate -seed FILE [-format json|yaml] [-attestations FILE] FILE
+//	ocrconfig inspect [-format json|yaml] [-event] [-keystore FILE -passphrase-file FILE -oracle-id N] [-attestations FILE] FILE
+//	ocrconfig lint [-format json|yaml] [-event] [-fail-on info|warning|error] [OPTIONS] FILE
+//	ocrconfig diff [-format text|json|yaml] [-event] [-plugin median] OLD NEW
+//
+// For generate, FILE is a JSON or YAML file (or - for stdin) describing the
+// oracles and the protocol parameters. Durations are written as strings such
+// as "500ms" or "2s", byte strings are hex-encoded with an optional 0x prefix.
+// In YAML, quote hex values so that they are not interpreted as numbers. An
+// example for OCR2:
+//
+//	protocol: ocr2
+//	chainID: 1
+//	contractAddress: "0x..."
+//	configCount: 1 # configCount of the contract after setConfig
+//	f: 1
+//	oracles:
+//	  - offchainPublicKey: "..."
+//	    onchainPublicKey: "0x..." # signer address
+//	    peerID: "12D3KooW..."
+//	    transmitAccount: "0x..."
+//	    configEncryptionPublicKey: "..."
+//	  # ...
+//	deltaProgress: 8s
+//	deltaResend: 5s
+//	deltaRound: 5s
+//	deltaGrace: 500ms
+//	deltaStage: 5s
+//	rMax: 3
+//	s: [1, 1, 1, 1]
+//	reportingPluginConfig: "0x..."
+//	onchainConfig: "0x..."
+//	maxDurationQuery: 0s
+//	maxDurationObservation: 1s
+//	maxDurationReport: 1s
+//	maxDurationShouldAcceptFinalizedReport: 1s
+//	maxDurationShouldTransmitAcceptedReport: 1s
+//
+// OCR3 additionally takes deltaInitial, deltaCertifiedCommitRequest and
+// maxDurationShouldAcceptAttestedReport instead of maxDurationReport and
+// maxDurationShouldAcceptFinalizedReport. Mercury takes the same durations
+// as OCR3 (but only uses maxDurationObservation) and a 32 byte feedID.
+// Mercury transmit accounts are hex-encoded Ed25519 public keys without 0x
+// prefix.
+//
+// The shared secret of the oracles is derived from the seed in the file passed
+// with -seed, a hex-encoded 32 byte value (e.g. from
+// "head -c 32 /dev/urandom | xxd -p -c 32"). Keep the seed as confidential as
+// the shared secret itself and use a fresh one for every config. generate is
+// deterministic: the same input and seed always produce the same output.
+//
+// The output contains signers, transmitters, f, onchainConfig,
+// offchainConfigVersion and offchainConfig, ready to be passed to setConfig,
+// the config digest the contract will compute for the config, and a manifest
+// (see package configbuilder) recording the inputs the config was built from.
+// Anybody with the manifest can check the config with
+// configbuilder.VerifyManifestWithoutSharedSecret, holders of the seed can
+// regenerate it byte-for-byte with configbuilder.VerifyManifest.
+//
+// inspect decodes a contract config into readable JSON or YAML. FILE is
+// either in the output format of generate (configCount is taken from the
+// manifest, if not given), or,
+// with -event, a raw ConfigSet log as returned by eth_getLogs. Given an
+// offchain keyring keystore and the oracle's index in the config, inspect also
+// checks that the oracle can decrypt the config's shared secret.
+//
+// generate and inspect take an optional list of identity attestations (see
+// package identityattestation) in JSON or YAML. generate refuses to produce a
+// config unless every oracle has a valid attestation for exactly its identity.
+// inspect reports for each oracle whether its attestation verifies.
+//
+// lint reads the same input as inspect and reports every problem it finds in
+// the config, each with a severity. It exits with non-zero status if any
+// finding is at least as severe as -fail-on, so that it can gate deployments.
+//
+// diff reads two configs in the same format as inspect and shows which
+// oracles and parameters change between them, and whether the change forces a
+// new shared secret.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"gopkg.in/yaml.v2"
+)
+
+type command struct {
+	name        string
+	description string
+	run         func(args []string) error
+}
+
+func commands() []command {
+	return []command{
+		{"generate", "generate setConfig arguments from a config file", runGenerate},
+		{"inspect", "decode the contents of a contract config", runInspect},
+		{"lint", "report problems and risky settings in a contract config", runLint},
+		{"diff", "show what changes between two contract configs", runDiff},
+	}
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+	for _, cmd := range commands() {
+		if cmd.name == os.Args[1] {
+			if err := cmd.run(os.Args[2:]); err != nil {
+				fmt.Fprintf(os.Stderr, "ocrconfig %s: %v\n", cmd.name, err)
+				os.Exit(1)
+			}
+			return
+		}
+	}
+	usage()
+	os.Exit(2)
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: ocrconfig COMMAND [ARGS]")
+	fmt.Fprintln(os.Stderr, "commands:")
+	for _, cmd := range commands() {
+		fmt.Fprintf(os.Stderr, "  %-12s %s\n", cmd.name, cmd.description)
+	}
+}
+
+func runGenerate(args []string) error {
+	flags := flag.NewFlagSet("generate", flag.ContinueOnError)
+	seedPath := flags.String("seed", "", "file containing the hex-encoded 32 byte seed the shared secret is derived from; required")
+	format := flags.String("format", "json", "output format, json or yaml")
+	attestationsPath := flags.String("attestations", "", "file with identity attestations; if set, refuse to generate a config unless every oracle's attestation verifies")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: ocrconfig generate -seed FILE [-format json|yaml] [-attestations FILE] FILE")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return fmt.Errorf("expected exactly one input file")
+	}
+	if *seedPath == "" {
+		flags.Usage()
+		return fmt.Errorf("-seed is required")
+	}
+	seed, err := readSeed(*seedPath)
+	if err != nil {
+		return err
+	}
+	input, err := readInput(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	if *attestationsPath != "" {
+		if err := verifyInputAttestations(input, *attestationsPath); err != nil {
+			return err
+		}
+	}
+	output, err := generate(input, seed)
+	if err != nil {
+		return err
+	}
+	return writeOutput(os.Stdout, *format, output)
+}
+
+// writeOutput writes v to w in the given format. YAML output uses the same
+// field names and encodings as JSON output.
+func writeOutput(w io.Writer, format string, v interface{}) error {
+	jsonData, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	switch format {
+	case "json":
+		_, err = fmt.Fprintf(w, "%s\n", jsonData)
+		return err
+	case "yaml":
+		// Unmarshal into MapSlices to preserve the order of fields
+		var generic interface{} = &yaml.MapSlice{}
+		if len(jsonData) != 0 && jsonData[0] == '[' {
+			generic = &[]yaml.MapSlice{}
+		}
+		if err := yaml.Unmarshal(jsonData, generic); err != nil {
+			return err
+		}
+		yamlData, err := yaml.Marshal(generic)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(yamlData)
+		return err
+	default:
+		return fmt.Errorf("unknown output format %q, expected json or yaml", format)
+	}
+}
@@ -42,6 +42,11 @@ type EndpointConfigV2 struct {
 	// IncomingMessageBufferSize to give the remote enough space to process
 	// them all in case we regained connection and now send a bunch at once
 	OutgoingMessageBufferSize int
+
+	// MessageCompression is the compression used for messages to remotes
+	// that have enabled the same compression. Messages to other remotes are
+	// sent uncompressed.
+	MessageCompression ragep2p.StreamCompression
 }
 
 // ocrEndpointV2 represents a member of a particular feed oracle group
@@ -172,7 +177,7 @@ func (o *ocrEndpointV2) Start() error {
 			continue
 		}
 		streamName := streamNameFromConfigDigest(o.configDigest)
-		stream, err := o.host.NewStream(
+		stream, err := o.host.NewStreamWithOptions(
 			pid,
 			streamName,
 			o.config.OutgoingMessageBufferSize,
@@ -186,6 +191,10 @@ func (o *ocrEndpointV2) Start() error {
 				o.limits.BytesRatePerOracle,
 				uint32(o.limits.BytesCapacityPerOracle),
 			},
+			ragep2p.StreamOptions{
+				ragep2p.StreamPriority{ragep2p.StreamPriorityClassNormal, 1},
+				o.config.MessageCompression,
+			},
 		)
 		if err != nil {
 			return fmt.Errorf("failed to create stream for oracle %v (peer id: %q): %w", oid, pid, err)
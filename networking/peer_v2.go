@@ -80,7 +80,7 @@ func NewPeer(c PeerConfig) (*concretePeerV2, error) {
 
 	discoverer := ragedisco.NewRagep2pDiscoverer(c.V2DeltaReconcile, announceAddresses, c.V2DiscovererDatabase, metricsRegistererWrapper)
 	host, err := ragep2p.NewHost(
-		ragep2p.HostConfig{c.V2DeltaDial},
+		ragep2p.HostConfig{c.V2DeltaDial, nil},
 		c.PrivKey,
 		c.V2ListenAddresses,
 		discoverer,
@@ -219,6 +219,7 @@ func (p2 *concretePeerV2) newEndpoint(
 		EndpointConfigV2{
 			p2.endpointConfig.IncomingMessageBufferSize,
 			p2.endpointConfig.OutgoingMessageBufferSize,
+			p2.endpointConfig.MessageCompression,
 		},
 		f,
 		limits,
@@ -157,7 +157,7 @@ func (r *Ragep2pDiscoverer) connectivityLoop() {
 					messagesLimit.Rate * maxMessageLength,
 					messagesLimit.Capacity * maxMessageLength,
 				}
-				s, err := r.host.NewStream(
+				s, err := r.host.NewStreamWithOptions(
 					c.peerID,
 					"ragedisco/v1",
 					bufferSize,
@@ -165,6 +165,12 @@ func (r *Ragep2pDiscoverer) connectivityLoop() {
 					maxMessageLength,
 					messagesLimit,
 					bytesLimit,
+					ragep2p.StreamOptions{
+						// discovery traffic is small and infrequent, no need
+						// to have it wait behind other streams
+						ragep2p.StreamPriority{ragep2p.StreamPriorityClassHigh, 1},
+						ragep2p.StreamCompressionNone,
+					},
 				)
 				if err != nil {
 					logger.Warn("NewStream failed!", reason(err))
@@ -0,0 +1,230 @@
+package simnet
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/smartcontractkit/libocr/commontypes"
+	"github.com/smartcontractkit/libocr/internal/loghelper"
+	"github.com/smartcontractkit/libocr/offchainreporting2plus/types"
+	"github.com/smartcontractkit/libocr/subprocesses"
+)
+
+type endpointFactory struct {
+	network *Network
+	peerID  string
+}
+
+var _ types.BinaryNetworkEndpointFactory = (*endpointFactory)(nil)
+
+func (f *endpointFactory) NewEndpoint(
+	configDigest types.ConfigDigest,
+	peerIDs []string,
+	_ []commontypes.BootstrapperLocator,
+	_ int,
+	limits types.BinaryNetworkEndpointLimits,
+) (commontypes.BinaryNetworkEndpoint, error) {
+	e, err := newEndpoint(f.network, configDigest, f.peerID, peerIDs, limits)
+	if err != nil {
+		return nil, err
+	}
+	if err := f.network.register(e); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+func (f *endpointFactory) PeerID() string {
+	return f.peerID
+}
+
+type endpointState int
+
+const (
+	_ endpointState = iota
+	endpointUnstarted
+	endpointStarted
+	endpointClosed
+)
+
+type receiveResult int
+
+const (
+	_ receiveResult = iota
+	receiveOK
+	receiveNotStarted
+	receiveUnknownSender
+	receiveTooLong
+	receiveBufferFull
+)
+
+type endpoint struct {
+	network      *Network
+	configDigest types.ConfigDigest
+	ownPeerID    string
+	ownOracleID  commontypes.OracleID
+	peerIDs      []string
+	oracleIDs    map[string]commontypes.OracleID
+	limits       types.BinaryNetworkEndpointLimits
+	logger       loghelper.LoggerWithContext
+
+	stateMu sync.RWMutex
+	state   endpointState
+
+	chIncoming chan commontypes.BinaryMessageWithSender
+	chClose    chan struct{}
+	subs       subprocesses.Subprocesses
+
+	// recv is exposed to clients of this network endpoint
+	recv chan commontypes.BinaryMessageWithSender
+}
+
+var _ commontypes.BinaryNetworkEndpoint = (*endpoint)(nil)
+
+func newEndpoint(
+	network *Network,
+	configDigest types.ConfigDigest,
+	ownPeerID string,
+	peerIDs []string,
+	limits types.BinaryNetworkEndpointLimits,
+) (*endpoint, error) {
+	oracleIDs := make(map[string]commontypes.OracleID, len(peerIDs))
+	for i, peerID := range peerIDs {
+		if _, ok := oracleIDs[peerID]; ok {
+			return nil, fmt.Errorf("duplicate peer ID %q", peerID)
+		}
+		oracleIDs[peerID] = commontypes.OracleID(i)
+	}
+	ownOracleID, ok := oracleIDs[ownPeerID]
+	if !ok {
+		return nil, fmt.Errorf("peer ID %q is not present in given peerIDs", ownPeerID)
+	}
+
+	return &endpoint{
+		network,
+		configDigest,
+		ownPeerID,
+		ownOracleID,
+		append([]string{}, peerIDs...),
+		oracleIDs,
+		limits,
+		network.logger.MakeChild(commontypes.LogFields{
+			"configDigest": configDigest.Hex(),
+			"oracleID":     ownOracleID,
+		}),
+
+		sync.RWMutex{},
+		endpointUnstarted,
+
+		make(chan commontypes.BinaryMessageWithSender, network.config.IncomingMessageBufferSize),
+		make(chan struct{}),
+		subprocesses.Subprocesses{},
+
+		make(chan commontypes.BinaryMessageWithSender),
+	}, nil
+}
+
+func (e *endpoint) Start() error {
+	e.stateMu.Lock()
+	defer e.stateMu.Unlock()
+	if e.state != endpointUnstarted {
+		return fmt.Errorf("cannot start simnet endpoint that is not unstarted, state was: %d", e.state)
+	}
+	e.state = endpointStarted
+
+	e.subs.Go(e.run)
+	e.logger.Debug("SimNet: endpoint started", nil)
+	return nil
+}
+
+func (e *endpoint) run() {
+	for {
+		select {
+		case msg := <-e.chIncoming:
+			select {
+			case e.recv <- msg:
+			case <-e.chClose:
+				return
+			}
+		case <-e.chClose:
+			return
+		}
+	}
+}
+
+// Close may be called even if Start was never called.
+func (e *endpoint) Close() error {
+	// Deregister before acquiring stateMu: the network calls receive while
+	// holding its own lock, so we must not hold stateMu while acquiring it.
+	e.network.deregister(e)
+
+	e.stateMu.Lock()
+	defer e.stateMu.Unlock()
+	if e.state == endpointClosed {
+		return fmt.Errorf("simnet endpoint already closed")
+	}
+	e.state = endpointClosed
+
+	close(e.chClose)
+	e.subs.Wait()
+	close(e.recv)
+	e.logger.Debug("SimNet: endpoint closed", nil)
+	return nil
+}
+
+func (e *endpoint) SendTo(payload []byte, to commontypes.OracleID) {
+	e.stateMu.RLock()
+	state := e.state
+	e.stateMu.RUnlock()
+	if state != endpointStarted {
+		e.logger.Error("Send on non-started simnet endpoint", commontypes.LogFields{"state": state})
+		return
+	}
+	if int(to) >= len(e.peerIDs) {
+		e.logger.Error("Send to unknown oracle", commontypes.LogFields{"to": to})
+		return
+	}
+
+	// Copy payload so that callers may reuse their buffer.
+	payload = append([]byte{}, payload...)
+
+	if to == e.ownOracleID {
+		// Messages to self never traverse the network.
+		e.receive(e.ownPeerID, payload)
+		return
+	}
+	e.network.send(e.configDigest, e.ownPeerID, e.peerIDs[to], payload)
+}
+
+func (e *endpoint) Broadcast(payload []byte) {
+	for i := range e.peerIDs {
+		e.SendTo(payload, commontypes.OracleID(i))
+	}
+}
+
+func (e *endpoint) Receive() <-chan commontypes.BinaryMessageWithSender {
+	return e.recv
+}
+
+// receive hands a message that has traversed the network to the endpoint.
+// Never blocks.
+func (e *endpoint) receive(fromPeerID string, payload []byte) receiveResult {
+	e.stateMu.RLock()
+	defer e.stateMu.RUnlock()
+	if e.state != endpointStarted {
+		return receiveNotStarted
+	}
+	sender, ok := e.oracleIDs[fromPeerID]
+	if !ok {
+		return receiveUnknownSender
+	}
+	if e.limits.MaxMessageLength > 0 && len(payload) > e.limits.MaxMessageLength {
+		return receiveTooLong
+	}
+	select {
+	case e.chIncoming <- commontypes.BinaryMessageWithSender{payload, sender}:
+		return receiveOK
+	default:
+		return receiveBufferFull
+	}
+}
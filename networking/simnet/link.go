@@ -0,0 +1,105 @@
+package simnet
+
+import (
+	"math"
+	"math/rand"
+	"time"
+)
+
+// LatencyDistribution describes how long a message takes to traverse a link.
+// Implementations must only draw randomness from the provided rng, so that a
+// Network's behaviour is reproducible given its Seed.
+type LatencyDistribution interface {
+	Sample(rng *rand.Rand) time.Duration
+}
+
+// ConstantLatency delays every message by the same amount.
+type ConstantLatency time.Duration
+
+func (c ConstantLatency) Sample(*rand.Rand) time.Duration {
+	return time.Duration(c)
+}
+
+// UniformLatency delays messages by a duration drawn uniformly from [Min, Max].
+type UniformLatency struct {
+	Min time.Duration
+	Max time.Duration
+}
+
+func (u UniformLatency) Sample(rng *rand.Rand) time.Duration {
+	if u.Max <= u.Min {
+		return u.Min
+	}
+	return u.Min + time.Duration(rng.Int63n(int64(u.Max-u.Min)+1))
+}
+
+// NormalLatency delays messages by a duration drawn from a normal distribution
+// with the given Mean and StdDev. Samples are clamped to [0, Mean+4*StdDev] so
+// that a single unlucky draw cannot stall a link indefinitely.
+type NormalLatency struct {
+	Mean   time.Duration
+	StdDev time.Duration
+}
+
+func (n NormalLatency) Sample(rng *rand.Rand) time.Duration {
+	d := time.Duration(rng.NormFloat64()*float64(n.StdDev)) + n.Mean
+	return clamp(d, 0, n.Mean+4*n.StdDev)
+}
+
+// ExponentialLatency delays messages by Base plus an exponentially distributed
+// extra delay with the given Mean. This roughly models a link that is usually
+// fast but has a long tail. The extra delay is clamped to 20*Mean.
+type ExponentialLatency struct {
+	Base time.Duration
+	Mean time.Duration
+}
+
+func (e ExponentialLatency) Sample(rng *rand.Rand) time.Duration {
+	extra := time.Duration(rng.ExpFloat64() * float64(e.Mean))
+	return e.Base + clamp(extra, 0, 20*e.Mean)
+}
+
+func clamp(d, lo, hi time.Duration) time.Duration {
+	return time.Duration(math.Max(float64(lo), math.Min(float64(hi), float64(d))))
+}
+
+// LinkConfig describes the faults injected on a directed link between two
+// peers. The zero value is a perfect link: no latency, no loss, no
+// duplication, FIFO delivery.
+type LinkConfig struct {
+	// Latency is sampled independently for every message. A nil Latency means
+	// messages are delivered as soon as possible.
+	Latency LatencyDistribution
+
+	// DropProbability is the probability in [0, 1] that a message is silently
+	// dropped.
+	DropProbability float64
+
+	// DuplicateProbability is the probability in [0, 1] that a message is
+	// delivered twice. The duplicate has its own independently sampled latency.
+	DuplicateProbability float64
+
+	// Messages on a link are delivered in the order they were sent, unless
+	// they are picked for reordering. ReorderProbability is the probability in
+	// [0, 1] that a message ignores the FIFO constraint and is delivered purely
+	// based on its own sampled latency, possibly overtaking earlier messages.
+	// Reordering only has an observable effect if Latency is non-constant.
+	ReorderProbability float64
+}
+
+func (c LinkConfig) sampleLatency(rng *rand.Rand) time.Duration {
+	if c.Latency == nil {
+		return 0
+	}
+	d := c.Latency.Sample(rng)
+	if d < 0 {
+		return 0
+	}
+	return d
+}
+
+// link identifies a directed link from one peer to another.
+type link struct {
+	from string
+	to   string
+}
@@ -0,0 +1,38 @@
+package simnet
+
+import (
+	"time"
+)
+
+// ScheduledPartition splits the network into isolated groups of peers for the
+// interval [Start, Start+Duration), measured from the creation of the
+// Network. Peers in the same group can talk to each other, peers in different
+// groups cannot. Peers not listed in any group are isolated from everyone.
+type ScheduledPartition struct {
+	Start    time.Duration
+	Duration time.Duration
+	Groups   [][]string
+}
+
+func (p ScheduledPartition) activeAt(elapsed time.Duration) bool {
+	return p.Start <= elapsed && elapsed < p.Start+p.Duration
+}
+
+// partition maps every peer to the index of its group.
+type partition map[string]int
+
+func newPartition(groups [][]string) partition {
+	p := partition{}
+	for i, group := range groups {
+		for _, peerID := range group {
+			p[peerID] = i
+		}
+	}
+	return p
+}
+
+func (p partition) connected(a, b string) bool {
+	ga, okA := p[a]
+	gb, okB := p[b]
+	return okA && okB && ga == gb
+}
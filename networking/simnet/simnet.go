@@ -0,0 +1,396 @@
+// Package simnet provides an in-process, fault-injecting implementation of
+// types.BinaryNetworkEndpointFactory. It allows running a full committee of
+// oracles inside a single process (e.g. in a unit test) without ragep2p or
+// sockets, while still exercising the protocol under adverse network
+// conditions: latency, message loss, duplication, reordering and partitions.
+//
+// simnet does not authenticate anything and must never be used in production.
+package simnet
+
+import (
+	"container/heap"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/smartcontractkit/libocr/clock"
+	"github.com/smartcontractkit/libocr/commontypes"
+	"github.com/smartcontractkit/libocr/internal/loghelper"
+	"github.com/smartcontractkit/libocr/offchainreporting2plus/types"
+	"github.com/smartcontractkit/libocr/subprocesses"
+)
+
+// Config configures a Network.
+type Config struct {
+	// Seed for the Network's source of randomness. All fault decisions are
+	// drawn from a single source seeded with Seed.
+	Seed int64
+
+	// DefaultLink is used for every directed link that has no override set
+	// through Network.SetLink.
+	DefaultLink LinkConfig
+
+	// IncomingMessageBufferSize is the number of delivered messages an endpoint
+	// buffers before its consumer reads them. Any additional messages are
+	// dropped. Defaults to defaultIncomingMessageBufferSize if zero.
+	IncomingMessageBufferSize int
+
+	// Partitions are applied automatically at the scheduled times. A partition
+	// set through Network.Partition takes precedence over scheduled ones.
+	Partitions []ScheduledPartition
+
+	// Clock determines when in-flight messages are delivered and which
+	// scheduled partitions are active. May be nil, in which case the wall
+	// clock is used. Use the same *clock.VirtualClock here and for the oracles
+	// to simulate a committee faster than real time.
+	Clock clock.Clock
+
+	// Logger is optional.
+	Logger commontypes.Logger
+}
+
+const defaultIncomingMessageBufferSize = 100
+
+// Stats counts what happened to messages sent over a Network.
+type Stats struct {
+	Sent       uint64
+	Delivered  uint64
+	Duplicated uint64
+
+	DroppedRandomly       uint64
+	DroppedByPartition    uint64
+	DroppedTooLong        uint64
+	DroppedNoRecipient    uint64
+	DroppedBufferOverflow uint64
+}
+
+// Network connects the endpoints created by all of its EndpointFactories.
+// Endpoints talk to each other iff they were created for the same config
+// digest. All methods are thread-safe.
+type Network struct {
+	config Config
+	clock  clock.Clock
+	logger loghelper.LoggerWithContext
+	start  time.Time
+
+	mu              sync.Mutex
+	rng             *rand.Rand
+	links           map[link]LinkConfig
+	lastDelivery    map[link]time.Time
+	manualPartition partition
+	endpoints       map[endpointKey]*endpoint
+	queue           deliveryQueue
+	seq             uint64
+	stats           Stats
+	closed          bool
+
+	chWake  chan struct{}
+	chClose chan struct{}
+	subs    subprocesses.Subprocesses
+}
+
+type endpointKey struct {
+	configDigest types.ConfigDigest
+	peerID       string
+}
+
+// NewNetwork creates a Network and starts its delivery loop. Call Close when
+// done with it.
+func NewNetwork(config Config) *Network {
+	if config.IncomingMessageBufferSize == 0 {
+		config.IncomingMessageBufferSize = defaultIncomingMessageBufferSize
+	}
+	var logger loghelper.LoggerWithContext
+	if config.Logger != nil {
+		logger = loghelper.MakeRootLoggerWithContext(config.Logger).MakeChild(commontypes.LogFields{
+			"id": "SimNet",
+		})
+	} else {
+		logger = loghelper.MakeRootLoggerWithContext(nopLogger{})
+	}
+
+	clk := clock.OrReal(config.Clock)
+	n := &Network{
+		config,
+		clk,
+		logger,
+		clk.Now(),
+
+		sync.Mutex{},
+		rand.New(rand.NewSource(config.Seed)),
+		map[link]LinkConfig{},
+		map[link]time.Time{},
+		nil,
+		map[endpointKey]*endpoint{},
+		nil,
+		0,
+		Stats{},
+		false,
+
+		make(chan struct{}, 1),
+		make(chan struct{}),
+		subprocesses.Subprocesses{},
+	}
+	n.subs.Go(n.run)
+	return n
+}
+
+// EndpointFactory returns a BinaryNetworkEndpointFactory for the peer with the
+// given ID. peerID is an arbitrary string, but must match the peer IDs that
+// appear in the contract configuration for the oracle to find itself.
+func (n *Network) EndpointFactory(peerID string) types.BinaryNetworkEndpointFactory {
+	return &endpointFactory{n, peerID}
+}
+
+// SetLink overrides the fault configuration of the directed link from -> to.
+func (n *Network) SetLink(from, to string, config LinkConfig) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.links[link{from, to}] = config
+}
+
+// SetLinkBidirectional overrides the fault configuration of the links a -> b
+// and b -> a.
+func (n *Network) SetLinkBidirectional(a, b string, config LinkConfig) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.links[link{a, b}] = config
+	n.links[link{b, a}] = config
+}
+
+// Partition immediately splits the network into the given groups until Heal
+// is called. See ScheduledPartition for the semantics of groups. Messages
+// already in flight between peers that end up in different groups are lost.
+func (n *Network) Partition(groups ...[]string) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.manualPartition = newPartition(groups)
+	n.logger.Info("SimNet: partitioned", commontypes.LogFields{"groups": groups})
+}
+
+// Heal removes a partition set through Partition. Scheduled partitions are
+// unaffected.
+func (n *Network) Heal() {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.manualPartition = nil
+	n.logger.Info("SimNet: healed", nil)
+}
+
+// Stats returns a snapshot of the Network's message counters.
+func (n *Network) Stats() Stats {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.stats
+}
+
+// Close stops the delivery loop. Messages still in flight are discarded.
+// Endpoints must be closed separately.
+func (n *Network) Close() error {
+	n.mu.Lock()
+	if n.closed {
+		n.mu.Unlock()
+		return fmt.Errorf("SimNet already closed")
+	}
+	n.closed = true
+	n.mu.Unlock()
+
+	close(n.chClose)
+	n.subs.Wait()
+	return nil
+}
+
+func (n *Network) register(e *endpoint) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.closed {
+		return fmt.Errorf("SimNet is closed")
+	}
+	key := endpointKey{e.configDigest, e.ownPeerID}
+	if _, ok := n.endpoints[key]; ok {
+		return fmt.Errorf("peer %q already has an endpoint for config digest %s", e.ownPeerID, e.configDigest)
+	}
+	n.endpoints[key] = e
+	return nil
+}
+
+func (n *Network) deregister(e *endpoint) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	key := endpointKey{e.configDigest, e.ownPeerID}
+	if n.endpoints[key] == e {
+		delete(n.endpoints, key)
+	}
+}
+
+// connected must be called with n.mu held.
+func (n *Network) connected(from, to string, now time.Time) bool {
+	if n.manualPartition != nil {
+		return n.manualPartition.connected(from, to)
+	}
+	elapsed := now.Sub(n.start)
+	for _, p := range n.config.Partitions {
+		if p.activeAt(elapsed) {
+			return newPartition(p.Groups).connected(from, to)
+		}
+	}
+	return true
+}
+
+// linkConfig must be called with n.mu held.
+func (n *Network) linkConfig(l link) LinkConfig {
+	if c, ok := n.links[l]; ok {
+		return c
+	}
+	return n.config.DefaultLink
+}
+
+func (n *Network) send(configDigest types.ConfigDigest, from, to string, payload []byte) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	n.stats.Sent++
+	now := n.clock.Now()
+	l := link{from, to}
+	cfg := n.linkConfig(l)
+
+	if !n.connected(from, to, now) {
+		n.stats.DroppedByPartition++
+		return
+	}
+	if n.rng.Float64() < cfg.DropProbability {
+		n.stats.DroppedRandomly++
+		return
+	}
+
+	copies := 1
+	if n.rng.Float64() < cfg.DuplicateProbability {
+		n.stats.Duplicated++
+		copies = 2
+	}
+
+	for i := 0; i < copies; i++ {
+		at := now.Add(cfg.sampleLatency(n.rng))
+		reorder := n.rng.Float64() < cfg.ReorderProbability
+		if !reorder {
+			if last, ok := n.lastDelivery[l]; ok && at.Before(last) {
+				at = last
+			}
+			n.lastDelivery[l] = at
+		}
+		n.seq++
+		heap.Push(&n.queue, delivery{
+			at,
+			n.seq,
+			endpointKey{configDigest, to},
+			from,
+			payload,
+		})
+	}
+
+	select {
+	case n.chWake <- struct{}{}:
+	default:
+	}
+}
+
+// deliver must be called with n.mu held.
+func (n *Network) deliver(d delivery, now time.Time) {
+	if !n.connected(d.fromPeerID, d.to.peerID, now) {
+		n.stats.DroppedByPartition++
+		return
+	}
+	e, ok := n.endpoints[d.to]
+	if !ok {
+		n.stats.DroppedNoRecipient++
+		return
+	}
+	switch e.receive(d.fromPeerID, d.payload) {
+	case receiveOK:
+		n.stats.Delivered++
+	case receiveTooLong:
+		n.stats.DroppedTooLong++
+	case receiveUnknownSender, receiveNotStarted:
+		n.stats.DroppedNoRecipient++
+	case receiveBufferFull:
+		n.stats.DroppedBufferOverflow++
+	}
+}
+
+func (n *Network) run() {
+	timer := n.clock.NewTimer(0)
+	defer timer.Stop()
+	for {
+		n.mu.Lock()
+		now := n.clock.Now()
+		for n.queue.Len() > 0 && !n.queue[0].at.After(now) {
+			n.deliver(heap.Pop(&n.queue).(delivery), now)
+		}
+		var wait time.Duration
+		hasNext := n.queue.Len() > 0
+		if hasNext {
+			wait = n.queue[0].at.Sub(now)
+		}
+		n.mu.Unlock()
+
+		if !timer.Stop() {
+			select {
+			case <-timer.C():
+			default:
+			}
+		}
+		var chTimer <-chan time.Time
+		if hasNext {
+			timer.Reset(wait)
+			chTimer = timer.C()
+		}
+
+		select {
+		case <-chTimer:
+		case <-n.chWake:
+		case <-n.chClose:
+			return
+		}
+	}
+}
+
+type delivery struct {
+	at         time.Time
+	seq        uint64 // tie-breaker, preserves send order for equal at
+	to         endpointKey
+	fromPeerID string
+	payload    []byte
+}
+
+// deliveryQueue implements heap.Interface, ordered by delivery time.
+type deliveryQueue []delivery
+
+func (q deliveryQueue) Len() int { return len(q) }
+
+func (q deliveryQueue) Less(i, j int) bool {
+	if q[i].at.Equal(q[j].at) {
+		return q[i].seq < q[j].seq
+	}
+	return q[i].at.Before(q[j].at)
+}
+
+func (q deliveryQueue) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+
+func (q *deliveryQueue) Push(x interface{}) { *q = append(*q, x.(delivery)) }
+
+func (q *deliveryQueue) Pop() interface{} {
+	old := *q
+	item := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return item
+}
+
+type nopLogger struct{}
+
+func (nopLogger) Trace(string, commontypes.LogFields)    {}
+func (nopLogger) Debug(string, commontypes.LogFields)    {}
+func (nopLogger) Info(string, commontypes.LogFields)     {}
+func (nopLogger) Warn(string, commontypes.LogFields)     {}
+func (nopLogger) Error(string, commontypes.LogFields)    {}
+func (nopLogger) Critical(string, commontypes.LogFields) {}
@@ -0,0 +1,236 @@
+package simnet_test
+
+import (
+	"encoding/binary"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/smartcontractkit/libocr/clock"
+	"github.com/smartcontractkit/libocr/commontypes"
+	"github.com/smartcontractkit/libocr/networking/simnet"
+	"github.com/smartcontractkit/libocr/offchainreporting2plus/types"
+)
+
+var peerIDs = []string{"a", "b"}
+
+type testNetwork struct {
+	network   *simnet.Network
+	clock     *clock.VirtualClock
+	endpoints []commontypes.BinaryNetworkEndpoint
+}
+
+func newTestNetwork(t *testing.T, config simnet.Config) testNetwork {
+	t.Helper()
+	clk := clock.NewVirtualClock(time.Unix(0, 0))
+	config.Clock = clk
+	if config.IncomingMessageBufferSize == 0 {
+		config.IncomingMessageBufferSize = 10000
+	}
+	network := simnet.NewNetwork(config)
+	t.Cleanup(func() { network.Close() })
+
+	endpoints := []commontypes.BinaryNetworkEndpoint{}
+	for _, peerID := range peerIDs {
+		endpoint, err := network.EndpointFactory(peerID).NewEndpoint(types.ConfigDigest{1}, peerIDs, nil, 0, types.BinaryNetworkEndpointLimits{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := endpoint.Start(); err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { endpoint.Close() })
+		endpoints = append(endpoints, endpoint)
+	}
+	return testNetwork{network, clk, endpoints}
+}
+
+// sendNumbered sends n messages from a to b, numbered from first.
+func (tn testNetwork) sendNumbered(first, n int) {
+	for i := first; i < first+n; i++ {
+		msg := make([]byte, 8)
+		binary.BigEndian.PutUint64(msg, uint64(i))
+		tn.endpoints[0].SendTo(msg, 1)
+	}
+}
+
+// receive advances the clock in steps until b has received n messages and
+// returns their numbers.
+func (tn testNetwork) receive(t *testing.T, n int) []uint64 {
+	t.Helper()
+	received := []uint64{}
+	deadline := time.Now().Add(10 * time.Second)
+	for len(received) < n {
+		if time.Now().After(deadline) {
+			t.Fatalf("received only %v of %v messages", len(received), n)
+		}
+		select {
+		case msg := <-tn.endpoints[1].Receive():
+			received = append(received, binary.BigEndian.Uint64(msg.Msg))
+		case <-time.After(time.Millisecond):
+			tn.clock.Advance(10 * time.Millisecond)
+		}
+	}
+	return received
+}
+
+// expectNothing checks that b receives nothing within a short wall clock
+// interval, without advancing the clock.
+func (tn testNetwork) expectNothing(t *testing.T) {
+	t.Helper()
+	select {
+	case msg := <-tn.endpoints[1].Receive():
+		t.Fatalf("unexpectedly received %x", msg.Msg)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestDelay(t *testing.T) {
+	tn := newTestNetwork(t, simnet.Config{DefaultLink: simnet.LinkConfig{Latency: simnet.ConstantLatency(time.Second)}})
+
+	start := tn.clock.Now()
+	tn.sendNumbered(0, 1)
+	tn.clock.Advance(999 * time.Millisecond)
+	tn.expectNothing(t)
+	tn.receive(t, 1)
+	if elapsed := clock.Since(tn.clock, start); elapsed < time.Second {
+		t.Fatalf("message was delivered after %v, expected at least 1s", elapsed)
+	}
+}
+
+func TestFIFOWithoutReordering(t *testing.T) {
+	tn := newTestNetwork(t, simnet.Config{DefaultLink: simnet.LinkConfig{
+		Latency: simnet.UniformLatency{0, time.Second},
+	}})
+	tn.sendNumbered(0, 100)
+	for i, number := range tn.receive(t, 100) {
+		if number != uint64(i) {
+			t.Fatalf("message %v has number %v", i, number)
+		}
+	}
+}
+
+// lossyRun sends n messages over a lossy, duplicating link and returns the
+// resulting stats and the messages received in order.
+func lossyRun(t *testing.T, seed int64, n int) (simnet.Stats, []uint64) {
+	tn := newTestNetwork(t, simnet.Config{
+		Seed: seed,
+		DefaultLink: simnet.LinkConfig{
+			simnet.UniformLatency{0, 100 * time.Millisecond},
+			0.3,
+			0.2,
+			0.5,
+		},
+	})
+	tn.sendNumbered(0, n)
+	// Every decision is made when sending, so the stats are final here
+	stats := tn.network.Stats()
+	received := tn.receive(t, int(stats.Sent-stats.DroppedRandomly+stats.Duplicated))
+	tn.expectNothing(t)
+	return tn.network.Stats(), received
+}
+
+func TestLossAndDuplication(t *testing.T) {
+	const n = 1000
+	stats, received := lossyRun(t, 1, n)
+	if stats.Sent != n {
+		t.Fatalf("expected %v messages sent, got %v", n, stats.Sent)
+	}
+	// Loose bounds, many standard deviations away from the expected values
+	if stats.DroppedRandomly < 200 || stats.DroppedRandomly > 400 {
+		t.Errorf("expected about 300 messages dropped, got %v", stats.DroppedRandomly)
+	}
+	if stats.Duplicated < 80 || stats.Duplicated > 200 {
+		t.Errorf("expected about 140 messages duplicated, got %v", stats.Duplicated)
+	}
+	if stats.Delivered != uint64(len(received)) {
+		t.Errorf("stats count %v deliveries, but %v messages were received", stats.Delivered, len(received))
+	}
+
+	counts := map[uint64]int{}
+	for _, number := range received {
+		counts[number]++
+	}
+	duplicates := 0
+	for number, count := range counts {
+		if number >= n || count > 2 {
+			t.Fatalf("message %v received %v times", number, count)
+		}
+		duplicates += count - 1
+	}
+	if uint64(len(counts)) != n-stats.DroppedRandomly || uint64(duplicates) != stats.Duplicated {
+		t.Fatalf("received %v distinct messages and %v duplicates, stats are %+v", len(counts), duplicates, stats)
+	}
+}
+
+func TestDeterminism(t *testing.T) {
+	const n = 200
+	stats1, received1 := lossyRun(t, 42, n)
+	stats2, received2 := lossyRun(t, 42, n)
+	if stats1 != stats2 {
+		t.Fatalf("same seed produced different stats: %+v vs %+v", stats1, stats2)
+	}
+	if !reflect.DeepEqual(received1, received2) {
+		t.Fatalf("same seed produced different deliveries:\n%v\n%v", received1, received2)
+	}
+
+	stats3, received3 := lossyRun(t, 43, n)
+	if stats1 == stats3 && reflect.DeepEqual(received1, received3) {
+		t.Fatalf("different seeds produced identical runs")
+	}
+}
+
+func TestPartitionAndHeal(t *testing.T) {
+	tn := newTestNetwork(t, simnet.Config{DefaultLink: simnet.LinkConfig{Latency: simnet.ConstantLatency(time.Second)}})
+
+	// In flight when the partition starts, lost
+	tn.sendNumbered(0, 1)
+	tn.network.Partition([]string{"a"}, []string{"b"})
+	// Sent during the partition, lost
+	tn.sendNumbered(1, 1)
+	tn.clock.Advance(2 * time.Second)
+	tn.expectNothing(t)
+
+	tn.network.Heal()
+	tn.sendNumbered(2, 1)
+	if received := tn.receive(t, 1); received[0] != 2 {
+		t.Fatalf("expected message 2 after healing, got %v", received)
+	}
+	tn.expectNothing(t)
+
+	stats := tn.network.Stats()
+	if stats.DroppedByPartition != 2 || stats.Delivered != 1 {
+		t.Fatalf("unexpected stats %+v", stats)
+	}
+}
+
+func TestScheduledPartition(t *testing.T) {
+	tn := newTestNetwork(t, simnet.Config{Partitions: []simnet.ScheduledPartition{
+		{time.Second, time.Second, [][]string{{"a"}, {"b"}}},
+	}})
+
+	tn.sendNumbered(0, 1)
+	tn.receive(t, 1)
+
+	tn.clock.Advance(1500 * time.Millisecond)
+	tn.sendNumbered(1, 1)
+	tn.expectNothing(t)
+
+	// The partition has ended
+	tn.clock.Advance(time.Second)
+	tn.sendNumbered(2, 1)
+	if received := tn.receive(t, 1); received[0] != 2 {
+		t.Fatalf("expected message 2 after partition ended, got %v", received)
+	}
+
+	// A manual partition takes precedence, Heal only removes that
+	tn.network.Partition([]string{"a", "b"})
+	tn.sendNumbered(3, 1)
+	tn.receive(t, 1)
+	tn.network.Heal()
+
+	stats := tn.network.Stats()
+	if stats.DroppedByPartition != 1 || stats.Delivered != 3 {
+		t.Fatalf("unexpected stats %+v", stats)
+	}
+}
@@ -25,7 +25,7 @@ func makeConfigDigestArgs() abi.Arguments {
 
 var configDigestArgs = makeConfigDigestArgs()
 
-func configDigest(
+func configDigestPreimage(
 	chainID uint64,
 	contractAddress common.Address,
 	configCount uint64,
@@ -35,7 +35,7 @@ func configDigest(
 	onchainConfig []byte,
 	offchainConfigVersion uint64,
 	offchainConfig []byte,
-) types.ConfigDigest {
+) []byte {
 	chainIDBig := new(big.Int)
 	chainIDBig.SetUint64(chainID)
 	msg, err := configDigestArgs.Pack(
@@ -53,6 +53,31 @@ func configDigest(
 		// assertion
 		panic(err)
 	}
+	return msg
+}
+
+func configDigest(
+	chainID uint64,
+	contractAddress common.Address,
+	configCount uint64,
+	oracles []common.Address,
+	transmitters []common.Address,
+	f uint8,
+	onchainConfig []byte,
+	offchainConfigVersion uint64,
+	offchainConfig []byte,
+) types.ConfigDigest {
+	msg := configDigestPreimage(
+		chainID,
+		contractAddress,
+		configCount,
+		oracles,
+		transmitters,
+		f,
+		onchainConfig,
+		offchainConfigVersion,
+		offchainConfig,
+	)
 	rawHash := crypto.Keccak256(msg)
 	configDigest := types.ConfigDigest{}
 	if n := copy(configDigest[:], rawHash); n != len(configDigest) {
@@ -0,0 +1,68 @@
+package evmutil_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/crypto"
+	"golang.org/x/crypto/sha3"
+
+	"github.com/smartcontractkit/libocr/offchainreporting2plus/chains/evmutil"
+	"github.com/smartcontractkit/libocr/offchainreporting2plus/chains/hashdigest"
+	"github.com/smartcontractkit/libocr/offchainreporting2plus/types"
+)
+
+// TestConfigDigestersAgainstContract checks the EVM digesters against the
+// digest computed by a deployed OCR2Aggregator.
+func TestConfigDigestersAgainstContract(t *testing.T) {
+	ctx := context.Background()
+	agg := deployTestAggregator(t, 4, 1)
+
+	details, err := agg.contract.LatestConfigDetails(&bind.CallOpts{Context: ctx})
+	if err != nil {
+		t.Fatal(err)
+	}
+	contractDigest := types.ConfigDigest(details.ConfigDigest)
+
+	// Must match the arguments to SetConfig in deployTestAggregator
+	var signers []types.OnchainPublicKey
+	var transmitters []types.Account
+	for i := range agg.signerKeys {
+		signers = append(signers, crypto.PubkeyToAddress(agg.signerKeys[i].PublicKey).Bytes())
+		transmitters = append(transmitters, types.Account(agg.transmitters[i].From.Hex()))
+	}
+	cc := types.ContractConfig{contractDigest, uint64(details.ConfigCount), signers, transmitters, 1, agg.onchainConfig, 2, []byte("offchain")}
+
+	digesters := map[string]types.OffchainConfigDigester{
+		"EVMOffchainConfigDigester": evmutil.EVMOffchainConfigDigester{simulatedChainID, agg.contractAddress},
+		"hashdigest with EVMPreimageEncoder": hashdigest.Digester{
+			types.ConfigDigestPrefixEVM,
+			sha3.NewLegacyKeccak256,
+			evmutil.EVMPreimageEncoder{simulatedChainID, agg.contractAddress},
+		},
+	}
+	for name, digester := range digesters {
+		digest, err := digester.ConfigDigest(cc)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if digest != contractDigest {
+			t.Errorf("%s: computed digest %v, contract has %v", name, digest, contractDigest)
+		}
+	}
+
+	// These only differ in the prefix
+	for prefix, digester := range map[types.ConfigDigestPrefix]types.OffchainConfigDigester{
+		types.ConfigDigestPrefixEVMThresholdDecryption: evmutil.NewEVMThresholdDecryptionOffchainConfigDigester(simulatedChainID, agg.contractAddress),
+		types.ConfigDigestPrefixEVMS4:                  evmutil.NewEVMS4OffchainConfigDigester(simulatedChainID, agg.contractAddress),
+	} {
+		digest, err := digester.ConfigDigest(cc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !prefix.IsPrefixOf(digest) || digest.Hex()[4:] != contractDigest.Hex()[4:] {
+			t.Errorf("digest %v for prefix %v does not match contract digest %v", digest, prefix.Describe(), contractDigest)
+		}
+	}
+}
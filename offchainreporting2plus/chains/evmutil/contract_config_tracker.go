@@ -0,0 +1,81 @@
+package evmutil
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/smartcontractkit/libocr/gethwrappers2/ocr2aggregator"
+	"github.com/smartcontractkit/libocr/offchainreporting2plus/types"
+)
+
+var _ types.ContractConfigTracker = (*EVMContractConfigTracker)(nil)
+
+// EVMContractConfigTracker tracks the config of an OCR2Aggregator contract
+// through a bind.ContractBackend. It polls latestConfigDetails and retrieves
+// configs from ConfigSet logs. It does not implement Notify, so config changes
+// are only picked up at the ContractConfigTrackerPollInterval.
+type EVMContractConfigTracker struct {
+	backend  bind.ContractBackend
+	contract *ocr2aggregator.OCR2Aggregator
+}
+
+// NewEVMContractConfigTracker creates a tracker for the OCR2Aggregator at
+// contractAddress.
+func NewEVMContractConfigTracker(
+	backend bind.ContractBackend,
+	contractAddress common.Address,
+) (*EVMContractConfigTracker, error) {
+	contract, err := ocr2aggregator.NewOCR2Aggregator(contractAddress, backend)
+	if err != nil {
+		return nil, fmt.Errorf("could not bind OCR2Aggregator at %s: %w", contractAddress.Hex(), err)
+	}
+	return &EVMContractConfigTracker{backend, contract}, nil
+}
+
+func (t *EVMContractConfigTracker) Notify() <-chan struct{} {
+	return nil
+}
+
+func (t *EVMContractConfigTracker) LatestConfigDetails(ctx context.Context) (changedInBlock uint64, configDigest types.ConfigDigest, err error) {
+	details, err := t.contract.LatestConfigDetails(&bind.CallOpts{Context: ctx})
+	if err != nil {
+		return 0, types.ConfigDigest{}, fmt.Errorf("error calling latestConfigDetails: %w", err)
+	}
+	return uint64(details.BlockNumber), details.ConfigDigest, nil
+}
+
+func (t *EVMContractConfigTracker) LatestConfig(ctx context.Context, changedInBlock uint64) (types.ContractConfig, error) {
+	it, err := t.contract.FilterConfigSet(&bind.FilterOpts{
+		Start:   changedInBlock,
+		End:     &changedInBlock,
+		Context: ctx,
+	})
+	if err != nil {
+		return types.ContractConfig{}, fmt.Errorf("error filtering ConfigSet logs: %w", err)
+	}
+	defer it.Close()
+
+	// A block could contain several ConfigSet events, the last one wins.
+	var latest *ocr2aggregator.OCR2AggregatorConfigSet
+	for it.Next() {
+		latest = it.Event
+	}
+	if err := it.Error(); err != nil {
+		return types.ContractConfig{}, fmt.Errorf("error iterating ConfigSet logs: %w", err)
+	}
+	if latest == nil {
+		return types.ContractConfig{}, fmt.Errorf("no ConfigSet log found in block %d", changedInBlock)
+	}
+	return ContractConfigFromConfigSetEvent(*latest), nil
+}
+
+func (t *EVMContractConfigTracker) LatestBlockHeight(ctx context.Context) (blockHeight uint64, err error) {
+	header, err := t.backend.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("error fetching latest header: %w", err)
+	}
+	return header.Number.Uint64(), nil
+}
@@ -0,0 +1,220 @@
+package evmutil_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind/backends"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/smartcontractkit/libocr/commontypes"
+	"github.com/smartcontractkit/libocr/gethwrappers2/link_token_interface"
+	"github.com/smartcontractkit/libocr/gethwrappers2/ocr2aggregator"
+	"github.com/smartcontractkit/libocr/offchainreporting2/reportingplugin/median"
+	"github.com/smartcontractkit/libocr/offchainreporting2/reportingplugin/median/evmreportcodec"
+	"github.com/smartcontractkit/libocr/offchainreporting2plus/chains/evmutil"
+	"github.com/smartcontractkit/libocr/offchainreporting2plus/types"
+)
+
+const simulatedChainID = 1337
+
+type testAggregator struct {
+	backend         *backends.SimulatedBackend
+	owner           *bind.TransactOpts
+	contractAddress common.Address
+	contract        *ocr2aggregator.OCR2Aggregator
+	signerKeys      []*ecdsa.PrivateKey
+	transmitters    []*bind.TransactOpts
+	onchainConfig   []byte
+}
+
+func newKeyedTransactor(t *testing.T) (*ecdsa.PrivateKey, *bind.TransactOpts) {
+	t.Helper()
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts, err := bind.NewKeyedTransactorWithChainID(key, big.NewInt(simulatedChainID))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key, opts
+}
+
+// deployTestAggregator deploys an OCR2Aggregator with n oracles on a fresh
+// simulated backend and sets its config.
+func deployTestAggregator(t *testing.T, n int, f uint8) *testAggregator {
+	t.Helper()
+
+	_, owner := newKeyedTransactor(t)
+	var signerKeys []*ecdsa.PrivateKey
+	var transmitters []*bind.TransactOpts
+	alloc := core.GenesisAlloc{owner.From: {Balance: new(big.Int).Lsh(big.NewInt(1), 100)}}
+	for i := 0; i < n; i++ {
+		signerKey, _ := newKeyedTransactor(t)
+		signerKeys = append(signerKeys, signerKey)
+		_, transmitter := newKeyedTransactor(t)
+		transmitters = append(transmitters, transmitter)
+		alloc[transmitter.From] = core.GenesisAccount{Balance: new(big.Int).Lsh(big.NewInt(1), 100)}
+	}
+	backend := backends.NewSimulatedBackend(alloc, 30_000_000)
+	t.Cleanup(func() { backend.Close() })
+
+	linkAddress, _, _, err := link_token_interface.DeployLinkToken(owner, backend)
+	if err != nil {
+		t.Fatal(err)
+	}
+	backend.Commit()
+
+	minAnswer, maxAnswer := big.NewInt(0), big.NewInt(1_000_000)
+	contractAddress, _, contract, err := ocr2aggregator.DeployOCR2Aggregator(
+		owner, backend, linkAddress, minAnswer, maxAnswer, common.Address{}, common.Address{}, 8, "test",
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	backend.Commit()
+
+	onchainConfig, err := median.StandardOnchainConfigCodec{}.Encode(median.OnchainConfig{minAnswer, maxAnswer})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var signerAddresses, transmitterAddresses []common.Address
+	for i := 0; i < n; i++ {
+		signerAddresses = append(signerAddresses, crypto.PubkeyToAddress(signerKeys[i].PublicKey))
+		transmitterAddresses = append(transmitterAddresses, transmitters[i].From)
+	}
+	if _, err := contract.SetConfig(owner, signerAddresses, transmitterAddresses, f, onchainConfig, 2, []byte("offchain")); err != nil {
+		t.Fatal(err)
+	}
+	backend.Commit()
+
+	return &testAggregator{backend, owner, contractAddress, contract, signerKeys, transmitters, onchainConfig}
+}
+
+func TestEVMContractConfigTracker(t *testing.T) {
+	ctx := context.Background()
+	agg := deployTestAggregator(t, 4, 1)
+
+	tracker, err := evmutil.NewEVMContractConfigTracker(agg.backend, agg.contractAddress)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	changedInBlock, configDigest, err := tracker.LatestConfigDetails(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	height, err := tracker.LatestBlockHeight(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if changedInBlock != height {
+		t.Fatalf("expected config to have changed in latest block %d, got %d", height, changedInBlock)
+	}
+
+	contractConfig, err := tracker.LatestConfig(ctx, changedInBlock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if contractConfig.ConfigDigest != configDigest {
+		t.Fatalf("config digest mismatch: %s vs %s", contractConfig.ConfigDigest, configDigest)
+	}
+	if contractConfig.ConfigCount != 1 || contractConfig.F != 1 || len(contractConfig.Signers) != 4 ||
+		contractConfig.OffchainConfigVersion != 2 || string(contractConfig.OffchainConfig) != "offchain" {
+		t.Fatalf("unexpected config %+v", contractConfig)
+	}
+
+	digester := evmutil.EVMOffchainConfigDigester{simulatedChainID, agg.contractAddress}
+	expectedDigest, err := digester.ConfigDigest(contractConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expectedDigest != configDigest {
+		t.Fatalf("digester disagrees with contract: %s vs %s", expectedDigest, configDigest)
+	}
+
+	if _, err := tracker.LatestConfig(ctx, changedInBlock-1); err == nil {
+		t.Fatal("expected error for block without ConfigSet log")
+	}
+}
+
+func TestEVMContractTransmitter(t *testing.T) {
+	ctx := context.Background()
+	agg := deployTestAggregator(t, 4, 1)
+
+	tracker, err := evmutil.NewEVMContractConfigTracker(agg.backend, agg.contractAddress)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, configDigest, err := tracker.LatestConfigDetails(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	transmitter, err := evmutil.NewEVMContractTransmitter(agg.backend, agg.contractAddress, agg.transmitters[2])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if account, _ := transmitter.FromAccount(); account != types.Account(agg.transmitters[2].From.Hex()) {
+		t.Fatalf("unexpected FromAccount %s", account)
+	}
+
+	report := buildTestReport(t, 20)
+	repctx := types.ReportContext{types.ReportTimestamp{configDigest, 3, 1}, [32]byte{0xaa}}
+	signatures := []types.AttributedOnchainSignature{
+		{sign(t, evmutil.NewEVMOnchainKeyring(agg.signerKeys[3]), repctx, report), commontypes.OracleID(3)},
+		{sign(t, evmutil.NewEVMOnchainKeyring(agg.signerKeys[1]), repctx, report), commontypes.OracleID(1)},
+	}
+
+	if err := transmitter.Transmit(ctx, repctx, report, signatures); err != nil {
+		t.Fatal(err)
+	}
+	agg.backend.Commit()
+
+	latestDigest, epoch, err := transmitter.LatestConfigDigestAndEpoch(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if latestDigest != configDigest || epoch != 3 {
+		t.Fatalf("unexpected LatestConfigDigestAndEpoch (%s, %d)", latestDigest, epoch)
+	}
+	answer, err := agg.contract.LatestAnswer(&bind.CallOpts{Context: ctx})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if answer.Cmp(big.NewInt(20)) != 0 {
+		t.Fatalf("unexpected latest answer %s", answer)
+	}
+
+	if err := transmitter.Transmit(ctx, repctx, report, []types.AttributedOnchainSignature{{[]byte{1, 2, 3}, 0}}); err == nil {
+		t.Fatal("expected error for malformed signature")
+	}
+}
+
+func sign(t *testing.T, keyring types.OnchainKeyring, repctx types.ReportContext, report types.Report) []byte {
+	t.Helper()
+	sig, err := keyring.Sign(repctx, report)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return sig
+}
+
+func buildTestReport(t *testing.T, value int64) types.Report {
+	t.Helper()
+	report, err := evmreportcodec.ReportCodec{}.BuildReport([]median.ParsedAttributedObservation{
+		{1, big.NewInt(value - 1), big.NewInt(1), 0},
+		{1, big.NewInt(value), big.NewInt(1), 1},
+		{1, big.NewInt(value + 1), big.NewInt(1), 2},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return report
+}
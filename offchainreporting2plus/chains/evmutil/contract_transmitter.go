@@ -0,0 +1,129 @@
+package evmutil
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/smartcontractkit/libocr/gethwrappers2/ocr2aggregator"
+	"github.com/smartcontractkit/libocr/offchainreporting2plus/types"
+)
+
+var _ types.ContractTransmitter = (*EVMContractTransmitter)(nil)
+
+// EVMContractTransmitter transmits reports to an OCR2Aggregator contract by
+// sending transactions through a bind.ContractBackend.
+//
+// Transmit returns as soon as the transaction has been submitted to the
+// backend; it does not wait for the transaction to be mined.
+type EVMContractTransmitter struct {
+	contract     *ocr2aggregator.OCR2Aggregator
+	transactOpts bind.TransactOpts
+	// serializes transactions, so that concurrent calls to Transmit don't
+	// pick the same nonce
+	sendMu sync.Mutex
+}
+
+// NewEVMContractTransmitter creates a transmitter for the OCR2Aggregator at
+// contractAddress. transactOpts determines the sending account, the signer,
+// and gas settings. Its Context is ignored in favour of the context passed to
+// Transmit.
+func NewEVMContractTransmitter(
+	backend bind.ContractBackend,
+	contractAddress common.Address,
+	transactOpts *bind.TransactOpts,
+) (*EVMContractTransmitter, error) {
+	if transactOpts == nil {
+		return nil, fmt.Errorf("transactOpts must not be nil")
+	}
+	contract, err := ocr2aggregator.NewOCR2Aggregator(contractAddress, backend)
+	if err != nil {
+		return nil, fmt.Errorf("could not bind OCR2Aggregator at %s: %w", contractAddress.Hex(), err)
+	}
+	return &EVMContractTransmitter{contract, *transactOpts, sync.Mutex{}}, nil
+}
+
+func (t *EVMContractTransmitter) Transmit(
+	ctx context.Context,
+	reportContext types.ReportContext,
+	report types.Report,
+	signatures []types.AttributedOnchainSignature,
+) error {
+	rs, ss, vs, err := splitSignatures(signatures)
+	if err != nil {
+		return err
+	}
+
+	t.sendMu.Lock()
+	defer t.sendMu.Unlock()
+
+	opts := t.transactOpts
+	opts.Context = ctx
+	_, err = t.contract.Transmit(&opts, RawReportContext(reportContext), report, rs, ss, vs)
+	if err != nil {
+		return fmt.Errorf("error sending transmit transaction: %w", err)
+	}
+	return nil
+}
+
+// LatestConfigDigestAndEpoch calls the contract's latestConfigDigestAndEpoch
+// function. If the contract asks us to scan logs instead, we look for the most
+// recent Transmitted event since the latest config change.
+func (t *EVMContractTransmitter) LatestConfigDigestAndEpoch(ctx context.Context) (configDigest types.ConfigDigest, epoch uint32, err error) {
+	callOpts := &bind.CallOpts{Context: ctx}
+	result, err := t.contract.LatestConfigDigestAndEpoch(callOpts)
+	if err != nil {
+		return types.ConfigDigest{}, 0, fmt.Errorf("error calling latestConfigDigestAndEpoch: %w", err)
+	}
+	if !result.ScanLogs {
+		return result.ConfigDigest, result.Epoch, nil
+	}
+
+	details, err := t.contract.LatestConfigDetails(callOpts)
+	if err != nil {
+		return types.ConfigDigest{}, 0, fmt.Errorf("error calling latestConfigDetails: %w", err)
+	}
+	it, err := t.contract.FilterTransmitted(&bind.FilterOpts{
+		Start:   uint64(details.BlockNumber),
+		Context: ctx,
+	})
+	if err != nil {
+		return types.ConfigDigest{}, 0, fmt.Errorf("error filtering Transmitted logs: %w", err)
+	}
+	defer it.Close()
+	configDigest = details.ConfigDigest
+	for it.Next() {
+		if it.Event.ConfigDigest == details.ConfigDigest {
+			epoch = it.Event.Epoch
+		}
+	}
+	if err := it.Error(); err != nil {
+		return types.ConfigDigest{}, 0, fmt.Errorf("error iterating Transmitted logs: %w", err)
+	}
+	return configDigest, epoch, nil
+}
+
+func (t *EVMContractTransmitter) FromAccount() (types.Account, error) {
+	return types.Account(t.transactOpts.From.Hex()), nil
+}
+
+// splitSignatures converts attributed signatures into the rs, ss, and rawVs
+// arguments expected by OCR2Aggregator.transmit.
+func splitSignatures(signatures []types.AttributedOnchainSignature) (rs [][32]byte, ss [][32]byte, vs [32]byte, err error) {
+	if len(signatures) > len(vs) {
+		return nil, nil, vs, fmt.Errorf("too many signatures, got %d, maximum is %d", len(signatures), len(vs))
+	}
+	for i, as := range signatures {
+		r, s, v, err := SplitSignature(as.Signature)
+		if err != nil {
+			return nil, nil, vs, fmt.Errorf("%d-th signature (by oracle %d) is malformed: %w", i, as.Signer, err)
+		}
+		rs = append(rs, r)
+		ss = append(ss, s)
+		vs[i] = v
+	}
+	return rs, ss, vs, nil
+}
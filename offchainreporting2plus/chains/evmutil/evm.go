@@ -5,6 +5,7 @@ import (
 	"fmt"
 
 	"github.com/ethereum/go-ethereum/common"
+	gethtypes "github.com/ethereum/go-ethereum/core/types"
 	"github.com/smartcontractkit/libocr/gethwrappers2/ocr2aggregator"
 	"github.com/smartcontractkit/libocr/offchainreporting2plus/types"
 )
@@ -49,3 +50,17 @@ func ContractConfigFromConfigSetEvent(changed ocr2aggregator.OCR2AggregatorConfi
 		changed.OffchainConfig,
 	}
 }
+
+// ContractConfigFromConfigSetLog decodes a raw ConfigSet log emitted by an
+// OCR2Aggregator (or a contract with the same ConfigSet event).
+func ContractConfigFromConfigSetLog(log gethtypes.Log) (types.ContractConfig, error) {
+	filterer, err := ocr2aggregator.NewOCR2AggregatorFilterer(log.Address, nil)
+	if err != nil {
+		return types.ContractConfig{}, err
+	}
+	changed, err := filterer.ParseConfigSet(log)
+	if err != nil {
+		return types.ContractConfig{}, fmt.Errorf("could not parse ConfigSet log: %w", err)
+	}
+	return ContractConfigFromConfigSetEvent(*changed), nil
+}
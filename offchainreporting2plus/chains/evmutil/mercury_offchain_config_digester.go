@@ -0,0 +1,111 @@
+package evmutil
+
+import (
+	"crypto/ed25519"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/smartcontractkit/libocr/offchainreporting2plus/types"
+)
+
+var _ types.OffchainConfigDigester = MercuryOffchainConfigDigester{}
+
+// MercuryOffchainConfigDigester computes config digests the way the Mercury
+// v0.2/v0.3 verifier contract does. Unlike OCR2Aggregator, the verifier holds
+// configs for many feeds, so the digest also commits to the feed ID, and
+// transmitters are 32 byte Ed25519 public keys, hex-encoded without 0x
+// prefix.
+type MercuryOffchainConfigDigester struct {
+	FeedID          [32]byte
+	ChainID         *big.Int
+	ContractAddress common.Address
+}
+
+func (d MercuryOffchainConfigDigester) ConfigDigest(cc types.ContractConfig) (types.ConfigDigest, error) {
+	if d.ChainID == nil {
+		return types.ConfigDigest{}, fmt.Errorf("chain id must not be nil")
+	}
+	signers := []common.Address{}
+	for i, signer := range cc.Signers {
+		if len(signer) != 20 {
+			return types.ConfigDigest{}, fmt.Errorf("%v-th evm signer should be a 20 byte address, but got %x", i, signer)
+		}
+		signers = append(signers, common.BytesToAddress(signer))
+	}
+	transmitters := [][32]byte{}
+	for i, transmitter := range cc.Transmitters {
+		var t [32]byte
+		if len(transmitter) != 2*ed25519.PublicKeySize {
+			return types.ConfigDigest{}, fmt.Errorf("%v-th mercury transmitter should be a 64 character hex-encoded ed25519 public key, but got '%v'", i, transmitter)
+		}
+		if _, err := hex.Decode(t[:], []byte(transmitter)); err != nil {
+			return types.ConfigDigest{}, fmt.Errorf("%v-th mercury transmitter should be a 64 character hex-encoded ed25519 public key, but got '%v': %w", i, transmitter, err)
+		}
+		transmitters = append(transmitters, t)
+	}
+
+	msg, err := mercuryConfigDigestArgs.Pack(
+		d.FeedID,
+		d.ChainID,
+		d.ContractAddress,
+		cc.ConfigCount,
+		signers,
+		transmitters,
+		cc.F,
+		cc.OnchainConfig,
+		cc.OffchainConfigVersion,
+		cc.OffchainConfig,
+	)
+	if err != nil {
+		return types.ConfigDigest{}, err
+	}
+	rawHash := crypto.Keccak256(msg)
+	configDigest := types.ConfigDigest{}
+	if n := copy(configDigest[:], rawHash); n != len(configDigest) {
+		// assertion
+		panic("copy too little data")
+	}
+	if types.ConfigDigestPrefixMercuryV02 != 6 {
+		// assertion
+		panic("wrong ConfigDigestPrefix")
+	}
+	configDigest[0] = 0
+	configDigest[1] = 6
+	return configDigest, nil
+}
+
+func (d MercuryOffchainConfigDigester) ConfigDigestPrefix() (types.ConfigDigestPrefix, error) {
+	return types.ConfigDigestPrefixMercuryV02, nil
+}
+
+func makeMercuryConfigDigestArgs() abi.Arguments {
+	mustNewType := func(t string) abi.Type {
+		result, err := abi.NewType(t, "", []abi.ArgumentMarshaling{})
+		if err != nil {
+			// assertion
+			panic(fmt.Sprintf("unexpected error during abi.NewType: %s", err))
+		}
+		return result
+	}
+	// Must match the arguments to abi.encode in the verifier's
+	// _configDigestFromConfigData
+	return abi.Arguments([]abi.Argument{
+		{Name: "feedId", Type: mustNewType("bytes32")},
+		{Name: "sourceChainId", Type: mustNewType("uint256")},
+		{Name: "sourceAddress", Type: mustNewType("address")},
+		{Name: "configCount", Type: mustNewType("uint64")},
+		{Name: "signers", Type: mustNewType("address[]")},
+		{Name: "offchainTransmitters", Type: mustNewType("bytes32[]")},
+		{Name: "f", Type: mustNewType("uint8")},
+		{Name: "onchainConfig", Type: mustNewType("bytes")},
+		{Name: "offchainConfigVersion", Type: mustNewType("uint64")},
+		{Name: "offchainConfig", Type: mustNewType("bytes")},
+	})
+}
+
+var mercuryConfigDigestArgs = makeMercuryConfigDigestArgs()
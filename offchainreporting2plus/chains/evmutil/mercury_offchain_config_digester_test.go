@@ -0,0 +1,158 @@
+package evmutil_test
+
+import (
+	"bytes"
+	"encoding/hex"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/smartcontractkit/libocr/offchainreporting2plus/chains/evmutil"
+	"github.com/smartcontractkit/libocr/offchainreporting2plus/types"
+)
+
+// abiWord left-pads b to a 32 byte ABI word.
+func abiWord(b []byte) []byte {
+	return common.LeftPadBytes(b, 32)
+}
+
+func abiUint(v uint64) []byte {
+	return abiWord(new(big.Int).SetUint64(v).Bytes())
+}
+
+// abiBytes encodes a dynamic bytes value: length followed by the data,
+// right-padded to a multiple of 32 bytes.
+func abiBytes(b []byte) []byte {
+	padded := make([]byte, (len(b)+31)/32*32)
+	copy(padded, b)
+	return append(abiUint(uint64(len(b))), padded...)
+}
+
+// mercuryConfigDigestReference computes the config digest like the Mercury
+// verifier's _configDigestFromConfigData:
+//
+//	keccak256(abi.encode(feedId, sourceChainId, sourceAddress, configCount,
+//	    signers, offchainTransmitters, f, onchainConfig,
+//	    offchainConfigVersion, offchainConfig))
+//
+// with the first two bytes replaced by the 0x0006 prefix. The ABI encoding is
+// spelled out word by word so that the test doesn't share any encoding logic
+// with the digester.
+func mercuryConfigDigestReference(feedID [32]byte, chainID *big.Int, contractAddress common.Address, cc types.ContractConfig, transmitters [][32]byte) types.ConfigDigest {
+	var signers, offchainTransmitters []byte
+	signers = abiUint(uint64(len(cc.Signers)))
+	for _, s := range cc.Signers {
+		signers = append(signers, abiWord(s)...)
+	}
+	offchainTransmitters = abiUint(uint64(len(transmitters)))
+	for _, t := range transmitters {
+		offchainTransmitters = append(offchainTransmitters, t[:]...)
+	}
+	onchainConfig := abiBytes(cc.OnchainConfig)
+	offchainConfig := abiBytes(cc.OffchainConfig)
+
+	const headSize = 10 * 32
+	signersOffset := uint64(headSize)
+	transmittersOffset := signersOffset + uint64(len(signers))
+	onchainConfigOffset := transmittersOffset + uint64(len(offchainTransmitters))
+	offchainConfigOffset := onchainConfigOffset + uint64(len(onchainConfig))
+
+	var encoded []byte
+	for _, word := range [][]byte{
+		feedID[:],
+		abiWord(chainID.Bytes()),
+		abiWord(contractAddress[:]),
+		abiUint(cc.ConfigCount),
+		abiUint(signersOffset),
+		abiUint(transmittersOffset),
+		abiUint(uint64(cc.F)),
+		abiUint(onchainConfigOffset),
+		abiUint(cc.OffchainConfigVersion),
+		abiUint(offchainConfigOffset),
+	} {
+		encoded = append(encoded, word...)
+	}
+	for _, tail := range [][]byte{signers, offchainTransmitters, onchainConfig, offchainConfig} {
+		encoded = append(encoded, tail...)
+	}
+
+	var digest types.ConfigDigest
+	copy(digest[:], crypto.Keccak256(encoded))
+	digest[0], digest[1] = 0x00, 0x06
+	return digest
+}
+
+func TestMercuryOffchainConfigDigester(t *testing.T) {
+	feedID := [32]byte{0xfe, 0xed}
+	chainID := big.NewInt(42161)
+	contractAddress := common.HexToAddress("0x478Aa2aC9F6D65F84e09D9185d126c3a17c2a93C")
+
+	var transmitters [][32]byte
+	cc := types.ContractConfig{
+		types.ConfigDigest{},
+		3,
+		nil,
+		nil,
+		1,
+		bytes.Repeat([]byte{0x01}, 64),
+		30,
+		[]byte("offchain config that spans more than one abi word"),
+	}
+	for i := 0; i < 4; i++ {
+		cc.Signers = append(cc.Signers, bytes.Repeat([]byte{byte(0x10 + i)}, 20))
+		var transmitter [32]byte
+		copy(transmitter[:], bytes.Repeat([]byte{byte(0x20 + i)}, 32))
+		transmitters = append(transmitters, transmitter)
+		cc.Transmitters = append(cc.Transmitters, types.Account(hex.EncodeToString(transmitter[:])))
+	}
+
+	digester := evmutil.MercuryOffchainConfigDigester{feedID, chainID, contractAddress}
+	digest, err := digester.ConfigDigest(cc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := mercuryConfigDigestReference(feedID, chainID, contractAddress, cc, transmitters); digest != expected {
+		t.Fatalf("digest %v does not match reference encoding %v", digest, expected)
+	}
+	// Computed with the reference encoding above. The verifier contract isn't
+	// part of this repository, so we can't check against a deployment here.
+	if expected := "0006722a021417faa9398af96a0da32798866a619ac5211bed085fd9f1c93f5c"; digest.Hex() != expected {
+		t.Fatalf("digest %v does not match known answer %v", digest, expected)
+	}
+
+	// Every input must affect the digest
+	for name, other := range map[string]evmutil.MercuryOffchainConfigDigester{
+		"feedID":          {[32]byte{0xfe, 0xee}, chainID, contractAddress},
+		"chainID":         {feedID, big.NewInt(1), contractAddress},
+		"contractAddress": {feedID, chainID, common.HexToAddress("0x01")},
+	} {
+		otherDigest, err := other.ConfigDigest(cc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if otherDigest == digest {
+			t.Errorf("changing %s did not change the digest", name)
+		}
+	}
+
+	for name, modify := range map[string]func(*types.ContractConfig){
+		"short signer":        func(cc *types.ContractConfig) { cc.Signers[0] = cc.Signers[0][:19] },
+		"0x transmitter":      func(cc *types.ContractConfig) { cc.Transmitters[0] = "0x" + cc.Transmitters[0][2:] },
+		"non-hex transmitter": func(cc *types.ContractConfig) { cc.Transmitters[0] = types.Account(strings.Repeat("zz", 32)) },
+		"address transmitter": func(cc *types.ContractConfig) { cc.Transmitters[0] = types.Account(contractAddress.Hex()) },
+	} {
+		invalid := cc
+		invalid.Signers = append([]types.OnchainPublicKey{}, cc.Signers...)
+		invalid.Transmitters = append([]types.Account{}, cc.Transmitters...)
+		modify(&invalid)
+		if _, err := digester.ConfigDigest(invalid); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+	if _, err := (evmutil.MercuryOffchainConfigDigester{feedID, nil, contractAddress}).ConfigDigest(cc); err == nil {
+		t.Errorf("expected error for nil chain id")
+	}
+}
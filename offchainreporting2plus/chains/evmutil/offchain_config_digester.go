@@ -5,6 +5,9 @@ import (
 	"strings"
 
 	"github.com/ethereum/go-ethereum/common"
+	"golang.org/x/crypto/sha3"
+
+	"github.com/smartcontractkit/libocr/offchainreporting2plus/chains/hashdigest"
 	"github.com/smartcontractkit/libocr/offchainreporting2plus/types"
 )
 
@@ -16,10 +19,33 @@ type EVMOffchainConfigDigester struct {
 }
 
 func (d EVMOffchainConfigDigester) ConfigDigest(cc types.ContractConfig) (types.ConfigDigest, error) {
+	signers, transmitters, err := evmSignersAndTransmitters(cc)
+	if err != nil {
+		return types.ConfigDigest{}, err
+	}
+
+	return configDigest(
+		d.ChainID,
+		d.ContractAddress,
+		cc.ConfigCount,
+		signers,
+		transmitters,
+		cc.F,
+		cc.OnchainConfig,
+		cc.OffchainConfigVersion,
+		cc.OffchainConfig,
+	), nil
+}
+
+func (d EVMOffchainConfigDigester) ConfigDigestPrefix() (types.ConfigDigestPrefix, error) {
+	return types.ConfigDigestPrefixEVM, nil
+}
+
+func evmSignersAndTransmitters(cc types.ContractConfig) ([]common.Address, []common.Address, error) {
 	signers := []common.Address{}
 	for i, signer := range cc.Signers {
 		if len(signer) != 20 {
-			return types.ConfigDigest{}, fmt.Errorf("%v-th evm signer should be a 20 byte address, but got %x", i, signer)
+			return nil, nil, fmt.Errorf("%v-th evm signer should be a 20 byte address, but got %x", i, signer)
 		}
 		a := common.BytesToAddress(signer)
 		signers = append(signers, a)
@@ -27,15 +53,32 @@ func (d EVMOffchainConfigDigester) ConfigDigest(cc types.ContractConfig) (types.
 	transmitters := []common.Address{}
 	for i, transmitter := range cc.Transmitters {
 		if !strings.HasPrefix(string(transmitter), "0x") || len(transmitter) != 42 || !common.IsHexAddress(string(transmitter)) {
-			return types.ConfigDigest{}, fmt.Errorf("%v-th evm transmitter should be a 42 character Ethereum address string, but got '%v'", i, transmitter)
+			return nil, nil, fmt.Errorf("%v-th evm transmitter should be a 42 character Ethereum address string, but got '%v'", i, transmitter)
 		}
 		a := common.HexToAddress(string(transmitter))
 		transmitters = append(transmitters, a)
 	}
+	return signers, transmitters, nil
+}
 
-	return configDigest(
-		d.ChainID,
-		d.ContractAddress,
+var _ hashdigest.PreimageEncoder = EVMPreimageEncoder{}
+
+// EVMPreimageEncoder encodes a ContractConfig the way OCR2Aggregator does
+// before hashing it with Keccak256. EVMOffchainConfigDigester is equivalent to
+// a hashdigest.Digester with this encoder and ConfigDigestPrefixEVM.
+type EVMPreimageEncoder struct {
+	ChainID         uint64
+	ContractAddress common.Address
+}
+
+func (e EVMPreimageEncoder) EncodePreimage(cc types.ContractConfig) ([]byte, error) {
+	signers, transmitters, err := evmSignersAndTransmitters(cc)
+	if err != nil {
+		return nil, err
+	}
+	return configDigestPreimage(
+		e.ChainID,
+		e.ContractAddress,
 		cc.ConfigCount,
 		signers,
 		transmitters,
@@ -46,6 +89,24 @@ func (d EVMOffchainConfigDigester) ConfigDigest(cc types.ContractConfig) (types.
 	), nil
 }
 
-func (d EVMOffchainConfigDigester) ConfigDigestPrefix() (types.ConfigDigestPrefix, error) {
-	return types.ConfigDigestPrefixEVM, nil
+// NewEVMThresholdDecryptionOffchainConfigDigester and
+// NewEVMS4OffchainConfigDigester return digesters for the threshold
+// decryption and S4 plugins, which run as part of another product (e.g.
+// Functions) under the same contract. The contract computes the digest like
+// OCR2Aggregator, but the prefix keeps the plugins' instances separate from
+// the product's.
+func NewEVMThresholdDecryptionOffchainConfigDigester(chainID uint64, contractAddress common.Address) hashdigest.Digester {
+	return hashdigest.Digester{
+		types.ConfigDigestPrefixEVMThresholdDecryption,
+		sha3.NewLegacyKeccak256,
+		EVMPreimageEncoder{chainID, contractAddress},
+	}
+}
+
+func NewEVMS4OffchainConfigDigester(chainID uint64, contractAddress common.Address) hashdigest.Digester {
+	return hashdigest.Digester{
+		types.ConfigDigestPrefixEVMS4,
+		sha3.NewLegacyKeccak256,
+		EVMPreimageEncoder{chainID, contractAddress},
+	}
 }
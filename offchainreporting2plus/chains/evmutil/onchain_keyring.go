@@ -0,0 +1,149 @@
+package evmutil
+
+import (
+	"crypto/ecdsa"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/smartcontractkit/libocr/offchainreporting2plus/ocr3types"
+	"github.com/smartcontractkit/libocr/offchainreporting2plus/types"
+)
+
+// Length of an EVM signature: 32 bytes r, 32 bytes s, and one byte v.
+const signatureLength = 65
+
+// ReportHash returns the hash that OCR2Aggregator.transmit recovers signers
+// from, i.e. keccak256(abi.encode(keccak256(report), reportContext)).
+func ReportHash(repctx types.ReportContext, report types.Report) []byte {
+	rawRepctx := RawReportContext(repctx)
+	msg := make([]byte, 0, 4*32)
+	msg = append(msg, crypto.Keccak256(report)...)
+	msg = append(msg, rawRepctx[0][:]...)
+	msg = append(msg, rawRepctx[1][:]...)
+	msg = append(msg, rawRepctx[2][:]...)
+	return crypto.Keccak256(msg)
+}
+
+// RecoverSigner returns the address that produced signature over repctx and
+// report. Like the contract, it expects signatures in [r || s || v] form with
+// v in {0, 1}.
+func RecoverSigner(repctx types.ReportContext, report types.Report, signature []byte) (common.Address, error) {
+	if len(signature) != signatureLength {
+		return common.Address{}, fmt.Errorf("signature has wrong length, expected %v, got %v", signatureLength, len(signature))
+	}
+	if signature[signatureLength-1] > 1 {
+		return common.Address{}, fmt.Errorf("signature has invalid recovery id %v", signature[signatureLength-1])
+	}
+	pub, err := crypto.SigToPub(ReportHash(repctx, report), signature)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("could not recover signer: %w", err)
+	}
+	return crypto.PubkeyToAddress(*pub), nil
+}
+
+var _ types.OnchainKeyring = (*EVMOnchainKeyring)(nil)
+
+// EVMOnchainKeyring signs reports with secp256k1 in exactly the way the
+// OCR2Aggregator contract verifies them. Its public key is the signer's
+// 20 byte address.
+type EVMOnchainKeyring struct {
+	privateKey *ecdsa.PrivateKey
+	address    common.Address
+}
+
+func NewEVMOnchainKeyring(privateKey *ecdsa.PrivateKey) *EVMOnchainKeyring {
+	return &EVMOnchainKeyring{privateKey, crypto.PubkeyToAddress(privateKey.PublicKey)}
+}
+
+func (k *EVMOnchainKeyring) PublicKey() types.OnchainPublicKey {
+	address := k.address
+	return types.OnchainPublicKey(address[:])
+}
+
+func (k *EVMOnchainKeyring) Sign(repctx types.ReportContext, report types.Report) (signature []byte, err error) {
+	return crypto.Sign(ReportHash(repctx, report), k.privateKey)
+}
+
+func (k *EVMOnchainKeyring) Verify(publicKey types.OnchainPublicKey, repctx types.ReportContext, report types.Report, signature []byte) bool {
+	return VerifyReportSignature(publicKey, repctx, report, signature)
+}
+
+// VerifyReportSignature implements EVMOnchainKeyring.Verify. Since
+// verification doesn't need a private key, it can be used on its own, e.g.
+// with remotesigner.Client.OnchainKeyring.
+func VerifyReportSignature(publicKey types.OnchainPublicKey, repctx types.ReportContext, report types.Report, signature []byte) bool {
+	if len(publicKey) != common.AddressLength {
+		return false
+	}
+	signer, err := RecoverSigner(repctx, report, signature)
+	if err != nil {
+		return false
+	}
+	return signer == common.BytesToAddress(publicKey)
+}
+
+func (k *EVMOnchainKeyring) MaxSignatureLength() int {
+	return signatureLength
+}
+
+// Largest seqNr that fits into the 40 bits of epoch and round.
+const maxSeqNrForReportContext = 1<<40 - 1
+
+// ReportContextFromSeqNr maps an OCR3 (configDigest, seqNr) pair to an OCR2
+// ReportContext that the OCR2Aggregator contract accepts: the upper 32 bits
+// of the 40 bit seqNr become the epoch, the lower 8 bits the round, and the
+// extra hash is zero. The mapping preserves order, so the contract's
+// stale-report check works as expected.
+func ReportContextFromSeqNr(configDigest types.ConfigDigest, seqNr uint64) (types.ReportContext, error) {
+	if seqNr > maxSeqNrForReportContext {
+		return types.ReportContext{}, fmt.Errorf("seqNr %v does not fit into 40 bits", seqNr)
+	}
+	return types.ReportContext{
+		types.ReportTimestamp{configDigest, uint32(seqNr >> 8), uint8(seqNr)},
+		[32]byte{},
+	}, nil
+}
+
+var _ ocr3types.OnchainKeyring[struct{}] = (*EVMOCR3OnchainKeyring[struct{}])(nil)
+
+// EVMOCR3OnchainKeyring is the OCR3 counterpart of EVMOnchainKeyring. It
+// signs (configDigest, seqNr, report) by way of ReportContextFromSeqNr.
+type EVMOCR3OnchainKeyring[RI any] struct {
+	keyring *EVMOnchainKeyring
+}
+
+func NewEVMOCR3OnchainKeyring[RI any](privateKey *ecdsa.PrivateKey) *EVMOCR3OnchainKeyring[RI] {
+	return &EVMOCR3OnchainKeyring[RI]{NewEVMOnchainKeyring(privateKey)}
+}
+
+func (k *EVMOCR3OnchainKeyring[RI]) PublicKey() types.OnchainPublicKey {
+	return k.keyring.PublicKey()
+}
+
+func (k *EVMOCR3OnchainKeyring[RI]) Sign(configDigest types.ConfigDigest, seqNr uint64, rwi ocr3types.ReportWithInfo[RI]) (signature []byte, err error) {
+	repctx, err := ReportContextFromSeqNr(configDigest, seqNr)
+	if err != nil {
+		return nil, err
+	}
+	return k.keyring.Sign(repctx, rwi.Report)
+}
+
+func (k *EVMOCR3OnchainKeyring[RI]) Verify(publicKey types.OnchainPublicKey, configDigest types.ConfigDigest, seqNr uint64, rwi ocr3types.ReportWithInfo[RI], signature []byte) bool {
+	return VerifyOCR3ReportSignature(publicKey, configDigest, seqNr, rwi, signature)
+}
+
+// VerifyOCR3ReportSignature implements EVMOCR3OnchainKeyring.Verify, see
+// VerifyReportSignature.
+func VerifyOCR3ReportSignature[RI any](publicKey types.OnchainPublicKey, configDigest types.ConfigDigest, seqNr uint64, rwi ocr3types.ReportWithInfo[RI], signature []byte) bool {
+	repctx, err := ReportContextFromSeqNr(configDigest, seqNr)
+	if err != nil {
+		return false
+	}
+	return VerifyReportSignature(publicKey, repctx, rwi.Report, signature)
+}
+
+func (k *EVMOCR3OnchainKeyring[RI]) MaxSignatureLength() int {
+	return k.keyring.MaxSignatureLength()
+}
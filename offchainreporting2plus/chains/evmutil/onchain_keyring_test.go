@@ -0,0 +1,135 @@
+package evmutil_test
+
+import (
+	"bytes"
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/smartcontractkit/libocr/commontypes"
+	"github.com/smartcontractkit/libocr/offchainreporting2plus/chains/evmutil"
+	"github.com/smartcontractkit/libocr/offchainreporting2plus/ocr3types"
+	"github.com/smartcontractkit/libocr/offchainreporting2plus/types"
+)
+
+func TestEVMOnchainKeyring(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyring := evmutil.NewEVMOnchainKeyring(key)
+	address := crypto.PubkeyToAddress(key.PublicKey)
+	if !bytes.Equal(keyring.PublicKey(), address[:]) {
+		t.Fatalf("public key %x is not the signer address %x", keyring.PublicKey(), address)
+	}
+
+	repctx := types.ReportContext{types.ReportTimestamp{types.ConfigDigest{0, 1, 2}, 7, 3}, [32]byte{0xbb}}
+	report := types.Report("report")
+	sig := sign(t, keyring, repctx, report)
+	if len(sig) != keyring.MaxSignatureLength() {
+		t.Fatalf("signature has length %d, expected %d", len(sig), keyring.MaxSignatureLength())
+	}
+	if !keyring.Verify(keyring.PublicKey(), repctx, report, sig) {
+		t.Fatal("valid signature did not verify")
+	}
+	if signer, err := evmutil.RecoverSigner(repctx, report, sig); err != nil || signer != address {
+		t.Fatalf("recovered %s (error %v), expected %s", signer, err, address)
+	}
+
+	otherRepctx := repctx
+	otherRepctx.Round++
+	corrupted := append([]byte{}, sig...)
+	corrupted[0] ^= 1
+	badV := append([]byte{}, sig...)
+	badV[64] = 27
+	for name, ok := range map[string]bool{
+		"other report context": keyring.Verify(keyring.PublicKey(), otherRepctx, report, sig),
+		"other report":         keyring.Verify(keyring.PublicKey(), repctx, types.Report("other"), sig),
+		"corrupted signature":  keyring.Verify(keyring.PublicKey(), repctx, report, corrupted),
+		"truncated signature":  keyring.Verify(keyring.PublicKey(), repctx, report, sig[:64]),
+		"invalid v":            keyring.Verify(keyring.PublicKey(), repctx, report, badV),
+		"malformed public key": keyring.Verify(keyring.PublicKey()[:19], repctx, report, sig),
+		"nil signature":        keyring.Verify(keyring.PublicKey(), repctx, report, nil),
+	} {
+		if ok {
+			t.Errorf("%s: invalid signature verified", name)
+		}
+	}
+}
+
+// TestEVMOnchainKeyringsAgainstContract checks that the contract accepts
+// signatures produced by the OCR2 and OCR3 keyrings.
+func TestEVMOnchainKeyringsAgainstContract(t *testing.T) {
+	ctx := context.Background()
+	agg := deployTestAggregator(t, 4, 1)
+
+	details, err := agg.contract.LatestConfigDetails(&bind.CallOpts{Context: ctx})
+	if err != nil {
+		t.Fatal(err)
+	}
+	configDigest := types.ConfigDigest(details.ConfigDigest)
+	transmitter, err := evmutil.NewEVMContractTransmitter(agg.backend, agg.contractAddress, agg.transmitters[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectAnswer := func(expected int64) {
+		t.Helper()
+		answer, err := agg.contract.LatestAnswer(&bind.CallOpts{Context: ctx})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if answer.Cmp(big.NewInt(expected)) != 0 {
+			t.Fatalf("expected latest answer %d, got %s", expected, answer)
+		}
+	}
+
+	// OCR2
+	report := buildTestReport(t, 100)
+	repctx := types.ReportContext{types.ReportTimestamp{configDigest, 1, 1}, [32]byte{0xcc}}
+	var signatures []types.AttributedOnchainSignature
+	for _, i := range []int{0, 2} {
+		keyring := evmutil.NewEVMOnchainKeyring(agg.signerKeys[i])
+		signatures = append(signatures, types.AttributedOnchainSignature{sign(t, keyring, repctx, report), commontypes.OracleID(i)})
+	}
+	if err := transmitter.Transmit(ctx, repctx, report, signatures); err != nil {
+		t.Fatal(err)
+	}
+	agg.backend.Commit()
+	expectAnswer(100)
+
+	// OCR3
+	const seqNr = 1000
+	rwi := ocr3types.ReportWithInfo[struct{}]{buildTestReport(t, 200), struct{}{}}
+	signatures = nil
+	for _, i := range []int{1, 3} {
+		keyring := evmutil.NewEVMOCR3OnchainKeyring[struct{}](agg.signerKeys[i])
+		sig, err := keyring.Sign(configDigest, seqNr, rwi)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !keyring.Verify(keyring.PublicKey(), configDigest, seqNr, rwi, sig) {
+			t.Fatal("valid OCR3 signature did not verify")
+		}
+		if keyring.Verify(keyring.PublicKey(), configDigest, seqNr+1, rwi, sig) {
+			t.Fatal("OCR3 signature verified for wrong seqNr")
+		}
+		signatures = append(signatures, types.AttributedOnchainSignature{sig, commontypes.OracleID(i)})
+	}
+	repctx, err = evmutil.ReportContextFromSeqNr(configDigest, seqNr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := transmitter.Transmit(ctx, repctx, rwi.Report, signatures); err != nil {
+		t.Fatal(err)
+	}
+	agg.backend.Commit()
+	expectAnswer(200)
+
+	if _, err := evmutil.ReportContextFromSeqNr(configDigest, 1<<40); err == nil {
+		t.Fatal("expected error for seqNr that does not fit into 40 bits")
+	}
+}
@@ -0,0 +1,158 @@
+package evmutil
+
+import (
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/smartcontractkit/libocr/offchainreporting2plus/types"
+)
+
+// TransmitRejectionReason identifies the check of OCR2Aggregator.transmit
+// that a transmission would fail.
+type TransmitRejectionReason int
+
+const (
+	_ TransmitRejectionReason = iota
+	// The report's (epoch, round) is not greater than the latest accepted one.
+	TransmitRejectionStaleReport
+	// The transmitter is not one of the configured transmitters.
+	TransmitRejectionUnauthorizedTransmitter
+	// The report context's config digest differs from the latest config.
+	TransmitRejectionConfigDigestMismatch
+	// The number of signatures is not f+1.
+	TransmitRejectionWrongNumberOfSignatures
+	// A signature is malformed or was not produced by a configured signer.
+	TransmitRejectionSignatureError
+	// Two signatures were produced by the same signer.
+	TransmitRejectionDuplicateSigner
+)
+
+func (r TransmitRejectionReason) String() string {
+	switch r {
+	case TransmitRejectionStaleReport:
+		return "stale report"
+	case TransmitRejectionUnauthorizedTransmitter:
+		return "unauthorized transmitter"
+	case TransmitRejectionConfigDigestMismatch:
+		return "configDigest mismatch"
+	case TransmitRejectionWrongNumberOfSignatures:
+		return "wrong number of signatures"
+	case TransmitRejectionSignatureError:
+		return "signature error"
+	case TransmitRejectionDuplicateSigner:
+		return "duplicate signer"
+	}
+	return fmt.Sprintf("TransmitRejectionReason(%d)", int(r))
+}
+
+// TransmitRejection explains why OCR2Aggregator.transmit would revert.
+type TransmitRejection struct {
+	Reason TransmitRejectionReason
+	// Index into the signatures passed to VerifyTransmit of the offending
+	// signature, for TransmitRejectionSignatureError and
+	// TransmitRejectionDuplicateSigner. -1 otherwise.
+	SignatureIndex int
+	// Human-readable details
+	Details string
+}
+
+func (r *TransmitRejection) Error() string {
+	if r.Details == "" {
+		return r.Reason.String()
+	}
+	return fmt.Sprintf("%s: %s", r.Reason, r.Details)
+}
+
+// TransmitContractState is the part of the OCR2Aggregator's state that
+// transmit validates against.
+type TransmitContractState struct {
+	// The latest config, as returned by ContractConfigTracker.LatestConfig
+	Config types.ContractConfig
+	// Epoch and round of the latest report accepted under Config. Both are
+	// zero if no report has been accepted yet.
+	LatestEpoch uint32
+	LatestRound uint8
+}
+
+// VerifyTransmit mirrors the validation performed by OCR2Aggregator.transmit
+// and returns nil if the contract would accept the transmission. Otherwise it
+// returns a *TransmitRejection describing the first failed check, in the same
+// order in which the contract performs its checks.
+//
+// If transmitter is empty, the transmitter check is skipped. Validation of the
+// report's contents (e.g. the median's min/max range) is out of scope.
+func VerifyTransmit(
+	state TransmitContractState,
+	transmitter types.Account,
+	repctx types.ReportContext,
+	report types.Report,
+	signatures []types.AttributedOnchainSignature,
+) error {
+	reject := func(reason TransmitRejectionReason, signatureIndex int, format string, args ...interface{}) error {
+		return &TransmitRejection{reason, signatureIndex, fmt.Sprintf(format, args...)}
+	}
+
+	latestEpochAndRound := uint64(state.LatestEpoch)<<8 | uint64(state.LatestRound)
+	epochAndRound := uint64(repctx.Epoch)<<8 | uint64(repctx.Round)
+	if latestEpochAndRound >= epochAndRound {
+		return reject(TransmitRejectionStaleReport, -1,
+			"report has epoch %d round %d, latest accepted report has epoch %d round %d",
+			repctx.Epoch, repctx.Round, state.LatestEpoch, state.LatestRound)
+	}
+
+	if transmitter != "" {
+		authorized := false
+		for _, t := range state.Config.Transmitters {
+			if common.IsHexAddress(string(t)) && common.IsHexAddress(string(transmitter)) &&
+				common.HexToAddress(string(t)) == common.HexToAddress(string(transmitter)) {
+				authorized = true
+				break
+			}
+		}
+		if !authorized {
+			return reject(TransmitRejectionUnauthorizedTransmitter, -1, "%s is not a configured transmitter", transmitter)
+		}
+	}
+
+	if repctx.ConfigDigest != state.Config.ConfigDigest {
+		return reject(TransmitRejectionConfigDigestMismatch, -1,
+			"report has config digest %s, contract has %s", repctx.ConfigDigest, state.Config.ConfigDigest)
+	}
+
+	if len(signatures) != int(state.Config.F)+1 {
+		return reject(TransmitRejectionWrongNumberOfSignatures, -1,
+			"got %d signatures, expected f+1 = %d", len(signatures), int(state.Config.F)+1)
+	}
+
+	signerIndices := map[common.Address]int{}
+	for i, signer := range state.Config.Signers {
+		if len(signer) == common.AddressLength {
+			signerIndices[common.BytesToAddress(signer)] = i
+		}
+	}
+	// Like the contract, check all signatures before checking for duplicates.
+	recoveredSignerIndices := make([]int, 0, len(signatures))
+	for i, as := range signatures {
+		address, err := RecoverSigner(repctx, report, as.Signature)
+		if err != nil {
+			return reject(TransmitRejectionSignatureError, i, "signature attributed to oracle %d: %v", as.Signer, err)
+		}
+		signerIndex, ok := signerIndices[address]
+		if !ok {
+			return reject(TransmitRejectionSignatureError, i,
+				"signature attributed to oracle %d recovers to %s, which is not a configured signer", as.Signer, address.Hex())
+		}
+		recoveredSignerIndices = append(recoveredSignerIndices, signerIndex)
+	}
+	signedBy := map[int]int{} // signer index -> signature index
+	for i, signerIndex := range recoveredSignerIndices {
+		if previous, ok := signedBy[signerIndex]; ok {
+			return reject(TransmitRejectionDuplicateSigner, i,
+				"signatures %d and %d were both produced by signer %d", previous, i, signerIndex)
+		}
+		signedBy[signerIndex] = i
+	}
+
+	return nil
+}
@@ -0,0 +1,99 @@
+package evmutil_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/smartcontractkit/libocr/commontypes"
+	"github.com/smartcontractkit/libocr/offchainreporting2plus/chains/evmutil"
+	"github.com/smartcontractkit/libocr/offchainreporting2plus/types"
+)
+
+// TestVerifyTransmit checks that VerifyTransmit agrees with the contract.
+func TestVerifyTransmit(t *testing.T) {
+	ctx := context.Background()
+	agg := deployTestAggregator(t, 4, 1)
+
+	tracker, err := evmutil.NewEVMContractConfigTracker(agg.backend, agg.contractAddress)
+	if err != nil {
+		t.Fatal(err)
+	}
+	changedInBlock, _, err := tracker.LatestConfigDetails(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config, err := tracker.LatestConfig(ctx, changedInBlock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	transmitter, err := evmutil.NewEVMContractTransmitter(agg.backend, agg.contractAddress, agg.transmitters[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	from, _ := transmitter.FromAccount()
+
+	report := buildTestReport(t, 50)
+	repctx := types.ReportContext{types.ReportTimestamp{config.ConfigDigest, 2, 2}, [32]byte{}}
+	sig := func(i int, repctx types.ReportContext) types.AttributedOnchainSignature {
+		return types.AttributedOnchainSignature{sign(t, evmutil.NewEVMOnchainKeyring(agg.signerKeys[i]), repctx, report), commontypes.OracleID(i)}
+	}
+	otherRepctx := repctx
+	otherRepctx.ConfigDigest[31] ^= 1
+	unconfiguredSigner := func() types.AttributedOnchainSignature {
+		key, _ := newKeyedTransactor(t)
+		return types.AttributedOnchainSignature{sign(t, evmutil.NewEVMOnchainKeyring(key), repctx, report), 0}
+	}()
+
+	for _, tc := range []struct {
+		name       string
+		repctx     types.ReportContext
+		signatures []types.AttributedOnchainSignature
+		reason     evmutil.TransmitRejectionReason
+	}{
+		{"config digest mismatch", otherRepctx, []types.AttributedOnchainSignature{sig(0, otherRepctx), sig(1, otherRepctx)}, evmutil.TransmitRejectionConfigDigestMismatch},
+		{"too few signatures", repctx, []types.AttributedOnchainSignature{sig(0, repctx)}, evmutil.TransmitRejectionWrongNumberOfSignatures},
+		{"too many signatures", repctx, []types.AttributedOnchainSignature{sig(0, repctx), sig(1, repctx), sig(2, repctx)}, evmutil.TransmitRejectionWrongNumberOfSignatures},
+		{"unconfigured signer", repctx, []types.AttributedOnchainSignature{sig(0, repctx), unconfiguredSigner}, evmutil.TransmitRejectionSignatureError},
+		{"signature over other context", repctx, []types.AttributedOnchainSignature{sig(0, repctx), sig(1, otherRepctx)}, evmutil.TransmitRejectionSignatureError},
+		{"duplicate signer", repctx, []types.AttributedOnchainSignature{sig(3, repctx), sig(3, repctx)}, evmutil.TransmitRejectionDuplicateSigner},
+	} {
+		state := evmutil.TransmitContractState{config, 0, 0}
+		err := evmutil.VerifyTransmit(state, from, tc.repctx, report, tc.signatures)
+		var rejection *evmutil.TransmitRejection
+		if !errors.As(err, &rejection) || rejection.Reason != tc.reason {
+			t.Errorf("%s: expected rejection %q, got %v", tc.name, tc.reason, err)
+		}
+		if err := transmitter.Transmit(ctx, tc.repctx, report, tc.signatures); err == nil {
+			t.Errorf("%s: contract accepted transmission that VerifyTransmit rejected", tc.name)
+		}
+	}
+
+	signatures := []types.AttributedOnchainSignature{sig(2, repctx), sig(0, repctx)}
+	if err := evmutil.VerifyTransmit(evmutil.TransmitContractState{config, 0, 0}, "", repctx, report, signatures); err != nil {
+		t.Fatalf("expected transmission without transmitter check to be accepted, got %v", err)
+	}
+	if err := evmutil.VerifyTransmit(evmutil.TransmitContractState{config, 0, 0}, types.Account(agg.owner.From.Hex()), repctx, report, signatures); err == nil {
+		t.Fatal("expected unauthorized transmitter to be rejected")
+	}
+	if err := evmutil.VerifyTransmit(evmutil.TransmitContractState{config, 0, 0}, from, repctx, report, signatures); err != nil {
+		t.Fatalf("expected transmission to be accepted, got %v", err)
+	}
+	if err := transmitter.Transmit(ctx, repctx, report, signatures); err != nil {
+		t.Fatalf("contract rejected transmission that VerifyTransmit accepted: %v", err)
+	}
+	agg.backend.Commit()
+
+	_, epoch, err := transmitter.LatestConfigDigestAndEpoch(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = evmutil.VerifyTransmit(evmutil.TransmitContractState{config, epoch, 2}, from, repctx, report, signatures)
+	var rejection *evmutil.TransmitRejection
+	if !errors.As(err, &rejection) || rejection.Reason != evmutil.TransmitRejectionStaleReport {
+		t.Fatalf("expected stale report, got %v", err)
+	}
+	if err := transmitter.Transmit(ctx, repctx, report, signatures); err == nil {
+		t.Fatal("contract accepted stale report")
+	}
+}
@@ -0,0 +1,373 @@
+// Package fakechain simulates an OCR2Aggregator contract on an in-memory
+// chain, so that oracles can be tested end-to-end without an Ethereum node.
+//
+// The simulated contract follows the rules of OCR2Aggregator.setConfig and
+// OCR2Aggregator.transmit: config digests are computed with
+// evmutil.EVMOffchainConfigDigester, and transmitted reports must carry
+// exactly f+1 valid signatures from distinct configured signers. The chain has
+// explicit block heights, so that ContractConfigConfirmations can be
+// exercised, and supports injecting reorgs.
+//
+// Use Chain.ConfigTracker and Chain.Transmitter to obtain the
+// types.ContractConfigTracker and types.ContractTransmitter to pass to
+// offchainreporting2plus.NewOracle.
+//
+// This package is intended for tests only.
+package fakechain
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/smartcontractkit/libocr/clock"
+	"github.com/smartcontractkit/libocr/offchainreporting2plus/chains/evmutil"
+	"github.com/smartcontractkit/libocr/offchainreporting2plus/types"
+)
+
+// Config configures a Chain.
+type Config struct {
+	// ChainID and ContractAddress are used for computing config digests.
+	ChainID         uint64
+	ContractAddress common.Address
+	// Blocks with at least FinalityDepth confirmations are final and cannot
+	// be reorged. If FinalityDepth is zero, any block can be reorged.
+	FinalityDepth uint64
+}
+
+// Transmission describes a successful call to transmit.
+type Transmission struct {
+	BlockNumber   uint64
+	ConfigDigest  types.ConfigDigest
+	Epoch         uint32
+	Round         uint8
+	Report        types.Report
+	Transmitter   types.Account
+	SignerIndices []int
+}
+
+// Chain is an in-memory chain containing a single simulated OCR2Aggregator
+// contract. Every successful transaction is included in a new block.
+//
+// All its functions are thread-safe.
+type Chain struct {
+	config    Config
+	digester  evmutil.EVMOffchainConfigDigester
+	mu        sync.Mutex
+	height    uint64
+	snapshots []snapshot // state after each transaction, in block order
+	trackers  []*configTracker
+}
+
+// snapshot is the state of the contract after the transaction included in
+// blockNumber.
+type snapshot struct {
+	blockNumber uint64
+	state       contractState
+}
+
+type contractState struct {
+	configCount             uint64
+	latestConfigBlockNumber uint64
+	latestConfig            types.ContractConfig
+	signers                 map[common.Address]int
+	latestEpochAndRound     uint64 // uint40 in the contract
+	transmission            *Transmission
+}
+
+func NewChain(config Config) *Chain {
+	return &Chain{
+		config,
+		evmutil.EVMOffchainConfigDigester{config.ChainID, config.ContractAddress},
+		sync.Mutex{},
+		0,
+		nil,
+		nil,
+	}
+}
+
+// OffchainConfigDigester returns the digester the contract uses to compute
+// config digests.
+func (c *Chain) OffchainConfigDigester() types.OffchainConfigDigester {
+	return c.digester
+}
+
+// BlockHeight returns the height of the most recent block.
+func (c *Chain) BlockHeight() uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.height
+}
+
+// Mine appends n empty blocks to the chain.
+func (c *Chain) Mine(n uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.height += n
+}
+
+// Run mines an empty block every interval, as measured by clk, until ctx is
+// done.
+func (c *Chain) Run(ctx context.Context, clk clock.Clock, interval time.Duration) {
+	clk = clock.OrReal(clk)
+	for {
+		select {
+		case <-clk.After(interval):
+			c.Mine(1)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Reorg removes the most recent depth blocks, together with all transactions
+// included in them, and replaces them with depth+1 empty blocks. Trackers are
+// notified if the reorg changed the latest config. Reorg returns an error if
+// it would remove a final block.
+func (c *Chain) Reorg(depth uint64) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if depth > c.height {
+		return fmt.Errorf("cannot reorg %d blocks, chain only has %d", depth, c.height)
+	}
+	if c.config.FinalityDepth != 0 && depth >= c.config.FinalityDepth {
+		return fmt.Errorf("cannot reorg %d blocks, blocks with %d confirmations are final", depth, c.config.FinalityDepth)
+	}
+	forkPoint := c.height - depth
+	configBefore := c.state().latestConfig.ConfigDigest
+	for len(c.snapshots) > 0 && c.snapshots[len(c.snapshots)-1].blockNumber > forkPoint {
+		c.snapshots = c.snapshots[:len(c.snapshots)-1]
+	}
+	c.height = forkPoint + depth + 1
+	if c.state().latestConfig.ConfigDigest != configBefore {
+		c.notifyTrackers()
+	}
+	return nil
+}
+
+// SetConfig calls setConfig on the contract and returns the new config
+// digest. The arguments have the same shape as those returned by
+// confighelper.ContractSetConfigArgsForTests. Signers must be 20 byte
+// addresses and transmitters hex-encoded addresses.
+func (c *Chain) SetConfig(
+	signers []types.OnchainPublicKey,
+	transmitters []types.Account,
+	f uint8,
+	onchainConfig []byte,
+	offchainConfigVersion uint64,
+	offchainConfig []byte,
+) (types.ConfigDigest, error) {
+	if len(signers) > types.MaxOracles {
+		return types.ConfigDigest{}, fmt.Errorf("too many signers")
+	}
+	if f == 0 {
+		return types.ConfigDigest{}, fmt.Errorf("f must be positive")
+	}
+	if len(signers) != len(transmitters) {
+		return types.ConfigDigest{}, fmt.Errorf("oracle addresses out of registration")
+	}
+	if 3*int(f) >= len(signers) {
+		return types.ConfigDigest{}, fmt.Errorf("faulty-oracle f too high")
+	}
+
+	signerIndices := map[common.Address]int{}
+	transmitterSet := map[common.Address]bool{}
+	for i := range signers {
+		if len(signers[i]) != common.AddressLength {
+			return types.ConfigDigest{}, fmt.Errorf("%d-th signer should be a 20 byte address, but got %x", i, signers[i])
+		}
+		signer := common.BytesToAddress(signers[i])
+		if signer == (common.Address{}) {
+			return types.ConfigDigest{}, fmt.Errorf("signer must not be empty")
+		}
+		if _, ok := signerIndices[signer]; ok {
+			return types.ConfigDigest{}, fmt.Errorf("repeated signer address")
+		}
+		signerIndices[signer] = i
+
+		transmitter, err := parseAccount(transmitters[i])
+		if err != nil {
+			return types.ConfigDigest{}, fmt.Errorf("%d-th transmitter: %w", i, err)
+		}
+		if transmitter == (common.Address{}) {
+			return types.ConfigDigest{}, fmt.Errorf("transmitter must not be empty")
+		}
+		if transmitterSet[transmitter] {
+			return types.ConfigDigest{}, fmt.Errorf("repeated transmitter address")
+		}
+		transmitterSet[transmitter] = true
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	blockNumber := c.height + 1
+	contractConfig := types.ContractConfig{
+		types.ConfigDigest{},
+		c.state().configCount + 1,
+		copyOnchainPublicKeys(signers),
+		append([]types.Account{}, transmitters...),
+		f,
+		append([]byte{}, onchainConfig...),
+		offchainConfigVersion,
+		append([]byte{}, offchainConfig...),
+	}
+	configDigest, err := c.digester.ConfigDigest(contractConfig)
+	if err != nil {
+		return types.ConfigDigest{}, err
+	}
+	contractConfig.ConfigDigest = configDigest
+
+	c.include(contractState{
+		contractConfig.ConfigCount,
+		blockNumber,
+		contractConfig,
+		signerIndices,
+		0,
+		c.state().transmission,
+	})
+	c.notifyTrackers()
+	return configDigest, nil
+}
+
+// Transmissions returns all successful transmissions on the canonical chain,
+// in the order in which they were included.
+func (c *Chain) Transmissions() []Transmission {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var result []Transmission
+	var previous *Transmission
+	for _, s := range c.snapshots {
+		if s.state.transmission != nil && s.state.transmission != previous {
+			result = append(result, copyTransmission(*s.state.transmission))
+		}
+		previous = s.state.transmission
+	}
+	return result
+}
+
+// LatestTransmission returns the most recent successful transmission on the
+// canonical chain, if any.
+func (c *Chain) LatestTransmission() (Transmission, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	t := c.state().transmission
+	if t == nil {
+		return Transmission{}, false
+	}
+	return copyTransmission(*t), true
+}
+
+func (c *Chain) transmit(
+	from common.Address,
+	reportContext types.ReportContext,
+	report types.Report,
+	signatures []types.AttributedOnchainSignature,
+) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	state := c.state()
+	err := evmutil.VerifyTransmit(
+		evmutil.TransmitContractState{
+			state.latestConfig,
+			uint32(state.latestEpochAndRound >> 8),
+			uint8(state.latestEpochAndRound),
+		},
+		types.Account(from.Hex()),
+		reportContext,
+		report,
+		signatures,
+	)
+	if err != nil {
+		return err
+	}
+
+	signerIndices := make([]int, 0, len(signatures))
+	for _, sig := range signatures {
+		signer, err := evmutil.RecoverSigner(reportContext, report, sig.Signature)
+		if err != nil {
+			return fmt.Errorf("signature passed VerifyTransmit but cannot be recovered: %w", err)
+		}
+		signerIndices = append(signerIndices, state.signers[signer])
+	}
+
+	epochAndRound := uint64(reportContext.Epoch)<<8 | uint64(reportContext.Round)
+	next := state
+	next.latestEpochAndRound = epochAndRound
+	next.transmission = &Transmission{
+		c.height + 1,
+		reportContext.ConfigDigest,
+		reportContext.Epoch,
+		reportContext.Round,
+		append(types.Report{}, report...),
+		types.Account(from.Hex()),
+		signerIndices,
+	}
+	c.include(next)
+	return nil
+}
+
+// state must be called with c.mu held.
+func (c *Chain) state() contractState {
+	if len(c.snapshots) == 0 {
+		return contractState{}
+	}
+	return c.snapshots[len(c.snapshots)-1].state
+}
+
+// include appends a new block containing a transaction that moved the
+// contract to state. It must be called with c.mu held.
+func (c *Chain) include(state contractState) {
+	c.height++
+	c.snapshots = append(c.snapshots, snapshot{c.height, state})
+}
+
+// notifyTrackers must be called with c.mu held.
+func (c *Chain) notifyTrackers() {
+	for _, t := range c.trackers {
+		select {
+		case t.chNotify <- struct{}{}:
+		default:
+		}
+	}
+}
+
+func parseAccount(account types.Account) (common.Address, error) {
+	s := string(account)
+	if !strings.HasPrefix(s, "0x") || len(s) != 42 || !common.IsHexAddress(s) {
+		return common.Address{}, fmt.Errorf("account should be a 42 character Ethereum address string, but got '%v'", account)
+	}
+	return common.HexToAddress(s), nil
+}
+
+func copyOnchainPublicKeys(keys []types.OnchainPublicKey) []types.OnchainPublicKey {
+	result := make([]types.OnchainPublicKey, 0, len(keys))
+	for _, k := range keys {
+		result = append(result, append(types.OnchainPublicKey{}, k...))
+	}
+	return result
+}
+
+func copyContractConfig(cc types.ContractConfig) types.ContractConfig {
+	return types.ContractConfig{
+		cc.ConfigDigest,
+		cc.ConfigCount,
+		copyOnchainPublicKeys(cc.Signers),
+		append([]types.Account{}, cc.Transmitters...),
+		cc.F,
+		append([]byte{}, cc.OnchainConfig...),
+		cc.OffchainConfigVersion,
+		append([]byte{}, cc.OffchainConfig...),
+	}
+}
+
+func copyTransmission(t Transmission) Transmission {
+	t.Report = append(types.Report{}, t.Report...)
+	t.SignerIndices = append([]int{}, t.SignerIndices...)
+	return t
+}
@@ -0,0 +1,294 @@
+package fakechain_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/smartcontractkit/libocr/commontypes"
+	"github.com/smartcontractkit/libocr/offchainreporting2plus/chains/evmutil"
+	"github.com/smartcontractkit/libocr/offchainreporting2plus/chains/fakechain"
+	"github.com/smartcontractkit/libocr/offchainreporting2plus/types"
+)
+
+const (
+	n = 4
+	f = 1
+)
+
+type testOracles struct {
+	signerKeys   []*ecdsa.PrivateKey
+	signers      []types.OnchainPublicKey
+	transmitters []types.Account
+}
+
+func newTestOracles(t *testing.T) testOracles {
+	t.Helper()
+	var oracles testOracles
+	for i := 0; i < n; i++ {
+		key, err := crypto.ToECDSA(common.LeftPadBytes([]byte{byte(i + 1)}, 32))
+		if err != nil {
+			t.Fatal(err)
+		}
+		oracles.signerKeys = append(oracles.signerKeys, key)
+		oracles.signers = append(oracles.signers, evmutil.NewEVMOnchainKeyring(key).PublicKey())
+		oracles.transmitters = append(oracles.transmitters, types.Account(common.BigToAddress(big.NewInt(int64(0x100+i))).Hex()))
+	}
+	return oracles
+}
+
+func (o testOracles) setConfig(t *testing.T, chain *fakechain.Chain) types.ConfigDigest {
+	t.Helper()
+	configDigest, err := chain.SetConfig(o.signers, o.transmitters, f, []byte{1}, 2, []byte{3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return configDigest
+}
+
+// signatures returns signatures of the given oracles over report.
+func (o testOracles) signatures(t *testing.T, repctx types.ReportContext, report types.Report, oracles ...int) []types.AttributedOnchainSignature {
+	t.Helper()
+	result := []types.AttributedOnchainSignature{}
+	for _, i := range oracles {
+		sig, err := evmutil.NewEVMOnchainKeyring(o.signerKeys[i]).Sign(repctx, report)
+		if err != nil {
+			t.Fatal(err)
+		}
+		result = append(result, types.AttributedOnchainSignature{sig, commontypes.OracleID(i)})
+	}
+	return result
+}
+
+func (o testOracles) transmit(t *testing.T, chain *fakechain.Chain, configDigest types.ConfigDigest, epoch uint32) error {
+	t.Helper()
+	repctx := types.ReportContext{types.ReportTimestamp{configDigest, epoch, 1}, [32]byte{}}
+	report := types.Report{byte(epoch)}
+	return chain.Transmitter(o.transmitters[0]).Transmit(context.Background(), repctx, report, o.signatures(t, repctx, report, 1, 2))
+}
+
+func latestConfig(t *testing.T, tracker types.ContractConfigTracker) (uint64, types.ContractConfig) {
+	t.Helper()
+	ctx := context.Background()
+	changedInBlock, configDigest, err := tracker.LatestConfigDetails(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if configDigest == (types.ConfigDigest{}) {
+		return changedInBlock, types.ContractConfig{}
+	}
+	config, err := tracker.LatestConfig(ctx, changedInBlock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.ConfigDigest != configDigest {
+		t.Fatalf("LatestConfig returned config digest %v, LatestConfigDetails %v", config.ConfigDigest, configDigest)
+	}
+	return changedInBlock, config
+}
+
+func expectNotified(t *testing.T, tracker types.ContractConfigTracker, expected bool) {
+	t.Helper()
+	select {
+	case <-tracker.Notify():
+		if !expected {
+			t.Fatalf("tracker was notified unexpectedly")
+		}
+	default:
+		if expected {
+			t.Fatalf("tracker was not notified")
+		}
+	}
+}
+
+func TestSetConfig(t *testing.T) {
+	oracles := newTestOracles(t)
+	chain := fakechain.NewChain(fakechain.Config{1, common.Address{2}, 0})
+	tracker := chain.ConfigTracker()
+
+	chain.Mine(5)
+	configDigest := oracles.setConfig(t, chain)
+	expectNotified(t, tracker, true)
+
+	changedInBlock, config := latestConfig(t, tracker)
+	expected := types.ContractConfig{configDigest, 1, oracles.signers, oracles.transmitters, f, []byte{1}, 2, []byte{3}}
+	if changedInBlock != 6 || !reflect.DeepEqual(config, expected) {
+		t.Fatalf("expected config %+v in block 6, got %+v in block %v", expected, config, changedInBlock)
+	}
+	if digest, err := chain.OffchainConfigDigester().ConfigDigest(config); err != nil || digest != configDigest {
+		t.Fatalf("expected digester to compute %v, got %v, %v", configDigest, digest, err)
+	}
+	if _, err := tracker.LatestConfig(context.Background(), 5); err == nil {
+		t.Fatalf("expected error for block without ConfigSet event")
+	}
+
+	for name, modify := range map[string]func(o *testOracles){
+		"mismatched lengths":   func(o *testOracles) { o.transmitters = o.transmitters[1:] },
+		"repeated signer":      func(o *testOracles) { o.signers[1] = o.signers[0] },
+		"repeated transmitter": func(o *testOracles) { o.transmitters[1] = o.transmitters[0] },
+		"short signer":         func(o *testOracles) { o.signers[0] = o.signers[0][1:] },
+		"non-hex transmitter":  func(o *testOracles) { o.transmitters[0] = "transmitter" },
+		"f too high":           func(o *testOracles) { o.signers, o.transmitters = o.signers[:3], o.transmitters[:3] },
+	} {
+		invalid := newTestOracles(t)
+		modify(&invalid)
+		if _, err := chain.SetConfig(invalid.signers, invalid.transmitters, f, nil, 2, nil); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+	if height := chain.BlockHeight(); height != 6 {
+		t.Fatalf("rejected configs changed block height to %v", height)
+	}
+	expectNotified(t, tracker, false)
+}
+
+func TestReorg(t *testing.T) {
+	oracles := newTestOracles(t)
+	chain := fakechain.NewChain(fakechain.Config{1, common.Address{2}, 0})
+	tracker := chain.ConfigTracker()
+
+	configDigest1 := oracles.setConfig(t, chain)
+	chain.Mine(2)
+	configDigest2 := oracles.setConfig(t, chain)
+	if err := oracles.transmit(t, chain, configDigest2, 1); err != nil {
+		t.Fatal(err)
+	}
+	expectNotified(t, tracker, true)
+	// Blocks: 1 config1, 2, 3, 4 config2, 5 transmission
+	if height := chain.BlockHeight(); height != 5 {
+		t.Fatalf("expected height 5, got %v", height)
+	}
+
+	// Reorging only the transmission does not touch the config
+	if err := chain.Reorg(1); err != nil {
+		t.Fatal(err)
+	}
+	expectNotified(t, tracker, false)
+	if _, ok := chain.LatestTransmission(); ok || len(chain.Transmissions()) != 0 {
+		t.Fatalf("expected transmission to be reorged away, got %v", chain.Transmissions())
+	}
+	if digest, epoch, err := chain.Transmitter(oracles.transmitters[0]).LatestConfigDigestAndEpoch(context.Background()); err != nil || digest != configDigest2 || epoch != 0 {
+		t.Fatalf("expected config digest %v and epoch 0, got %v, %v, %v", configDigest2, digest, epoch, err)
+	}
+	// Blocks: 1 config1, 2, 3, 4 config2, 5, 6
+	if height := chain.BlockHeight(); height != 6 {
+		t.Fatalf("expected height 6, got %v", height)
+	}
+
+	if err := chain.Reorg(3); err != nil {
+		t.Fatal(err)
+	}
+	expectNotified(t, tracker, true)
+	if changedInBlock, config := latestConfig(t, tracker); changedInBlock != 1 || config.ConfigDigest != configDigest1 {
+		t.Fatalf("expected first config in block 1, got %v in block %v", config.ConfigDigest, changedInBlock)
+	}
+
+	if err := chain.Reorg(chain.BlockHeight() + 1); err == nil {
+		t.Fatalf("expected error when reorging more blocks than exist")
+	}
+	if err := chain.Reorg(chain.BlockHeight()); err != nil {
+		t.Fatal(err)
+	}
+	if changedInBlock, config := latestConfig(t, tracker); changedInBlock != 0 || config.ConfigDigest != (types.ConfigDigest{}) {
+		t.Fatalf("expected no config, got %v in block %v", config.ConfigDigest, changedInBlock)
+	}
+}
+
+func TestFinalityDepth(t *testing.T) {
+	oracles := newTestOracles(t)
+	chain := fakechain.NewChain(fakechain.Config{1, common.Address{2}, 3})
+	tracker := chain.ConfigTracker()
+
+	configDigest := oracles.setConfig(t, chain)
+	chain.Mine(2)
+	// Block 1 has 3 confirmations and is final
+	if err := chain.Reorg(3); err == nil {
+		t.Fatalf("expected error when reorging final block")
+	}
+	if err := chain.Reorg(2); err != nil {
+		t.Fatal(err)
+	}
+	// Block 1 has 4 confirmations now
+	if height := chain.BlockHeight(); height != 4 {
+		t.Fatalf("expected height 4, got %v", height)
+	}
+	if err := chain.Reorg(3); err == nil {
+		t.Fatalf("expected error when reorging final block")
+	}
+	if _, config := latestConfig(t, tracker); config.ConfigDigest != configDigest {
+		t.Fatalf("final config was reorged away")
+	}
+}
+
+func TestTransmit(t *testing.T) {
+	oracles := newTestOracles(t)
+	chain := fakechain.NewChain(fakechain.Config{1, common.Address{2}, 0})
+	configDigest := oracles.setConfig(t, chain)
+
+	if err := oracles.transmit(t, chain, configDigest, 2); err != nil {
+		t.Fatal(err)
+	}
+	transmission, ok := chain.LatestTransmission()
+	expected := fakechain.Transmission{2, configDigest, 2, 1, types.Report{2}, oracles.transmitters[0], []int{1, 2}}
+	if !ok || !reflect.DeepEqual(transmission, expected) {
+		t.Fatalf("expected transmission %+v, got %+v", expected, transmission)
+	}
+
+	repctx := types.ReportContext{types.ReportTimestamp{configDigest, 3, 1}, [32]byte{}}
+	report := types.Report{3}
+	for _, test := range []struct {
+		name        string
+		transmitter types.Account
+		repctx      types.ReportContext
+		signatures  []types.AttributedOnchainSignature
+		reason      evmutil.TransmitRejectionReason
+	}{
+		{
+			"stale",
+			oracles.transmitters[0],
+			types.ReportContext{types.ReportTimestamp{configDigest, 2, 1}, [32]byte{}},
+			oracles.signatures(t, types.ReportContext{types.ReportTimestamp{configDigest, 2, 1}, [32]byte{}}, report, 0, 1),
+			evmutil.TransmitRejectionStaleReport,
+		},
+		{
+			"unauthorized transmitter",
+			types.Account(common.Address{0xff}.Hex()),
+			repctx,
+			oracles.signatures(t, repctx, report, 0, 1),
+			evmutil.TransmitRejectionUnauthorizedTransmitter,
+		},
+		{
+			"too few signatures",
+			oracles.transmitters[0],
+			repctx,
+			oracles.signatures(t, repctx, report, 0),
+			evmutil.TransmitRejectionWrongNumberOfSignatures,
+		},
+		{
+			"duplicate signer",
+			oracles.transmitters[0],
+			repctx,
+			oracles.signatures(t, repctx, report, 3, 3),
+			evmutil.TransmitRejectionDuplicateSigner,
+		},
+	} {
+		err := chain.Transmitter(test.transmitter).Transmit(context.Background(), test.repctx, report, test.signatures)
+		var rejection *evmutil.TransmitRejection
+		if !errors.As(err, &rejection) || rejection.Reason != test.reason {
+			t.Errorf("%s: expected rejection %v, got %v", test.name, test.reason, err)
+		}
+	}
+
+	if transmissions := chain.Transmissions(); len(transmissions) != 1 || chain.BlockHeight() != 2 {
+		t.Fatalf("rejected transmissions changed the chain: %v", transmissions)
+	}
+	if err := chain.Transmitter("transmitter").Transmit(context.Background(), repctx, report, oracles.signatures(t, repctx, report, 0, 1)); err == nil {
+		t.Fatalf("expected error for invalid transmitter account")
+	}
+}
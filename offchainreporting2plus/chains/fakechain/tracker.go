@@ -0,0 +1,56 @@
+package fakechain
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/smartcontractkit/libocr/offchainreporting2plus/types"
+)
+
+// ConfigTracker returns a new types.ContractConfigTracker for the contract.
+// Its Notify channel fires whenever the latest config changes, either
+// because of a call to SetConfig or because of a reorg.
+func (c *Chain) ConfigTracker() types.ContractConfigTracker {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	t := &configTracker{c, make(chan struct{}, 1)}
+	c.trackers = append(c.trackers, t)
+	return t
+}
+
+type configTracker struct {
+	chain    *Chain
+	chNotify chan struct{}
+}
+
+var _ types.ContractConfigTracker = (*configTracker)(nil)
+
+func (t *configTracker) Notify() <-chan struct{} {
+	return t.chNotify
+}
+
+func (t *configTracker) LatestConfigDetails(ctx context.Context) (changedInBlock uint64, configDigest types.ConfigDigest, err error) {
+	t.chain.mu.Lock()
+	defer t.chain.mu.Unlock()
+	state := t.chain.state()
+	return state.latestConfigBlockNumber, state.latestConfig.ConfigDigest, nil
+}
+
+func (t *configTracker) LatestConfig(ctx context.Context, changedInBlock uint64) (types.ContractConfig, error) {
+	t.chain.mu.Lock()
+	defer t.chain.mu.Unlock()
+	for i := len(t.chain.snapshots) - 1; i >= 0; i-- {
+		s := t.chain.snapshots[i]
+		if s.blockNumber < changedInBlock {
+			break
+		}
+		if s.blockNumber == changedInBlock && s.state.latestConfigBlockNumber == changedInBlock {
+			return copyContractConfig(s.state.latestConfig), nil
+		}
+	}
+	return types.ContractConfig{}, fmt.Errorf("no ConfigSet event in block %d", changedInBlock)
+}
+
+func (t *configTracker) LatestBlockHeight(ctx context.Context) (blockHeight uint64, err error) {
+	return t.chain.BlockHeight(), nil
+}
@@ -0,0 +1,49 @@
+package fakechain
+
+import (
+	"context"
+
+	"github.com/smartcontractkit/libocr/offchainreporting2plus/types"
+)
+
+// Transmitter returns a types.ContractTransmitter that calls transmit on the
+// contract from account. Transmissions are included in a new block
+// immediately. If the contract would revert, Transmit returns the
+// *evmutil.TransmitRejection explaining why.
+func (c *Chain) Transmitter(account types.Account) types.ContractTransmitter {
+	return &contractTransmitter{c, account}
+}
+
+type contractTransmitter struct {
+	chain   *Chain
+	account types.Account
+}
+
+var _ types.ContractTransmitter = (*contractTransmitter)(nil)
+
+func (t *contractTransmitter) Transmit(
+	ctx context.Context,
+	reportContext types.ReportContext,
+	report types.Report,
+	signatures []types.AttributedOnchainSignature,
+) error {
+	from, err := parseAccount(t.account)
+	if err != nil {
+		return err
+	}
+	return t.chain.transmit(from, reportContext, report, signatures)
+}
+
+// LatestConfigDigestAndEpoch behaves like the contract's function of the same
+// name: it returns the latest config digest, and the epoch of the latest
+// report accepted under that config (or zero, if there is none).
+func (t *contractTransmitter) LatestConfigDigestAndEpoch(ctx context.Context) (configDigest types.ConfigDigest, epoch uint32, err error) {
+	t.chain.mu.Lock()
+	defer t.chain.mu.Unlock()
+	state := t.chain.state()
+	return state.latestConfig.ConfigDigest, uint32(state.latestEpochAndRound >> 8), nil
+}
+
+func (t *contractTransmitter) FromAccount() (types.Account, error) {
+	return t.account, nil
+}
@@ -0,0 +1,62 @@
+// Package hashdigest provides OffchainConfigDigesters for chains whose
+// contracts compute the config digest by hashing a chain-specific encoding (the
+// "preimage") of the config and overwriting the first two bytes of the hash
+// with the ConfigDigestPrefix.
+//
+// Digester is generic over the hash function and the preimage encoding. This
+// package contains preimage encoders for the Solana and Terra prefixes. They
+// follow the field order of the Solana program and the Terra contract, but
+// haven't been checked against digests computed by either, so don't rely on
+// them to reproduce onchain digests yet. The EVM encoding, which is also used
+// with the EVMThresholdDecryption and EVMS4 prefixes, lives in evmutil.
+//
+// The following prefixes are out of scope and have no digester here:
+//   - Starknet: the contract hashes field elements with the Pedersen hash
+//     rather than a byte string, which doesn't fit Digester.
+//   - LLO: digests are computed by the channel config contracts of each chain
+//     in their own way.
+package hashdigest
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash"
+
+	"github.com/smartcontractkit/libocr/offchainreporting2plus/types"
+)
+
+// PreimageEncoder encodes a ContractConfig for hashing.
+type PreimageEncoder interface {
+	EncodePreimage(cc types.ContractConfig) ([]byte, error)
+}
+
+var _ types.OffchainConfigDigester = Digester{}
+
+type Digester struct {
+	Prefix types.ConfigDigestPrefix
+	// Must produce at least 32 bytes of output
+	NewHash func() hash.Hash
+	Encoder PreimageEncoder
+}
+
+func (d Digester) ConfigDigest(cc types.ContractConfig) (types.ConfigDigest, error) {
+	preimage, err := d.Encoder.EncodePreimage(cc)
+	if err != nil {
+		return types.ConfigDigest{}, err
+	}
+	h := d.NewHash()
+	_, _ = h.Write(preimage)
+	rawHash := h.Sum(nil)
+
+	configDigest := types.ConfigDigest{}
+	if len(rawHash) < len(configDigest) {
+		return types.ConfigDigest{}, fmt.Errorf("hash output has %v bytes, need at least %v", len(rawHash), len(configDigest))
+	}
+	copy(configDigest[:], rawHash)
+	binary.BigEndian.PutUint16(configDigest[:2], uint16(d.Prefix))
+	return configDigest, nil
+}
+
+func (d Digester) ConfigDigestPrefix() (types.ConfigDigestPrefix, error) {
+	return d.Prefix, nil
+}
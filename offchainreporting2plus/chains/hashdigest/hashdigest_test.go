@@ -0,0 +1,126 @@
+package hashdigest_test
+
+import (
+	"bytes"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/smartcontractkit/libocr/offchainreporting2plus/chains/hashdigest"
+	"github.com/smartcontractkit/libocr/offchainreporting2plus/types"
+)
+
+func mustDecodeHex(t *testing.T, s string) []byte {
+	t.Helper()
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func testContractConfig(signers []types.OnchainPublicKey, transmitters []types.Account) types.ContractConfig {
+	return types.ContractConfig{
+		types.ConfigDigest{},
+		7,
+		signers,
+		transmitters,
+		1,
+		[]byte{0xaa, 0xbb},
+		2,
+		[]byte("offchain"),
+	}
+}
+
+// Expected encoding of everything after the contract-specific header for
+// testContractConfig
+func testConfigTail(signersAndTransmitters string) string {
+	return "00000007" + // config count
+		"02" + // number of oracles
+		signersAndTransmitters +
+		"01" + // f
+		"00000002" + "aabb" + // onchain config
+		"0000000000000002" + // offchain config version
+		"00000008" + hex.EncodeToString([]byte("offchain"))
+}
+
+// The expected preimages are written out by hand following the order in which
+// the Terra contract and the Solana program feed the fields into the hash. They
+// are not vectors produced by the contracts themselves, so this only guards
+// against accidental changes to the encoding.
+func TestPreimageLayout(t *testing.T) {
+	var programID, stateID [32]byte
+	copy(programID[:], bytes.Repeat([]byte{0x11}, 32))
+	copy(stateID[:], bytes.Repeat([]byte{0x22}, 32))
+
+	for _, tc := range []struct {
+		name             string
+		digester         hashdigest.Digester
+		cc               types.ContractConfig
+		expectedPreimage string
+		expectedPrefix   string
+	}{
+		{
+			"solana",
+			hashdigest.NewSolanaDigester(programID, stateID),
+			testContractConfig(
+				[]types.OnchainPublicKey{bytes.Repeat([]byte{1}, 20), bytes.Repeat([]byte{2}, 20)},
+				[]types.Account{
+					"CktRuQ2mttgRGkXJtyksdKHjUdc2C4TgDzyB98oEzy8", // 32 times 0x03
+					"GgBaCs3NCBuZN12kCJgAW63ydqohFkHEdfdEXBPzLHq", // 32 times 0x04
+				},
+			),
+			strings.Repeat("11", 32) + strings.Repeat("22", 32) + testConfigTail(
+				strings.Repeat("01", 20)+strings.Repeat("02", 20)+strings.Repeat("03", 32)+strings.Repeat("04", 32),
+			),
+			"0003",
+		},
+		{
+			"terra",
+			hashdigest.NewTerraDigester("terra1contract"),
+			testContractConfig(
+				[]types.OnchainPublicKey{bytes.Repeat([]byte{5}, 32), bytes.Repeat([]byte{6}, 32)},
+				[]types.Account{"terra1aaa", "terra1bbb"},
+			),
+			hex.EncodeToString([]byte("terra1contract")) + testConfigTail(
+				strings.Repeat("05", 32)+strings.Repeat("06", 32)+hex.EncodeToString([]byte("terra1aaaterra1bbb")),
+			),
+			"0002",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			preimage, err := tc.digester.Encoder.EncodePreimage(tc.cc)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(preimage, mustDecodeHex(t, tc.expectedPreimage)) {
+				t.Fatalf("preimage is %x, expected %s", preimage, tc.expectedPreimage)
+			}
+			digest, err := tc.digester.ConfigDigest(tc.cc)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.HasPrefix(digest.Hex(), tc.expectedPrefix) {
+				t.Fatalf("digest %v does not have prefix %s", digest, tc.expectedPrefix)
+			}
+		})
+	}
+}
+
+func TestSolanaRejectsMalformedConfig(t *testing.T) {
+	digester := hashdigest.NewSolanaDigester([32]byte{}, [32]byte{})
+	for name, cc := range map[string]types.ContractConfig{
+		"short signer": testContractConfig(
+			[]types.OnchainPublicKey{bytes.Repeat([]byte{1}, 19)},
+			[]types.Account{"CktRuQ2mttgRGkXJtyksdKHjUdc2C4TgDzyB98oEzy8"},
+		),
+		"hex transmitter": testContractConfig(
+			[]types.OnchainPublicKey{bytes.Repeat([]byte{1}, 20)},
+			[]types.Account{"0x0303030303030303030303030303030303030303030303030303030303030303"},
+		),
+	} {
+		if _, err := digester.ConfigDigest(cc); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
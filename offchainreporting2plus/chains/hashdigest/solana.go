@@ -0,0 +1,91 @@
+package hashdigest
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"math"
+
+	"github.com/mr-tron/base58"
+
+	"github.com/smartcontractkit/libocr/offchainreporting2plus/types"
+)
+
+// NewSolanaDigester returns a digester for ConfigDigestPrefixSolana. It hashes
+// the fields in the order used by the Solana OCR2 program, but has not been
+// verified against digests computed by the program. Signers are 20 byte
+// Ethereum addresses and transmitters are base58-encoded 32 byte Solana public
+// keys.
+func NewSolanaDigester(programID [32]byte, stateID [32]byte) Digester {
+	return Digester{
+		types.ConfigDigestPrefixSolana,
+		sha256.New,
+		SolanaPreimageEncoder{programID, stateID},
+	}
+}
+
+var _ PreimageEncoder = SolanaPreimageEncoder{}
+
+type SolanaPreimageEncoder struct {
+	ProgramID [32]byte
+	// Address of the program's state account for the feed
+	StateID [32]byte
+}
+
+func (e SolanaPreimageEncoder) EncodePreimage(cc types.ContractConfig) ([]byte, error) {
+	transmitters := [][]byte{}
+	for i, transmitter := range cc.Transmitters {
+		pk, err := base58.Decode(string(transmitter))
+		if err != nil || len(pk) != 32 {
+			return nil, fmt.Errorf("%v-th solana transmitter should be a base58-encoded 32 byte public key, but got '%v'", i, transmitter)
+		}
+		transmitters = append(transmitters, pk)
+	}
+	for i, signer := range cc.Signers {
+		if len(signer) != 20 {
+			return nil, fmt.Errorf("%v-th solana signer should be a 20 byte address, but got %x", i, signer)
+		}
+	}
+
+	var buf bytes.Buffer
+	buf.Write(e.ProgramID[:])
+	buf.Write(e.StateID[:])
+	if err := writeLengthPrefixedConfig(&buf, cc, transmitters); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// writeLengthPrefixedConfig writes the encoding shared by the Solana and Terra
+// encoders: config count (u32), number of oracles (u8), signers,
+// transmitters, f (u8), onchain config with u32 length prefix, offchain
+// config version (u64), offchain config with u32 length prefix. All integers
+// are big-endian.
+func writeLengthPrefixedConfig(buf *bytes.Buffer, cc types.ContractConfig, transmitters [][]byte) error {
+	if cc.ConfigCount > math.MaxUint32 {
+		return fmt.Errorf("config count %v does not fit into 32 bits", cc.ConfigCount)
+	}
+	if len(cc.Signers) > math.MaxUint8 {
+		return fmt.Errorf("too many signers (%v)", len(cc.Signers))
+	}
+	if len(cc.OnchainConfig) > math.MaxUint32 || len(cc.OffchainConfig) > math.MaxUint32 {
+		return fmt.Errorf("config too long")
+	}
+
+	_ = binary.Write(buf, binary.BigEndian, uint32(cc.ConfigCount))
+	buf.WriteByte(uint8(len(cc.Signers)))
+	for _, signer := range cc.Signers {
+		buf.Write(signer)
+	}
+	for _, transmitter := range transmitters {
+		buf.Write(transmitter)
+	}
+	buf.WriteByte(cc.F)
+	_ = binary.Write(buf, binary.BigEndian, uint32(len(cc.OnchainConfig)))
+	buf.Write(cc.OnchainConfig)
+	_ = binary.Write(buf, binary.BigEndian, cc.OffchainConfigVersion)
+	_ = binary.Write(buf, binary.BigEndian, uint32(len(cc.OffchainConfig)))
+	buf.Write(cc.OffchainConfig)
+	return nil
+}
@@ -0,0 +1,48 @@
+package hashdigest
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+
+	"github.com/smartcontractkit/libocr/offchainreporting2plus/types"
+)
+
+// NewTerraDigester returns a digester for ConfigDigestPrefixTerra. It hashes
+// the fields in the order used by the Terra OCR2 contract, but has not been
+// verified against digests computed by the contract. contractAddress and
+// transmitters are bech32-encoded addresses, which are hashed as strings.
+// Signers are hashed as is.
+func NewTerraDigester(contractAddress string) Digester {
+	return Digester{
+		types.ConfigDigestPrefixTerra,
+		sha256.New,
+		TerraPreimageEncoder{contractAddress},
+	}
+}
+
+var _ PreimageEncoder = TerraPreimageEncoder{}
+
+type TerraPreimageEncoder struct {
+	ContractAddress string
+}
+
+func (e TerraPreimageEncoder) EncodePreimage(cc types.ContractConfig) ([]byte, error) {
+	if e.ContractAddress == "" {
+		return nil, fmt.Errorf("contract address must not be empty")
+	}
+	transmitters := [][]byte{}
+	for i, transmitter := range cc.Transmitters {
+		if transmitter == "" {
+			return nil, fmt.Errorf("%v-th terra transmitter must not be empty", i)
+		}
+		transmitters = append(transmitters, []byte(transmitter))
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(e.ContractAddress)
+	if err := writeLengthPrefixedConfig(&buf, cc, transmitters); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,302 @@
+// Package configbuilder generates contract configs for production use.
+//
+// Unlike confighelper.ContractSetConfigArgsForTests and friends, all
+// randomness that goes into a config comes from an explicit
+// SharedSecretSource, and Build records its inputs and outputs in a Manifest.
+// Given the manifest and the same SharedSecretSource, anybody can regenerate
+// the config byte-for-byte, e.g. each signer of a multisig before approving a
+// setConfig transaction. Signers who do not have access to the
+// SharedSecretSource can still check everything but the encryptions of the
+// shared secret using VerifyManifestWithoutSharedSecret.
+package configbuilder
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/smartcontractkit/libocr/offchainreporting2plus/confighelper"
+	"github.com/smartcontractkit/libocr/offchainreporting2plus/identityattestation"
+	"github.com/smartcontractkit/libocr/offchainreporting2plus/internal/config"
+	"github.com/smartcontractkit/libocr/offchainreporting2plus/internal/config/ocr2config"
+	"github.com/smartcontractkit/libocr/offchainreporting2plus/internal/config/ocr3config"
+	"github.com/smartcontractkit/libocr/offchainreporting2plus/types"
+)
+
+type Protocol string
+
+const (
+	ProtocolOCR2 Protocol = "ocr2"
+	ProtocolOCR3 Protocol = "ocr3"
+)
+
+// Options are the inputs to Build. Fields that do not apply to Protocol must
+// be left zero.
+type Options struct {
+	Protocol Protocol
+
+	// The configCount the contract will have after setConfig has been called
+	// with the generated config, and the digester of the contract. Used to
+	// compute the config digest.
+	ConfigCount uint64
+	Digester    types.OffchainConfigDigester
+
+	Oracles []confighelper.OracleIdentityExtra
+	F       int
+
+	DeltaProgress               time.Duration
+	DeltaResend                 time.Duration
+	DeltaInitial                time.Duration // OCR3 only
+	DeltaRound                  time.Duration
+	DeltaGrace                  time.Duration
+	DeltaCertifiedCommitRequest time.Duration // OCR3 only
+	DeltaStage                  time.Duration
+	RMax                        uint64 // at most 255 for OCR2
+	S                           []int
+
+	ReportingPluginConfig []byte
+	OnchainConfig         []byte
+
+	MaxDurationQuery                        time.Duration
+	MaxDurationObservation                  time.Duration
+	MaxDurationReport                       time.Duration // OCR2 only
+	MaxDurationShouldAcceptFinalizedReport  time.Duration // OCR2 only
+	MaxDurationShouldAcceptAttestedReport   time.Duration // OCR3 only
+	MaxDurationShouldTransmitAcceptedReport time.Duration
+}
+
+// Validate checks that the options produce a config that oracles will accept.
+func (o Options) Validate() error {
+	if o.Digester == nil {
+		return fmt.Errorf("Digester must be set")
+	}
+	if len(o.Oracles) == 0 || len(o.Oracles) > types.MaxOracles {
+		return fmt.Errorf("number of oracles (%v) must be between 1 and %v", len(o.Oracles), types.MaxOracles)
+	}
+	for i, oracle := range o.Oracles {
+		if len(oracle.OnchainPublicKey) == 0 {
+			return fmt.Errorf("oracle %v: OnchainPublicKey must not be empty", i)
+		}
+		if oracle.PeerID == "" {
+			return fmt.Errorf("oracle %v: PeerID must not be empty", i)
+		}
+		if oracle.TransmitAccount == "" {
+			return fmt.Errorf("oracle %v: TransmitAccount must not be empty", i)
+		}
+		if oracle.OffchainPublicKey == (types.OffchainPublicKey{}) {
+			return fmt.Errorf("oracle %v: OffchainPublicKey must not be zero", i)
+		}
+		if oracle.ConfigEncryptionPublicKey == (types.ConfigEncryptionPublicKey{}) {
+			return fmt.Errorf("oracle %v: ConfigEncryptionPublicKey must not be zero", i)
+		}
+	}
+
+	switch o.Protocol {
+	case ProtocolOCR2:
+		if o.RMax > 255 {
+			return fmt.Errorf("RMax (%v) must be at most 255 for %v", o.RMax, o.Protocol)
+		}
+		if o.DeltaInitial != 0 || o.DeltaCertifiedCommitRequest != 0 || o.MaxDurationShouldAcceptAttestedReport != 0 {
+			return fmt.Errorf("DeltaInitial, DeltaCertifiedCommitRequest and MaxDurationShouldAcceptAttestedReport must be zero for %v", o.Protocol)
+		}
+	case ProtocolOCR3:
+		if o.MaxDurationReport != 0 || o.MaxDurationShouldAcceptFinalizedReport != 0 {
+			return fmt.Errorf("MaxDurationReport and MaxDurationShouldAcceptFinalizedReport must be zero for %v", o.Protocol)
+		}
+	default:
+		return fmt.Errorf("unknown Protocol %q, expected %q or %q", o.Protocol, ProtocolOCR2, ProtocolOCR3)
+	}
+
+	// The remaining checks are the ones oracles perform. The secrets don't
+	// affect them.
+	cc, err := o.contractConfig(&[config.SharedSecretSize]byte{}, &[32]byte{})
+	if err != nil {
+		return err
+	}
+	return o.checkContractConfig(cc)
+}
+
+// VerifyAttestations checks that every oracle in o.Oracles has a valid
+// identity attestation in attestations that matches its identity exactly,
+// including the config encryption key. Call it before Build to make sure that
+// the config only contains keys that the oracles hold.
+func (o Options) VerifyAttestations(attestations []identityattestation.Attestation, onchainVerifier identityattestation.OnchainVerifier) error {
+	return identityattestation.VerifyIdentities(o.Oracles, attestations, onchainVerifier)
+}
+
+// SharedSecretSource provides the secret randomness for a config.
+type SharedSecretSource interface {
+	// SharedSecret returns the shared secret of the oracles and the
+	// ephemeral X25519 secret key used to encrypt the shared secret to each
+	// oracle's ConfigEncryptionPublicKey. It must return the same values on
+	// every call.
+	SharedSecret() (sharedSecret [config.SharedSecretSize]byte, ephemeralSecretKey [32]byte, err error)
+}
+
+// Seed is a SharedSecretSource that derives both secrets from a 32 byte seed.
+// The seed must be kept as confidential as the shared secret itself. Use a
+// fresh seed for every config, reusing a seed reuses the shared secret.
+type Seed [32]byte
+
+var _ SharedSecretSource = Seed{}
+
+// NewSeed draws a Seed from rand, typically crypto/rand.Reader.
+func NewSeed(rand io.Reader) (Seed, error) {
+	var seed Seed
+	if _, err := io.ReadFull(rand, seed[:]); err != nil {
+		return Seed{}, fmt.Errorf("could not read seed: %w", err)
+	}
+	return seed, nil
+}
+
+func (s Seed) SharedSecret() (sharedSecret [config.SharedSecretSize]byte, ephemeralSecretKey [32]byte, err error) {
+	copy(sharedSecret[:], crypto.Keccak256([]byte("libocr configbuilder shared secret"), s[:]))
+	copy(ephemeralSecretKey[:], crypto.Keccak256([]byte("libocr configbuilder ephemeral secret key"), s[:]))
+	return sharedSecret, ephemeralSecretKey, nil
+}
+
+// Build generates the config described by opts, with secrets from source.
+// The returned ContractConfig has its ConfigDigest set. The same opts and
+// source always produce the same output.
+func Build(opts Options, source SharedSecretSource) (types.ContractConfig, Manifest, error) {
+	if err := opts.Validate(); err != nil {
+		return types.ContractConfig{}, Manifest{}, fmt.Errorf("invalid options: %w", err)
+	}
+	sharedSecret, ephemeralSecretKey, err := source.SharedSecret()
+	if err != nil {
+		return types.ContractConfig{}, Manifest{}, fmt.Errorf("could not get shared secret: %w", err)
+	}
+	cc, err := opts.contractConfig(&sharedSecret, &ephemeralSecretKey)
+	if err != nil {
+		return types.ContractConfig{}, Manifest{}, err
+	}
+	// Validate has already checked this for a different shared secret, but
+	// we never return a config that oracles would reject.
+	if err := opts.checkContractConfig(cc); err != nil {
+		return types.ContractConfig{}, Manifest{}, fmt.Errorf("generated config is invalid: %w", err)
+	}
+	manifest, err := newManifest(opts, crypto.Keccak256(sharedSecret[:]), cc)
+	if err != nil {
+		return types.ContractConfig{}, Manifest{}, err
+	}
+	return cc, manifest, nil
+}
+
+func (o Options) contractConfig(sharedSecret *[config.SharedSecretSize]byte, ephemeralSecretKey *[32]byte) (types.ContractConfig, error) {
+	identities := []config.OracleIdentity{}
+	configEncryptionPublicKeys := []types.ConfigEncryptionPublicKey{}
+	for _, oracle := range o.Oracles {
+		identities = append(identities, config.OracleIdentity{
+			oracle.OffchainPublicKey,
+			oracle.OnchainPublicKey,
+			oracle.PeerID,
+			oracle.TransmitAccount,
+		})
+		configEncryptionPublicKeys = append(configEncryptionPublicKeys, oracle.ConfigEncryptionPublicKey)
+	}
+	// The ephemeral secret key is the only thing read from rand
+	rand := bytes.NewReader(ephemeralSecretKey[:])
+
+	var (
+		signers               []types.OnchainPublicKey
+		transmitters          []types.Account
+		f                     uint8
+		onchainConfig         []byte
+		offchainConfigVersion uint64
+		offchainConfig        []byte
+		err                   error
+	)
+	switch o.Protocol {
+	case ProtocolOCR2:
+		signers, transmitters, f, onchainConfig, offchainConfigVersion, offchainConfig, err = ocr2config.XXXContractSetConfigArgsFromSharedConfigWithRand(
+			ocr2config.SharedConfig{
+				ocr2config.PublicConfig{
+					o.DeltaProgress,
+					o.DeltaResend,
+					o.DeltaRound,
+					o.DeltaGrace,
+					o.DeltaStage,
+					uint8(o.RMax),
+					o.S,
+					identities,
+					o.ReportingPluginConfig,
+					o.MaxDurationQuery,
+					o.MaxDurationObservation,
+					o.MaxDurationReport,
+					o.MaxDurationShouldAcceptFinalizedReport,
+					o.MaxDurationShouldTransmitAcceptedReport,
+					o.F,
+					o.OnchainConfig,
+					types.ConfigDigest{},
+				},
+				sharedSecret,
+			},
+			configEncryptionPublicKeys,
+			rand,
+		)
+	case ProtocolOCR3:
+		signers, transmitters, f, onchainConfig, offchainConfigVersion, offchainConfig, err = ocr3config.XXXContractSetConfigArgsFromSharedConfigWithRand(
+			ocr3config.SharedConfig{
+				ocr3config.PublicConfig{
+					o.DeltaProgress,
+					o.DeltaResend,
+					o.DeltaInitial,
+					o.DeltaRound,
+					o.DeltaGrace,
+					o.DeltaCertifiedCommitRequest,
+					o.DeltaStage,
+					o.RMax,
+					o.S,
+					identities,
+					o.ReportingPluginConfig,
+					o.MaxDurationQuery,
+					o.MaxDurationObservation,
+					o.MaxDurationShouldAcceptAttestedReport,
+					o.MaxDurationShouldTransmitAcceptedReport,
+					o.F,
+					o.OnchainConfig,
+					types.ConfigDigest{},
+				},
+				sharedSecret,
+			},
+			configEncryptionPublicKeys,
+			rand,
+		)
+	default:
+		return types.ContractConfig{}, fmt.Errorf("unknown Protocol %q", o.Protocol)
+	}
+	if err != nil {
+		return types.ContractConfig{}, err
+	}
+
+	cc := types.ContractConfig{
+		types.ConfigDigest{},
+		o.ConfigCount,
+		signers,
+		transmitters,
+		f,
+		onchainConfig,
+		offchainConfigVersion,
+		offchainConfig,
+	}
+	cc.ConfigDigest, err = o.Digester.ConfigDigest(cc)
+	if err != nil {
+		return types.ContractConfig{}, fmt.Errorf("could not compute config digest: %w", err)
+	}
+	return cc, nil
+}
+
+func (o Options) checkContractConfig(cc types.ContractConfig) error {
+	var err error
+	switch o.Protocol {
+	case ProtocolOCR2:
+		_, err = ocr2config.PublicConfigFromContractConfig(false, cc)
+	case ProtocolOCR3:
+		_, err = ocr3config.PublicConfigFromContractConfig(false, cc)
+	default:
+		err = fmt.Errorf("unknown Protocol %q", o.Protocol)
+	}
+	return err
+}
@@ -0,0 +1,171 @@
+package configbuilder_test
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/smartcontractkit/libocr/offchainreporting2plus/chains/evmutil"
+	"github.com/smartcontractkit/libocr/offchainreporting2plus/configbuilder"
+	"github.com/smartcontractkit/libocr/offchainreporting2plus/confighelper"
+	"github.com/smartcontractkit/libocr/offchainreporting2plus/types"
+	ragetypes "github.com/smartcontractkit/libocr/ragep2p/types"
+)
+
+var testDigester = evmutil.EVMOffchainConfigDigester{1, common.HexToAddress("0x1234")}
+
+func testOracles(n int) []confighelper.OracleIdentityExtra {
+	oracles := []confighelper.OracleIdentityExtra{}
+	for i := 0; i < n; i++ {
+		seed := bytes.Repeat([]byte{byte(i + 1)}, ed25519.SeedSize)
+		var offchainPublicKey types.OffchainPublicKey
+		copy(offchainPublicKey[:], ed25519.NewKeyFromSeed(seed).Public().(ed25519.PublicKey))
+		peerID, err := ragetypes.PeerIDFromPrivateKey(ed25519.NewKeyFromSeed(seed))
+		if err != nil {
+			panic(err)
+		}
+		oracles = append(oracles, confighelper.OracleIdentityExtra{
+			confighelper.OracleIdentity{
+				offchainPublicKey,
+				bytes.Repeat([]byte{byte(0x10 + i)}, 20),
+				peerID.String(),
+				types.Account(common.BytesToAddress(bytes.Repeat([]byte{byte(0x20 + i)}, 20)).Hex()),
+			},
+			types.ConfigEncryptionPublicKey{byte(0x30 + i), 1},
+		})
+	}
+	return oracles
+}
+
+func testOptions(protocol configbuilder.Protocol) configbuilder.Options {
+	opts := configbuilder.Options{
+		Protocol:                                protocol,
+		ConfigCount:                             3,
+		Digester:                                testDigester,
+		Oracles:                                 testOracles(4),
+		F:                                       1,
+		DeltaProgress:                           8 * time.Second,
+		DeltaResend:                             30 * time.Second,
+		DeltaRound:                              1500 * time.Millisecond,
+		DeltaGrace:                              500 * time.Millisecond,
+		DeltaStage:                              20 * time.Second,
+		RMax:                                    5,
+		S:                                       []int{1, 1, 2},
+		ReportingPluginConfig:                   []byte{0xde, 0xad},
+		OnchainConfig:                           []byte{0xbe, 0xef},
+		MaxDurationQuery:                        0,
+		MaxDurationObservation:                  time.Second,
+		MaxDurationShouldTransmitAcceptedReport: time.Second,
+	}
+	switch protocol {
+	case configbuilder.ProtocolOCR2:
+		opts.MaxDurationReport = time.Second
+		opts.MaxDurationShouldAcceptFinalizedReport = time.Second
+	case configbuilder.ProtocolOCR3:
+		opts.DeltaInitial = 3 * time.Second
+		opts.DeltaCertifiedCommitRequest = time.Second
+		opts.MaxDurationShouldAcceptAttestedReport = time.Second
+	}
+	return opts
+}
+
+var protocols = []configbuilder.Protocol{configbuilder.ProtocolOCR2, configbuilder.ProtocolOCR3}
+
+func TestBuildIsDeterministic(t *testing.T) {
+	for _, protocol := range protocols {
+		t.Run(string(protocol), func(t *testing.T) {
+			seed, err := configbuilder.NewSeed(bytes.NewReader(bytes.Repeat([]byte{7}, 32)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			cc1, m1, err := configbuilder.Build(testOptions(protocol), seed)
+			if err != nil {
+				t.Fatal(err)
+			}
+			cc2, m2, err := configbuilder.Build(testOptions(protocol), seed)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(cc1, cc2) || !reflect.DeepEqual(m1, m2) {
+				t.Fatalf("Build is not deterministic\nfirst:  %+v\nsecond: %+v", cc1, cc2)
+			}
+			j1, err := json.Marshal(m1)
+			if err != nil {
+				t.Fatal(err)
+			}
+			j2, err := json.Marshal(m2)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(j1, j2) {
+				t.Fatalf("manifest encodings differ\nfirst:  %s\nsecond: %s", j1, j2)
+			}
+
+			// A different seed changes the shared secret and nothing else
+			cc3, m3, err := configbuilder.Build(testOptions(protocol), configbuilder.Seed{8})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if bytes.Equal(cc1.OffchainConfig, cc3.OffchainConfig) || cc1.ConfigDigest == cc3.ConfigDigest || bytes.Equal(m1.SharedSecretHash, m3.SharedSecretHash) {
+				t.Fatalf("different seeds produced the same shared secret")
+			}
+			if !reflect.DeepEqual(m1.Inputs, m3.Inputs) || !reflect.DeepEqual(cc1.Signers, cc3.Signers) || !reflect.DeepEqual(cc1.Transmitters, cc3.Transmitters) || !bytes.Equal(cc1.OnchainConfig, cc3.OnchainConfig) {
+				t.Fatalf("different seeds produced different public parts of the config")
+			}
+
+			digest, err := testDigester.ConfigDigest(cc1)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if digest != cc1.ConfigDigest {
+				t.Fatalf("config digest %v doesn't match digester's %v", cc1.ConfigDigest, digest)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	for _, protocol := range protocols {
+		if err := testOptions(protocol).Validate(); err != nil {
+			t.Fatalf("%v: %v", protocol, err)
+		}
+	}
+
+	for name, test := range map[string]struct {
+		protocol configbuilder.Protocol
+		modify   func(*configbuilder.Options)
+	}{
+		"OCR2 with DeltaInitial":                          {configbuilder.ProtocolOCR2, func(o *configbuilder.Options) { o.DeltaInitial = time.Second }},
+		"OCR2 with DeltaCertifiedCommitRequest":           {configbuilder.ProtocolOCR2, func(o *configbuilder.Options) { o.DeltaCertifiedCommitRequest = time.Second }},
+		"OCR2 with MaxDurationShouldAcceptAttestedReport": {configbuilder.ProtocolOCR2, func(o *configbuilder.Options) { o.MaxDurationShouldAcceptAttestedReport = time.Second }},
+		"OCR2 with RMax above 255":                        {configbuilder.ProtocolOCR2, func(o *configbuilder.Options) { o.RMax = 256 }},
+		"OCR3 with MaxDurationReport":                     {configbuilder.ProtocolOCR3, func(o *configbuilder.Options) { o.MaxDurationReport = time.Second }},
+		"OCR3 with MaxDurationShouldAcceptFinalizedReport": {configbuilder.ProtocolOCR3, func(o *configbuilder.Options) {
+			o.MaxDurationShouldAcceptFinalizedReport = time.Second
+		}},
+		"unknown protocol": {configbuilder.ProtocolOCR2, func(o *configbuilder.Options) { o.Protocol = "ocr1" }},
+		"no digester":      {configbuilder.ProtocolOCR3, func(o *configbuilder.Options) { o.Digester = nil }},
+		"no oracles":       {configbuilder.ProtocolOCR3, func(o *configbuilder.Options) { o.Oracles = nil }},
+		"missing peer id":  {configbuilder.ProtocolOCR3, func(o *configbuilder.Options) { o.Oracles[1].PeerID = "" }},
+		"missing encryption key": {configbuilder.ProtocolOCR2, func(o *configbuilder.Options) {
+			o.Oracles[2].ConfigEncryptionPublicKey = types.ConfigEncryptionPublicKey{}
+		}},
+		"f too large":               {configbuilder.ProtocolOCR2, func(o *configbuilder.Options) { o.F = 2 }},
+		"oracles reject (S)":        {configbuilder.ProtocolOCR3, func(o *configbuilder.Options) { o.S = []int{1, -1} }},
+		"oracles reject (duration)": {configbuilder.ProtocolOCR2, func(o *configbuilder.Options) { o.DeltaResend = 0 }},
+	} {
+		opts := testOptions(test.protocol)
+		test.modify(&opts)
+		if err := opts.Validate(); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+		if _, _, err := configbuilder.Build(opts, configbuilder.Seed{}); err == nil {
+			t.Errorf("%s: expected Build to fail", name)
+		}
+	}
+}
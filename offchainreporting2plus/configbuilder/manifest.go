@@ -0,0 +1,424 @@
+package configbuilder
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	cryptorand "crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/smartcontractkit/libocr/offchainreporting2plus/confighelper"
+	"github.com/smartcontractkit/libocr/offchainreporting2plus/internal/config"
+	"github.com/smartcontractkit/libocr/offchainreporting2plus/internal/config/configtext"
+	"github.com/smartcontractkit/libocr/offchainreporting2plus/internal/config/ocr2config"
+	"github.com/smartcontractkit/libocr/offchainreporting2plus/internal/config/ocr3config"
+	"github.com/smartcontractkit/libocr/offchainreporting2plus/types"
+)
+
+const ManifestVersion = 1
+
+// Manifest records the inputs and outputs of Build. It is meant to be
+// serialized as JSON and passed along with a proposed config.
+type Manifest struct {
+	Version int
+	Inputs  ManifestInputs
+	// Keccak256 hash of the shared secret, as contained in the offchain
+	// config. It lets holders of the SharedSecretSource check that they have
+	// the right one without regenerating the entire config.
+	SharedSecretHash HexBytes
+	Output           ManifestOutput
+}
+
+// ManifestInputs mirrors Options. The digester is represented by its prefix
+// only, verifiers need to supply the digester themselves.
+type ManifestInputs struct {
+	Protocol           Protocol
+	ConfigCount        uint64
+	ConfigDigestPrefix types.ConfigDigestPrefix
+
+	Oracles []ManifestOracle
+	F       int
+
+	DeltaProgress               Duration
+	DeltaResend                 Duration
+	DeltaInitial                Duration
+	DeltaRound                  Duration
+	DeltaGrace                  Duration
+	DeltaCertifiedCommitRequest Duration
+	DeltaStage                  Duration
+	RMax                        uint64
+	S                           []int
+
+	ReportingPluginConfig HexBytes
+	OnchainConfig         HexBytes
+
+	MaxDurationQuery                        Duration
+	MaxDurationObservation                  Duration
+	MaxDurationReport                       Duration
+	MaxDurationShouldAcceptFinalizedReport  Duration
+	MaxDurationShouldAcceptAttestedReport   Duration
+	MaxDurationShouldTransmitAcceptedReport Duration
+}
+
+type ManifestOracle struct {
+	OffchainPublicKey         HexBytes
+	OnchainPublicKey          HexBytes
+	PeerID                    string
+	TransmitAccount           types.Account
+	ConfigEncryptionPublicKey HexBytes
+}
+
+// ManifestOutput is the generated types.ContractConfig.
+type ManifestOutput struct {
+	ConfigDigest          HexBytes
+	Signers               []HexBytes
+	Transmitters          []types.Account
+	F                     uint8
+	OnchainConfig         HexBytes
+	OffchainConfigVersion uint64
+	OffchainConfig        HexBytes
+}
+
+// HexBytes is encoded as a hex string with 0x prefix. Manifests share the
+// encoding of the other config text formats, see configtext.HexBytes.
+type HexBytes = configtext.HexBytes
+
+// Duration is encoded like "1.5s".
+type Duration = configtext.Duration
+
+func newManifest(opts Options, sharedSecretHash []byte, cc types.ContractConfig) (Manifest, error) {
+	prefix, err := opts.Digester.ConfigDigestPrefix()
+	if err != nil {
+		return Manifest{}, fmt.Errorf("could not get config digest prefix: %w", err)
+	}
+	oracles := []ManifestOracle{}
+	for _, oracle := range opts.Oracles {
+		offchainPublicKey := oracle.OffchainPublicKey
+		configEncryptionPublicKey := oracle.ConfigEncryptionPublicKey
+		oracles = append(oracles, ManifestOracle{
+			offchainPublicKey[:],
+			HexBytes(oracle.OnchainPublicKey),
+			oracle.PeerID,
+			oracle.TransmitAccount,
+			configEncryptionPublicKey[:],
+		})
+	}
+	signers := []HexBytes{}
+	for _, signer := range cc.Signers {
+		signers = append(signers, HexBytes(signer))
+	}
+	return Manifest{
+		ManifestVersion,
+		ManifestInputs{
+			opts.Protocol,
+			opts.ConfigCount,
+			prefix,
+			oracles,
+			opts.F,
+			Duration(opts.DeltaProgress),
+			Duration(opts.DeltaResend),
+			Duration(opts.DeltaInitial),
+			Duration(opts.DeltaRound),
+			Duration(opts.DeltaGrace),
+			Duration(opts.DeltaCertifiedCommitRequest),
+			Duration(opts.DeltaStage),
+			opts.RMax,
+			opts.S,
+			opts.ReportingPluginConfig,
+			opts.OnchainConfig,
+			Duration(opts.MaxDurationQuery),
+			Duration(opts.MaxDurationObservation),
+			Duration(opts.MaxDurationReport),
+			Duration(opts.MaxDurationShouldAcceptFinalizedReport),
+			Duration(opts.MaxDurationShouldAcceptAttestedReport),
+			Duration(opts.MaxDurationShouldTransmitAcceptedReport),
+		},
+		sharedSecretHash,
+		ManifestOutput{
+			cc.ConfigDigest[:],
+			signers,
+			cc.Transmitters,
+			cc.F,
+			cc.OnchainConfig,
+			cc.OffchainConfigVersion,
+			cc.OffchainConfig,
+		},
+	}, nil
+}
+
+// Options reconstructs the Options the manifest was built from. digester must
+// have the prefix recorded in the manifest.
+func (m Manifest) Options(digester types.OffchainConfigDigester) (Options, error) {
+	if m.Version != ManifestVersion {
+		return Options{}, fmt.Errorf("unsupported manifest version %v, expected %v", m.Version, ManifestVersion)
+	}
+	prefix, err := digester.ConfigDigestPrefix()
+	if err != nil {
+		return Options{}, fmt.Errorf("could not get config digest prefix: %w", err)
+	}
+	if prefix != m.Inputs.ConfigDigestPrefix {
+		return Options{}, fmt.Errorf("digester has prefix %v, but manifest was built with prefix %v", prefix, m.Inputs.ConfigDigestPrefix)
+	}
+
+	oracles := []confighelper.OracleIdentityExtra{}
+	for i, oracle := range m.Inputs.Oracles {
+		var offchainPublicKey types.OffchainPublicKey
+		if len(oracle.OffchainPublicKey) != len(offchainPublicKey) {
+			return Options{}, fmt.Errorf("oracle %v: OffchainPublicKey has wrong length", i)
+		}
+		copy(offchainPublicKey[:], oracle.OffchainPublicKey)
+		var configEncryptionPublicKey types.ConfigEncryptionPublicKey
+		if len(oracle.ConfigEncryptionPublicKey) != len(configEncryptionPublicKey) {
+			return Options{}, fmt.Errorf("oracle %v: ConfigEncryptionPublicKey has wrong length", i)
+		}
+		copy(configEncryptionPublicKey[:], oracle.ConfigEncryptionPublicKey)
+		oracles = append(oracles, confighelper.OracleIdentityExtra{
+			confighelper.OracleIdentity{
+				offchainPublicKey,
+				types.OnchainPublicKey(oracle.OnchainPublicKey),
+				oracle.PeerID,
+				oracle.TransmitAccount,
+			},
+			configEncryptionPublicKey,
+		})
+	}
+
+	in := m.Inputs
+	return Options{
+		in.Protocol,
+		in.ConfigCount,
+		digester,
+		oracles,
+		in.F,
+		time.Duration(in.DeltaProgress),
+		time.Duration(in.DeltaResend),
+		time.Duration(in.DeltaInitial),
+		time.Duration(in.DeltaRound),
+		time.Duration(in.DeltaGrace),
+		time.Duration(in.DeltaCertifiedCommitRequest),
+		time.Duration(in.DeltaStage),
+		in.RMax,
+		in.S,
+		in.ReportingPluginConfig,
+		in.OnchainConfig,
+		time.Duration(in.MaxDurationQuery),
+		time.Duration(in.MaxDurationObservation),
+		time.Duration(in.MaxDurationReport),
+		time.Duration(in.MaxDurationShouldAcceptFinalizedReport),
+		time.Duration(in.MaxDurationShouldAcceptAttestedReport),
+		time.Duration(in.MaxDurationShouldTransmitAcceptedReport),
+	}, nil
+}
+
+// ContractConfig returns the config recorded in the manifest's output.
+func (m Manifest) ContractConfig() (types.ContractConfig, error) {
+	configDigest, err := types.BytesToConfigDigest(m.Output.ConfigDigest)
+	if err != nil {
+		return types.ContractConfig{}, err
+	}
+	signers := []types.OnchainPublicKey{}
+	for _, signer := range m.Output.Signers {
+		signers = append(signers, types.OnchainPublicKey(signer))
+	}
+	return types.ContractConfig{
+		configDigest,
+		m.Inputs.ConfigCount,
+		signers,
+		m.Output.Transmitters,
+		m.Output.F,
+		m.Output.OnchainConfig,
+		m.Output.OffchainConfigVersion,
+		m.Output.OffchainConfig,
+	}, nil
+}
+
+// VerifyManifest regenerates the config from the manifest's inputs and source
+// and checks that the result matches the manifest's output byte-for-byte.
+func VerifyManifest(m Manifest, digester types.OffchainConfigDigester, source SharedSecretSource) error {
+	opts, err := m.Options(digester)
+	if err != nil {
+		return err
+	}
+	_, regenerated, err := Build(opts, source)
+	if err != nil {
+		return fmt.Errorf("could not regenerate config: %w", err)
+	}
+	if !bytes.Equal(regenerated.SharedSecretHash, m.SharedSecretHash) {
+		return fmt.Errorf("SharedSecretHash does not match, the shared secret source differs from the one the manifest was built with")
+	}
+	if field := firstDifferingField(regenerated.Output, m.Output); field != "" {
+		return fmt.Errorf("regenerated config differs from manifest in Output.%s", field)
+	}
+	return nil
+}
+
+// VerifyManifestWithoutSharedSecret checks everything VerifyManifest checks,
+// except for the encryptions of the shared secret, for which the
+// SharedSecretSource would be required.
+func VerifyManifestWithoutSharedSecret(m Manifest, digester types.OffchainConfigDigester) error {
+	opts, err := m.Options(digester)
+	if err != nil {
+		return err
+	}
+	// We regenerate with an arbitrary shared secret. Everything but the
+	// shared secret encryptions in the offchain config (and hence the config
+	// digest) must match.
+	seed, err := NewSeed(cryptorand.Reader)
+	if err != nil {
+		return err
+	}
+	_, regenerated, err := Build(opts, seed)
+	if err != nil {
+		return fmt.Errorf("could not regenerate config: %w", err)
+	}
+	expected, actual := regenerated.Output, m.Output
+	expected.ConfigDigest, actual.ConfigDigest = nil, nil
+	expected.OffchainConfig, actual.OffchainConfig = nil, nil
+	if field := firstDifferingField(expected, actual); field != "" {
+		return fmt.Errorf("regenerated config differs from manifest in Output.%s", field)
+	}
+
+	// The encryptions have fixed size, so the lengths must match exactly
+	if len(regenerated.Output.OffchainConfig) != len(m.Output.OffchainConfig) {
+		return fmt.Errorf("regenerated config differs from manifest in length of Output.OffchainConfig")
+	}
+	var expectedSSE, actualSSE config.SharedSecretEncryptions
+	var offchainConfigsMatch bool
+	switch opts.Protocol {
+	case ProtocolOCR2:
+		expectedOC, err := ocr2config.XXXDeserializeOffchainConfig(regenerated.Output.OffchainConfig)
+		if err != nil {
+			return err
+		}
+		actualOC, err := ocr2config.XXXDeserializeOffchainConfig(m.Output.OffchainConfig)
+		if err != nil {
+			return fmt.Errorf("could not deserialize Output.OffchainConfig: %w", err)
+		}
+		expectedSSE, actualSSE = expectedOC.SharedSecretEncryptions, actualOC.SharedSecretEncryptions
+		expectedOC.SharedSecretEncryptions, actualOC.SharedSecretEncryptions = config.SharedSecretEncryptions{}, config.SharedSecretEncryptions{}
+		offchainConfigsMatch = reflect.DeepEqual(expectedOC, actualOC)
+	case ProtocolOCR3:
+		expectedOC, err := ocr3config.XXXDeserializeOffchainConfig(regenerated.Output.OffchainConfig)
+		if err != nil {
+			return err
+		}
+		actualOC, err := ocr3config.XXXDeserializeOffchainConfig(m.Output.OffchainConfig)
+		if err != nil {
+			return fmt.Errorf("could not deserialize Output.OffchainConfig: %w", err)
+		}
+		expectedSSE, actualSSE = expectedOC.SharedSecretEncryptions, actualOC.SharedSecretEncryptions
+		expectedOC.SharedSecretEncryptions, actualOC.SharedSecretEncryptions = config.SharedSecretEncryptions{}, config.SharedSecretEncryptions{}
+		offchainConfigsMatch = reflect.DeepEqual(expectedOC, actualOC)
+	}
+	if !offchainConfigsMatch {
+		return fmt.Errorf("regenerated config differs from manifest in Output.OffchainConfig")
+	}
+	if len(actualSSE.Encryptions) != len(expectedSSE.Encryptions) {
+		return fmt.Errorf("Output.OffchainConfig contains %v shared secret encryptions, expected %v", len(actualSSE.Encryptions), len(expectedSSE.Encryptions))
+	}
+	if !bytes.Equal(actualSSE.SharedSecretHash[:], m.SharedSecretHash) {
+		return fmt.Errorf("SharedSecretHash in Output.OffchainConfig does not match manifest")
+	}
+
+	cc, err := m.ContractConfig()
+	if err != nil {
+		return err
+	}
+	configDigest, err := digester.ConfigDigest(cc)
+	if err != nil {
+		return fmt.Errorf("could not compute config digest: %w", err)
+	}
+	if configDigest != cc.ConfigDigest {
+		return fmt.Errorf("Output.ConfigDigest is %v, but config has digest %v", cc.ConfigDigest, configDigest)
+	}
+	return nil
+}
+
+func firstDifferingField(a, b ManifestOutput) string {
+	va, vb := reflect.ValueOf(a), reflect.ValueOf(b)
+	for i := 0; i < va.NumField(); i++ {
+		// compare JSON encodings, so that nil and empty values are equal
+		ja, _ := json.Marshal(va.Field(i).Interface())
+		jb, _ := json.Marshal(vb.Field(i).Interface())
+		if !bytes.Equal(ja, jb) {
+			return va.Type().Field(i).Name
+		}
+	}
+	return ""
+}
+
+// SignedManifest is a Manifest with Ed25519 signatures over it. Typically,
+// the generator of a config signs first and every party who has verified the
+// manifest adds their signature.
+type SignedManifest struct {
+	Manifest   Manifest
+	Signatures []ManifestSignature
+}
+
+type ManifestSignature struct {
+	PublicKey HexBytes
+	Signature HexBytes
+}
+
+const manifestSignatureDomainSeparator = "libocr configbuilder manifest signature v1\x00"
+
+func manifestSigningPayload(m Manifest) ([]byte, error) {
+	encoded, err := json.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(manifestSignatureDomainSeparator), encoded...), nil
+}
+
+// SignManifest returns m with a signature by key.
+func SignManifest(m Manifest, key ed25519.PrivateKey) (SignedManifest, error) {
+	sm := SignedManifest{m, nil}
+	if err := sm.AddSignature(key); err != nil {
+		return SignedManifest{}, err
+	}
+	return sm, nil
+}
+
+// AddSignature adds a signature by key. Callers should check the manifest
+// with VerifyManifest or VerifyManifestWithoutSharedSecret first.
+func (sm *SignedManifest) AddSignature(key ed25519.PrivateKey) error {
+	payload, err := manifestSigningPayload(sm.Manifest)
+	if err != nil {
+		return err
+	}
+	sm.Signatures = append(sm.Signatures, ManifestSignature{
+		HexBytes(key.Public().(ed25519.PublicKey)),
+		ed25519.Sign(key, payload),
+	})
+	return nil
+}
+
+// VerifySignatures checks that all signatures are valid and that each of
+// signers has signed.
+func (sm SignedManifest) VerifySignatures(signers ...ed25519.PublicKey) error {
+	payload, err := manifestSigningPayload(sm.Manifest)
+	if err != nil {
+		return err
+	}
+	signed := map[string]bool{}
+	for i, sig := range sm.Signatures {
+		if len(sig.PublicKey) != ed25519.PublicKeySize || !ed25519.Verify(ed25519.PublicKey(sig.PublicKey), payload, sig.Signature) {
+			return fmt.Errorf("signature %v by %x is invalid", i, []byte(sig.PublicKey))
+		}
+		signed[string(sig.PublicKey)] = true
+	}
+	var missing []string
+	for _, signer := range signers {
+		if !signed[string(signer)] {
+			missing = append(missing, hex.EncodeToString(signer))
+		}
+	}
+	if len(missing) != 0 {
+		return fmt.Errorf("manifest lacks signatures by %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,191 @@
+package configbuilder_test
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/smartcontractkit/libocr/offchainreporting2plus/chains/evmutil"
+	"github.com/smartcontractkit/libocr/offchainreporting2plus/configbuilder"
+)
+
+// jsonRoundTrip encodes in as JSON and decodes it into out.
+func jsonRoundTrip(t *testing.T, in interface{}, out interface{}) {
+	t.Helper()
+	encoded, err := json.Marshal(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(encoded, out); err != nil {
+		t.Fatalf("%v\n%s", err, encoded)
+	}
+}
+
+func TestManifestRoundTripVerifies(t *testing.T) {
+	for _, protocol := range protocols {
+		t.Run(string(protocol), func(t *testing.T) {
+			seed := configbuilder.Seed{1}
+			cc, built, err := configbuilder.Build(testOptions(protocol), seed)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var m configbuilder.Manifest
+			jsonRoundTrip(t, built, &m)
+
+			if err := configbuilder.VerifyManifest(m, testDigester, seed); err != nil {
+				t.Fatal(err)
+			}
+			if err := configbuilder.VerifyManifestWithoutSharedSecret(m, testDigester); err != nil {
+				t.Fatal(err)
+			}
+			recorded, err := m.ContractConfig()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(recorded, cc) {
+				t.Fatalf("manifest records config %+v, expected %+v", recorded, cc)
+			}
+			opts, err := m.Options(testDigester)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(opts, testOptions(protocol)) {
+				t.Fatalf("manifest records options %+v, expected %+v", opts, testOptions(protocol))
+			}
+
+			if err := configbuilder.VerifyManifest(m, testDigester, configbuilder.Seed{2}); err == nil {
+				t.Fatalf("expected error for wrong shared secret source")
+			}
+			otherDigester := evmutil.EVMOffchainConfigDigester{2, common.HexToAddress("0x1234")}
+			if err := configbuilder.VerifyManifestWithoutSharedSecret(m, otherDigester); err == nil {
+				t.Fatalf("expected error for wrong digester")
+			}
+		})
+	}
+}
+
+// Manifests share their hex encoding with the other config text formats,
+// which accept hex strings without 0x prefix.
+func TestManifestAcceptsHexWithoutPrefix(t *testing.T) {
+	seed := configbuilder.Seed{1}
+	_, built, err := configbuilder.Build(testOptions(configbuilder.ProtocolOCR3), seed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	encoded, err := json.Marshal(built)
+	if err != nil {
+		t.Fatal(err)
+	}
+	withoutPrefix := strings.ReplaceAll(string(encoded), `"OnchainConfig":"0xbeef"`, `"OnchainConfig":"beef"`)
+	if strings.Count(withoutPrefix, `"OnchainConfig":"beef"`) != 2 {
+		t.Fatalf("replacement didn't apply: %s", encoded)
+	}
+	var m configbuilder.Manifest
+	if err := json.Unmarshal([]byte(withoutPrefix), &m); err != nil {
+		t.Fatal(err)
+	}
+	if err := configbuilder.VerifyManifest(m, testDigester, seed); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestVerifyManifestRejectsTampering(t *testing.T) {
+	for _, protocol := range protocols {
+		t.Run(string(protocol), func(t *testing.T) {
+			seed := configbuilder.Seed{1}
+			_, built, err := configbuilder.Build(testOptions(protocol), seed)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for name, modify := range map[string]func(m *configbuilder.Manifest){
+				"version":            func(m *configbuilder.Manifest) { m.Version++ },
+				"input F":            func(m *configbuilder.Manifest) { m.Inputs.F = 0 },
+				"input DeltaRound":   func(m *configbuilder.Manifest) { m.Inputs.DeltaRound += configbuilder.Duration(time.Millisecond) },
+				"input config count": func(m *configbuilder.Manifest) { m.Inputs.ConfigCount++ },
+				"input oracle": func(m *configbuilder.Manifest) {
+					m.Inputs.Oracles[0], m.Inputs.Oracles[1] = m.Inputs.Oracles[1], m.Inputs.Oracles[0]
+				},
+				"input encryption key":   func(m *configbuilder.Manifest) { m.Inputs.Oracles[2].ConfigEncryptionPublicKey[1]++ },
+				"input plugin config":    func(m *configbuilder.Manifest) { m.Inputs.ReportingPluginConfig = []byte{1} },
+				"shared secret hash":     func(m *configbuilder.Manifest) { m.SharedSecretHash[0]++ },
+				"output digest":          func(m *configbuilder.Manifest) { m.Output.ConfigDigest[31]++ },
+				"output signer":          func(m *configbuilder.Manifest) { m.Output.Signers[0][0]++ },
+				"output transmitter":     func(m *configbuilder.Manifest) { m.Output.Transmitters[3] = m.Output.Transmitters[0] },
+				"output F":               func(m *configbuilder.Manifest) { m.Output.F++ },
+				"output onchain config":  func(m *configbuilder.Manifest) { m.Output.OnchainConfig = append(m.Output.OnchainConfig, 0) },
+				"output offchain config": func(m *configbuilder.Manifest) { m.Output.OffchainConfig[len(m.Output.OffchainConfig)-1]++ },
+				"output offchain config version": func(m *configbuilder.Manifest) {
+					m.Output.OffchainConfigVersion++
+				},
+			} {
+				// Modify a deep copy
+				var m configbuilder.Manifest
+				jsonRoundTrip(t, built, &m)
+				modify(&m)
+
+				if err := configbuilder.VerifyManifest(m, testDigester, seed); err == nil {
+					t.Errorf("%s: VerifyManifest: expected error", name)
+				}
+				// The encryption keys only affect the shared secret
+				// encryptions, which can't be checked without the secret.
+				if name == "input encryption key" {
+					continue
+				}
+				if err := configbuilder.VerifyManifestWithoutSharedSecret(m, testDigester); err == nil {
+					t.Errorf("%s: VerifyManifestWithoutSharedSecret: expected error", name)
+				}
+			}
+		})
+	}
+}
+
+func TestSignedManifest(t *testing.T) {
+	_, m, err := configbuilder.Build(testOptions(configbuilder.ProtocolOCR3), configbuilder.Seed{1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	alice := ed25519.NewKeyFromSeed(bytes.Repeat([]byte{0xa}, ed25519.SeedSize))
+	bob := ed25519.NewKeyFromSeed(bytes.Repeat([]byte{0xb}, ed25519.SeedSize))
+	carol := ed25519.NewKeyFromSeed(bytes.Repeat([]byte{0xc}, ed25519.SeedSize))
+
+	signed, err := configbuilder.SignManifest(m, alice)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := signed.AddSignature(bob); err != nil {
+		t.Fatal(err)
+	}
+	var decoded configbuilder.SignedManifest
+	jsonRoundTrip(t, signed, &decoded)
+	for _, sm := range []configbuilder.SignedManifest{signed, decoded} {
+		if err := sm.VerifySignatures(alice.Public().(ed25519.PublicKey), bob.Public().(ed25519.PublicKey)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := decoded.VerifySignatures(carol.Public().(ed25519.PublicKey)); err == nil {
+		t.Fatalf("expected error for missing signature")
+	}
+
+	for name, modify := range map[string]func(sm *configbuilder.SignedManifest){
+		"manifest":   func(sm *configbuilder.SignedManifest) { sm.Manifest.Inputs.F++ },
+		"signature":  func(sm *configbuilder.SignedManifest) { sm.Signatures[1].Signature[0]++ },
+		"public key": func(sm *configbuilder.SignedManifest) { sm.Signatures[0].PublicKey = sm.Signatures[1].PublicKey },
+		"short public key": func(sm *configbuilder.SignedManifest) {
+			sm.Signatures[0].PublicKey = sm.Signatures[0].PublicKey[:31]
+		},
+	} {
+		var sm configbuilder.SignedManifest
+		jsonRoundTrip(t, signed, &sm)
+		modify(&sm)
+		if err := sm.VerifySignatures(); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
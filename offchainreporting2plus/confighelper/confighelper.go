@@ -59,6 +59,10 @@ func PublicConfigFromContractConfig(skipResourceExhaustionChecks bool, change ty
 	if err != nil {
 		return PublicConfig{}, err
 	}
+	return publicConfigFromInternal(internalPublicConfig), nil
+}
+
+func publicConfigFromInternal(internalPublicConfig ocr2config.PublicConfig) PublicConfig {
 	identities := []OracleIdentity{}
 	for _, internalIdentity := range internalPublicConfig.OracleIdentities {
 		identities = append(identities, OracleIdentity{
@@ -86,7 +90,7 @@ func PublicConfigFromContractConfig(skipResourceExhaustionChecks bool, change ty
 		internalPublicConfig.F,
 		internalPublicConfig.OnchainConfig,
 		internalPublicConfig.ConfigDigest,
-	}, nil
+	}
 }
 
 type OracleIdentityExtra struct {
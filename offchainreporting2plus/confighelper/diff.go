@@ -0,0 +1,429 @@
+package confighelper
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/smartcontractkit/libocr/offchainreporting2plus/internal/config/ocr2config"
+	"github.com/smartcontractkit/libocr/offchainreporting2plus/types"
+)
+
+// ConfigDiff describes what changes between two configs of the same protocol.
+// It is meant to be reviewed by humans before a config change is approved,
+// either through String() or rendered as JSON.
+type ConfigDiff struct {
+	Oracles    []OracleChange
+	Parameters []ParameterChange
+	// Changes to the reporting plugin config and onchain config. If a
+	// PluginConfigDecoder is given for a blob, changes are reported per
+	// field, e.g. "ReportingPluginConfig.DeltaC", otherwise for the entire
+	// blob.
+	ReportingPluginConfig []ParameterChange
+	OnchainConfig         []ParameterChange
+
+	// Whether the oracle changes require the new config to use a different
+	// shared secret than the old config, e.g. because a removed oracle knows
+	// the old shared secret. ForcesNewSharedSecretReasons explains why.
+	ForcesNewSharedSecret        bool
+	ForcesNewSharedSecretReasons []string
+	// Whether the new config actually uses a different shared secret. Only
+	// known when diffing ContractConfigs, nil otherwise.
+	SharedSecretChanged *bool
+}
+
+// Empty returns true if the configs are identical except for their shared
+// secret.
+func (d ConfigDiff) Empty() bool {
+	return len(d.Oracles) == 0 && len(d.Parameters) == 0 && len(d.ReportingPluginConfig) == 0 && len(d.OnchainConfig) == 0
+}
+
+func (d ConfigDiff) String() string {
+	var b strings.Builder
+	if d.Empty() {
+		b.WriteString("no changes\n")
+	}
+	for _, oc := range d.Oracles {
+		fmt.Fprintf(&b, "%v\n", oc)
+	}
+	for _, changes := range [][]ParameterChange{d.Parameters, d.ReportingPluginConfig, d.OnchainConfig} {
+		for _, pc := range changes {
+			fmt.Fprintf(&b, "%v\n", pc)
+		}
+	}
+	if d.ForcesNewSharedSecret {
+		fmt.Fprintf(&b, "change forces a new shared secret: %s\n", strings.Join(d.ForcesNewSharedSecretReasons, "; "))
+	}
+	if d.SharedSecretChanged != nil {
+		if *d.SharedSecretChanged {
+			b.WriteString("shared secret changed\n")
+		} else if d.ForcesNewSharedSecret {
+			b.WriteString("WARNING: shared secret unchanged even though the change forces a new shared secret\n")
+		} else {
+			b.WriteString("shared secret unchanged\n")
+		}
+	}
+	return b.String()
+}
+
+type OracleChangeType int
+
+const (
+	_ OracleChangeType = iota
+	OracleAdded
+	OracleRemoved
+	// Some, but not all, of the oracle's identity changed
+	OracleRotated
+	// The oracle's identity is unchanged, but its index (and thus its
+	// commontypes.OracleID) changed
+	OracleMoved
+)
+
+func (t OracleChangeType) String() string {
+	switch t {
+	case OracleAdded:
+		return "added"
+	case OracleRemoved:
+		return "removed"
+	case OracleRotated:
+		return "rotated"
+	case OracleMoved:
+		return "moved"
+	}
+	return fmt.Sprintf("OracleChangeType(%d)", int(t))
+}
+
+func (t OracleChangeType) MarshalText() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+type OracleChange struct {
+	Type OracleChangeType
+	// Index of the oracle in the old and new config, -1 if the oracle was
+	// added or removed, respectively.
+	OldIndex int
+	NewIndex int
+	// Zero values if the oracle was added or removed, respectively.
+	Old OracleIdentity
+	New OracleIdentity
+	// Names of the OracleIdentity fields that differ between Old and New.
+	// Only set for OracleRotated.
+	ChangedFields []string
+}
+
+func (c OracleChange) String() string {
+	switch c.Type {
+	case OracleAdded:
+		return fmt.Sprintf("oracle added at index %v: %s", c.NewIndex, formatOracleIdentity(c.New))
+	case OracleRemoved:
+		return fmt.Sprintf("oracle removed from index %v: %s", c.OldIndex, formatOracleIdentity(c.Old))
+	case OracleRotated:
+		return fmt.Sprintf("oracle rotated %s (index %v -> %v): %s -> %s",
+			strings.Join(c.ChangedFields, ", "), c.OldIndex, c.NewIndex, formatOracleIdentity(c.Old), formatOracleIdentity(c.New))
+	case OracleMoved:
+		return fmt.Sprintf("oracle moved from index %v to %v: %s", c.OldIndex, c.NewIndex, formatOracleIdentity(c.New))
+	}
+	return fmt.Sprintf("%v oracle (index %v -> %v)", c.Type, c.OldIndex, c.NewIndex)
+}
+
+func formatOracleIdentity(id OracleIdentity) string {
+	return fmt.Sprintf("{offchainPublicKey: %x, signer: 0x%x, peerID: %s, transmitter: %s}",
+		id.OffchainPublicKey, []byte(id.OnchainPublicKey), id.PeerID, id.TransmitAccount)
+}
+
+// ParameterChange describes a changed value. Values are formatted with %v,
+// byte strings are hex-encoded.
+type ParameterChange struct {
+	Name string
+	Old  string
+	New  string
+}
+
+func (c ParameterChange) String() string {
+	return fmt.Sprintf("%s: %s -> %s", c.Name, c.Old, c.New)
+}
+
+// PluginConfigDecoder decodes a plugin-specific config blob, such as
+// ReportingPluginConfig. If the decoded value is a struct, changes are reported
+// per exported field.
+type PluginConfigDecoder func([]byte) (interface{}, error)
+
+type DiffOptions struct {
+	// Both optional
+	ReportingPluginConfigDecoder PluginConfigDecoder
+	OnchainConfigDecoder         PluginConfigDecoder
+}
+
+// Parameter is a named protocol parameter compared by Diff.
+type Parameter struct {
+	Name  string
+	Value interface{}
+}
+
+// DiffInput is the protocol-independent representation of a config compared
+// by Diff.
+type DiffInput struct {
+	OracleIdentities      []OracleIdentity
+	Parameters            []Parameter
+	ReportingPluginConfig []byte
+	OnchainConfig         []byte
+}
+
+// DiffPublicConfigs compares two OCR2 configs. See
+// ocr3confighelper.DiffPublicConfigs for OCR3.
+func DiffPublicConfigs(old, new PublicConfig, opts DiffOptions) ConfigDiff {
+	return Diff(diffInput(old), diffInput(new), opts)
+}
+
+// DiffContractConfigs compares two OCR2 contract configs. Unlike
+// PublicConfigFromContractConfig, it also accepts configs that oracles would
+// reject, as long as they can be decoded. See
+// ocr3confighelper.DiffContractConfigs for OCR3.
+func DiffContractConfigs(old, new types.ContractConfig, opts DiffOptions) (ConfigDiff, error) {
+	oldInternal, _, _, err := ocr2config.XXXPublicConfigAndErrorsFromContractConfig(old)
+	if err != nil {
+		return ConfigDiff{}, fmt.Errorf("could not decode old config: %w", err)
+	}
+	newInternal, _, _, err := ocr2config.XXXPublicConfigAndErrorsFromContractConfig(new)
+	if err != nil {
+		return ConfigDiff{}, fmt.Errorf("could not decode new config: %w", err)
+	}
+	oldOC, err := ocr2config.XXXDeserializeOffchainConfig(old.OffchainConfig)
+	if err != nil {
+		return ConfigDiff{}, err
+	}
+	newOC, err := ocr2config.XXXDeserializeOffchainConfig(new.OffchainConfig)
+	if err != nil {
+		return ConfigDiff{}, err
+	}
+
+	diff := DiffPublicConfigs(publicConfigFromInternal(oldInternal), publicConfigFromInternal(newInternal), opts)
+	sharedSecretChanged := oldOC.SharedSecretEncryptions.SharedSecretHash != newOC.SharedSecretEncryptions.SharedSecretHash
+	diff.SharedSecretChanged = &sharedSecretChanged
+	return diff, nil
+}
+
+func diffInput(pc PublicConfig) DiffInput {
+	return DiffInput{
+		pc.OracleIdentities,
+		[]Parameter{
+			{"F", pc.F},
+			{"DeltaProgress", pc.DeltaProgress},
+			{"DeltaResend", pc.DeltaResend},
+			{"DeltaRound", pc.DeltaRound},
+			{"DeltaGrace", pc.DeltaGrace},
+			{"DeltaStage", pc.DeltaStage},
+			{"RMax", pc.RMax},
+			{"S", pc.S},
+			{"MaxDurationQuery", pc.MaxDurationQuery},
+			{"MaxDurationObservation", pc.MaxDurationObservation},
+			{"MaxDurationReport", pc.MaxDurationReport},
+			{"MaxDurationShouldAcceptFinalizedReport", pc.MaxDurationShouldAcceptFinalizedReport},
+			{"MaxDurationShouldTransmitAcceptedReport", pc.MaxDurationShouldTransmitAcceptedReport},
+		},
+		pc.ReportingPluginConfig,
+		pc.OnchainConfig,
+	}
+}
+
+// Diff compares two configs given in protocol-independent form. Parameters are
+// matched by name. Most callers will want to use DiffPublicConfigs or
+// DiffContractConfigs instead.
+func Diff(old, new DiffInput, opts DiffOptions) ConfigDiff {
+	diff := ConfigDiff{
+		diffOracleIdentities(old.OracleIdentities, new.OracleIdentities),
+		diffParameters(old.Parameters, new.Parameters),
+		diffPluginConfig("ReportingPluginConfig", old.ReportingPluginConfig, new.ReportingPluginConfig, opts.ReportingPluginConfigDecoder),
+		diffPluginConfig("OnchainConfig", old.OnchainConfig, new.OnchainConfig, opts.OnchainConfigDecoder),
+		false,
+		nil,
+		nil,
+	}
+
+	for _, oc := range diff.Oracles {
+		switch oc.Type {
+		case OracleRemoved:
+			diff.ForcesNewSharedSecretReasons = append(diff.ForcesNewSharedSecretReasons,
+				fmt.Sprintf("removed oracle %v (peer ID %s) knows the old shared secret", oc.OldIndex, oc.Old.PeerID))
+		case OracleRotated:
+			for _, field := range oc.ChangedFields {
+				if field == "OffchainPublicKey" {
+					diff.ForcesNewSharedSecretReasons = append(diff.ForcesNewSharedSecretReasons,
+						fmt.Sprintf("oracle %v rotated its offchain key, the old key can decrypt the old shared secret", oc.NewIndex))
+				}
+			}
+		}
+	}
+	diff.ForcesNewSharedSecret = len(diff.ForcesNewSharedSecretReasons) != 0
+	return diff
+}
+
+// oracleIdentityKeys returns the fields that identify an oracle, in the order
+// in which they are reported in OracleChange.ChangedFields.
+func oracleIdentityKeys(id OracleIdentity) []string {
+	return []string{
+		string(id.OffchainPublicKey[:]),
+		string(id.OnchainPublicKey),
+		id.PeerID,
+		string(id.TransmitAccount),
+	}
+}
+
+var oracleIdentityFieldNames = []string{"OffchainPublicKey", "OnchainPublicKey", "PeerID", "TransmitAccount"}
+
+// diffOracleIdentities matches oracles in old to oracles in new. Identical
+// identities are matched first, then identities that share at least one key,
+// preferring those that share the most. Unmatched oracles are reported as
+// removed or added.
+func diffOracleIdentities(old, new []OracleIdentity) []OracleChange {
+	newMatched := make([]bool, len(new))
+	oldMatch := make([]int, len(old))
+	for i := range oldMatch {
+		oldMatch[i] = -1
+	}
+
+	sharedKeys := func(i, j int) int {
+		oldKeys, newKeys := oracleIdentityKeys(old[i]), oracleIdentityKeys(new[j])
+		count := 0
+		for k := range oldKeys {
+			if oldKeys[k] == newKeys[k] {
+				count++
+			}
+		}
+		return count
+	}
+
+	// Identical identities, preferring the same index
+	for i := range old {
+		if i < len(new) && sharedKeys(i, i) == len(oracleIdentityFieldNames) {
+			oldMatch[i], newMatched[i] = i, true
+		}
+	}
+	for i := range old {
+		if oldMatch[i] != -1 {
+			continue
+		}
+		for j := range new {
+			if !newMatched[j] && sharedKeys(i, j) == len(oracleIdentityFieldNames) {
+				oldMatch[i], newMatched[j] = j, true
+				break
+			}
+		}
+	}
+	// Partial matches
+	for i := range old {
+		if oldMatch[i] != -1 {
+			continue
+		}
+		best, bestShared := -1, 0
+		for j := range new {
+			if newMatched[j] {
+				continue
+			}
+			if shared := sharedKeys(i, j); shared > bestShared {
+				best, bestShared = j, shared
+			}
+		}
+		if best != -1 {
+			oldMatch[i], newMatched[best] = best, true
+		}
+	}
+
+	var changes []OracleChange
+	for i, j := range oldMatch {
+		if j == -1 {
+			changes = append(changes, OracleChange{OracleRemoved, i, -1, old[i], OracleIdentity{}, nil})
+			continue
+		}
+		var changedFields []string
+		oldKeys, newKeys := oracleIdentityKeys(old[i]), oracleIdentityKeys(new[j])
+		for k := range oldKeys {
+			if oldKeys[k] != newKeys[k] {
+				changedFields = append(changedFields, oracleIdentityFieldNames[k])
+			}
+		}
+		if len(changedFields) != 0 {
+			changes = append(changes, OracleChange{OracleRotated, i, j, old[i], new[j], changedFields})
+		} else if i != j {
+			changes = append(changes, OracleChange{OracleMoved, i, j, old[i], new[j], nil})
+		}
+	}
+	for j := range new {
+		if !newMatched[j] {
+			changes = append(changes, OracleChange{OracleAdded, -1, j, OracleIdentity{}, new[j], nil})
+		}
+	}
+	return changes
+}
+
+func diffParameters(old, new []Parameter) []ParameterChange {
+	var changes []ParameterChange
+	newByName := map[string]interface{}{}
+	for _, p := range new {
+		newByName[p.Name] = p.Value
+	}
+	oldNames := map[string]bool{}
+	for _, p := range old {
+		oldNames[p.Name] = true
+		newValue, ok := newByName[p.Name]
+		if !ok {
+			changes = append(changes, ParameterChange{p.Name, formatValue(p.Value), "<none>"})
+		} else if !reflect.DeepEqual(p.Value, newValue) {
+			changes = append(changes, ParameterChange{p.Name, formatValue(p.Value), formatValue(newValue)})
+		}
+	}
+	for _, p := range new {
+		if !oldNames[p.Name] {
+			changes = append(changes, ParameterChange{p.Name, "<none>", formatValue(p.Value)})
+		}
+	}
+	return changes
+}
+
+func formatValue(v interface{}) string {
+	if b, ok := v.([]byte); ok {
+		return "0x" + hex.EncodeToString(b)
+	}
+	return fmt.Sprintf("%v", v)
+}
+
+func diffPluginConfig(name string, old, new []byte, decoder PluginConfigDecoder) []ParameterChange {
+	if bytes.Equal(old, new) {
+		return nil
+	}
+	blobChange := []ParameterChange{{name, formatValue(old), formatValue(new)}}
+	if decoder == nil {
+		return blobChange
+	}
+	oldDecoded, oldErr := decoder(old)
+	newDecoded, newErr := decoder(new)
+	if oldErr != nil || newErr != nil {
+		return blobChange
+	}
+
+	oldValue, newValue := reflect.ValueOf(oldDecoded), reflect.ValueOf(newDecoded)
+	if oldValue.Kind() != reflect.Struct || oldValue.Type() != newValue.Type() {
+		if reflect.DeepEqual(oldDecoded, newDecoded) {
+			return blobChange
+		}
+		return []ParameterChange{{name, fmt.Sprintf("%+v", oldDecoded), fmt.Sprintf("%+v", newDecoded)}}
+	}
+	var changes []ParameterChange
+	for i := 0; i < oldValue.NumField(); i++ {
+		field := oldValue.Type().Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		oldField, newField := oldValue.Field(i).Interface(), newValue.Field(i).Interface()
+		if !reflect.DeepEqual(oldField, newField) {
+			changes = append(changes, ParameterChange{name + "." + field.Name, formatValue(oldField), formatValue(newField)})
+		}
+	}
+	if len(changes) == 0 {
+		// Only the encoding differs
+		return blobChange
+	}
+	return changes
+}
@@ -0,0 +1,210 @@
+package confighelper_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/smartcontractkit/libocr/offchainreporting2plus/configbuilder"
+	"github.com/smartcontractkit/libocr/offchainreporting2plus/confighelper"
+	"github.com/smartcontractkit/libocr/offchainreporting2plus/types"
+)
+
+type oracleChangeSummary struct {
+	Type          confighelper.OracleChangeType
+	OldIndex      int
+	NewIndex      int
+	ChangedFields []string
+}
+
+func summarizeOracleChanges(changes []confighelper.OracleChange) []oracleChangeSummary {
+	var summaries []oracleChangeSummary
+	for _, c := range changes {
+		summaries = append(summaries, oracleChangeSummary{c.Type, c.OldIndex, c.NewIndex, c.ChangedFields})
+	}
+	return summaries
+}
+
+func TestDiffContractConfigs(t *testing.T) {
+	oracles := testOracles(6)
+	// The sixth oracle is only used as a replacement
+	base := oracles[:5]
+
+	build := func(t *testing.T, oracles []confighelper.OracleIdentityExtra, seed configbuilder.Seed, modify func(*configbuilder.Options)) types.ContractConfig {
+		t.Helper()
+		opts := testOptions(configbuilder.ProtocolOCR2, oracles)
+		if modify != nil {
+			modify(&opts)
+		}
+		cc, _, err := configbuilder.Build(opts, seed)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return cc
+	}
+
+	withOracle := func(i int, modify func(*confighelper.OracleIdentityExtra)) []confighelper.OracleIdentityExtra {
+		modified := append([]confighelper.OracleIdentityExtra{}, base...)
+		modify(&modified[i])
+		return modified
+	}
+
+	for _, test := range []struct {
+		name                  string
+		new                   []confighelper.OracleIdentityExtra
+		modify                func(*configbuilder.Options)
+		expectedOracles       []oracleChangeSummary
+		expectedParameters    []string
+		forcesNewSharedSecret bool
+	}{
+		{
+			"unchanged",
+			base,
+			nil,
+			nil,
+			nil,
+			false,
+		},
+		{
+			"oracle added",
+			oracles,
+			nil,
+			[]oracleChangeSummary{{confighelper.OracleAdded, -1, 5, nil}},
+			nil,
+			false,
+		},
+		{
+			"oracle removed",
+			append(append([]confighelper.OracleIdentityExtra{}, base[:2]...), base[3:]...),
+			nil,
+			[]oracleChangeSummary{
+				{confighelper.OracleRemoved, 2, -1, nil},
+				{confighelper.OracleMoved, 3, 2, nil},
+				{confighelper.OracleMoved, 4, 3, nil},
+			},
+			nil,
+			true,
+		},
+		{
+			"oracles moved",
+			[]confighelper.OracleIdentityExtra{base[1], base[0], base[2], base[3], base[4]},
+			nil,
+			[]oracleChangeSummary{
+				{confighelper.OracleMoved, 0, 1, nil},
+				{confighelper.OracleMoved, 1, 0, nil},
+			},
+			nil,
+			false,
+		},
+		{
+			"offchain key rotated",
+			withOracle(2, func(o *confighelper.OracleIdentityExtra) {
+				o.OffchainPublicKey = oracles[5].OffchainPublicKey
+			}),
+			nil,
+			[]oracleChangeSummary{{confighelper.OracleRotated, 2, 2, []string{"OffchainPublicKey"}}},
+			nil,
+			true,
+		},
+		{
+			"transmitter and signer rotated",
+			withOracle(3, func(o *confighelper.OracleIdentityExtra) {
+				o.TransmitAccount = oracles[5].TransmitAccount
+				o.OnchainPublicKey = oracles[5].OnchainPublicKey
+			}),
+			nil,
+			[]oracleChangeSummary{{confighelper.OracleRotated, 3, 3, []string{"OnchainPublicKey", "TransmitAccount"}}},
+			nil,
+			false,
+		},
+		{
+			"oracle replaced",
+			withOracle(4, func(o *confighelper.OracleIdentityExtra) {
+				*o = oracles[5]
+			}),
+			nil,
+			[]oracleChangeSummary{
+				{confighelper.OracleRemoved, 4, -1, nil},
+				{confighelper.OracleAdded, -1, 4, nil},
+			},
+			nil,
+			true,
+		},
+		{
+			"parameters changed",
+			base,
+			func(opts *configbuilder.Options) {
+				opts.DeltaRound *= 2
+				opts.OnchainConfig = []byte{0x01}
+			},
+			nil,
+			[]string{"DeltaRound", "OnchainConfig"},
+			false,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			old := build(t, base, configbuilder.Seed{1}, nil)
+			for _, seed := range []configbuilder.Seed{{1}, {2}} {
+				new := build(t, test.new, seed, test.modify)
+				diff, err := confighelper.DiffContractConfigs(old, new, confighelper.DiffOptions{})
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if oracles := summarizeOracleChanges(diff.Oracles); !reflect.DeepEqual(oracles, test.expectedOracles) {
+					t.Errorf("expected oracle changes %+v, got %+v", test.expectedOracles, oracles)
+				}
+				var parameters []string
+				for _, changes := range [][]confighelper.ParameterChange{diff.Parameters, diff.ReportingPluginConfig, diff.OnchainConfig} {
+					for _, pc := range changes {
+						parameters = append(parameters, pc.Name)
+					}
+				}
+				if !reflect.DeepEqual(parameters, test.expectedParameters) {
+					t.Errorf("expected parameter changes %v, got %v", test.expectedParameters, parameters)
+				}
+				if diff.Empty() != (test.expectedOracles == nil && test.expectedParameters == nil) {
+					t.Errorf("unexpected Empty() %v", diff.Empty())
+				}
+
+				if diff.ForcesNewSharedSecret != test.forcesNewSharedSecret {
+					t.Errorf("expected ForcesNewSharedSecret %v, got %v (%v)", test.forcesNewSharedSecret, diff.ForcesNewSharedSecret, diff.ForcesNewSharedSecretReasons)
+				}
+				if diff.ForcesNewSharedSecret != (len(diff.ForcesNewSharedSecretReasons) != 0) {
+					t.Errorf("ForcesNewSharedSecret doesn't match reasons %v", diff.ForcesNewSharedSecretReasons)
+				}
+				sharedSecretChanged := seed != configbuilder.Seed{1}
+				if diff.SharedSecretChanged == nil || *diff.SharedSecretChanged != sharedSecretChanged {
+					t.Errorf("expected SharedSecretChanged %v, got %v", sharedSecretChanged, diff.SharedSecretChanged)
+				}
+			}
+		})
+	}
+}
+
+func TestDiffContractConfigsPluginDecoder(t *testing.T) {
+	old, _, err := configbuilder.Build(testOptions(configbuilder.ProtocolOCR2, testOracles(4)), configbuilder.Seed{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts := testOptions(configbuilder.ProtocolOCR2, testOracles(4))
+	opts.ReportingPluginConfig = []byte{0xde, 0xaf}
+	new, _, err := configbuilder.Build(opts, configbuilder.Seed{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type pluginConfig struct {
+		A, B byte
+	}
+	decoder := func(b []byte) (interface{}, error) {
+		return pluginConfig{b[0], b[1]}, nil
+	}
+	diff, err := confighelper.DiffContractConfigs(old, new, confighelper.DiffOptions{decoder, nil})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []confighelper.ParameterChange{{"ReportingPluginConfig.B", "173", "175"}}
+	if !reflect.DeepEqual(diff.ReportingPluginConfig, expected) {
+		t.Fatalf("expected %+v, got %+v", expected, diff.ReportingPluginConfig)
+	}
+}
@@ -0,0 +1,287 @@
+package confighelper
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/smartcontractkit/libocr/offchainreporting2plus/internal/config/configtext"
+	"github.com/smartcontractkit/libocr/offchainreporting2plus/types"
+)
+
+// PublicConfigText, OracleIdentityText, and OracleIdentityExtraText wrap
+// PublicConfig, OracleIdentity, and OracleIdentityExtra, respectively. They
+// implement json.Marshaler/Unmarshaler and yaml.Marshaler/Unmarshaler with a
+// stable, versioned, human-readable encoding that is suitable for keeping
+// configs in version control: durations are rendered like "1.5s", byte strings
+// and keys are hex-encoded with 0x prefix, and peer IDs are kept in their usual
+// base58 form. Decoding rejects unknown fields. Every value that survives a
+// round-trip through the protobuf serialization of the offchain config also
+// survives a round-trip through the text encoding unchanged.
+//
+// Example (YAML):
+//
+//	version: 1
+//	protocol: ocr2
+//	deltaProgress: 8s
+//	...
+//	oracles:
+//	- offchainPublicKey: 0x...
+//	  onchainPublicKey: 0x...
+//	  peerID: 12D3KooW...
+//	  transmitAccount: 0x...
+//	...
+//
+// Note that YAML parses unquoted values like 0x1234 as integers. The encoder
+// always quotes such values; hand-written YAML must do the same.
+//
+// The wrapped types themselves keep encoding/json's default encoding. Convert
+// to and from the wrappers to use the text encoding, e.g.
+//
+//	encoded, err := json.Marshal(confighelper.PublicConfigText(pc))
+//
+// PublicConfigText (and OracleIdentityText within it) encodes the oracle
+// identities with the text encoding, too.
+
+// PublicConfigText is a PublicConfig with the versioned text encoding.
+type PublicConfigText PublicConfig
+
+// OracleIdentityText is an OracleIdentity with the versioned text encoding.
+// It is encoded without version header, since it only ever appears nested
+// inside other documents.
+type OracleIdentityText OracleIdentity
+
+// OracleIdentityExtraText is an OracleIdentityExtra with the versioned text
+// encoding. It is encoded with version header, since lists of oracles are
+// typically kept in files of their own. The header isn't specific to a
+// protocol.
+type OracleIdentityExtraText OracleIdentityExtra
+
+var (
+	_ json.Marshaler   = PublicConfigText{}
+	_ json.Unmarshaler = &PublicConfigText{}
+	_ json.Marshaler   = OracleIdentityText{}
+	_ json.Unmarshaler = &OracleIdentityText{}
+	_ json.Marshaler   = OracleIdentityExtraText{}
+	_ json.Unmarshaler = &OracleIdentityExtraText{}
+)
+
+const publicConfigTextProtocol = "ocr2"
+
+type publicConfigText struct {
+	configtext.Header
+
+	DeltaProgress configtext.Duration  `json:"deltaProgress"`
+	DeltaResend   configtext.Duration  `json:"deltaResend"`
+	DeltaRound    configtext.Duration  `json:"deltaRound"`
+	DeltaGrace    configtext.Duration  `json:"deltaGrace"`
+	DeltaStage    configtext.Duration  `json:"deltaStage"`
+	RMax          uint8                `json:"rMax"`
+	S             []int                `json:"s"`
+	Oracles       []OracleIdentityText `json:"oracles"`
+
+	ReportingPluginConfig configtext.HexBytes `json:"reportingPluginConfig"`
+
+	MaxDurationQuery                        configtext.Duration `json:"maxDurationQuery"`
+	MaxDurationObservation                  configtext.Duration `json:"maxDurationObservation"`
+	MaxDurationReport                       configtext.Duration `json:"maxDurationReport"`
+	MaxDurationShouldAcceptFinalizedReport  configtext.Duration `json:"maxDurationShouldAcceptFinalizedReport"`
+	MaxDurationShouldTransmitAcceptedReport configtext.Duration `json:"maxDurationShouldTransmitAcceptedReport"`
+
+	F             int                 `json:"f"`
+	OnchainConfig configtext.HexBytes `json:"onchainConfig"`
+	// Omitted if zero, which is the case for configs that haven't been
+	// deployed yet
+	ConfigDigest configtext.HexBytes `json:"configDigest,omitempty"`
+}
+
+func (pc PublicConfigText) MarshalJSON() ([]byte, error) {
+	var configDigest configtext.HexBytes
+	if pc.ConfigDigest != (types.ConfigDigest{}) {
+		configDigest = pc.ConfigDigest[:]
+	}
+	return json.Marshal(publicConfigText{
+		configtext.Header{configtext.Version, publicConfigTextProtocol},
+		configtext.Duration(pc.DeltaProgress),
+		configtext.Duration(pc.DeltaResend),
+		configtext.Duration(pc.DeltaRound),
+		configtext.Duration(pc.DeltaGrace),
+		configtext.Duration(pc.DeltaStage),
+		pc.RMax,
+		pc.S,
+		OracleIdentitiesText(pc.OracleIdentities),
+		pc.ReportingPluginConfig,
+		configtext.Duration(pc.MaxDurationQuery),
+		configtext.Duration(pc.MaxDurationObservation),
+		configtext.Duration(pc.MaxDurationReport),
+		configtext.Duration(pc.MaxDurationShouldAcceptFinalizedReport),
+		configtext.Duration(pc.MaxDurationShouldTransmitAcceptedReport),
+		pc.F,
+		pc.OnchainConfig,
+		configDigest,
+	})
+}
+
+func (pc *PublicConfigText) UnmarshalJSON(data []byte) error {
+	var t publicConfigText
+	if err := configtext.UnmarshalStrict(data, &t); err != nil {
+		return fmt.Errorf("could not decode PublicConfig: %w", err)
+	}
+	if err := t.Header.Check(publicConfigTextProtocol); err != nil {
+		return err
+	}
+	var configDigest types.ConfigDigest
+	if t.ConfigDigest != nil {
+		if err := configtext.DecodeFixed("configDigest", t.ConfigDigest, configDigest[:]); err != nil {
+			return err
+		}
+	}
+	*pc = PublicConfigText{
+		time.Duration(t.DeltaProgress),
+		time.Duration(t.DeltaResend),
+		time.Duration(t.DeltaRound),
+		time.Duration(t.DeltaGrace),
+		time.Duration(t.DeltaStage),
+		t.RMax,
+		t.S,
+		OracleIdentitiesFromText(t.Oracles),
+		t.ReportingPluginConfig,
+		time.Duration(t.MaxDurationQuery),
+		time.Duration(t.MaxDurationObservation),
+		time.Duration(t.MaxDurationReport),
+		time.Duration(t.MaxDurationShouldAcceptFinalizedReport),
+		time.Duration(t.MaxDurationShouldTransmitAcceptedReport),
+		t.F,
+		t.OnchainConfig,
+		configDigest,
+	}
+	return nil
+}
+
+func (pc PublicConfigText) MarshalYAML() (interface{}, error) {
+	return configtext.MarshalYAML(pc)
+}
+
+func (pc *PublicConfigText) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	return configtext.UnmarshalYAML(unmarshal, pc)
+}
+
+type oracleIdentityText struct {
+	OffchainPublicKey configtext.HexBytes `json:"offchainPublicKey"`
+	OnchainPublicKey  configtext.HexBytes `json:"onchainPublicKey"`
+	PeerID            string              `json:"peerID"`
+	TransmitAccount   types.Account       `json:"transmitAccount"`
+}
+
+func (t oracleIdentityText) decode() (OracleIdentity, error) {
+	var offchainPublicKey types.OffchainPublicKey
+	if err := configtext.DecodeFixed("offchainPublicKey", t.OffchainPublicKey, offchainPublicKey[:]); err != nil {
+		return OracleIdentity{}, err
+	}
+	return OracleIdentity{
+		offchainPublicKey,
+		types.OnchainPublicKey(t.OnchainPublicKey),
+		t.PeerID,
+		t.TransmitAccount,
+	}, nil
+}
+
+func (oi OracleIdentityText) text() oracleIdentityText {
+	return oracleIdentityText{
+		oi.OffchainPublicKey[:],
+		configtext.HexBytes(oi.OnchainPublicKey),
+		oi.PeerID,
+		oi.TransmitAccount,
+	}
+}
+
+func (oi OracleIdentityText) MarshalJSON() ([]byte, error) {
+	return json.Marshal(oi.text())
+}
+
+func (oi *OracleIdentityText) UnmarshalJSON(data []byte) error {
+	var t oracleIdentityText
+	if err := configtext.UnmarshalStrict(data, &t); err != nil {
+		return fmt.Errorf("could not decode OracleIdentity: %w", err)
+	}
+	decoded, err := t.decode()
+	if err != nil {
+		return err
+	}
+	*oi = OracleIdentityText(decoded)
+	return nil
+}
+
+func (oi OracleIdentityText) MarshalYAML() (interface{}, error) {
+	return configtext.MarshalYAML(oi)
+}
+
+func (oi *OracleIdentityText) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	return configtext.UnmarshalYAML(unmarshal, oi)
+}
+
+type oracleIdentityExtraText struct {
+	configtext.Header
+	oracleIdentityText
+	ConfigEncryptionPublicKey configtext.HexBytes `json:"configEncryptionPublicKey"`
+}
+
+func (oi OracleIdentityExtraText) MarshalJSON() ([]byte, error) {
+	return json.Marshal(oracleIdentityExtraText{
+		configtext.Header{configtext.Version, ""},
+		OracleIdentityText(oi.OracleIdentity).text(),
+		oi.ConfigEncryptionPublicKey[:],
+	})
+}
+
+func (oi *OracleIdentityExtraText) UnmarshalJSON(data []byte) error {
+	var t oracleIdentityExtraText
+	if err := configtext.UnmarshalStrict(data, &t); err != nil {
+		return fmt.Errorf("could not decode OracleIdentityExtra: %w", err)
+	}
+	if err := t.Header.Check(""); err != nil {
+		return err
+	}
+	identity, err := t.oracleIdentityText.decode()
+	if err != nil {
+		return err
+	}
+	var configEncryptionPublicKey types.ConfigEncryptionPublicKey
+	if err := configtext.DecodeFixed("configEncryptionPublicKey", t.ConfigEncryptionPublicKey, configEncryptionPublicKey[:]); err != nil {
+		return err
+	}
+	*oi = OracleIdentityExtraText{identity, configEncryptionPublicKey}
+	return nil
+}
+
+func (oi OracleIdentityExtraText) MarshalYAML() (interface{}, error) {
+	return configtext.MarshalYAML(oi)
+}
+
+func (oi *OracleIdentityExtraText) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	return configtext.UnmarshalYAML(unmarshal, oi)
+}
+
+// OracleIdentitiesText converts identities for use with the text encoding.
+func OracleIdentitiesText(identities []OracleIdentity) []OracleIdentityText {
+	if identities == nil {
+		return nil
+	}
+	result := make([]OracleIdentityText, 0, len(identities))
+	for _, identity := range identities {
+		result = append(result, OracleIdentityText(identity))
+	}
+	return result
+}
+
+// OracleIdentitiesFromText is the inverse of OracleIdentitiesText.
+func OracleIdentitiesFromText(identities []OracleIdentityText) []OracleIdentity {
+	if identities == nil {
+		return nil
+	}
+	result := make([]OracleIdentity, 0, len(identities))
+	for _, identity := range identities {
+		result = append(result, OracleIdentity(identity))
+	}
+	return result
+}
@@ -0,0 +1,219 @@
+package confighelper_test
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"gopkg.in/yaml.v2"
+
+	"github.com/smartcontractkit/libocr/offchainreporting2plus/chains/evmutil"
+	"github.com/smartcontractkit/libocr/offchainreporting2plus/configbuilder"
+	"github.com/smartcontractkit/libocr/offchainreporting2plus/confighelper"
+	"github.com/smartcontractkit/libocr/offchainreporting2plus/types"
+	ragetypes "github.com/smartcontractkit/libocr/ragep2p/types"
+)
+
+func testOracles(n int) []confighelper.OracleIdentityExtra {
+	oracles := []confighelper.OracleIdentityExtra{}
+	for i := 0; i < n; i++ {
+		seed := bytes.Repeat([]byte{byte(i + 1)}, ed25519.SeedSize)
+		var offchainPublicKey types.OffchainPublicKey
+		copy(offchainPublicKey[:], ed25519.NewKeyFromSeed(seed).Public().(ed25519.PublicKey))
+		peerID, err := ragetypes.PeerIDFromPrivateKey(ed25519.NewKeyFromSeed(seed))
+		if err != nil {
+			panic(err)
+		}
+		oracles = append(oracles, confighelper.OracleIdentityExtra{
+			confighelper.OracleIdentity{
+				offchainPublicKey,
+				bytes.Repeat([]byte{byte(0x10 + i)}, 20),
+				peerID.String(),
+				types.Account(common.BytesToAddress(bytes.Repeat([]byte{byte(0x20 + i)}, 20)).Hex()),
+			},
+			types.ConfigEncryptionPublicKey{byte(0x30 + i), 1},
+		})
+	}
+	return oracles
+}
+
+func testOptions(protocol configbuilder.Protocol, oracles []confighelper.OracleIdentityExtra) configbuilder.Options {
+	opts := configbuilder.Options{
+		Protocol:                                protocol,
+		ConfigCount:                             3,
+		Digester:                                evmutil.EVMOffchainConfigDigester{1, common.HexToAddress("0x1234")},
+		Oracles:                                 oracles,
+		F:                                       1,
+		DeltaProgress:                           8*time.Second + 1,
+		DeltaResend:                             30 * time.Second,
+		DeltaRound:                              1500 * time.Millisecond,
+		DeltaGrace:                              500 * time.Millisecond,
+		DeltaStage:                              20 * time.Second,
+		RMax:                                    5,
+		S:                                       []int{1, 1, 2},
+		ReportingPluginConfig:                   []byte{0xde, 0xad},
+		OnchainConfig:                           []byte{0xbe, 0xef},
+		MaxDurationQuery:                        0,
+		MaxDurationObservation:                  time.Second,
+		MaxDurationShouldTransmitAcceptedReport: time.Second,
+	}
+	switch protocol {
+	case configbuilder.ProtocolOCR2:
+		opts.MaxDurationReport = time.Second
+		opts.MaxDurationShouldAcceptFinalizedReport = time.Second
+	case configbuilder.ProtocolOCR3:
+		opts.DeltaInitial = 3 * time.Second
+		opts.DeltaCertifiedCommitRequest = time.Second
+		opts.MaxDurationShouldAcceptAttestedReport = time.Second
+	}
+	return opts
+}
+
+// roundTrip encodes in as JSON and YAML, decodes both into fresh values of the
+// same type, and checks that they are equal to in.
+func roundTrip(t *testing.T, in interface{}) {
+	t.Helper()
+	for _, format := range []struct {
+		name      string
+		marshal   func(interface{}) ([]byte, error)
+		unmarshal func([]byte, interface{}) error
+	}{
+		{"json", json.Marshal, json.Unmarshal},
+		{"yaml", yaml.Marshal, yaml.Unmarshal},
+	} {
+		encoded, err := format.marshal(in)
+		if err != nil {
+			t.Fatalf("%s: %v", format.name, err)
+		}
+		out := reflect.New(reflect.TypeOf(in))
+		if err := format.unmarshal(encoded, out.Interface()); err != nil {
+			t.Fatalf("%s: %v\n%s", format.name, err, encoded)
+		}
+		if !reflect.DeepEqual(in, out.Elem().Interface()) {
+			t.Fatalf("%s: round-trip changed value\nbefore: %+v\nafter:  %+v\nencoding:\n%s", format.name, in, out.Elem().Interface(), encoded)
+		}
+	}
+}
+
+func TestPublicConfigTextRoundTrip(t *testing.T) {
+	oracles := testOracles(4)
+	seed := configbuilder.Seed{1}
+
+	for _, oracle := range oracles {
+		roundTrip(t, confighelper.OracleIdentityExtraText(oracle))
+		roundTrip(t, confighelper.OracleIdentityText(oracle.OracleIdentity))
+	}
+
+	cc, _, err := configbuilder.Build(testOptions(configbuilder.ProtocolOCR2, oracles), seed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pc, err := confighelper.PublicConfigFromContractConfig(false, cc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	roundTrip(t, confighelper.PublicConfigText(pc))
+
+	// Rebuild the config from the decoded text encoding and check that the
+	// result is identical byte-for-byte.
+	var decoded confighelper.PublicConfigText
+	if err := json.Unmarshal(mustMarshal(t, confighelper.PublicConfigText(pc)), &decoded); err != nil {
+		t.Fatal(err)
+	}
+	rebuilt := configbuilder.Options{
+		configbuilder.ProtocolOCR2, 3, evmutil.EVMOffchainConfigDigester{1, common.HexToAddress("0x1234")},
+		withEncryptionKeys(decoded.OracleIdentities, oracles), decoded.F,
+		decoded.DeltaProgress, decoded.DeltaResend, 0, decoded.DeltaRound, decoded.DeltaGrace, 0, decoded.DeltaStage,
+		uint64(decoded.RMax), decoded.S, decoded.ReportingPluginConfig, decoded.OnchainConfig,
+		decoded.MaxDurationQuery, decoded.MaxDurationObservation, decoded.MaxDurationReport,
+		decoded.MaxDurationShouldAcceptFinalizedReport, 0, decoded.MaxDurationShouldTransmitAcceptedReport,
+	}
+	rebuiltCC, _, err := configbuilder.Build(rebuilt, seed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(cc, rebuiltCC) {
+		t.Fatalf("rebuilt contract config differs\nbefore: %+v\nafter:  %+v", cc, rebuiltCC)
+	}
+}
+
+func TestPublicConfigTextRejectsMismatches(t *testing.T) {
+	cc, _, err := configbuilder.Build(testOptions(configbuilder.ProtocolOCR2, testOracles(4)), configbuilder.Seed{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	pc, err := confighelper.PublicConfigFromContractConfig(false, cc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	encoded := string(mustMarshal(t, confighelper.PublicConfigText(pc)))
+
+	for name, text := range map[string]string{
+		"wrong protocol": strings.Replace(encoded, `"protocol":"ocr2"`, `"protocol":"ocr3"`, 1),
+		"wrong version":  strings.Replace(encoded, `"version":1`, `"version":2`, 1),
+		"unknown field":  strings.Replace(encoded, `"version":1`, `"version":1,"deltaInitial":"1s"`, 1),
+		"short key":      strings.Replace(encoded, `"offchainPublicKey":"0x`, `"offchainPublicKey":"0x00`, 1),
+	} {
+		var decoded confighelper.PublicConfigText
+		if err := json.Unmarshal([]byte(text), &decoded); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+// The text encoding is opt-in, the wrapped types keep encoding/json's default
+// encoding.
+func TestDefaultJSONEncodingUnchanged(t *testing.T) {
+	cc, _, err := configbuilder.Build(testOptions(configbuilder.ProtocolOCR2, testOracles(4)), configbuilder.Seed{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	pc, err := confighelper.PublicConfigFromContractConfig(false, cc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	encoded := string(mustMarshal(t, pc))
+	for _, expected := range []string{`"DeltaProgress":8000000001`, `"OracleIdentities":[{"OffchainPublicKey":[`} {
+		if !strings.Contains(encoded, expected) {
+			t.Errorf("default encoding %s does not contain %s", encoded, expected)
+		}
+	}
+	if strings.Contains(encoded, `"version"`) {
+		t.Errorf("default encoding %s contains version header", encoded)
+	}
+
+	oracle := testOracles(1)[0]
+	var decoded confighelper.OracleIdentityExtra
+	if err := json.Unmarshal(mustMarshal(t, oracle), &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(decoded, oracle) {
+		t.Fatalf("default encoding doesn't round-trip\nbefore: %+v\nafter:  %+v", oracle, decoded)
+	}
+}
+
+func mustMarshal(t *testing.T, v interface{}) []byte {
+	t.Helper()
+	b, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func withEncryptionKeys(identities []confighelper.OracleIdentity, oracles []confighelper.OracleIdentityExtra) []confighelper.OracleIdentityExtra {
+	result := []confighelper.OracleIdentityExtra{}
+	for i, identity := range identities {
+		result = append(result, confighelper.OracleIdentityExtra{identity, oracles[i].ConfigEncryptionPublicKey})
+	}
+	if len(result) != len(oracles) {
+		panic(fmt.Sprintf("expected %v identities, got %v", len(oracles), len(result)))
+	}
+	return result
+}
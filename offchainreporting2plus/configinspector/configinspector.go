@@ -0,0 +1,314 @@
+// Package configinspector decodes a types.ContractConfig into a readable form
+// for debugging. Unlike the functions in confighelper and ocr3confighelper, it
+// decodes configs even if they would be rejected by oracles, and reports why
+// they would be rejected alongside the decoded contents.
+package configinspector
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/smartcontractkit/libocr/commontypes"
+	"github.com/smartcontractkit/libocr/offchainreporting2plus/confighelper"
+	"github.com/smartcontractkit/libocr/offchainreporting2plus/identityattestation"
+	"github.com/smartcontractkit/libocr/offchainreporting2plus/internal/config"
+	"github.com/smartcontractkit/libocr/offchainreporting2plus/internal/config/configtext"
+	"github.com/smartcontractkit/libocr/offchainreporting2plus/internal/config/ocr2config"
+	"github.com/smartcontractkit/libocr/offchainreporting2plus/internal/config/ocr3config"
+	"github.com/smartcontractkit/libocr/offchainreporting2plus/types"
+)
+
+// Protocol names used in Inspection.Protocol
+const (
+	ProtocolOCR2 = "ocr2"
+	ProtocolOCR3 = "ocr3" // also used by Mercury
+)
+
+// Inspection is the decoded contents of a types.ContractConfig. It is meant to
+// be rendered as JSON: byte strings are hex-encoded and durations are
+// rendered like "1.5s". Fields that do not exist in the config's protocol are
+// omitted.
+type Inspection struct {
+	Protocol              string
+	ConfigDigest          string
+	ConfigCount           uint64
+	OffchainConfigVersion uint64
+	N                     int
+	F                     uint8
+
+	DeltaProgress               Duration
+	DeltaResend                 Duration
+	DeltaInitial                *Duration `json:",omitempty"` // OCR3 only
+	DeltaRound                  Duration
+	DeltaGrace                  Duration
+	DeltaCertifiedCommitRequest *Duration `json:",omitempty"` // OCR3 only
+	DeltaStage                  Duration
+	RMax                        uint64
+	S                           []int
+
+	MaxDurationQuery                        Duration
+	MaxDurationObservation                  Duration
+	MaxDurationReport                       *Duration `json:",omitempty"` // OCR2 only
+	MaxDurationShouldAcceptFinalizedReport  *Duration `json:",omitempty"` // OCR2 only
+	MaxDurationShouldAcceptAttestedReport   *Duration `json:",omitempty"` // OCR3 only
+	MaxDurationShouldTransmitAcceptedReport Duration
+
+	OracleIdentities []OracleIdentity
+
+	ReportingPluginConfig HexBytes
+	OnchainConfig         HexBytes
+
+	SharedSecretEncryptions SharedSecretEncryptions
+
+	// ValidationError explains why oracles would reject the config. Empty
+	// if the config is valid.
+	ValidationError string `json:",omitempty"`
+
+	// Set by InspectWithKeyring
+	SharedSecretDecryption *SharedSecretDecryption `json:",omitempty"`
+
+	sharedSecretEncryptions config.SharedSecretEncryptions
+}
+
+// OracleIdentity combines the onchain and offchain parts of an oracle's
+// identity. If the lists in the onchain and offchain part of the config have
+// different lengths, missing values are left empty.
+type OracleIdentity struct {
+	OracleID          int
+	OffchainPublicKey HexBytes
+	OnchainPublicKey  HexBytes
+	PeerID            string
+	TransmitAccount   types.Account
+
+	// Set by VerifyAttestations
+	Attestation *AttestationVerification `json:",omitempty"`
+}
+
+// AttestationVerification describes whether an oracle's identity in the config
+// is backed by a valid identity attestation. The config does not contain the
+// oracles' config encryption keys in plain, so they are not checked.
+type AttestationVerification struct {
+	Verified bool
+	Error    string `json:",omitempty"`
+}
+
+type SharedSecretEncryptions struct {
+	DiffieHellmanPoint HexBytes
+	SharedSecretHash   HexBytes
+	Encryptions        []HexBytes
+}
+
+// SharedSecretDecryption describes whether an oracle can decrypt the shared
+// secret. The shared secret itself is never included.
+type SharedSecretDecryption struct {
+	OracleID commontypes.OracleID
+	// Whether the keyring's OffchainPublicKey matches the config's
+	// OffchainPublicKey for OracleID
+	OffchainPublicKeyMatches bool
+	Success                  bool
+	Error                    string `json:",omitempty"`
+}
+
+// HexBytes is rendered as a hex string with 0x prefix. It is shared with the
+// other config text formats, so that an Inspection can be decoded again.
+type HexBytes = configtext.HexBytes
+
+// Duration is rendered like "1.5s".
+type Duration = configtext.Duration
+
+func durationPtr(d time.Duration) *Duration {
+	result := Duration(d)
+	return &result
+}
+
+// Inspect decodes cc. It only returns an error if the offchain config cannot
+// be deserialized at all. If the config can be deserialized but would be
+// rejected by oracles, the reason is recorded in ValidationError.
+func Inspect(cc types.ContractConfig) (*Inspection, error) {
+	var (
+		inspection         *Inspection
+		offchainPublicKeys []types.OffchainPublicKey
+		peerIDs            []string
+		validationErr      error
+	)
+	switch cc.OffchainConfigVersion {
+	case config.OCR2OffchainConfigVersion:
+		oc, err := ocr2config.XXXDeserializeOffchainConfig(cc.OffchainConfig)
+		if err != nil {
+			return nil, err
+		}
+		inspection = &Inspection{
+			ProtocolOCR2,
+			cc.ConfigDigest.Hex(),
+			cc.ConfigCount,
+			cc.OffchainConfigVersion,
+			0,
+			cc.F,
+			Duration(oc.DeltaProgress),
+			Duration(oc.DeltaResend),
+			nil,
+			Duration(oc.DeltaRound),
+			Duration(oc.DeltaGrace),
+			nil,
+			Duration(oc.DeltaStage),
+			uint64(oc.RMax),
+			oc.S,
+			Duration(oc.MaxDurationQuery),
+			Duration(oc.MaxDurationObservation),
+			durationPtr(oc.MaxDurationReport),
+			durationPtr(oc.MaxDurationShouldAcceptFinalizedReport),
+			nil,
+			Duration(oc.MaxDurationShouldTransmitAcceptedReport),
+			nil,
+			oc.ReportingPluginConfig,
+			cc.OnchainConfig,
+			SharedSecretEncryptions{},
+			"",
+			nil,
+			oc.SharedSecretEncryptions,
+		}
+		offchainPublicKeys, peerIDs = oc.OffchainPublicKeys, oc.PeerIDs
+		_, validationErr = ocr2config.PublicConfigFromContractConfig(false, cc)
+	case config.OCR3OffchainConfigVersion:
+		oc, err := ocr3config.XXXDeserializeOffchainConfig(cc.OffchainConfig)
+		if err != nil {
+			return nil, err
+		}
+		inspection = &Inspection{
+			ProtocolOCR3,
+			cc.ConfigDigest.Hex(),
+			cc.ConfigCount,
+			cc.OffchainConfigVersion,
+			0,
+			cc.F,
+			Duration(oc.DeltaProgress),
+			Duration(oc.DeltaResend),
+			durationPtr(oc.DeltaInitial),
+			Duration(oc.DeltaRound),
+			Duration(oc.DeltaGrace),
+			durationPtr(oc.DeltaCertifiedCommitRequest),
+			Duration(oc.DeltaStage),
+			oc.RMax,
+			oc.S,
+			Duration(oc.MaxDurationQuery),
+			Duration(oc.MaxDurationObservation),
+			nil,
+			nil,
+			durationPtr(oc.MaxDurationShouldAcceptAttestedReport),
+			Duration(oc.MaxDurationShouldTransmitAcceptedReport),
+			nil,
+			oc.ReportingPluginConfig,
+			cc.OnchainConfig,
+			SharedSecretEncryptions{},
+			"",
+			nil,
+			oc.SharedSecretEncryptions,
+		}
+		offchainPublicKeys, peerIDs = oc.OffchainPublicKeys, oc.PeerIDs
+		_, validationErr = ocr3config.PublicConfigFromContractConfig(false, cc)
+	default:
+		return nil, fmt.Errorf("unsupported OffchainConfigVersion %v, supported versions are %v (OCR2) and %v (OCR3)",
+			cc.OffchainConfigVersion, config.OCR2OffchainConfigVersion, config.OCR3OffchainConfigVersion)
+	}
+
+	n := len(cc.Signers)
+	for _, l := range []int{len(cc.Transmitters), len(offchainPublicKeys), len(peerIDs)} {
+		if l > n {
+			n = l
+		}
+	}
+	inspection.N = n
+	for i := 0; i < n; i++ {
+		identity := OracleIdentity{OracleID: i}
+		if i < len(offchainPublicKeys) {
+			identity.OffchainPublicKey = offchainPublicKeys[i][:]
+		}
+		if i < len(cc.Signers) {
+			identity.OnchainPublicKey = HexBytes(cc.Signers[i])
+		}
+		if i < len(peerIDs) {
+			identity.PeerID = peerIDs[i]
+		}
+		if i < len(cc.Transmitters) {
+			identity.TransmitAccount = cc.Transmitters[i]
+		}
+		inspection.OracleIdentities = append(inspection.OracleIdentities, identity)
+	}
+
+	sse := inspection.sharedSecretEncryptions
+	inspection.SharedSecretEncryptions = SharedSecretEncryptions{
+		sse.DiffieHellmanPoint[:],
+		sse.SharedSecretHash[:],
+		nil,
+	}
+	for _, encryption := range sse.Encryptions {
+		encryption := encryption
+		inspection.SharedSecretEncryptions.Encryptions = append(inspection.SharedSecretEncryptions.Encryptions, encryption[:])
+	}
+
+	if validationErr != nil {
+		inspection.ValidationError = validationErr.Error()
+	}
+	return inspection, nil
+}
+
+// InspectWithKeyring is like Inspect, but additionally checks whether the
+// oracle with the given ID can decrypt the shared secret using keyring, i.e.
+// whether it can actually join the protocol instance. The result is stored in
+// SharedSecretDecryption.
+func InspectWithKeyring(cc types.ContractConfig, oracleID commontypes.OracleID, keyring types.OffchainKeyring) (*Inspection, error) {
+	inspection, err := Inspect(cc)
+	if err != nil {
+		return nil, err
+	}
+	decryption := &SharedSecretDecryption{OracleID: oracleID}
+	inspection.SharedSecretDecryption = decryption
+
+	if int(oracleID) >= len(inspection.OracleIdentities) {
+		decryption.Error = fmt.Sprintf("oracle id %v is out of range, config has %v oracles", oracleID, len(inspection.OracleIdentities))
+		return inspection, nil
+	}
+	offchainPublicKey := keyring.OffchainPublicKey()
+	decryption.OffchainPublicKeyMatches = string(inspection.OracleIdentities[oracleID].OffchainPublicKey) == string(offchainPublicKey[:])
+
+	if _, err := inspection.sharedSecretEncryptions.Decrypt(oracleID, keyring); err != nil {
+		decryption.Error = err.Error()
+	} else {
+		decryption.Success = true
+	}
+	return inspection, nil
+}
+
+// VerifyAttestations checks each oracle identity in the inspection against
+// attestations and records the result in the identity's Attestation field.
+// It can be combined with both Inspect and InspectWithKeyring.
+func (inspection *Inspection) VerifyAttestations(attestations []identityattestation.Attestation, onchainVerifier identityattestation.OnchainVerifier) {
+	for i := range inspection.OracleIdentities {
+		identity := &inspection.OracleIdentities[i]
+		verification := &AttestationVerification{}
+		identity.Attestation = verification
+
+		var offchainPublicKey types.OffchainPublicKey
+		if len(identity.OffchainPublicKey) != len(offchainPublicKey) {
+			verification.Error = "config lacks a valid OffchainPublicKey for this oracle"
+			continue
+		}
+		copy(offchainPublicKey[:], identity.OffchainPublicKey)
+		err := identityattestation.VerifyIdentity(
+			confighelper.OracleIdentity{
+				offchainPublicKey,
+				types.OnchainPublicKey(identity.OnchainPublicKey),
+				identity.PeerID,
+				identity.TransmitAccount,
+			},
+			nil,
+			attestations,
+			onchainVerifier,
+		)
+		if err != nil {
+			verification.Error = err.Error()
+		} else {
+			verification.Verified = true
+		}
+	}
+}
@@ -0,0 +1,273 @@
+package configinspector_test
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/smartcontractkit/libocr/commontypes"
+	"github.com/smartcontractkit/libocr/offchainreporting2plus/confighelper"
+	"github.com/smartcontractkit/libocr/offchainreporting2plus/configinspector"
+	"github.com/smartcontractkit/libocr/offchainreporting2plus/ocr3confighelper"
+	"github.com/smartcontractkit/libocr/offchainreporting2plus/offchainkeyring"
+	"github.com/smartcontractkit/libocr/offchainreporting2plus/types"
+	ragetypes "github.com/smartcontractkit/libocr/ragep2p/types"
+)
+
+const n = 4
+
+func testKeyrings(t *testing.T) ([]*offchainkeyring.Keyring, []confighelper.OracleIdentityExtra) {
+	t.Helper()
+	keyrings := []*offchainkeyring.Keyring{}
+	oracles := []confighelper.OracleIdentityExtra{}
+	for i := 0; i < n; i++ {
+		keyring, err := offchainkeyring.FromSeeds(bytes.Repeat([]byte{byte(i + 1)}, 32), bytes.Repeat([]byte{byte(0x30 + i)}, 32))
+		if err != nil {
+			t.Fatal(err)
+		}
+		peerID, err := ragetypes.PeerIDFromPrivateKey(ed25519.NewKeyFromSeed(bytes.Repeat([]byte{byte(0x40 + i)}, ed25519.SeedSize)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		keyrings = append(keyrings, keyring)
+		oracles = append(oracles, keyring.OracleIdentityExtra(
+			bytes.Repeat([]byte{byte(0x10 + i)}, 20),
+			peerID.String(),
+			types.Account(strings.Repeat(string(rune('a'+i)), 4)),
+		))
+	}
+	return keyrings, oracles
+}
+
+func contractConfig(signers []types.OnchainPublicKey, transmitters []types.Account, f uint8, onchainConfig []byte, offchainConfigVersion uint64, offchainConfig []byte, err error) (types.ContractConfig, error) {
+	return types.ContractConfig{types.ConfigDigest{0, 1, 2}, 3, signers, transmitters, f, onchainConfig, offchainConfigVersion, offchainConfig}, err
+}
+
+func ocr2ContractConfig(t *testing.T, oracles []confighelper.OracleIdentityExtra) types.ContractConfig {
+	t.Helper()
+	cc, err := contractConfig(confighelper.ContractSetConfigArgsForTests(
+		8*time.Second,
+		5*time.Second,
+		3*time.Second,
+		500*time.Millisecond,
+		20*time.Second,
+		3,
+		[]int{1, 1, 2},
+		oracles,
+		[]byte{0xaa},
+		time.Second,
+		1500*time.Millisecond,
+		time.Second,
+		time.Second,
+		time.Second,
+		1,
+		[]byte{0xbb},
+	))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cc
+}
+
+func ocr3ContractConfig(t *testing.T, oracles []confighelper.OracleIdentityExtra) types.ContractConfig {
+	t.Helper()
+	cc, err := contractConfig(ocr3confighelper.ContractSetConfigArgsForTests(
+		8*time.Second,
+		5*time.Second,
+		400*time.Millisecond,
+		3*time.Second,
+		500*time.Millisecond,
+		2*time.Second,
+		20*time.Second,
+		100,
+		[]int{1, 1, 2},
+		oracles,
+		[]byte{0xaa},
+		time.Second,
+		1500*time.Millisecond,
+		time.Second,
+		time.Second,
+		1,
+		[]byte{0xbb},
+	))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cc
+}
+
+// inspectJSON renders inspection as JSON and decodes it into a map, which is
+// how users of the inspector see it.
+func inspectJSON(t *testing.T, inspection *configinspector.Inspection) map[string]interface{} {
+	t.Helper()
+	b, err := json.Marshal(inspection)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var result map[string]interface{}
+	if err := json.Unmarshal(b, &result); err != nil {
+		t.Fatal(err)
+	}
+	return result
+}
+
+func TestInspect(t *testing.T) {
+	_, oracles := testKeyrings(t)
+	for _, test := range []struct {
+		name     string
+		cc       types.ContractConfig
+		expected map[string]interface{}
+		omitted  []string
+	}{
+		{
+			"OCR2",
+			ocr2ContractConfig(t, oracles),
+			map[string]interface{}{
+				"Protocol":                               configinspector.ProtocolOCR2,
+				"ConfigDigest":                           types.ConfigDigest{0, 1, 2}.Hex(),
+				"ConfigCount":                            3.0,
+				"N":                                      4.0,
+				"F":                                      1.0,
+				"DeltaProgress":                          "8s",
+				"DeltaRound":                             "3s",
+				"DeltaStage":                             "20s",
+				"RMax":                                   3.0,
+				"MaxDurationObservation":                 "1.5s",
+				"MaxDurationReport":                      "1s",
+				"MaxDurationShouldAcceptFinalizedReport": "1s",
+				"ReportingPluginConfig":                  "0xaa",
+				"OnchainConfig":                          "0xbb",
+			},
+			[]string{"DeltaInitial", "DeltaCertifiedCommitRequest", "MaxDurationShouldAcceptAttestedReport", "ValidationError", "SharedSecretDecryption"},
+		},
+		{
+			"OCR3",
+			ocr3ContractConfig(t, oracles),
+			map[string]interface{}{
+				"Protocol":                              configinspector.ProtocolOCR3,
+				"N":                                     4.0,
+				"DeltaInitial":                          "400ms",
+				"DeltaCertifiedCommitRequest":           "2s",
+				"RMax":                                  100.0,
+				"MaxDurationShouldAcceptAttestedReport": "1s",
+				"ReportingPluginConfig":                 "0xaa",
+			},
+			[]string{"MaxDurationReport", "MaxDurationShouldAcceptFinalizedReport", "ValidationError", "SharedSecretDecryption"},
+		},
+	} {
+		inspection, err := configinspector.Inspect(test.cc)
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+		rendered := inspectJSON(t, inspection)
+		for key, expected := range test.expected {
+			if rendered[key] != expected {
+				t.Errorf("%s: expected %s to be %v, got %v", test.name, key, expected, rendered[key])
+			}
+		}
+		for _, key := range test.omitted {
+			if _, ok := rendered[key]; ok {
+				t.Errorf("%s: expected %s to be omitted, got %v", test.name, key, rendered[key])
+			}
+		}
+
+		if len(inspection.OracleIdentities) != n {
+			t.Fatalf("%s: expected %v oracle identities, got %+v", test.name, n, inspection.OracleIdentities)
+		}
+		for i, identity := range inspection.OracleIdentities {
+			if identity.OracleID != i ||
+				!bytes.Equal(identity.OffchainPublicKey, oracles[i].OffchainPublicKey[:]) ||
+				!bytes.Equal(identity.OnchainPublicKey, oracles[i].OnchainPublicKey) ||
+				identity.PeerID != oracles[i].PeerID ||
+				identity.TransmitAccount != oracles[i].TransmitAccount {
+				t.Errorf("%s: oracle %v has unexpected identity %+v", test.name, i, identity)
+			}
+		}
+		if len(inspection.SharedSecretEncryptions.Encryptions) != n || len(inspection.SharedSecretEncryptions.DiffieHellmanPoint) != 32 {
+			t.Errorf("%s: unexpected shared secret encryptions %+v", test.name, inspection.SharedSecretEncryptions)
+		}
+	}
+}
+
+func TestInspectInvalidConfig(t *testing.T) {
+	_, oracles := testKeyrings(t)
+
+	// Rejected by oracles, but still decoded
+	cc := ocr3ContractConfig(t, oracles)
+	cc.F = 2
+	inspection, err := configinspector.Inspect(cc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := "F (2) must be non-negative and less than N/3 (N = 4)"; inspection.ValidationError != expected {
+		t.Errorf("expected validation error %q, got %q", expected, inspection.ValidationError)
+	}
+
+	// Identities are padded to the longest list
+	cc = ocr2ContractConfig(t, oracles)
+	cc.Signers = append(cc.Signers, types.OnchainPublicKey{0x99})
+	cc.Transmitters = cc.Transmitters[:2]
+	inspection, err = configinspector.Inspect(cc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if inspection.N != 5 || len(inspection.OracleIdentities) != 5 || inspection.ValidationError == "" {
+		t.Fatalf("expected 5 oracle identities and a validation error, got %+v", inspection)
+	}
+	if last := inspection.OracleIdentities[4]; !bytes.Equal(last.OnchainPublicKey, []byte{0x99}) || last.OffchainPublicKey != nil || last.PeerID != "" || last.TransmitAccount != "" {
+		t.Errorf("expected only the onchain public key of oracle 4 to be set, got %+v", last)
+	}
+	if identity := inspection.OracleIdentities[2]; identity.PeerID != oracles[2].PeerID || identity.TransmitAccount != "" {
+		t.Errorf("expected oracle 2 to lack a transmit account, got %+v", identity)
+	}
+
+	for name, cc := range map[string]types.ContractConfig{
+		"unsupported version": {OffchainConfigVersion: 1},
+		"garbage OCR2":        {OffchainConfigVersion: 2, OffchainConfig: []byte{0xff, 0xff}},
+		"garbage OCR3":        {OffchainConfigVersion: 30, OffchainConfig: []byte{0xff, 0xff}},
+	} {
+		if inspection, err := configinspector.Inspect(cc); err == nil {
+			t.Errorf("%s: expected error, got %+v", name, inspection)
+		}
+	}
+}
+
+func TestInspectWithKeyring(t *testing.T) {
+	keyrings, oracles := testKeyrings(t)
+	for name, cc := range map[string]types.ContractConfig{
+		"OCR2": ocr2ContractConfig(t, oracles),
+		"OCR3": ocr3ContractConfig(t, oracles),
+	} {
+		for _, test := range []struct {
+			oracleID        commontypes.OracleID
+			keyring         *offchainkeyring.Keyring
+			expectedMatches bool
+			expectedSuccess bool
+		}{
+			{0, keyrings[0], true, true},
+			{3, keyrings[3], true, true},
+			// Wrong oracle ID for the keyring
+			{1, keyrings[0], false, false},
+			{4, keyrings[0], false, false},
+		} {
+			inspection, err := configinspector.InspectWithKeyring(cc, test.oracleID, test.keyring)
+			if err != nil {
+				t.Fatalf("%s: %v", name, err)
+			}
+			decryption := inspection.SharedSecretDecryption
+			if decryption == nil || decryption.OracleID != test.oracleID ||
+				decryption.OffchainPublicKeyMatches != test.expectedMatches ||
+				decryption.Success != test.expectedSuccess ||
+				(decryption.Error == "") != test.expectedSuccess {
+				t.Errorf("%s: oracle %v: unexpected decryption result %+v", name, test.oracleID, decryption)
+			}
+		}
+
+		if _, err := configinspector.InspectWithKeyring(types.ContractConfig{OffchainConfigVersion: 1}, 0, keyrings[0]); err == nil {
+			t.Errorf("%s: expected error for undecodable config", name)
+		}
+	}
+}
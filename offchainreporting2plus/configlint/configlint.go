@@ -0,0 +1,279 @@
+// Package configlint analyses a types.ContractConfig and reports every problem
+// it finds, each with a severity, so that deployment pipelines can gate on the
+// result.
+//
+// Findings with SeverityError correspond to the checks oracles perform when
+// they pick up a config: oracles will refuse to run a config with any such
+// finding. Findings with SeverityWarning are legal but risky settings, e.g.
+// ones that may hurt liveness. Findings with SeverityInfo are merely
+// noteworthy.
+package configlint
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/smartcontractkit/libocr/offchainreporting2plus/internal/config"
+	"github.com/smartcontractkit/libocr/offchainreporting2plus/internal/config/ocr2config"
+	"github.com/smartcontractkit/libocr/offchainreporting2plus/internal/config/ocr3config"
+	"github.com/smartcontractkit/libocr/offchainreporting2plus/internal/managed/limits"
+	"github.com/smartcontractkit/libocr/offchainreporting2plus/ocr3types"
+	"github.com/smartcontractkit/libocr/offchainreporting2plus/types"
+)
+
+type Severity int
+
+const (
+	SeverityInfo Severity = iota
+	SeverityWarning
+	SeverityError
+)
+
+func (s Severity) String() string {
+	switch s {
+	case SeverityInfo:
+		return "info"
+	case SeverityWarning:
+		return "warning"
+	case SeverityError:
+		return "error"
+	}
+	return fmt.Sprintf("Severity(%d)", int(s))
+}
+
+func (s Severity) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// Identifiers of the checks, used in Finding.Check. They are stable, so that
+// pipelines can e.g. suppress individual checks.
+const (
+	CheckDecode              = "decode"
+	CheckProtocol            = "protocol"
+	CheckResourceExhaustion  = "resource-exhaustion"
+	CheckDeltaProgressMargin = "delta-progress-margin"
+	CheckDeltaInitial        = "delta-initial"
+	CheckTransmissionStages  = "transmission-stages"
+	CheckFaultTolerance      = "fault-tolerance"
+	CheckRMax                = "rmax"
+	CheckBandwidth           = "bandwidth"
+)
+
+type Finding struct {
+	Severity Severity
+	Check    string
+	Message  string
+}
+
+func (f Finding) String() string {
+	return fmt.Sprintf("%s [%s]: %s", f.Severity, f.Check, f.Message)
+}
+
+// MaxSeverity returns the highest severity among findings, or -1 if there are
+// no findings.
+func MaxSeverity(findings []Finding) Severity {
+	max := Severity(-1)
+	for _, f := range findings {
+		if f.Severity > max {
+			max = f.Severity
+		}
+	}
+	return max
+}
+
+const (
+	DefaultExpectedEpochStartLatency = 500 * time.Millisecond
+	DefaultMaxLeaderTenure           = 10 * time.Minute
+	DefaultMaxBytesRatePerOracle     = 1024 * 1024 // 1 MiB/s
+)
+
+// Options tune the warnings. Zero values are replaced by the corresponding
+// defaults.
+type Options struct {
+	// How long it takes after an epoch change until followers receive the
+	// new leader's EPOCH-START message in OCR3, roughly two round trips in
+	// the deployment's network plus processing time. DeltaInitial should be
+	// comfortably larger.
+	ExpectedEpochStartLatency time.Duration
+
+	// Upper bound on RMax*DeltaRound, the time a leader (possibly a faulty
+	// one) stays in charge of an epoch that makes progress.
+	MaxLeaderTenure time.Duration
+
+	// If set, the network limits of an OCR3 config are estimated with these
+	// plugin limits and the given maximum onchain signature length, and
+	// compared against MaxBytesRatePerOracle.
+	OCR3ReportingPluginLimits *ocr3types.ReportingPluginLimits
+	OCR3MaxSignatureLength    int
+	// Upper bound on the estimated bytes per second an oracle may need to
+	// receive from each other oracle.
+	MaxBytesRatePerOracle float64
+}
+
+func (o Options) withDefaults() Options {
+	if o.ExpectedEpochStartLatency == 0 {
+		o.ExpectedEpochStartLatency = DefaultExpectedEpochStartLatency
+	}
+	if o.MaxLeaderTenure == 0 {
+		o.MaxLeaderTenure = DefaultMaxLeaderTenure
+	}
+	if o.MaxBytesRatePerOracle == 0 {
+		o.MaxBytesRatePerOracle = DefaultMaxBytesRatePerOracle
+	}
+	return o
+}
+
+// Safety factor by which DeltaProgress should exceed the expected worst-case
+// duration of a round.
+const progressMarginFactor = 1.5
+
+// Lint returns all findings for cc, ordered by decreasing severity (and
+// otherwise in the order the checks ran). If cc cannot be decoded at all, the
+// only finding is a CheckDecode error.
+func Lint(cc types.ContractConfig, opts Options) []Finding {
+	opts = opts.withDefaults()
+	var l linter
+	switch cc.OffchainConfigVersion {
+	case config.OCR2OffchainConfigVersion:
+		cfg, errs, resourceExhaustionErrs, err := ocr2config.XXXPublicConfigAndErrorsFromContractConfig(cc)
+		if err != nil {
+			return []Finding{{SeverityError, CheckDecode, err.Error()}}
+		}
+		l.addErrors(CheckProtocol, errs)
+		l.addErrors(CheckResourceExhaustion, resourceExhaustionErrs)
+		l.lintOCR2(cfg, opts)
+	case config.OCR3OffchainConfigVersion:
+		cfg, errs, resourceExhaustionErrs, err := ocr3config.XXXPublicConfigAndErrorsFromContractConfig(cc)
+		if err != nil {
+			return []Finding{{SeverityError, CheckDecode, err.Error()}}
+		}
+		l.addErrors(CheckProtocol, errs)
+		l.addErrors(CheckResourceExhaustion, resourceExhaustionErrs)
+		l.lintOCR3(cfg, opts)
+	default:
+		return []Finding{{SeverityError, CheckDecode, fmt.Sprintf(
+			"unsupported OffchainConfigVersion %v, supported versions are %v (OCR2) and %v (OCR3)",
+			cc.OffchainConfigVersion, config.OCR2OffchainConfigVersion, config.OCR3OffchainConfigVersion,
+		)}}
+	}
+	return l.sorted()
+}
+
+type linter struct {
+	findings []Finding
+}
+
+func (l *linter) add(severity Severity, check string, format string, args ...interface{}) {
+	l.findings = append(l.findings, Finding{severity, check, fmt.Sprintf(format, args...)})
+}
+
+func (l *linter) addErrors(check string, errs []error) {
+	for _, err := range errs {
+		l.add(SeverityError, check, "%s", err)
+	}
+}
+
+func (l *linter) sorted() []Finding {
+	var result []Finding
+	for severity := SeverityError; severity >= SeverityInfo; severity-- {
+		for _, f := range l.findings {
+			if f.Severity == severity {
+				result = append(result, f)
+			}
+		}
+	}
+	return result
+}
+
+func (l *linter) lintOCR2(cfg ocr2config.PublicConfig, opts Options) {
+	// A round starts after DeltaRound at the latest, and a correct leader then
+	// waits for the query, observations (plus grace period) and report.
+	// Finalizing and accepting the report adds another plugin call.
+	round := cfg.DeltaRound + cfg.MaxDurationQuery + cfg.MaxDurationObservation + cfg.DeltaGrace +
+		cfg.MaxDurationReport + cfg.MaxDurationShouldAcceptFinalizedReport
+	l.lintProgressMargin(cfg.DeltaProgress, round, "DeltaRound+MaxDurationQuery+MaxDurationObservation+DeltaGrace+MaxDurationReport+MaxDurationShouldAcceptFinalizedReport")
+	l.lintS(cfg.S, cfg.N(), cfg.F)
+	l.lintF(cfg.F, cfg.N())
+	l.lintRMax(uint64(cfg.RMax), cfg.DeltaRound, opts)
+}
+
+func (l *linter) lintOCR3(cfg ocr3config.PublicConfig, opts Options) {
+	// Rounds start every DeltaRound at the earliest. A correct leader then
+	// waits for the query and observations (plus grace period) before the
+	// outcome is agreed upon.
+	round := cfg.DeltaRound + cfg.MaxDurationQuery + cfg.MaxDurationObservation + cfg.DeltaGrace
+	l.lintProgressMargin(cfg.DeltaProgress, round, "DeltaRound+MaxDurationQuery+MaxDurationObservation+DeltaGrace")
+
+	if cfg.DeltaInitial < opts.ExpectedEpochStartLatency {
+		l.add(SeverityWarning, CheckDeltaInitial,
+			"DeltaInitial (%v) is smaller than the expected epoch start latency (%v); followers may abandon correct leaders before their first message arrives",
+			cfg.DeltaInitial, opts.ExpectedEpochStartLatency)
+	}
+
+	l.lintS(cfg.S, cfg.N(), cfg.F)
+	l.lintF(cfg.F, cfg.N())
+	l.lintRMax(cfg.RMax, cfg.DeltaRound, opts)
+
+	if opts.OCR3ReportingPluginLimits != nil && cfg.DeltaRound > 0 && cfg.DeltaResend > 0 {
+		lims, err := limits.OCR3Limits(cfg, *opts.OCR3ReportingPluginLimits, opts.OCR3MaxSignatureLength)
+		if err != nil {
+			l.add(SeverityError, CheckBandwidth, "could not compute network limits: %v", err)
+		} else {
+			if lims.BytesRatePerOracle > opts.MaxBytesRatePerOracle {
+				l.add(SeverityWarning, CheckBandwidth,
+					"estimated bandwidth per oracle (%.0f bytes/s) exceeds %.0f bytes/s; consider lower plugin limits or a larger DeltaRound",
+					lims.BytesRatePerOracle, opts.MaxBytesRatePerOracle)
+			}
+			l.add(SeverityInfo, CheckBandwidth,
+				"estimated limits per oracle: max message length %v bytes, %.1f messages/s, %.0f bytes/s",
+				lims.MaxMessageLength, lims.MessagesRatePerOracle, lims.BytesRatePerOracle)
+		}
+	}
+}
+
+func (l *linter) lintProgressMargin(deltaProgress time.Duration, round time.Duration, roundDescription string) {
+	if float64(deltaProgress) < progressMarginFactor*float64(round) {
+		l.add(SeverityWarning, CheckDeltaProgressMargin,
+			"DeltaProgress (%v) is less than %v times %s (%v); epochs may time out under load even with a correct leader",
+			deltaProgress, progressMarginFactor, roundDescription, round)
+	}
+}
+
+func (l *linter) lintS(s []int, n int, f int) {
+	sum := 0
+	for _, x := range s {
+		sum += x
+	}
+	if sum < n {
+		l.add(SeverityWarning, CheckTransmissionStages,
+			"S sums to %v, less than n (%v); some oracles will never transmit", sum, n)
+	} else if sum > n {
+		l.add(SeverityWarning, CheckTransmissionStages,
+			"S sums to %v, more than n (%v); later stages will be cut short", sum, n)
+	}
+	if sum < f+1 {
+		l.add(SeverityWarning, CheckTransmissionStages,
+			"S sums to %v, less than f+1 (%v); faulty oracles may prevent all transmissions", sum, f+1)
+	}
+}
+
+func (l *linter) lintF(f int, n int) {
+	if n < 1 {
+		return
+	}
+	optimal := (n - 1) / 3
+	if f < optimal {
+		l.add(SeverityWarning, CheckFaultTolerance,
+			"f (%v) is less than floor((n-1)/3) (%v); the instance tolerates fewer faults than n (%v) allows",
+			f, optimal, n)
+	}
+}
+
+func (l *linter) lintRMax(rMax uint64, deltaRound time.Duration, opts Options) {
+	tenure := time.Duration(float64(rMax) * float64(deltaRound))
+	if float64(rMax)*float64(deltaRound) > float64(opts.MaxLeaderTenure) {
+		l.add(SeverityWarning, CheckRMax,
+			"RMax (%v) is excessive: a (possibly faulty) leader stays in charge for up to RMax*DeltaRound (%v), more than %v",
+			rMax, tenure, opts.MaxLeaderTenure)
+	}
+}
@@ -0,0 +1,208 @@
+package configlint_test
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/smartcontractkit/libocr/offchainreporting2plus/configlint"
+	"github.com/smartcontractkit/libocr/offchainreporting2plus/internal/config"
+	"github.com/smartcontractkit/libocr/offchainreporting2plus/internal/config/ocr2config"
+	"github.com/smartcontractkit/libocr/offchainreporting2plus/internal/config/ocr3config"
+	"github.com/smartcontractkit/libocr/offchainreporting2plus/ocr3types"
+	"github.com/smartcontractkit/libocr/offchainreporting2plus/types"
+)
+
+const n = 4
+
+func testIdentities() ([]config.OracleIdentity, []types.ConfigEncryptionPublicKey) {
+	identities := []config.OracleIdentity{}
+	encryptionKeys := []types.ConfigEncryptionPublicKey{}
+	for i := 0; i < n; i++ {
+		identities = append(identities, config.OracleIdentity{
+			types.OffchainPublicKey{byte(i + 1)},
+			bytes.Repeat([]byte{byte(0x10 + i)}, 20),
+			fmt.Sprintf("peer%v", i),
+			types.Account(fmt.Sprintf("transmitter%v", i)),
+		})
+		encryptionKeys = append(encryptionKeys, types.ConfigEncryptionPublicKey{byte(0x30 + i), 1})
+	}
+	return identities, encryptionKeys
+}
+
+func ocr2ContractConfig(t *testing.T, modify func(*ocr2config.PublicConfig)) types.ContractConfig {
+	identities, encryptionKeys := testIdentities()
+	pc := ocr2config.PublicConfig{
+		8 * time.Second,
+		30 * time.Second,
+		time.Second,
+		500 * time.Millisecond,
+		20 * time.Second,
+		5,
+		[]int{1, 1, 2},
+		identities,
+		nil,
+		0,
+		time.Second,
+		time.Second,
+		time.Second,
+		time.Second,
+		1,
+		nil,
+		types.ConfigDigest{},
+	}
+	modify(&pc)
+	signers, transmitters, f, onchainConfig, offchainConfigVersion, offchainConfig, err := ocr2config.XXXContractSetConfigArgsFromSharedConfigWithRand(
+		ocr2config.SharedConfig{pc, &[config.SharedSecretSize]byte{}}, encryptionKeys, bytes.NewReader(make([]byte, 32)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return types.ContractConfig{types.ConfigDigest{1}, 1, signers, transmitters, f, onchainConfig, offchainConfigVersion, offchainConfig}
+}
+
+func ocr3ContractConfig(t *testing.T, modify func(*ocr3config.PublicConfig)) types.ContractConfig {
+	identities, encryptionKeys := testIdentities()
+	pc := ocr3config.PublicConfig{
+		8 * time.Second,
+		30 * time.Second,
+		3 * time.Second,
+		time.Second,
+		500 * time.Millisecond,
+		time.Second,
+		20 * time.Second,
+		5,
+		[]int{1, 1, 2},
+		identities,
+		nil,
+		0,
+		time.Second,
+		time.Second,
+		time.Second,
+		1,
+		nil,
+		types.ConfigDigest{},
+	}
+	modify(&pc)
+	signers, transmitters, f, onchainConfig, offchainConfigVersion, offchainConfig, err := ocr3config.XXXContractSetConfigArgsFromSharedConfigWithRand(
+		ocr3config.SharedConfig{pc, &[config.SharedSecretSize]byte{}}, encryptionKeys, bytes.NewReader(make([]byte, 32)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return types.ContractConfig{types.ConfigDigest{1}, 1, signers, transmitters, f, onchainConfig, offchainConfigVersion, offchainConfig}
+}
+
+type severityAndCheck struct {
+	Severity configlint.Severity
+	Check    string
+}
+
+func severitiesAndChecks(findings []configlint.Finding) []severityAndCheck {
+	result := []severityAndCheck{}
+	for _, f := range findings {
+		result = append(result, severityAndCheck{f.Severity, f.Check})
+	}
+	return result
+}
+
+func TestLintCleanConfigs(t *testing.T) {
+	for name, cc := range map[string]types.ContractConfig{
+		"OCR2": ocr2ContractConfig(t, func(*ocr2config.PublicConfig) {}),
+		"OCR3": ocr3ContractConfig(t, func(*ocr3config.PublicConfig) {}),
+	} {
+		if findings := configlint.Lint(cc, configlint.Options{}); len(findings) != 0 {
+			t.Errorf("%s: expected no findings, got %v", name, findings)
+		}
+	}
+}
+
+func TestLintReportsAllFindingsSortedBySeverity(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		cc       types.ContractConfig
+		opts     configlint.Options
+		expected []severityAndCheck
+	}{
+		{
+			"OCR2",
+			ocr2ContractConfig(t, func(pc *ocr2config.PublicConfig) {
+				pc.F = 0
+				pc.S = []int{1}
+				pc.DeltaResend = 100 * time.Millisecond
+				pc.RMax = 0
+				pc.DeltaStage = -1
+			}),
+			configlint.Options{},
+			[]severityAndCheck{
+				{configlint.SeverityError, configlint.CheckProtocol},
+				{configlint.SeverityError, configlint.CheckProtocol},
+				{configlint.SeverityError, configlint.CheckResourceExhaustion},
+				{configlint.SeverityWarning, configlint.CheckTransmissionStages},
+				{configlint.SeverityWarning, configlint.CheckFaultTolerance},
+			},
+		},
+		{
+			"OCR3",
+			ocr3ContractConfig(t, func(pc *ocr3config.PublicConfig) {
+				pc.DeltaRound = pc.DeltaProgress
+				pc.DeltaInitial = 50 * time.Millisecond
+				pc.S = []int{1}
+				pc.F = 0
+			}),
+			configlint.Options{OCR3ReportingPluginLimits: &ocr3types.ReportingPluginLimits{100, 100, 100, 100, 1}, OCR3MaxSignatureLength: 65},
+			[]severityAndCheck{
+				{configlint.SeverityError, configlint.CheckProtocol},
+				{configlint.SeverityError, configlint.CheckResourceExhaustion},
+				{configlint.SeverityWarning, configlint.CheckDeltaProgressMargin},
+				{configlint.SeverityWarning, configlint.CheckDeltaInitial},
+				{configlint.SeverityWarning, configlint.CheckTransmissionStages},
+				{configlint.SeverityWarning, configlint.CheckFaultTolerance},
+				{configlint.SeverityInfo, configlint.CheckBandwidth},
+			},
+		},
+	} {
+		findings := configlint.Lint(test.cc, test.opts)
+		if actual := severitiesAndChecks(findings); !reflect.DeepEqual(actual, test.expected) {
+			t.Errorf("%s: expected findings %v, got %v", test.name, test.expected, findings)
+		}
+		if max := configlint.MaxSeverity(findings); max != configlint.SeverityError {
+			t.Errorf("%s: expected max severity error, got %v", test.name, max)
+		}
+	}
+}
+
+func TestLintProtocolErrorsMatchOracles(t *testing.T) {
+	cc := ocr3ContractConfig(t, func(pc *ocr3config.PublicConfig) {
+		pc.DeltaStage = -1
+		pc.RMax = 0
+	})
+	_, oracleErr := ocr3config.PublicConfigFromContractConfig(false, cc)
+	if oracleErr == nil {
+		t.Fatalf("expected oracles to reject config")
+	}
+	findings := configlint.Lint(cc, configlint.Options{})
+	if len(findings) != 2 || findings[0].Message != oracleErr.Error() {
+		t.Fatalf("expected first finding to be %q, got %v", oracleErr, findings)
+	}
+}
+
+func TestLintUndecodableConfig(t *testing.T) {
+	for name, cc := range map[string]types.ContractConfig{
+		"unsupported version": {OffchainConfigVersion: 42},
+		"garbage OCR3":        {OffchainConfigVersion: config.OCR3OffchainConfigVersion, OffchainConfig: []byte{0xff}},
+		"mismatched lengths": func() types.ContractConfig {
+			cc := ocr2ContractConfig(t, func(*ocr2config.PublicConfig) {})
+			cc.Transmitters = cc.Transmitters[1:]
+			return cc
+		}(),
+	} {
+		findings := configlint.Lint(cc, configlint.Options{})
+		if len(findings) != 1 || findings[0].Severity != configlint.SeverityError || findings[0].Check != configlint.CheckDecode {
+			t.Errorf("%s: expected a single decode error, got %v", name, findings)
+		}
+	}
+	if max := configlint.MaxSeverity(nil); max != -1 {
+		t.Errorf("expected max severity -1 for no findings, got %v", max)
+	}
+}
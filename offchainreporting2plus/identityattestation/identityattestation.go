@@ -0,0 +1,257 @@
+// Package identityattestation lets oracles prove that they hold the keys they
+// put into a config.
+//
+// Nothing in a contract config proves that the listed keys and peer IDs belong
+// to anybody: a typo or a malicious config author can add keys that nobody
+// holds, silently reducing the number of functioning oracles. An Attestation
+// is a statement by an oracle about its full identity, signed with each of its
+// keys that can produce signatures: the offchain signing key, the onchain
+// signing key and the peer key. Config authors collect attestations from all
+// oracles and verify them before generating a config; config reviewers verify
+// them against the config that was generated.
+//
+// The config encryption key is an X25519 key and cannot produce signatures.
+// It is bound to the oracle by being part of the signed statement, so an
+// oracle vouches for it, but possession is not proven. Oracles detect an
+// encryption key they don't hold when they fail to decrypt the shared secret
+// (see configinspector.InspectWithKeyring).
+package identityattestation
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/smartcontractkit/libocr/offchainreporting2plus/confighelper"
+	"github.com/smartcontractkit/libocr/offchainreporting2plus/internal/config/configtext"
+	"github.com/smartcontractkit/libocr/offchainreporting2plus/types"
+	ragetypes "github.com/smartcontractkit/libocr/ragep2p/types"
+)
+
+// Version of the attestation format. It is part of the signed statement.
+const Version = 1
+
+const domainSeparator = "libocr identity attestation"
+
+// Attestation is an oracle's signed statement about its identity. It is
+// encoded as JSON/YAML with the same conventions as
+// confighelper.OracleIdentityExtra.
+type Attestation struct {
+	Version  int
+	Identity confighelper.OracleIdentityExtra
+	// Ed25519 signature by Identity.OffchainPublicKey
+	OffchainSignature []byte
+	// Signature by Identity.OnchainPublicKey, in the format of the onchain
+	// keyring, see OnchainVerifier
+	OnchainSignature []byte
+	// Ed25519 signature by the key underlying Identity.PeerID
+	PeerSignature []byte
+}
+
+// OnchainVerifier verifies onchain signatures. It is satisfied by every
+// types.OnchainKeyring. Verification does not depend on the keyring's secret
+// key, so e.g. &evmutil.EVMOnchainKeyring{} can be used to verify attestations
+// of EVM oracles.
+type OnchainVerifier interface {
+	Verify(_ types.OnchainPublicKey, _ types.ReportContext, _ types.Report, signature []byte) bool
+}
+
+// payload is the byte string that all keys sign.
+func payload(version int, identity confighelper.OracleIdentityExtra) []byte {
+	var buf bytes.Buffer
+	buf.WriteString(domainSeparator)
+	buf.WriteByte(0)
+	_ = binary.Write(&buf, binary.BigEndian, uint64(version))
+	for _, field := range [][]byte{
+		identity.OffchainPublicKey[:],
+		identity.ConfigEncryptionPublicKey[:],
+		identity.OnchainPublicKey,
+		[]byte(identity.PeerID),
+		[]byte(identity.TransmitAccount),
+	} {
+		_ = binary.Write(&buf, binary.BigEndian, uint64(len(field)))
+		buf.Write(field)
+	}
+	return buf.Bytes()
+}
+
+// onchainReportContext wraps the payload in a ReportContext and Report, since
+// onchain keyrings only sign reports. The config digest uses prefix 0, which
+// is reserved and never used by a contract, so that the signature cannot be
+// mistaken for a signature over an actual report.
+func onchainReportContext(p []byte) (types.ReportContext, types.Report) {
+	configDigest := types.ConfigDigest(sha256.Sum256(p))
+	binary.BigEndian.PutUint16(configDigest[:2], 0)
+	return types.ReportContext{types.ReportTimestamp{configDigest, 0, 0}, [32]byte{}}, types.Report(p)
+}
+
+// Sign produces an attestation for identity. The keyrings and peerKey must
+// match the keys in identity.
+func Sign(
+	identity confighelper.OracleIdentityExtra,
+	offchainKeyring types.OffchainKeyring,
+	onchainKeyring types.OnchainKeyring,
+	peerKey ed25519.PrivateKey,
+) (Attestation, error) {
+	if offchainKeyring.OffchainPublicKey() != identity.OffchainPublicKey {
+		return Attestation{}, fmt.Errorf("offchain keyring does not match OffchainPublicKey")
+	}
+	if offchainKeyring.ConfigEncryptionPublicKey() != identity.ConfigEncryptionPublicKey {
+		return Attestation{}, fmt.Errorf("offchain keyring does not match ConfigEncryptionPublicKey")
+	}
+	if !bytes.Equal(onchainKeyring.PublicKey(), identity.OnchainPublicKey) {
+		return Attestation{}, fmt.Errorf("onchain keyring does not match OnchainPublicKey")
+	}
+	if len(peerKey) != ed25519.PrivateKeySize {
+		return Attestation{}, fmt.Errorf("peer key has wrong length, expected %v, got %v", ed25519.PrivateKeySize, len(peerKey))
+	}
+	peerID, err := ragetypes.PeerIDFromPrivateKey(peerKey)
+	if err != nil {
+		return Attestation{}, err
+	}
+	if peerID.String() != identity.PeerID {
+		return Attestation{}, fmt.Errorf("peer key does not match PeerID")
+	}
+
+	p := payload(Version, identity)
+	offchainSignature, err := offchainKeyring.OffchainSign(p)
+	if err != nil {
+		return Attestation{}, fmt.Errorf("could not sign with offchain key: %w", err)
+	}
+	onchainSignature, err := onchainKeyring.Sign(onchainReportContext(p))
+	if err != nil {
+		return Attestation{}, fmt.Errorf("could not sign with onchain key: %w", err)
+	}
+	return Attestation{
+		Version,
+		identity,
+		offchainSignature,
+		onchainSignature,
+		ed25519.Sign(peerKey, p),
+	}, nil
+}
+
+// Verify checks all signatures of the attestation.
+func (a Attestation) Verify(onchainVerifier OnchainVerifier) error {
+	if a.Version != Version {
+		return fmt.Errorf("unsupported attestation version %v, expected %v", a.Version, Version)
+	}
+	p := payload(a.Version, a.Identity)
+	if !ed25519.Verify(a.Identity.OffchainPublicKey[:], p, a.OffchainSignature) {
+		return fmt.Errorf("invalid offchain signature")
+	}
+	repctx, report := onchainReportContext(p)
+	if !onchainVerifier.Verify(a.Identity.OnchainPublicKey, repctx, report, a.OnchainSignature) {
+		return fmt.Errorf("invalid onchain signature")
+	}
+	var peerID ragetypes.PeerID
+	if err := peerID.UnmarshalText([]byte(a.Identity.PeerID)); err != nil {
+		return fmt.Errorf("invalid PeerID: %w", err)
+	}
+	if !ed25519.Verify(peerID[:], p, a.PeerSignature) {
+		return fmt.Errorf("invalid peer signature")
+	}
+	return nil
+}
+
+// VerifyIdentity checks that attestations contain a valid attestation for
+// identity. Attestations are matched by OffchainPublicKey. If
+// configEncryptionPublicKey is nil, the config encryption key is not compared,
+// which is useful for identities taken from a deployed config, since these
+// don't include the config encryption key.
+func VerifyIdentity(
+	identity confighelper.OracleIdentity,
+	configEncryptionPublicKey *types.ConfigEncryptionPublicKey,
+	attestations []Attestation,
+	onchainVerifier OnchainVerifier,
+) error {
+	for _, a := range attestations {
+		if a.Identity.OffchainPublicKey != identity.OffchainPublicKey {
+			continue
+		}
+		if !bytes.Equal(a.Identity.OnchainPublicKey, identity.OnchainPublicKey) {
+			return fmt.Errorf("attested OnchainPublicKey %x differs from %x", []byte(a.Identity.OnchainPublicKey), []byte(identity.OnchainPublicKey))
+		}
+		if a.Identity.PeerID != identity.PeerID {
+			return fmt.Errorf("attested PeerID %v differs from %v", a.Identity.PeerID, identity.PeerID)
+		}
+		if a.Identity.TransmitAccount != identity.TransmitAccount {
+			return fmt.Errorf("attested TransmitAccount %v differs from %v", a.Identity.TransmitAccount, identity.TransmitAccount)
+		}
+		if configEncryptionPublicKey != nil && a.Identity.ConfigEncryptionPublicKey != *configEncryptionPublicKey {
+			return fmt.Errorf("attested ConfigEncryptionPublicKey %x differs from %x", a.Identity.ConfigEncryptionPublicKey[:], configEncryptionPublicKey[:])
+		}
+		return a.Verify(onchainVerifier)
+	}
+	return fmt.Errorf("no attestation for OffchainPublicKey %x", identity.OffchainPublicKey[:])
+}
+
+// VerifyIdentities calls VerifyIdentity for every oracle and combines all
+// failures into a single error.
+func VerifyIdentities(
+	oracles []confighelper.OracleIdentityExtra,
+	attestations []Attestation,
+	onchainVerifier OnchainVerifier,
+) error {
+	var failures []string
+	for i, oracle := range oracles {
+		oracle := oracle
+		if err := VerifyIdentity(oracle.OracleIdentity, &oracle.ConfigEncryptionPublicKey, attestations, onchainVerifier); err != nil {
+			failures = append(failures, fmt.Sprintf("oracle %v: %v", i, err))
+		}
+	}
+	if len(failures) != 0 {
+		return fmt.Errorf("identity attestations do not verify: %s", strings.Join(failures, "; "))
+	}
+	return nil
+}
+
+var (
+	_ json.Marshaler   = Attestation{}
+	_ json.Unmarshaler = &Attestation{}
+)
+
+type attestationText struct {
+	Version           int                                  `json:"version"`
+	Identity          confighelper.OracleIdentityExtraText `json:"identity"`
+	OffchainSignature configtext.HexBytes                  `json:"offchainSignature"`
+	OnchainSignature  configtext.HexBytes                  `json:"onchainSignature"`
+	PeerSignature     configtext.HexBytes                  `json:"peerSignature"`
+}
+
+func (a Attestation) MarshalJSON() ([]byte, error) {
+	return json.Marshal(attestationText{
+		a.Version,
+		confighelper.OracleIdentityExtraText(a.Identity),
+		a.OffchainSignature,
+		a.OnchainSignature,
+		a.PeerSignature,
+	})
+}
+
+func (a *Attestation) UnmarshalJSON(data []byte) error {
+	var t attestationText
+	if err := configtext.UnmarshalStrict(data, &t); err != nil {
+		return fmt.Errorf("could not decode Attestation: %w", err)
+	}
+	*a = Attestation{
+		t.Version,
+		confighelper.OracleIdentityExtra(t.Identity),
+		t.OffchainSignature,
+		t.OnchainSignature,
+		t.PeerSignature,
+	}
+	return nil
+}
+
+func (a Attestation) MarshalYAML() (interface{}, error) {
+	return configtext.MarshalYAML(a)
+}
+
+func (a *Attestation) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	return configtext.UnmarshalYAML(unmarshal, a)
+}
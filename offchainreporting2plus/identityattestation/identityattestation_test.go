@@ -0,0 +1,114 @@
+package identityattestation_test
+
+import (
+	"crypto/ed25519"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/smartcontractkit/libocr/offchainreporting2plus/chains/evmutil"
+	"github.com/smartcontractkit/libocr/offchainreporting2plus/confighelper"
+	"github.com/smartcontractkit/libocr/offchainreporting2plus/identityattestation"
+	"github.com/smartcontractkit/libocr/offchainreporting2plus/offchainkeyring"
+	"github.com/smartcontractkit/libocr/offchainreporting2plus/types"
+	ragetypes "github.com/smartcontractkit/libocr/ragep2p/types"
+)
+
+var verifier = &evmutil.EVMOnchainKeyring{}
+
+func newAttestation(t *testing.T) identityattestation.Attestation {
+	t.Helper()
+	offchainKeyring, err := offchainkeyring.New(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	onchainKey, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	onchainKeyring := evmutil.NewEVMOnchainKeyring(onchainKey)
+	_, peerKey, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	peerID, err := ragetypes.PeerIDFromPrivateKey(peerKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	identity := offchainKeyring.OracleIdentityExtra(onchainKeyring.PublicKey(), peerID.String(), "0x0000000000000000000000000000000000000001")
+	attestation, err := identityattestation.Sign(identity, offchainKeyring, onchainKeyring, peerKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := identityattestation.Sign(identity, offchainKeyring, onchainKeyring, ed25519.NewKeyFromSeed(make([]byte, 32))); err == nil {
+		t.Fatal("Sign accepted a peer key that doesn't match the identity")
+	}
+	return attestation
+}
+
+func TestAttestation(t *testing.T) {
+	attestation := newAttestation(t)
+	if err := attestation.Verify(verifier); err != nil {
+		t.Fatal(err)
+	}
+
+	encoded, err := json.Marshal(attestation)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded identityattestation.Attestation
+	if err := json.Unmarshal(encoded, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(attestation, decoded) {
+		t.Fatalf("JSON round-trip changed attestation\nbefore: %+v\nafter:  %+v", attestation, decoded)
+	}
+
+	other := newAttestation(t)
+	for name, tamper := range map[string]func(a *identityattestation.Attestation){
+		"offchain key": func(a *identityattestation.Attestation) {
+			a.Identity.OffchainPublicKey = other.Identity.OffchainPublicKey
+		},
+		"encryption key": func(a *identityattestation.Attestation) { a.Identity.ConfigEncryptionPublicKey[0] ^= 1 },
+		"onchain key": func(a *identityattestation.Attestation) {
+			a.Identity.OnchainPublicKey = other.Identity.OnchainPublicKey
+		},
+		"peer id":     func(a *identityattestation.Attestation) { a.Identity.PeerID = other.Identity.PeerID },
+		"transmitter": func(a *identityattestation.Attestation) { a.Identity.TransmitAccount = "0x02" },
+		"version":     func(a *identityattestation.Attestation) { a.Version++ },
+		"onchain signature from other oracle": func(a *identityattestation.Attestation) {
+			a.OnchainSignature = other.OnchainSignature
+		},
+	} {
+		tampered := attestation
+		tampered.Identity.OnchainPublicKey = append(types.OnchainPublicKey{}, attestation.Identity.OnchainPublicKey...)
+		tamper(&tampered)
+		if err := tampered.Verify(verifier); err == nil {
+			t.Errorf("%s: tampered attestation verified", name)
+		}
+	}
+}
+
+func TestVerifyIdentities(t *testing.T) {
+	a, b := newAttestation(t), newAttestation(t)
+	attestations := []identityattestation.Attestation{b, a}
+
+	if err := identityattestation.VerifyIdentities([]confighelper.OracleIdentityExtra{a.Identity, b.Identity}, attestations, verifier); err != nil {
+		t.Fatal(err)
+	}
+
+	unattested := newAttestation(t).Identity
+	if err := identityattestation.VerifyIdentities([]confighelper.OracleIdentityExtra{a.Identity, unattested}, attestations, verifier); err == nil {
+		t.Fatal("expected error for oracle without attestation")
+	}
+
+	swapped := a.Identity
+	swapped.PeerID = b.Identity.PeerID
+	if err := identityattestation.VerifyIdentities([]confighelper.OracleIdentityExtra{swapped}, attestations, verifier); err == nil {
+		t.Fatal("expected error for identity that differs from the attested one")
+	}
+}
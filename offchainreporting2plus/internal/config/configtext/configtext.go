@@ -0,0 +1,170 @@
+// Package configtext contains building blocks for the human-readable JSON/YAML
+// encodings of the public config types in confighelper and ocr3confighelper.
+//
+// JSON is the canonical encoding. YAML is produced by converting the JSON
+// encoding, so both encodings always contain the same fields in the same
+// order.
+package configtext
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Version is the version of the text encoding. It is included in every
+// top-level document and must be bumped whenever the encoding changes in a way
+// that older decoders cannot handle.
+const Version = 1
+
+// HexBytes is encoded as a hex string with 0x prefix. Decoding also accepts
+// strings without the prefix. An empty string decodes to nil, matching the
+// protobuf decoding of an empty bytes field.
+type HexBytes []byte
+
+func (h HexBytes) MarshalText() ([]byte, error) {
+	return []byte("0x" + hex.EncodeToString(h)), nil
+}
+
+func (h *HexBytes) UnmarshalText(text []byte) error {
+	b, err := hex.DecodeString(strings.TrimPrefix(string(text), "0x"))
+	if err != nil {
+		return fmt.Errorf("invalid hex string %q: %w", text, err)
+	}
+	if len(b) == 0 {
+		b = nil
+	}
+	*h = b
+	return nil
+}
+
+// DecodeFixed decodes h into out, which must have exactly the same length.
+func DecodeFixed(name string, h HexBytes, out []byte) error {
+	if len(h) != len(out) {
+		return fmt.Errorf("%s must have %v bytes, got %v", name, len(out), len(h))
+	}
+	copy(out, h)
+	return nil
+}
+
+// Duration is encoded like "1.5s". time.Duration.String is exact down to the
+// nanosecond, so encoding and decoding a Duration never loses precision.
+type Duration time.Duration
+
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(time.Duration(d).String()), nil
+}
+
+func (d *Duration) UnmarshalText(text []byte) error {
+	parsed, err := time.ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+// Header is embedded at the start of every top-level document. Protocol is
+// empty for documents that aren't specific to a protocol.
+type Header struct {
+	Version  int    `json:"version"`
+	Protocol string `json:"protocol,omitempty"`
+}
+
+// Check returns an error unless h describes a document in the current version
+// for the given protocol.
+func (h Header) Check(protocol string) error {
+	if h.Version != Version {
+		return fmt.Errorf("unsupported config text version %v, expected %v", h.Version, Version)
+	}
+	if h.Protocol != protocol {
+		return fmt.Errorf("config text is for protocol %q, expected %q", h.Protocol, protocol)
+	}
+	return nil
+}
+
+// UnmarshalStrict is like json.Unmarshal, but rejects unknown fields and
+// trailing data. Typos in hand-edited configs thus don't silently fall back to
+// zero values.
+func UnmarshalStrict(data []byte, v interface{}) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(v); err != nil {
+		return err
+	}
+	if decoder.More() {
+		return fmt.Errorf("unexpected data after JSON value")
+	}
+	return nil
+}
+
+// MarshalYAML converts the JSON encoding of v into a value that yaml.v2
+// encodes with the same fields in the same order. Use it to implement
+// yaml.Marshaler.
+func MarshalYAML(v json.Marshaler) (interface{}, error) {
+	j, err := v.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	var out yaml.MapSlice
+	if err := yaml.Unmarshal(j, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// UnmarshalYAML decodes YAML by converting it to JSON and passing the result
+// to v. Use it to implement yaml.Unmarshaler.
+func UnmarshalYAML(unmarshal func(interface{}) error, v json.Unmarshaler) error {
+	var raw interface{}
+	if err := unmarshal(&raw); err != nil {
+		return err
+	}
+	normalized, err := normalizeYAML(raw)
+	if err != nil {
+		return err
+	}
+	j, err := json.Marshal(normalized)
+	if err != nil {
+		return err
+	}
+	return v.UnmarshalJSON(j)
+}
+
+// yaml.v2 decodes mappings as map[interface{}]interface{}, which
+// encoding/json cannot handle.
+func normalizeYAML(v interface{}) (interface{}, error) {
+	switch v := v.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for key, value := range v {
+			keyString, ok := key.(string)
+			if !ok {
+				return nil, fmt.Errorf("mapping key %v is not a string", key)
+			}
+			normalized, err := normalizeYAML(value)
+			if err != nil {
+				return nil, err
+			}
+			m[keyString] = normalized
+		}
+		return m, nil
+	case []interface{}:
+		s := make([]interface{}, len(v))
+		for i, value := range v {
+			normalized, err := normalizeYAML(value)
+			if err != nil {
+				return nil, err
+			}
+			s[i] = normalized
+		}
+		return s, nil
+	default:
+		return v, nil
+	}
+}
@@ -0,0 +1,44 @@
+package ocr2config
+
+import (
+	"fmt"
+
+	"github.com/smartcontractkit/libocr/offchainreporting2plus/internal/config"
+	"github.com/smartcontractkit/libocr/offchainreporting2plus/types"
+)
+
+// OffchainConfig exposes the contents of ContractConfig.OffchainConfig to
+// debugging tools.
+type OffchainConfig = offchainConfig
+
+// XXXDeserializeOffchainConfig deserializes ContractConfig.OffchainConfig
+// without performing any of the checks of PublicConfigFromContractConfig.
+// Only use this for inspecting configs, *not* for running the protocol.
+func XXXDeserializeOffchainConfig(b []byte) (OffchainConfig, error) {
+	return deserializeOffchainConfig(b)
+}
+
+// XXXPublicConfigAndErrorsFromContractConfig is like
+// PublicConfigFromContractConfig, but rather than stopping at the first failed
+// check, it returns all of them: errs contains the errors of the regular
+// checks and resourceExhaustionErrs those of the checks that
+// PublicConfigFromContractConfig skips if skipResourceExhaustionChecks is set.
+// err is only non-nil if change cannot be decoded into a PublicConfig at all.
+func XXXPublicConfigAndErrorsFromContractConfig(change types.ContractConfig) (cfg PublicConfig, errs []error, resourceExhaustionErrs []error, err error) {
+	if change.OffchainConfigVersion != config.OCR2OffchainConfigVersion {
+		return PublicConfig{}, nil, nil, fmt.Errorf("unsuppported OffchainConfigVersion %v, supported OffchainConfigVersion is %v", change.OffchainConfigVersion, config.OCR2OffchainConfigVersion)
+	}
+
+	oc, err := deserializeOffchainConfig(change.OffchainConfig)
+	if err != nil {
+		return PublicConfig{}, nil, nil, err
+	}
+
+	if err := checkIdentityListsHaveTheSameLength(change, oc); err != nil {
+		return PublicConfig{}, nil, nil, err
+	}
+
+	cfg = assemblePublicConfig(change, oc)
+	errs = append(identityListDuplicateErrors(change, oc), publicConfigParameterErrors(cfg)...)
+	return cfg, errs, resourceExhaustionErrors(cfg), nil
+}
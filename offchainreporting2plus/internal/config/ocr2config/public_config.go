@@ -117,11 +117,29 @@ func publicConfigFromContractConfig(skipResourceExhaustionChecks bool, change ty
 	}
 
 	// must check that all lists have the same length, or bad input could crash
-	// the following for loop.
+	// assemblePublicConfig.
 	if err := checkIdentityListsHaveTheSameLength(change, oc); err != nil {
 		return PublicConfig{}, config.SharedSecretEncryptions{}, err
 	}
 
+	cfg := assemblePublicConfig(change, oc)
+
+	if err := checkPublicConfigParameters(cfg); err != nil {
+		return PublicConfig{}, config.SharedSecretEncryptions{}, err
+	}
+
+	if !skipResourceExhaustionChecks {
+		if err := checkResourceExhaustion(cfg); err != nil {
+			return PublicConfig{}, config.SharedSecretEncryptions{}, err
+		}
+	}
+
+	return cfg, oc.SharedSecretEncryptions, nil
+}
+
+// assemblePublicConfig combines the contents of change and oc. The identity
+// lists must have the same length.
+func assemblePublicConfig(change types.ContractConfig, oc offchainConfig) PublicConfig {
 	identities := []config.OracleIdentity{}
 	for i := range change.Signers {
 		identities = append(identities, config.OracleIdentity{
@@ -132,7 +150,7 @@ func publicConfigFromContractConfig(skipResourceExhaustionChecks bool, change ty
 		})
 	}
 
-	cfg := PublicConfig{
+	return PublicConfig{
 		oc.DeltaProgress,
 		oc.DeltaResend,
 		oc.DeltaRound,
@@ -152,26 +170,23 @@ func publicConfigFromContractConfig(skipResourceExhaustionChecks bool, change ty
 		change.OnchainConfig,
 		change.ConfigDigest,
 	}
+}
 
-	if err := checkPublicConfigParameters(cfg); err != nil {
-		return PublicConfig{}, config.SharedSecretEncryptions{}, err
-	}
-
-	if !skipResourceExhaustionChecks {
-		if err := checkResourceExhaustion(cfg); err != nil {
-			return PublicConfig{}, config.SharedSecretEncryptions{}, err
-		}
+func checkIdentityListsHaveNoDuplicates(change types.ContractConfig, oc offchainConfig) error {
+	if errs := identityListDuplicateErrors(change, oc); len(errs) != 0 {
+		return errs[0]
 	}
-
-	return cfg, oc.SharedSecretEncryptions, nil
+	return nil
 }
 
-func checkIdentityListsHaveNoDuplicates(change types.ContractConfig, oc offchainConfig) error {
+func identityListDuplicateErrors(change types.ContractConfig, oc offchainConfig) []error {
+	var errs []error
+
 	// inefficient, but it doesn't matter
 	for i := range change.Signers {
 		for j := range change.Signers {
-			if i != j && bytes.Equal(change.Signers[i], change.Signers[j]) {
-				return fmt.Errorf("%v-th and %v-th signer are identical: %x", i, j, change.Signers[i])
+			if i < j && bytes.Equal(change.Signers[i], change.Signers[j]) {
+				errs = append(errs, fmt.Errorf("%v-th and %v-th signer are identical: %x", i, j, change.Signers[i]))
 			}
 		}
 	}
@@ -180,7 +195,7 @@ func checkIdentityListsHaveNoDuplicates(change types.ContractConfig, oc offchain
 		uniquePeerIDs := map[string]struct{}{}
 		for _, peerID := range oc.PeerIDs {
 			if _, ok := uniquePeerIDs[peerID]; ok {
-				return fmt.Errorf("duplicate PeerID '%v'", peerID)
+				errs = append(errs, fmt.Errorf("duplicate PeerID '%v'", peerID))
 			}
 			uniquePeerIDs[peerID] = struct{}{}
 		}
@@ -190,7 +205,7 @@ func checkIdentityListsHaveNoDuplicates(change types.ContractConfig, oc offchain
 		uniqueOffchainPublicKeys := map[types.OffchainPublicKey]struct{}{}
 		for _, ocpk := range oc.OffchainPublicKeys {
 			if _, ok := uniqueOffchainPublicKeys[ocpk]; ok {
-				return fmt.Errorf("duplicate OffchainPublicKey %x", ocpk)
+				errs = append(errs, fmt.Errorf("duplicate OffchainPublicKey %x", ocpk))
 			}
 			uniqueOffchainPublicKeys[ocpk] = struct{}{}
 		}
@@ -202,7 +217,7 @@ func checkIdentityListsHaveNoDuplicates(change types.ContractConfig, oc offchain
 		uniqueTransmitters := map[types.Account]struct{}{}
 		for _, transmitter := range change.Transmitters {
 			if _, ok := uniqueTransmitters[transmitter]; ok {
-				return fmt.Errorf("duplicate transmitter '%v'", transmitter)
+				errs = append(errs, fmt.Errorf("duplicate transmitter '%v'", transmitter))
 			}
 			uniqueTransmitters[transmitter] = struct{}{}
 		}
@@ -210,7 +225,7 @@ func checkIdentityListsHaveNoDuplicates(change types.ContractConfig, oc offchain
 
 	// no point in checking SharedSecretEncryptions for uniqueness
 
-	return nil
+	return errs
 }
 
 func checkIdentityListsHaveTheSameLength(
@@ -241,71 +256,80 @@ func checkIdentityListsHaveTheSameLength(
 // (3) (some) simple mistakes
 
 func checkPublicConfigParameters(cfg PublicConfig) error {
+	if errs := publicConfigParameterErrors(cfg); len(errs) != 0 {
+		return errs[0]
+	}
+	return nil
+}
+
+func publicConfigParameterErrors(cfg PublicConfig) []error {
 	/////////////////////////////////////////////////////////////////
 	// Be sure to think about changes to other tooling that need to
 	// be made when you change this function!
 	/////////////////////////////////////////////////////////////////
 
+	var errs []error
+
 	if !(0 <= cfg.DeltaStage) {
-		return fmt.Errorf("DeltaStage (%v) must be non-negative", cfg.DeltaStage)
+		errs = append(errs, fmt.Errorf("DeltaStage (%v) must be non-negative", cfg.DeltaStage))
 	}
 
 	if !(0 <= cfg.DeltaRound) {
-		return fmt.Errorf("DeltaRound (%v) must be non-negative", cfg.DeltaRound)
+		errs = append(errs, fmt.Errorf("DeltaRound (%v) must be non-negative", cfg.DeltaRound))
 	}
 
 	if !(0 <= cfg.DeltaProgress) {
-		return fmt.Errorf("DeltaProgress (%v) must be non-negative", cfg.DeltaProgress)
+		errs = append(errs, fmt.Errorf("DeltaProgress (%v) must be non-negative", cfg.DeltaProgress))
 	}
 
 	if !(0 <= cfg.DeltaResend) {
-		return fmt.Errorf("DeltaResend (%v) must be non-negative", cfg.DeltaResend)
+		errs = append(errs, fmt.Errorf("DeltaResend (%v) must be non-negative", cfg.DeltaResend))
 	}
 
 	if !(0 <= cfg.F && cfg.F*3 < cfg.N()) {
-		return fmt.Errorf("F (%v) must be non-negative and less than N/3 (N = %v)",
-			cfg.F, cfg.N())
+		errs = append(errs, fmt.Errorf("F (%v) must be non-negative and less than N/3 (N = %v)",
+			cfg.F, cfg.N()))
 	}
 
 	if !(cfg.N() <= types.MaxOracles) {
-		return fmt.Errorf("N (%v) must be less than or equal MaxOracles (%v)",
-			cfg.N(), types.MaxOracles)
+		errs = append(errs, fmt.Errorf("N (%v) must be less than or equal MaxOracles (%v)",
+			cfg.N(), types.MaxOracles))
 	}
 
 	if !(0 <= cfg.DeltaGrace) {
-		return fmt.Errorf("DeltaGrace (%v) must be non-negative",
-			cfg.DeltaGrace)
+		errs = append(errs, fmt.Errorf("DeltaGrace (%v) must be non-negative",
+			cfg.DeltaGrace))
 	}
 
 	if !(0 <= cfg.MaxDurationQuery) {
-		return fmt.Errorf("MaxDurationQuery (%v) must be non-negative", cfg.MaxDurationQuery)
+		errs = append(errs, fmt.Errorf("MaxDurationQuery (%v) must be non-negative", cfg.MaxDurationQuery))
 	}
 
 	if !(0 <= cfg.MaxDurationObservation) {
-		return fmt.Errorf("MaxDurationObservation (%v) must be non-negative", cfg.MaxDurationObservation)
+		errs = append(errs, fmt.Errorf("MaxDurationObservation (%v) must be non-negative", cfg.MaxDurationObservation))
 	}
 
 	if !(0 <= cfg.MaxDurationReport) {
-		return fmt.Errorf("MaxDurationReport (%v) must be non-negative", cfg.MaxDurationReport)
+		errs = append(errs, fmt.Errorf("MaxDurationReport (%v) must be non-negative", cfg.MaxDurationReport))
 	}
 
 	if !(0 <= cfg.MaxDurationShouldAcceptFinalizedReport) {
-		return fmt.Errorf("MaxDurationShouldAcceptFinalizedReport (%v) must be non-negative", cfg.MaxDurationShouldAcceptFinalizedReport)
+		errs = append(errs, fmt.Errorf("MaxDurationShouldAcceptFinalizedReport (%v) must be non-negative", cfg.MaxDurationShouldAcceptFinalizedReport))
 	}
 
 	if !(0 <= cfg.MaxDurationShouldTransmitAcceptedReport) {
-		return fmt.Errorf("MaxDurationShouldTransmitAcceptedReport (%v) must be non-negative", cfg.MaxDurationShouldTransmitAcceptedReport)
+		errs = append(errs, fmt.Errorf("MaxDurationShouldTransmitAcceptedReport (%v) must be non-negative", cfg.MaxDurationShouldTransmitAcceptedReport))
 	}
 
 	if !(cfg.DeltaRound < cfg.DeltaProgress) {
-		return fmt.Errorf("DeltaRound (%v) must be less than DeltaProgress (%v)",
-			cfg.DeltaRound, cfg.DeltaProgress)
+		errs = append(errs, fmt.Errorf("DeltaRound (%v) must be less than DeltaProgress (%v)",
+			cfg.DeltaRound, cfg.DeltaProgress))
 	}
 
 	sumMaxDurationsReportGeneration := cfg.MaxDurationQuery + cfg.MaxDurationObservation + cfg.MaxDurationReport
 	if !(sumMaxDurationsReportGeneration < cfg.DeltaProgress) {
-		return fmt.Errorf("sum of MaxDurationQuery/Observation/Report (%v) must be less than DeltaProgress (%v)",
-			sumMaxDurationsReportGeneration, cfg.DeltaProgress)
+		errs = append(errs, fmt.Errorf("sum of MaxDurationQuery/Observation/Report (%v) must be less than DeltaProgress (%v)",
+			sumMaxDurationsReportGeneration, cfg.DeltaProgress))
 	}
 
 	// We cannot easily add a similar check for the MaxDuration variables used
@@ -318,35 +342,44 @@ func checkPublicConfigParameters(cfg PublicConfig) error {
 	// *less* than 255 is intentional!
 	// In report_generation_leader.go, we add 1 to a round number that can equal RMax.
 	if !(0 < cfg.RMax && cfg.RMax < 255) {
-		return fmt.Errorf("RMax (%v) must be greater than zero and less than 255", cfg.RMax)
+		errs = append(errs, fmt.Errorf("RMax (%v) must be greater than zero and less than 255", cfg.RMax))
 	}
 
 	// This prevents possible overflows adding up the elements of S. We should never
 	// hit this.
 	if !(len(cfg.S) < 1000) {
-		return fmt.Errorf("len(S) (%v) must be less than 1000", len(cfg.S))
+		errs = append(errs, fmt.Errorf("len(S) (%v) must be less than 1000", len(cfg.S)))
 	}
 
 	for i, s := range cfg.S {
 		if !(0 <= s && s <= types.MaxOracles) {
-			return fmt.Errorf("S[%v] (%v) must be between 0 and types.MaxOracles (%v)", i, s, types.MaxOracles)
+			errs = append(errs, fmt.Errorf("S[%v] (%v) must be between 0 and types.MaxOracles (%v)", i, s, types.MaxOracles))
 		}
 	}
 
-	return nil
+	return errs
 }
 
 func checkResourceExhaustion(cfg PublicConfig) error {
+	if errs := resourceExhaustionErrors(cfg); len(errs) != 0 {
+		return errs[0]
+	}
+	return nil
+}
+
+func resourceExhaustionErrors(cfg PublicConfig) []error {
+	var errs []error
+
 	// Sending a NewEpoch more than every 200ms shouldn't be necessary in any
 	// realistic WAN deployment and could cause resource exhaustion
 	const safeInterval = 200 * time.Millisecond
 	if cfg.DeltaProgress < safeInterval {
-		return fmt.Errorf("DeltaProgress (%v) is set below the resource exhaustion safe interval (%v)", cfg.DeltaProgress, safeInterval)
+		errs = append(errs, fmt.Errorf("DeltaProgress (%v) is set below the resource exhaustion safe interval (%v)", cfg.DeltaProgress, safeInterval))
 	}
 	if cfg.DeltaResend < safeInterval {
-		return fmt.Errorf("DeltaResend (%v) is set below the resource exhaustion safe interval (%v)", cfg.DeltaResend, safeInterval)
+		errs = append(errs, fmt.Errorf("DeltaResend (%v) is set below the resource exhaustion safe interval (%v)", cfg.DeltaResend, safeInterval))
 	}
 	// We don't check DeltaGrace, DeltaRound, DeltaStage since none of them
 	// would exhaust the oracle's resources even if they are all set to 0.
-	return nil
+	return errs
 }
@@ -0,0 +1,191 @@
+package ocr2config
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/smartcontractkit/libocr/offchainreporting2plus/internal/config"
+	"github.com/smartcontractkit/libocr/offchainreporting2plus/types"
+)
+
+// testContractConfig returns a valid config with n oracles, after applying
+// modify to its offchain and onchain parts.
+func testContractConfig(n int, modify func(*offchainConfig, *types.ContractConfig)) types.ContractConfig {
+	oc := offchainConfig{
+		8 * time.Second,
+		30 * time.Second,
+		1500 * time.Millisecond,
+		500 * time.Millisecond,
+		20 * time.Second,
+		5,
+		[]int{1, 1, 2},
+		nil,
+		nil,
+		nil,
+		0,
+		time.Second,
+		time.Second,
+		time.Second,
+		time.Second,
+		config.SharedSecretEncryptions{},
+	}
+	cc := types.ContractConfig{
+		types.ConfigDigest{1},
+		1,
+		nil,
+		nil,
+		1,
+		nil,
+		config.OCR2OffchainConfigVersion,
+		nil,
+	}
+	for i := 0; i < n; i++ {
+		oc.OffchainPublicKeys = append(oc.OffchainPublicKeys, types.OffchainPublicKey{byte(i + 1)})
+		oc.PeerIDs = append(oc.PeerIDs, fmt.Sprintf("peer%v", i))
+		oc.SharedSecretEncryptions.Encryptions = append(oc.SharedSecretEncryptions.Encryptions, config.EncryptedSharedSecret{byte(i + 1)})
+		cc.Signers = append(cc.Signers, bytes.Repeat([]byte{byte(i + 1)}, 20))
+		cc.Transmitters = append(cc.Transmitters, types.Account(fmt.Sprintf("transmitter%v", i)))
+	}
+	if modify != nil {
+		modify(&oc, &cc)
+	}
+	cc.OffchainConfig = oc.serialize()
+	return cc
+}
+
+// The checks were changed to collect all errors for the config linter.
+// PublicConfigFromContractConfig must still return the error of the first
+// failing check, in the original order.
+func TestPublicConfigFromContractConfigFirstError(t *testing.T) {
+	for _, test := range []struct {
+		name                       string
+		modify                     func(*offchainConfig, *types.ContractConfig)
+		expectedErr                string
+		expectedErrsWithoutSkip    int
+		expectedResourceExhaustion int
+	}{
+		{
+			"valid",
+			nil,
+			"",
+			0,
+			0,
+		},
+		{
+			"duplicate signer and peer id",
+			func(oc *offchainConfig, cc *types.ContractConfig) {
+				cc.Signers[2] = cc.Signers[0]
+				oc.PeerIDs[3] = oc.PeerIDs[1]
+			},
+			"0-th and 2-th signer are identical: 0101010101010101010101010101010101010101",
+			2,
+			0,
+		},
+		{
+			"negative DeltaStage and DeltaRound",
+			func(oc *offchainConfig, cc *types.ContractConfig) {
+				oc.DeltaStage = -1
+				oc.DeltaRound = -1
+			},
+			"DeltaStage (-1ns) must be non-negative",
+			2,
+			0,
+		},
+		{
+			"F too large and negative MaxDurationQuery",
+			func(oc *offchainConfig, cc *types.ContractConfig) {
+				cc.F = 2
+				oc.MaxDurationQuery = -1
+			},
+			"F (2) must be non-negative and less than N/3 (N = 4)",
+			2,
+			0,
+		},
+		{
+			"DeltaRound not less than DeltaProgress and RMax zero",
+			func(oc *offchainConfig, cc *types.ContractConfig) {
+				oc.DeltaRound = oc.DeltaProgress
+				oc.RMax = 0
+			},
+			"DeltaRound (8s) must be less than DeltaProgress (8s)",
+			2,
+			0,
+		},
+		{
+			"MaxDurations too long and S out of range",
+			func(oc *offchainConfig, cc *types.ContractConfig) {
+				oc.MaxDurationReport = 7 * time.Second
+				oc.S = []int{1, types.MaxOracles + 1, 2}
+			},
+			"sum of MaxDurationQuery/Observation/Report (8s) must be less than DeltaProgress (8s)",
+			2,
+			0,
+		},
+		{
+			"S out of range",
+			func(oc *offchainConfig, cc *types.ContractConfig) {
+				oc.S = []int{1, types.MaxOracles + 1, types.MaxOracles + 2}
+			},
+			"S[1] (32) must be between 0 and types.MaxOracles (31)",
+			2,
+			0,
+		},
+		{
+			"resource exhaustion",
+			func(oc *offchainConfig, cc *types.ContractConfig) {
+				oc.DeltaResend = 100 * time.Millisecond
+				oc.DeltaProgress = 150 * time.Millisecond
+				oc.DeltaRound = 100 * time.Millisecond
+				oc.MaxDurationObservation = 0
+				oc.MaxDurationReport = 0
+			},
+			"DeltaProgress (150ms) is set below the resource exhaustion safe interval (200ms)",
+			0,
+			2,
+		},
+	} {
+		cc := testContractConfig(4, test.modify)
+
+		_, err := PublicConfigFromContractConfig(false, cc)
+		if test.expectedErr == "" {
+			if err != nil {
+				t.Errorf("%s: unexpected error: %v", test.name, err)
+			}
+		} else if err == nil || err.Error() != test.expectedErr {
+			t.Errorf("%s: expected error %q, got %v", test.name, test.expectedErr, err)
+		}
+
+		_, err = PublicConfigFromContractConfig(true, cc)
+		if (err == nil) != (test.expectedErrsWithoutSkip == 0) {
+			t.Errorf("%s: unexpected result with skipResourceExhaustionChecks: %v", test.name, err)
+		}
+
+		_, errs, resourceExhaustionErrs, err := XXXPublicConfigAndErrorsFromContractConfig(cc)
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+		if len(errs) != test.expectedErrsWithoutSkip || len(resourceExhaustionErrs) != test.expectedResourceExhaustion {
+			t.Errorf("%s: expected %v errors and %v resource exhaustion errors, got %v and %v", test.name,
+				test.expectedErrsWithoutSkip, test.expectedResourceExhaustion, errs, resourceExhaustionErrs)
+		}
+		if len(errs) != 0 && errs[0].Error() != test.expectedErr {
+			t.Errorf("%s: expected first of all errors to be %q, got %q", test.name, test.expectedErr, errs[0])
+		}
+	}
+}
+
+func TestPublicConfigFromContractConfigMismatchedLengths(t *testing.T) {
+	cc := testContractConfig(4, func(oc *offchainConfig, cc *types.ContractConfig) {
+		oc.PeerIDs = oc.PeerIDs[:3]
+		oc.DeltaStage = -1
+	})
+	expectedErr := "peer ids list must have same length as onchain signers list: 3 ≠ 4"
+	if _, err := PublicConfigFromContractConfig(false, cc); err == nil || err.Error() != expectedErr {
+		t.Fatalf("expected error %q, got %v", expectedErr, err)
+	}
+	if _, _, _, err := XXXPublicConfigAndErrorsFromContractConfig(cc); err == nil || err.Error() != expectedErr {
+		t.Fatalf("expected error %q, got %v", expectedErr, err)
+	}
+}
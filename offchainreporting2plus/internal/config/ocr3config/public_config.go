@@ -139,11 +139,29 @@ func publicConfigFromContractConfig(skipResourceExhaustionChecks bool, change ty
 	}
 
 	// must check that all lists have the same length, or bad input could crash
-	// the following for loop.
+	// assemblePublicConfig.
 	if err := checkIdentityListsHaveTheSameLength(change, oc); err != nil {
 		return PublicConfig{}, config.SharedSecretEncryptions{}, err
 	}
 
+	cfg := assemblePublicConfig(change, oc)
+
+	if err := checkPublicConfigParameters(cfg); err != nil {
+		return PublicConfig{}, config.SharedSecretEncryptions{}, err
+	}
+
+	if !skipResourceExhaustionChecks {
+		if err := checkResourceExhaustion(cfg); err != nil {
+			return PublicConfig{}, config.SharedSecretEncryptions{}, err
+		}
+	}
+
+	return cfg, oc.SharedSecretEncryptions, nil
+}
+
+// assemblePublicConfig combines the contents of change and oc. The identity
+// lists must have the same length.
+func assemblePublicConfig(change types.ContractConfig, oc offchainConfig) PublicConfig {
 	identities := []config.OracleIdentity{}
 	for i := range change.Signers {
 		identities = append(identities, config.OracleIdentity{
@@ -154,7 +172,7 @@ func publicConfigFromContractConfig(skipResourceExhaustionChecks bool, change ty
 		})
 	}
 
-	cfg := PublicConfig{
+	return PublicConfig{
 		oc.DeltaProgress,
 		oc.DeltaResend,
 		oc.DeltaInitial,
@@ -175,26 +193,23 @@ func publicConfigFromContractConfig(skipResourceExhaustionChecks bool, change ty
 		change.OnchainConfig,
 		change.ConfigDigest,
 	}
+}
 
-	if err := checkPublicConfigParameters(cfg); err != nil {
-		return PublicConfig{}, config.SharedSecretEncryptions{}, err
-	}
-
-	if !skipResourceExhaustionChecks {
-		if err := checkResourceExhaustion(cfg); err != nil {
-			return PublicConfig{}, config.SharedSecretEncryptions{}, err
-		}
+func checkIdentityListsHaveNoDuplicates(change types.ContractConfig, oc offchainConfig) error {
+	if errs := identityListDuplicateErrors(change, oc); len(errs) != 0 {
+		return errs[0]
 	}
-
-	return cfg, oc.SharedSecretEncryptions, nil
+	return nil
 }
 
-func checkIdentityListsHaveNoDuplicates(change types.ContractConfig, oc offchainConfig) error {
+func identityListDuplicateErrors(change types.ContractConfig, oc offchainConfig) []error {
+	var errs []error
+
 	// inefficient, but it doesn't matter
 	for i := range change.Signers {
 		for j := range change.Signers {
-			if i != j && bytes.Equal(change.Signers[i], change.Signers[j]) {
-				return fmt.Errorf("%v-th and %v-th signer are identical: %x", i, j, change.Signers[i])
+			if i < j && bytes.Equal(change.Signers[i], change.Signers[j]) {
+				errs = append(errs, fmt.Errorf("%v-th and %v-th signer are identical: %x", i, j, change.Signers[i]))
 			}
 		}
 	}
@@ -203,7 +218,7 @@ func checkIdentityListsHaveNoDuplicates(change types.ContractConfig, oc offchain
 		uniquePeerIDs := map[string]struct{}{}
 		for _, peerID := range oc.PeerIDs {
 			if _, ok := uniquePeerIDs[peerID]; ok {
-				return fmt.Errorf("duplicate PeerID '%v'", peerID)
+				errs = append(errs, fmt.Errorf("duplicate PeerID '%v'", peerID))
 			}
 			uniquePeerIDs[peerID] = struct{}{}
 		}
@@ -213,7 +228,7 @@ func checkIdentityListsHaveNoDuplicates(change types.ContractConfig, oc offchain
 		uniqueOffchainPublicKeys := map[types.OffchainPublicKey]struct{}{}
 		for _, ocpk := range oc.OffchainPublicKeys {
 			if _, ok := uniqueOffchainPublicKeys[ocpk]; ok {
-				return fmt.Errorf("duplicate OffchainPublicKey %x", ocpk)
+				errs = append(errs, fmt.Errorf("duplicate OffchainPublicKey %x", ocpk))
 			}
 			uniqueOffchainPublicKeys[ocpk] = struct{}{}
 		}
@@ -225,7 +240,7 @@ func checkIdentityListsHaveNoDuplicates(change types.ContractConfig, oc offchain
 		uniqueTransmitters := map[types.Account]struct{}{}
 		for _, transmitter := range change.Transmitters {
 			if _, ok := uniqueTransmitters[transmitter]; ok {
-				return fmt.Errorf("duplicate transmitter '%v'", transmitter)
+				errs = append(errs, fmt.Errorf("duplicate transmitter '%v'", transmitter))
 			}
 			uniqueTransmitters[transmitter] = struct{}{}
 		}
@@ -233,7 +248,7 @@ func checkIdentityListsHaveNoDuplicates(change types.ContractConfig, oc offchain
 
 	// no point in checking SharedSecretEncryptions for uniqueness
 
-	return nil
+	return errs
 }
 
 func checkIdentityListsHaveTheSameLength(
@@ -264,75 +279,84 @@ func checkIdentityListsHaveTheSameLength(
 // (3) (some) simple mistakes
 
 func checkPublicConfigParameters(cfg PublicConfig) error {
+	if errs := publicConfigParameterErrors(cfg); len(errs) != 0 {
+		return errs[0]
+	}
+	return nil
+}
+
+func publicConfigParameterErrors(cfg PublicConfig) []error {
 	/////////////////////////////////////////////////////////////////
 	// Be sure to think about changes to other tooling that need to
 	// be made when you change this function!
 	/////////////////////////////////////////////////////////////////
 
+	var errs []error
+
 	if !(0 <= cfg.F && cfg.F*3 < cfg.N()) {
-		return fmt.Errorf("F (%v) must be non-negative and less than N/3 (N = %v)",
-			cfg.F, cfg.N())
+		errs = append(errs, fmt.Errorf("F (%v) must be non-negative and less than N/3 (N = %v)",
+			cfg.F, cfg.N()))
 	}
 
 	if !(cfg.N() <= types.MaxOracles) {
-		return fmt.Errorf("N (%v) must be less than or equal MaxOracles (%v)",
-			cfg.N(), types.MaxOracles)
+		errs = append(errs, fmt.Errorf("N (%v) must be less than or equal MaxOracles (%v)",
+			cfg.N(), types.MaxOracles))
 	}
 
 	if !(0 <= cfg.DeltaProgress) {
-		return fmt.Errorf("DeltaProgress (%v) must be non-negative", cfg.DeltaProgress)
+		errs = append(errs, fmt.Errorf("DeltaProgress (%v) must be non-negative", cfg.DeltaProgress))
 	}
 
 	if !(0 <= cfg.DeltaResend) {
-		return fmt.Errorf("DeltaResend (%v) must be non-negative", cfg.DeltaResend)
+		errs = append(errs, fmt.Errorf("DeltaResend (%v) must be non-negative", cfg.DeltaResend))
 	}
 
 	if !(0 <= cfg.DeltaInitial) {
-		return fmt.Errorf("DeltaInitial (%v) must be non-negative", cfg.DeltaInitial)
+		errs = append(errs, fmt.Errorf("DeltaInitial (%v) must be non-negative", cfg.DeltaInitial))
 	}
 
 	if !(0 <= cfg.DeltaRound) {
-		return fmt.Errorf("DeltaRound (%v) must be non-negative", cfg.DeltaRound)
+		errs = append(errs, fmt.Errorf("DeltaRound (%v) must be non-negative", cfg.DeltaRound))
 	}
 
 	if !(0 <= cfg.DeltaGrace) {
-		return fmt.Errorf("DeltaGrace (%v) must be non-negative",
-			cfg.DeltaGrace)
+		errs = append(errs, fmt.Errorf("DeltaGrace (%v) must be non-negative",
+			cfg.DeltaGrace))
 	}
 
 	if !(0 <= cfg.DeltaCertifiedCommitRequest) {
-		return fmt.Errorf("DeltaCertifiedCommitRequest (%v) must be non-negative", cfg.DeltaCertifiedCommitRequest)
+		errs = append(errs, fmt.Errorf("DeltaCertifiedCommitRequest (%v) must be non-negative", cfg.DeltaCertifiedCommitRequest))
 	}
 
 	if !(0 <= cfg.DeltaStage) {
-		return fmt.Errorf("DeltaStage (%v) must be non-negative", cfg.DeltaStage)
+		errs = append(errs, fmt.Errorf("DeltaStage (%v) must be non-negative", cfg.DeltaStage))
 	}
 
 	if !(0 <= cfg.MaxDurationQuery) {
-		return fmt.Errorf("MaxDurationQuery (%v) must be non-negative", cfg.MaxDurationQuery)
+		errs = append(errs, fmt.Errorf("MaxDurationQuery (%v) must be non-negative", cfg.MaxDurationQuery))
 	}
 
 	if !(0 <= cfg.MaxDurationObservation) {
-		return fmt.Errorf("MaxDurationObservation (%v) must be non-negative", cfg.MaxDurationObservation)
+		errs = append(errs, fmt.Errorf("MaxDurationObservation (%v) must be non-negative", cfg.MaxDurationObservation))
 	}
 
 	if !(0 <= cfg.MaxDurationShouldAcceptAttestedReport) {
-		return fmt.Errorf("MaxDurationShouldAcceptAttestedReport (%v) must be non-negative", cfg.MaxDurationShouldAcceptAttestedReport)
+		errs = append(errs, fmt.Errorf("MaxDurationShouldAcceptAttestedReport (%v) must be non-negative", cfg.MaxDurationShouldAcceptAttestedReport))
 	}
 
 	if !(0 <= cfg.MaxDurationShouldTransmitAcceptedReport) {
-		return fmt.Errorf("MaxDurationShouldTransmitAcceptedReport (%v) must be non-negative", cfg.MaxDurationShouldTransmitAcceptedReport)
+		errs = append(errs, fmt.Errorf("MaxDurationShouldTransmitAcceptedReport (%v) must be non-negative", cfg.MaxDurationShouldTransmitAcceptedReport))
 	}
 
 	if !(cfg.DeltaRound < cfg.DeltaProgress) {
-		return fmt.Errorf("DeltaRound (%v) must be less than DeltaProgress (%v)",
-			cfg.DeltaRound, cfg.DeltaProgress)
+		errs = append(errs, fmt.Errorf("DeltaRound (%v) must be less than DeltaProgress (%v)",
+			cfg.DeltaRound, cfg.DeltaProgress))
 	}
 
 	sumMaxDurationsOutcomeGeneration := cfg.MaxDurationQuery + cfg.MaxDurationObservation + cfg.DeltaGrace
 	if !(sumMaxDurationsOutcomeGeneration < cfg.DeltaProgress) {
-		return fmt.Errorf("sum of MaxDurationQuery/MaxDurationObservation/DeltaGrace (%v) must be less than DeltaProgress (%v)",
-			sumMaxDurationsOutcomeGeneration, cfg.DeltaProgress)
+		errs = append(errs, fmt.Errorf("sum of MaxDurationQuery/MaxDurationObservation/DeltaGrace (%v) must be less than DeltaProgress (%v)",
+			sumMaxDurationsOutcomeGeneration, cfg.DeltaProgress))
 	}
 
 	// We cannot easily add a similar check for the MaxDuration variables used
@@ -343,42 +367,51 @@ func checkPublicConfigParameters(cfg PublicConfig) error {
 	// MaxDurationShouldTransmitAcceptedReport < round duration.
 
 	if !(0 < cfg.RMax) {
-		return fmt.Errorf("RMax (%v) must be greater than zero", cfg.RMax)
+		errs = append(errs, fmt.Errorf("RMax (%v) must be greater than zero", cfg.RMax))
 	}
 
 	// This prevents possible overflows adding up the elements of S. We should never
 	// hit this.
 	if !(len(cfg.S) < 1000) {
-		return fmt.Errorf("len(S) (%v) must be less than 1000", len(cfg.S))
+		errs = append(errs, fmt.Errorf("len(S) (%v) must be less than 1000", len(cfg.S)))
 	}
 
 	for i, s := range cfg.S {
 		if !(0 <= s && s <= types.MaxOracles) {
-			return fmt.Errorf("S[%v] (%v) must be between 0 and types.MaxOracles (%v)", i, s, types.MaxOracles)
+			errs = append(errs, fmt.Errorf("S[%v] (%v) must be between 0 and types.MaxOracles (%v)", i, s, types.MaxOracles))
 		}
 	}
 
-	return nil
+	return errs
 }
 
 func checkResourceExhaustion(cfg PublicConfig) error {
+	if errs := resourceExhaustionErrors(cfg); len(errs) != 0 {
+		return errs[0]
+	}
+	return nil
+}
+
+func resourceExhaustionErrors(cfg PublicConfig) []error {
+	var errs []error
+
 	// Sending messages related to epoch changes and missing certified commits
 	// shouldn't be necessary in any realistic WAN deployment and could cause
 	// resource exhaustion
 	const safeInterval = 100 * time.Millisecond
 	if cfg.DeltaProgress < safeInterval {
-		return fmt.Errorf("DeltaProgress (%v) is set below the resource exhaustion safe interval (%v)", cfg.DeltaProgress, safeInterval)
+		errs = append(errs, fmt.Errorf("DeltaProgress (%v) is set below the resource exhaustion safe interval (%v)", cfg.DeltaProgress, safeInterval))
 	}
 	if cfg.DeltaResend < safeInterval {
-		return fmt.Errorf("DeltaResend (%v) is set below the resource exhaustion safe interval (%v)", cfg.DeltaResend, safeInterval)
+		errs = append(errs, fmt.Errorf("DeltaResend (%v) is set below the resource exhaustion safe interval (%v)", cfg.DeltaResend, safeInterval))
 	}
 	if cfg.DeltaInitial < safeInterval {
-		return fmt.Errorf("DeltaInitial (%v) is set below the resource exhaustion safe interval (%v)", cfg.DeltaInitial, safeInterval)
+		errs = append(errs, fmt.Errorf("DeltaInitial (%v) is set below the resource exhaustion safe interval (%v)", cfg.DeltaInitial, safeInterval))
 	}
 	if cfg.DeltaCertifiedCommitRequest < safeInterval {
-		return fmt.Errorf("DeltaCertifiedCommitRequest (%v) is set below the resource exhaustion safe interval (%v)", cfg.DeltaCertifiedCommitRequest, safeInterval)
+		errs = append(errs, fmt.Errorf("DeltaCertifiedCommitRequest (%v) is set below the resource exhaustion safe interval (%v)", cfg.DeltaCertifiedCommitRequest, safeInterval))
 	}
 	// We don't check DeltaGrace, DeltaRound, DeltaStage since none of them
 	// would exhaust the oracle's resources even if they are all set to 0.
-	return nil
+	return errs
 }
@@ -6,6 +6,7 @@ import (
 	cryptorand "crypto/rand"
 	"crypto/sha256"
 	"fmt"
+	"io"
 	"math"
 
 	"github.com/ethereum/go-ethereum/common"
@@ -116,6 +117,26 @@ func XXXContractSetConfigArgsFromSharedConfig(
 	offchainConfigVersion uint64,
 	offchainConfig_ []byte,
 	err error,
+) {
+	return XXXContractSetConfigArgsFromSharedConfigWithRand(c, sharedSecretEncryptionPublicKeys, cryptorand.Reader)
+}
+
+// XXXContractSetConfigArgsFromSharedConfigWithRand is like
+// XXXContractSetConfigArgsFromSharedConfig, but draws the ephemeral key for
+// encrypting the shared secret from rand. The output is fully determined by
+// the arguments, which allows for reproducible config generation.
+func XXXContractSetConfigArgsFromSharedConfigWithRand(
+	c SharedConfig,
+	sharedSecretEncryptionPublicKeys []types.ConfigEncryptionPublicKey,
+	rand io.Reader,
+) (
+	signers []types.OnchainPublicKey,
+	transmitters []types.Account,
+	f uint8,
+	onchainConfig []byte,
+	offchainConfigVersion uint64,
+	offchainConfig_ []byte,
+	err error,
 ) {
 	offChainPublicKeys := []types.OffchainPublicKey{}
 	peerIDs := []string{}
@@ -148,7 +169,7 @@ func XXXContractSetConfigArgsFromSharedConfig(
 		config.XXXEncryptSharedSecret(
 			sharedSecretEncryptionPublicKeys,
 			c.SharedSecret,
-			cryptorand.Reader,
+			rand,
 		),
 	}).serialize()
 	err = nil
@@ -0,0 +1,37 @@
+package ocr3config
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+
+	"github.com/smartcontractkit/libocr/permutation"
+)
+
+// TransmissionPermutation returns the permutation of oracles that determines
+// the order in which they transmit the report with the given seqNr and index.
+// Oracle i transmits in the stage given by
+// TransmissionStage(S, TransmissionPermutation(...)[i]).
+func TransmissionPermutation(transmissionOrderKey [16]byte, n int, seqNr uint64, index int) []int {
+	mac := hmac.New(sha256.New, transmissionOrderKey[:])
+	_ = binary.Write(mac, binary.BigEndian, seqNr)
+	_ = binary.Write(mac, binary.BigEndian, uint64(index))
+
+	var key [16]byte
+	_ = copy(key[:], mac.Sum(nil))
+	return permutation.Permutation(n, key)
+}
+
+// TransmissionStage returns the stage of the transmission schedule s in which
+// the oracle at the given position of the transmission permutation transmits.
+// Returns false if the oracle never transmits.
+func TransmissionStage(s []int, position int) (int, bool) {
+	sum := 0
+	for i, stageSize := range s {
+		sum += stageSize
+		if position < sum {
+			return i, true
+		}
+	}
+	return 0, false
+}
@@ -25,7 +25,7 @@ func (d prefixCheckConfigDigester) ConfigDigest(cc types.ContractConfig) (types.
 	}
 
 	if !prefix.IsPrefixOf(cd) {
-		return types.ConfigDigest{}, fmt.Errorf("ConfigDigest has prefix %s, but wanted prefix %s", types.ConfigDigestPrefixFromConfigDigest(cd), prefix)
+		return types.ConfigDigest{}, fmt.Errorf("ConfigDigest has prefix %s, but wanted prefix %s", types.ConfigDigestPrefixFromConfigDigest(cd).Describe(), prefix.Describe())
 	}
 
 	return cd, nil
@@ -40,6 +40,11 @@ func (d prefixCheckConfigDigester) CheckContractConfig(cc types.ContractConfig)
 	}
 
 	if goodConfigDigest != cc.ConfigDigest {
+		goodPrefix := types.ConfigDigestPrefixFromConfigDigest(goodConfigDigest)
+		if prefix := types.ConfigDigestPrefixFromConfigDigest(cc.ConfigDigest); prefix != goodPrefix {
+			return fmt.Errorf("ConfigDigest mismatch. Expected %s but got %s. The contract uses prefix %s, but the OffchainConfigDigester uses prefix %s; is the digester right for this contract?",
+				goodConfigDigest, cc.ConfigDigest, prefix.Describe(), goodPrefix.Describe())
+		}
 		return fmt.Errorf("ConfigDigest mismatch. Expected %s but got %s", goodConfigDigest, cc.ConfigDigest)
 	}
 
@@ -3,6 +3,7 @@ package managed
 import (
 	"context"
 
+	"github.com/smartcontractkit/libocr/clock"
 	"github.com/smartcontractkit/libocr/commontypes"
 	"github.com/smartcontractkit/libocr/internal/loghelper"
 	"github.com/smartcontractkit/libocr/offchainreporting2plus/internal/config/netconfig"
@@ -25,6 +26,7 @@ func RunManagedBootstrapper(
 	runWithContractConfig(
 		ctx,
 
+		clock.Real(),
 		contractConfigTracker,
 		database,
 		func(ctx context.Context, contractConfig types.ContractConfig, logger loghelper.LoggerWithContext) {
@@ -5,6 +5,7 @@ import (
 	"fmt"
 
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/smartcontractkit/libocr/clock"
 	"github.com/smartcontractkit/libocr/commontypes"
 	"github.com/smartcontractkit/libocr/internal/loghelper"
 	"github.com/smartcontractkit/libocr/internal/metricshelper"
@@ -28,6 +29,7 @@ func RunManagedMercuryOracle(
 	ctx context.Context,
 
 	v2bootstrappers []commontypes.BootstrapperLocator,
+	clock clock.Clock,
 	configTracker types.ContractConfigTracker,
 	contractTransmitter types.ContractTransmitter,
 	database ocr3types.Database,
@@ -58,6 +60,7 @@ func RunManagedMercuryOracle(
 	runWithContractConfig(
 		ctx,
 
+		clock,
 		configTracker,
 		database,
 		func(ctx context.Context, contractConfig types.ContractConfig, logger loghelper.LoggerWithContext) {
@@ -212,6 +215,7 @@ func RunManagedMercuryOracle(
 
 			protocol.RunOracle[mercuryshim.MercuryReportInfo](
 				ctx,
+				clock,
 				sharedConfig,
 				mercuryshim.NewMercuryOCR3ContractTransmitter(contractTransmitter),
 				&shim.SerializingOCR3Database{database},
@@ -5,6 +5,7 @@ import (
 	"fmt"
 
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/smartcontractkit/libocr/clock"
 	"github.com/smartcontractkit/libocr/commontypes"
 	"github.com/smartcontractkit/libocr/internal/loghelper"
 	"github.com/smartcontractkit/libocr/internal/metricshelper"
@@ -27,6 +28,7 @@ func RunManagedOCR3Oracle[RI any](
 	ctx context.Context,
 
 	v2bootstrappers []commontypes.BootstrapperLocator,
+	clock clock.Clock,
 	configTracker types.ContractConfigTracker,
 	contractTransmitter ocr3types.ContractTransmitter[RI],
 	database ocr3types.Database,
@@ -57,6 +59,7 @@ func RunManagedOCR3Oracle[RI any](
 	runWithContractConfig(
 		ctx,
 
+		clock,
 		configTracker,
 		database,
 		func(ctx context.Context, contractConfig types.ContractConfig, logger loghelper.LoggerWithContext) {
@@ -193,6 +196,7 @@ func RunManagedOCR3Oracle[RI any](
 
 			protocol.RunOracle[RI](
 				ctx,
+				clock,
 				sharedConfig,
 				contractTransmitter,
 				&shim.SerializingOCR3Database{database},
@@ -3,6 +3,7 @@ package managed
 import (
 	"context"
 
+	"github.com/smartcontractkit/libocr/clock"
 	"github.com/smartcontractkit/libocr/commontypes"
 	"github.com/smartcontractkit/libocr/internal/loghelper"
 	"github.com/smartcontractkit/libocr/offchainreporting2plus/types"
@@ -15,6 +16,7 @@ import (
 func runWithContractConfig(
 	ctx context.Context,
 
+	clock clock.Clock,
 	contractConfigTracker types.ContractConfigTracker,
 	database types.ConfigDatabase,
 	fn func(context.Context, types.ContractConfig, loghelper.LoggerWithContext),
@@ -25,6 +27,7 @@ func runWithContractConfig(
 	rwcc := runWithContractConfigState{
 		ctx,
 
+		clock,
 		types.ConfigDigest{},
 		contractConfigTracker,
 		database,
@@ -43,6 +46,7 @@ func runWithContractConfig(
 type runWithContractConfigState struct {
 	ctx context.Context
 
+	clock                 clock.Clock
 	configDigest          types.ConfigDigest
 	contractConfigTracker types.ContractConfigTracker
 	database              types.ConfigDatabase
@@ -64,7 +68,7 @@ func (rwcc *runWithContractConfigState) run() {
 	// Only start tracking config after we attempted to load config from db
 	chNewConfig := make(chan types.ContractConfig, 5)
 	rwcc.otherSubs.Go(func() {
-		TrackConfig(rwcc.ctx, rwcc.configDigester, rwcc.contractConfigTracker, rwcc.configDigest, rwcc.localConfig, rwcc.logger, chNewConfig)
+		TrackConfig(rwcc.ctx, rwcc.clock, rwcc.configDigester, rwcc.contractConfigTracker, rwcc.configDigest, rwcc.localConfig, rwcc.logger, chNewConfig)
 	})
 
 	for {
@@ -4,6 +4,7 @@ import (
 	"context"
 	"time"
 
+	"github.com/smartcontractkit/libocr/clock"
 	"github.com/smartcontractkit/libocr/commontypes"
 	"github.com/smartcontractkit/libocr/internal/loghelper"
 	"github.com/smartcontractkit/libocr/offchainreporting2plus/types"
@@ -13,6 +14,7 @@ import (
 type trackConfigState struct {
 	ctx context.Context
 	// in
+	clock          clock.Clock
 	configDigester prefixCheckConfigDigester
 	configTracker  types.ContractConfigTracker
 	localConfig    types.LocalConfig
@@ -26,7 +28,7 @@ type trackConfigState struct {
 
 func (state *trackConfigState) run() {
 	// Check immediately after startup
-	tCheckLatestConfigDetails := time.After(0)
+	tCheckLatestConfigDetails := state.clock.After(0)
 
 	chNotify := state.configTracker.Notify()
 
@@ -35,7 +37,7 @@ func (state *trackConfigState) run() {
 		case _, ok := <-chNotify:
 			if ok {
 				// Check immediately for new config
-				tCheckLatestConfigDetails = time.After(0 * time.Second)
+				tCheckLatestConfigDetails = state.clock.After(0 * time.Second)
 				state.logger.Info("TrackConfig: ContractConfigTracker.Notify() fired", nil)
 			} else {
 				chNotify = nil
@@ -51,12 +53,12 @@ func (state *trackConfigState) run() {
 				if state.localConfig.ContractConfigTrackerPollInterval < wait {
 					wait = state.localConfig.ContractConfigTrackerPollInterval
 				}
-				tCheckLatestConfigDetails = time.After(wait)
+				tCheckLatestConfigDetails = state.clock.After(wait)
 				state.logger.Info("TrackConfig: awaiting confirmation of new config", commontypes.LogFields{
 					"wait": wait,
 				})
 			} else {
-				tCheckLatestConfigDetails = time.After(state.localConfig.ContractConfigTrackerPollInterval)
+				tCheckLatestConfigDetails = state.clock.After(state.localConfig.ContractConfigTrackerPollInterval)
 			}
 
 			if change != nil {
@@ -154,6 +156,7 @@ func (state *trackConfigState) checkLatestConfigDetails() (
 func TrackConfig(
 	ctx context.Context,
 
+	clock clock.Clock,
 	configDigester prefixCheckConfigDigester,
 	configTracker types.ContractConfigTracker,
 	initialConfigDigest types.ConfigDigest,
@@ -165,6 +168,7 @@ func TrackConfig(
 	state := trackConfigState{
 		ctx,
 		// in
+		clock,
 		configDigester,
 		configTracker,
 		localConfig,
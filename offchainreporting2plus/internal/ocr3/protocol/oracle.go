@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/smartcontractkit/libocr/clock"
 	"github.com/smartcontractkit/libocr/commontypes"
 	"github.com/smartcontractkit/libocr/internal/loghelper"
 	"github.com/smartcontractkit/libocr/offchainreporting2plus/internal/config/ocr3config"
@@ -22,6 +23,7 @@ import (
 func RunOracle[RI any](
 	ctx context.Context,
 
+	clock clock.Clock,
 	config ocr3config.SharedConfig,
 	contractTransmitter ocr3types.ContractTransmitter[RI],
 	database Database,
@@ -38,6 +40,7 @@ func RunOracle[RI any](
 	o := oracleState[RI]{
 		ctx: ctx,
 
+		clock:               clock,
 		config:              config,
 		contractTransmitter: contractTransmitter,
 		database:            database,
@@ -57,6 +60,7 @@ func RunOracle[RI any](
 type oracleState[RI any] struct {
 	ctx context.Context
 
+	clock               clock.Clock
 	config              ocr3config.SharedConfig
 	contractTransmitter ocr3types.ContractTransmitter[RI]
 	database            Database
@@ -168,6 +172,7 @@ func (o *oracleState[RI]) run() {
 			chNetToPacemaker,
 			chPacemakerToOutcomeGeneration,
 			chOutcomeGenerationToPacemaker,
+			o.clock,
 			o.config,
 			o.database,
 			o.id,
@@ -189,6 +194,7 @@ func (o *oracleState[RI]) run() {
 			chPacemakerToOutcomeGeneration,
 			chOutcomeGenerationToPacemaker,
 			chOutcomeGenerationToReportAttestation,
+			o.clock,
 			o.config,
 			o.database,
 			o.id,
@@ -211,6 +217,7 @@ func (o *oracleState[RI]) run() {
 			chNetToReportAttestation,
 			chOutcomeGenerationToReportAttestation,
 			chReportAttestationToTransmission,
+			o.clock,
 			o.config,
 			o.contractTransmitter,
 			o.logger,
@@ -225,6 +232,7 @@ func (o *oracleState[RI]) run() {
 			&o.subprocesses,
 
 			chReportAttestationToTransmission,
+			o.clock,
 			o.config,
 			o.contractTransmitter,
 			o.id,
@@ -267,7 +275,7 @@ func (o *oracleState[RI]) run() {
 	}
 }
 
-func tryUntilSuccess[T any](ctx context.Context, logger commontypes.Logger, retryPeriod time.Duration, fnTimeout time.Duration, fnName string, fn func(context.Context) (T, error)) (T, error) {
+func tryUntilSuccess[T any](ctx context.Context, clock clock.Clock, logger commontypes.Logger, retryPeriod time.Duration, fnTimeout time.Duration, fnName string, fn func(context.Context) (T, error)) (T, error) {
 	for {
 		var result T
 		var err error
@@ -285,7 +293,7 @@ func tryUntilSuccess[T any](ctx context.Context, logger commontypes.Logger, retr
 		})
 
 		select {
-		case <-time.After(retryPeriod):
+		case <-clock.After(retryPeriod):
 		case <-ctx.Done():
 			var zero T
 			return zero, ctx.Err()
@@ -298,6 +306,7 @@ func (o *oracleState[RI]) restoreFromDatabase() (PacemakerState, CertifiedPrepar
 
 	paceState, err := tryUntilSuccess[PacemakerState](
 		o.ctx,
+		o.clock,
 		o.logger,
 		retryPeriod,
 		o.localConfig.DatabaseTimeout,
@@ -316,6 +325,7 @@ func (o *oracleState[RI]) restoreFromDatabase() (PacemakerState, CertifiedPrepar
 
 	cert, err := tryUntilSuccess[CertifiedPrepareOrCommit](
 		o.ctx,
+		o.clock,
 		o.logger,
 		retryPeriod,
 		o.localConfig.DatabaseTimeout,
@@ -6,6 +6,7 @@ import (
 
 	"github.com/prometheus/client_golang/prometheus"
 
+	"github.com/smartcontractkit/libocr/clock"
 	"github.com/smartcontractkit/libocr/commontypes"
 	"github.com/smartcontractkit/libocr/internal/loghelper"
 	"github.com/smartcontractkit/libocr/offchainreporting2plus/internal/config/ocr3config"
@@ -30,6 +31,7 @@ func RunOutcomeGeneration[RI any](
 	chPacemakerToOutcomeGeneration <-chan EventToOutcomeGeneration[RI],
 	chOutcomeGenerationToPacemaker chan<- EventToPacemaker[RI],
 	chOutcomeGenerationToReportAttestation chan<- EventToReportAttestation[RI],
+	clock clock.Clock,
 	config ocr3config.SharedConfig,
 	database Database,
 	id commontypes.OracleID,
@@ -50,6 +52,7 @@ func RunOutcomeGeneration[RI any](
 		chPacemakerToOutcomeGeneration:         chPacemakerToOutcomeGeneration,
 		chOutcomeGenerationToPacemaker:         chOutcomeGenerationToPacemaker,
 		chOutcomeGenerationToReportAttestation: chOutcomeGenerationToReportAttestation,
+		clock:                                  clock,
 		config:                                 config,
 		database:                               database,
 		id:                                     id,
@@ -71,6 +74,7 @@ type outcomeGenerationState[RI any] struct {
 	chPacemakerToOutcomeGeneration         <-chan EventToOutcomeGeneration[RI]
 	chOutcomeGenerationToPacemaker         chan<- EventToPacemaker[RI]
 	chOutcomeGenerationToReportAttestation chan<- EventToReportAttestation[RI]
+	clock                                  clock.Clock
 	config                                 ocr3config.SharedConfig
 	database                               Database
 	id                                     commontypes.OracleID
@@ -288,7 +292,7 @@ func (outgen *outcomeGenerationState[RI]) eventNewEpochStart(ev EventNewEpochSta
 	outgen.sharedState.seqNr = 0
 
 	outgen.followerState.phase = outgenFollowerPhaseNewEpoch
-	outgen.followerState.tInitial = time.After(outgen.config.DeltaInitial)
+	outgen.followerState.tInitial = outgen.clock.After(outgen.config.DeltaInitial)
 	outgen.followerState.outcome = outcomeAndDigests{}
 
 	outgen.followerState.roundStartPool = pool.NewPool[MessageRoundStart[RI]](poolSize)
@@ -328,7 +332,7 @@ func (outgen *outcomeGenerationState[RI]) eventNewEpochStart(ev EventNewEpochSta
 	}, outgen.sharedState.l)
 
 	if outgen.id == outgen.sharedState.l {
-		outgen.leaderState.tRound = time.After(outgen.config.DeltaRound)
+		outgen.leaderState.tRound = outgen.clock.After(outgen.config.DeltaRound)
 	}
 
 	outgen.unbufferMessages()
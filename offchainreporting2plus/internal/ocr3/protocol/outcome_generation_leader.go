@@ -2,7 +2,6 @@ package protocol
 
 import (
 	"context"
-	"time"
 
 	"github.com/smartcontractkit/libocr/commontypes"
 	"github.com/smartcontractkit/libocr/offchainreporting2plus/ocr3types"
@@ -211,7 +210,7 @@ func (outgen *outcomeGenerationState[RI]) startSubsequentLeaderRound() {
 
 	outgen.leaderState.observations = map[commontypes.OracleID]*SignedObservation{}
 
-	outgen.leaderState.tRound = time.After(outgen.config.DeltaRound)
+	outgen.leaderState.tRound = outgen.clock.After(outgen.config.DeltaRound)
 
 	outgen.leaderState.phase = outgenLeaderPhaseSentRoundStart
 	outgen.logger.Debug("broadcasting MessageRoundStart", commontypes.LogFields{
@@ -327,7 +326,7 @@ func (outgen *outcomeGenerationState[RI]) messageObservation(msg MessageObservat
 			"observationQuorum": quorum,
 		})
 		outgen.leaderState.phase = outgenLeaderPhaseGrace
-		outgen.leaderState.tGrace = time.After(outgen.config.DeltaGrace)
+		outgen.leaderState.tGrace = outgen.clock.After(outgen.config.DeltaGrace)
 	}
 }
 
@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/smartcontractkit/libocr/clock"
 	"github.com/smartcontractkit/libocr/commontypes"
 	"github.com/smartcontractkit/libocr/internal/loghelper"
 	"github.com/smartcontractkit/libocr/offchainreporting2plus/internal/config/ocr3config"
@@ -23,6 +24,7 @@ func RunPacemaker[RI any](
 	chNetToPacemaker <-chan MessageToPacemakerWithSender[RI],
 	chPacemakerToOutcomeGeneration chan<- EventToOutcomeGeneration[RI],
 	chOutcomeGenerationToPacemaker <-chan EventToPacemaker[RI],
+	clock clock.Clock,
 	config ocr3config.SharedConfig,
 	database Database,
 	id commontypes.OracleID,
@@ -38,7 +40,7 @@ func RunPacemaker[RI any](
 	pace := makePacemakerState[RI](
 		ctx, chNetToPacemaker,
 		chPacemakerToOutcomeGeneration, chOutcomeGenerationToPacemaker,
-		config, database,
+		clock, config, database,
 		id, localConfig, logger, metricsRegisterer, netSender, offchainKeyring,
 		telemetrySender,
 	)
@@ -50,6 +52,7 @@ func makePacemakerState[RI any](
 	chNetToPacemaker <-chan MessageToPacemakerWithSender[RI],
 	chPacemakerToOutcomeGeneration chan<- EventToOutcomeGeneration[RI],
 	chOutcomeGenerationToPacemaker <-chan EventToPacemaker[RI],
+	clock clock.Clock,
 	config ocr3config.SharedConfig,
 	database Database, id commontypes.OracleID,
 	localConfig types.LocalConfig,
@@ -65,6 +68,7 @@ func makePacemakerState[RI any](
 		chNetToPacemaker:               chNetToPacemaker,
 		chPacemakerToOutcomeGeneration: chPacemakerToOutcomeGeneration,
 		chOutcomeGenerationToPacemaker: chOutcomeGenerationToPacemaker,
+		clock:                          clock,
 		config:                         config,
 		database:                       database,
 		id:                             id,
@@ -85,6 +89,7 @@ type pacemakerState[RI any] struct {
 	chNetToPacemaker               <-chan MessageToPacemakerWithSender[RI]
 	chPacemakerToOutcomeGeneration chan<- EventToOutcomeGeneration[RI]
 	chOutcomeGenerationToPacemaker <-chan EventToPacemaker[RI]
+	clock                          clock.Clock
 	config                         ocr3config.SharedConfig
 	database                       Database
 	id                             commontypes.OracleID
@@ -139,7 +144,7 @@ func (pace *pacemakerState[RI]) run(restoredState PacemakerState) {
 	}
 	pace.l = Leader(pace.e, pace.config.N(), pace.config.LeaderSelectionKey())
 
-	pace.tProgress = time.After(pace.config.DeltaProgress)
+	pace.tProgress = pace.clock.After(pace.config.DeltaProgress)
 
 	pace.sendNewEpochWish()
 
@@ -189,12 +194,12 @@ func (pace *pacemakerState[RI]) run(restoredState PacemakerState) {
 }
 
 func (pace *pacemakerState[RI]) eventProgress() {
-	pace.tProgress = time.After(pace.config.DeltaProgress)
+	pace.tProgress = pace.clock.After(pace.config.DeltaProgress)
 }
 
 func (pace *pacemakerState[RI]) sendNewEpochWish() {
 	pace.netSender.Broadcast(MessageNewEpochWish[RI]{pace.ne})
-	pace.tResend = time.After(pace.config.DeltaResend)
+	pace.tResend = pace.clock.After(pace.config.DeltaResend)
 }
 
 func (pace *pacemakerState[RI]) eventTResendTimeout() {
@@ -309,7 +314,7 @@ func (pace *pacemakerState[RI]) messageNewEpochWish(msg MessageNewEpochWish[RI],
 		}
 		pace.metrics.epoch.Set(float64(pace.e))
 		pace.metrics.leader.Set(float64(pace.l))
-		pace.tProgress = time.After(pace.config.DeltaProgress) // restart timer T_{progress}
+		pace.tProgress = pace.clock.After(pace.config.DeltaProgress) // restart timer T_{progress}
 
 		pace.notifyOutcomeGenerationOfNewEpoch = true // invoke event newEpochStart(e, l)
 	}
@@ -10,6 +10,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/smartcontractkit/libocr/clock"
 	"github.com/smartcontractkit/libocr/commontypes"
 	"github.com/smartcontractkit/libocr/internal/loghelper"
 	"github.com/smartcontractkit/libocr/offchainreporting2plus/internal/config/ocr3config"
@@ -24,6 +25,7 @@ func RunReportAttestation[RI any](
 	chNetToReportAttestation <-chan MessageToReportAttestationWithSender[RI],
 	chOutcomeGenerationToReportAttestation <-chan EventToReportAttestation[RI],
 	chReportAttestationToTransmission chan<- EventToTransmission[RI],
+	clock clock.Clock,
 	config ocr3config.SharedConfig,
 	contractTransmitter ocr3types.ContractTransmitter[RI],
 	logger loghelper.LoggerWithContext,
@@ -31,7 +33,7 @@ func RunReportAttestation[RI any](
 	onchainKeyring ocr3types.OnchainKeyring[RI],
 	reportingPlugin ocr3types.ReportingPlugin[RI],
 ) {
-	sched := scheduler.NewScheduler[EventMissingOutcome[RI]]()
+	sched := scheduler.NewScheduler[EventMissingOutcome[RI]](clock)
 	defer sched.Close()
 
 	newReportAttestationState(ctx, chNetToReportAttestation,
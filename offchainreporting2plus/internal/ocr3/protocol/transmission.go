@@ -2,18 +2,15 @@ package protocol
 
 import (
 	"context"
-	"crypto/hmac"
-	"crypto/sha256"
-	"encoding/binary"
 	"time"
 
+	"github.com/smartcontractkit/libocr/clock"
 	"github.com/smartcontractkit/libocr/commontypes"
 	"github.com/smartcontractkit/libocr/internal/loghelper"
 	"github.com/smartcontractkit/libocr/offchainreporting2plus/internal/config/ocr3config"
 	"github.com/smartcontractkit/libocr/offchainreporting2plus/internal/ocr3/scheduler"
 	"github.com/smartcontractkit/libocr/offchainreporting2plus/ocr3types"
 	"github.com/smartcontractkit/libocr/offchainreporting2plus/types"
-	"github.com/smartcontractkit/libocr/permutation"
 	"github.com/smartcontractkit/libocr/subprocesses"
 )
 
@@ -24,6 +21,7 @@ func RunTransmission[RI any](
 	subprocesses *subprocesses.Subprocesses,
 
 	chReportAttestationToTransmission <-chan EventToTransmission[RI],
+	clock clock.Clock,
 	config ocr3config.SharedConfig,
 	contractTransmitter ocr3types.ContractTransmitter[RI],
 	id commontypes.OracleID,
@@ -31,7 +29,7 @@ func RunTransmission[RI any](
 	logger loghelper.LoggerWithContext,
 	reportingPlugin ocr3types.ReportingPlugin[RI],
 ) {
-	sched := scheduler.NewScheduler[EventAttestedReport[RI]]()
+	sched := scheduler.NewScheduler[EventAttestedReport[RI]](clock)
 	defer sched.Close()
 
 	t := transmissionState[RI]{
@@ -39,6 +37,7 @@ func RunTransmission[RI any](
 		subprocesses,
 
 		chReportAttestationToTransmission,
+		clock,
 		config,
 		contractTransmitter,
 		id,
@@ -57,6 +56,7 @@ type transmissionState[RI any] struct {
 	subprocesses *subprocesses.Subprocesses
 
 	chReportAttestationToTransmission <-chan EventToTransmission[RI]
+	clock                             clock.Clock
 	config                            ocr3config.SharedConfig
 	contractTransmitter               ocr3types.ContractTransmitter[RI]
 	id                                commontypes.OracleID
@@ -92,7 +92,7 @@ func (t *transmissionState[RI]) run() {
 }
 
 func (t *transmissionState[RI]) eventAttestedReport(ev EventAttestedReport[RI]) {
-	now := time.Now()
+	now := t.clock.Now()
 
 	shouldAccept, ok := callPlugin[bool](
 		t.ctx,
@@ -218,22 +218,11 @@ func (t *transmissionState[RI]) scheduled(ev EventAttestedReport[RI]) {
 }
 
 func (t *transmissionState[RI]) transmitDelay(seqNr uint64, index int) *time.Duration {
-	transmissionOrderKey := t.config.TransmissionOrderKey()
-	mac := hmac.New(sha256.New, transmissionOrderKey[:])
-	_ = binary.Write(mac, binary.BigEndian, seqNr)
-	_ = binary.Write(mac, binary.BigEndian, uint64(index))
-
-	var key [16]byte
-	_ = copy(key[:], mac.Sum(nil))
-	pi := permutation.Permutation(t.config.N(), key)
-
-	sum := 0
-	for i, s := range t.config.S {
-		sum += s
-		if pi[t.id] < sum {
-			result := time.Duration(i) * t.config.DeltaStage
-			return &result
-		}
+	pi := ocr3config.TransmissionPermutation(t.config.TransmissionOrderKey(), t.config.N(), seqNr, index)
+	stage, ok := ocr3config.TransmissionStage(t.config.S, pi[t.id])
+	if !ok {
+		return nil
 	}
-	return nil
+	result := time.Duration(stage) * t.config.DeltaStage
+	return &result
 }
@@ -4,6 +4,7 @@ import (
 	"context"
 	"time"
 
+	"github.com/smartcontractkit/libocr/clock"
 	"github.com/smartcontractkit/libocr/offchainreporting2plus/internal/ocr3/minheap"
 	"github.com/smartcontractkit/libocr/subprocesses"
 )
@@ -14,6 +15,7 @@ type itemWithDeadline[T any] struct {
 }
 
 type Scheduler[T any] struct {
+	clock  clock.Clock
 	subs   subprocesses.Subprocesses
 	ctx    context.Context
 	cancel context.CancelFunc
@@ -22,13 +24,14 @@ type Scheduler[T any] struct {
 	out <-chan T
 }
 
-func NewScheduler[T any]() *Scheduler[T] {
+func NewScheduler[T any](clk clock.Clock) *Scheduler[T] {
 	ctx, cancel := context.WithCancel(context.Background())
 
 	in := make(chan itemWithDeadline[T])
 	out := make(chan T)
 
 	scheduler := &Scheduler[T]{
+		clk,
 		subprocesses.Subprocesses{},
 		ctx,
 		cancel,
@@ -39,9 +42,9 @@ func NewScheduler[T any]() *Scheduler[T] {
 
 	scheduler.subs.Go(func() {
 		// create an expired timer
-		timer := time.NewTimer(0)
+		timer := clk.NewTimer(0)
 		defer timer.Stop()
-		<-timer.C
+		<-timer.C()
 
 		heap := minheap.NewMinHeap(func(a, b itemWithDeadline[T]) bool {
 			return a.Deadline.Before(b.Deadline)
@@ -56,24 +59,24 @@ func NewScheduler[T any]() *Scheduler[T] {
 				if maybeOut == nil {
 					if heap.Len() == 0 {
 						// the timer must be stopped already
-						timer.Reset(time.Until(item.Deadline))
+						timer.Reset(clock.Until(clk, item.Deadline))
 					} else if heap.Peek().Deadline.After(item.Deadline) {
 						// we're dealing with the new minimum
 						if timer.Stop() {
 							// timer hasn't fired yet
-							timer.Reset(time.Until(item.Deadline))
+							timer.Reset(clock.Until(clk, item.Deadline))
 						} // else: timer has fired. no need to do anything since
 						//   we will handle <-timer.C in an upcoming loop iteration
 					}
 				}
 				heap.Push(item)
-			case <-timer.C:
+			case <-timer.C():
 				pendingItem = heap.Pop().Item
 				maybeOut = out
 			case maybeOut <- pendingItem:
 				maybeOut = nil
 				if heap.Len() != 0 {
-					timer.Reset(time.Until(heap.Peek().Deadline))
+					timer.Reset(clock.Until(clk, heap.Peek().Deadline))
 				}
 			case <-ctx.Done():
 				return
@@ -92,7 +95,7 @@ func (s *Scheduler[T]) ScheduleDeadline(item T, deadline time.Time) {
 }
 
 func (s *Scheduler[T]) ScheduleDelay(item T, delay time.Duration) {
-	s.ScheduleDeadline(item, time.Now().Add(delay))
+	s.ScheduleDeadline(item, s.clock.Now().Add(delay))
 }
 
 func (s *Scheduler[T]) Scheduled() <-chan T {
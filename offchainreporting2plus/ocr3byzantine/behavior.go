@@ -0,0 +1,269 @@
+package ocr3byzantine
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/smartcontractkit/libocr/commontypes"
+	"github.com/smartcontractkit/libocr/offchainreporting2plus/internal/ocr3/protocol"
+)
+
+// MessageKind identifies the type of an OCR3 protocol message.
+type MessageKind int
+
+const (
+	_ MessageKind = iota
+	MessageKindNewEpochWish
+	MessageKindEpochStartRequest
+	MessageKindEpochStart
+	MessageKindRoundStart
+	MessageKindObservation
+	MessageKindProposal
+	MessageKindPrepare
+	MessageKindCommit
+	MessageKindReportSignatures
+	MessageKindCertifiedCommitRequest
+	MessageKindCertifiedCommit
+)
+
+func (k MessageKind) String() string {
+	switch k {
+	case MessageKindNewEpochWish:
+		return "NewEpochWish"
+	case MessageKindEpochStartRequest:
+		return "EpochStartRequest"
+	case MessageKindEpochStart:
+		return "EpochStart"
+	case MessageKindRoundStart:
+		return "RoundStart"
+	case MessageKindObservation:
+		return "Observation"
+	case MessageKindProposal:
+		return "Proposal"
+	case MessageKindPrepare:
+		return "Prepare"
+	case MessageKindCommit:
+		return "Commit"
+	case MessageKindReportSignatures:
+		return "ReportSignatures"
+	case MessageKindCertifiedCommitRequest:
+		return "CertifiedCommitRequest"
+	case MessageKindCertifiedCommit:
+		return "CertifiedCommit"
+	}
+	return "Unknown"
+}
+
+func kindOf(msg message) MessageKind {
+	switch msg.(type) {
+	case protocol.MessageNewEpochWish[wireRI]:
+		return MessageKindNewEpochWish
+	case protocol.MessageEpochStartRequest[wireRI]:
+		return MessageKindEpochStartRequest
+	case protocol.MessageEpochStart[wireRI]:
+		return MessageKindEpochStart
+	case protocol.MessageRoundStart[wireRI]:
+		return MessageKindRoundStart
+	case protocol.MessageObservation[wireRI]:
+		return MessageKindObservation
+	case protocol.MessageProposal[wireRI]:
+		return MessageKindProposal
+	case protocol.MessagePrepare[wireRI]:
+		return MessageKindPrepare
+	case protocol.MessageCommit[wireRI]:
+		return MessageKindCommit
+	case protocol.MessageReportSignatures[wireRI]:
+		return MessageKindReportSignatures
+	case protocol.MessageCertifiedCommitRequest[wireRI]:
+		return MessageKindCertifiedCommitRequest
+	case protocol.MessageCertifiedCommit[wireRI]:
+		return MessageKindCertifiedCommit
+	}
+	return 0
+}
+
+// Behavior describes a way in which an oracle misbehaves. Behaviors are
+// created with the functions in this package and passed to
+// NewEndpointFactory.
+type Behavior interface {
+	newInterceptor(env Environment) interceptor
+}
+
+// interceptor is the per-endpoint instance of a Behavior. intercept returns
+// the messages that should be sent to "to" instead of msg. It must be
+// thread-safe.
+type interceptor interface {
+	intercept(msg message, to commontypes.OracleID) []message
+}
+
+type interceptorFunc func(msg message, to commontypes.OracleID) []message
+
+func (f interceptorFunc) intercept(msg message, to commontypes.OracleID) []message {
+	return f(msg, to)
+}
+
+type behaviorFunc func(env Environment) interceptor
+
+func (f behaviorFunc) newInterceptor(env Environment) interceptor {
+	return f(env)
+}
+
+// Silent drops all outgoing messages, as if the oracle had crashed. The
+// oracle still receives messages.
+func Silent() Behavior {
+	return behaviorFunc(func(Environment) interceptor {
+		return interceptorFunc(func(message, commontypes.OracleID) []message {
+			return nil
+		})
+	})
+}
+
+// Withhold drops all outgoing messages of the given kinds.
+func Withhold(kinds ...MessageKind) Behavior {
+	drop := map[MessageKind]bool{}
+	for _, k := range kinds {
+		drop[k] = true
+	}
+	return behaviorFunc(func(Environment) interceptor {
+		return interceptorFunc(func(msg message, _ commontypes.OracleID) []message {
+			if drop[kindOf(msg)] {
+				return nil
+			}
+			return []message{msg}
+		})
+	})
+}
+
+// WithholdReportSignatures drops all outgoing MessageReportSignatures, so
+// that the oracle never helps others attest reports.
+func WithholdReportSignatures() Behavior {
+	return Withhold(MessageKindReportSignatures)
+}
+
+// WithholdFrom drops all outgoing messages to the given oracles.
+func WithholdFrom(oracles ...commontypes.OracleID) Behavior {
+	drop := map[commontypes.OracleID]bool{}
+	for _, o := range oracles {
+		drop[o] = true
+	}
+	return behaviorFunc(func(Environment) interceptor {
+		return interceptorFunc(func(msg message, to commontypes.OracleID) []message {
+			if drop[to] {
+				return nil
+			}
+			return []message{msg}
+		})
+	})
+}
+
+// EquivocateProposals makes the oracle, whenever it acts as leader, send
+// conflicting MessageProposals for the same round: followers with even
+// OracleID receive the honest proposal, followers with odd OracleID receive
+// one with the last attributed signed observation removed. If removing an
+// observation would leave fewer than 2f+1 observations, odd followers
+// instead receive a proposal without any observations. Either way, the two
+// halves of the committee see different outcome inputs for the same seqNr.
+func EquivocateProposals() Behavior {
+	return behaviorFunc(func(env Environment) interceptor {
+		return interceptorFunc(func(msg message, to commontypes.OracleID) []message {
+			proposal, ok := msg.(protocol.MessageProposal[wireRI])
+			if !ok || to%2 == 0 {
+				return []message{msg}
+			}
+			asos := proposal.AttributedSignedObservations
+			if len(asos) > 2*env.F+1 {
+				asos = asos[:len(asos)-1]
+			} else {
+				asos = nil
+			}
+			return []message{protocol.MessageProposal[wireRI]{
+				proposal.Epoch,
+				proposal.SeqNr,
+				asos,
+			}}
+		})
+	})
+}
+
+// CorruptObservationSignatures flips a bit in the signature of every outgoing
+// MessageObservation, so that the leader must reject the observation.
+func CorruptObservationSignatures() Behavior {
+	return behaviorFunc(func(Environment) interceptor {
+		return interceptorFunc(func(msg message, _ commontypes.OracleID) []message {
+			obs, ok := msg.(protocol.MessageObservation[wireRI])
+			if !ok {
+				return []message{msg}
+			}
+			sig := append([]byte{}, obs.SignedObservation.Signature...)
+			if len(sig) > 0 {
+				sig[0] ^= 0x01
+			} else {
+				sig = []byte{0x01}
+			}
+			return []message{protocol.MessageObservation[wireRI]{
+				obs.Epoch,
+				obs.SeqNr,
+				protocol.SignedObservation{obs.SignedObservation.Observation, sig},
+			}}
+		})
+	})
+}
+
+// ReplayStale makes the oracle re-send old MessagePrepares and
+// MessageCommits. Whenever it sends a MessagePrepare (resp. MessageCommit)
+// for seqNr s to some oracle, it first sends that oracle the MessagePrepare
+// (resp. MessageCommit) it previously sent for seqNr s-lag, if any.
+// lag must be positive.
+func ReplayStale(lag uint64) (Behavior, error) {
+	if lag == 0 {
+		return nil, fmt.Errorf("lag must be positive")
+	}
+	return behaviorFunc(func(Environment) interceptor {
+		return &replayStaleInterceptor{lag: lag, sent: map[replayKey]message{}}
+	}), nil
+}
+
+type replayKey struct {
+	kind  MessageKind
+	seqNr uint64
+}
+
+type replayStaleInterceptor struct {
+	lag uint64
+
+	mu   sync.Mutex
+	sent map[replayKey]message
+}
+
+func (r *replayStaleInterceptor) intercept(msg message, _ commontypes.OracleID) []message {
+	var seqNr uint64
+	switch m := msg.(type) {
+	case protocol.MessagePrepare[wireRI]:
+		seqNr = m.SeqNr
+	case protocol.MessageCommit[wireRI]:
+		seqNr = m.SeqNr
+	default:
+		return []message{msg}
+	}
+	kind := kindOf(msg)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.sent[replayKey{kind, seqNr}] = msg
+	if seqNr <= r.lag {
+		return []message{msg}
+	}
+	staleSeqNr := seqNr - r.lag
+	stale, ok := r.sent[replayKey{kind, staleSeqNr}]
+	// prevent unbounded growth
+	for k := range r.sent {
+		if k.kind == kind && k.seqNr < staleSeqNr {
+			delete(r.sent, k)
+		}
+	}
+	if !ok {
+		return []message{msg}
+	}
+	return []message{stale, msg}
+}
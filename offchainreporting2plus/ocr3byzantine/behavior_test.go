@@ -0,0 +1,269 @@
+package ocr3byzantine
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/smartcontractkit/libocr/commontypes"
+	"github.com/smartcontractkit/libocr/offchainreporting2plus/internal/ocr3/protocol"
+	"github.com/smartcontractkit/libocr/offchainreporting2plus/internal/ocr3/serialization"
+	"github.com/smartcontractkit/libocr/offchainreporting2plus/types"
+)
+
+const (
+	testN    = 4
+	testF    = 1
+	testSelf = commontypes.OracleID(1)
+)
+
+var testPeerIDs = []string{"peer0", "peer1", "peer2", "peer3"}
+
+type sentMessage struct {
+	to      commontypes.OracleID
+	payload []byte
+}
+
+// recordingEndpoint records the messages sent through it.
+type recordingEndpoint struct {
+	sent []sentMessage
+}
+
+func (e *recordingEndpoint) SendTo(payload []byte, to commontypes.OracleID) {
+	e.sent = append(e.sent, sentMessage{to, payload})
+}
+
+func (e *recordingEndpoint) Broadcast(payload []byte) {
+	panic("the byzantine endpoint must split broadcasts")
+}
+
+func (e *recordingEndpoint) Receive() <-chan commontypes.BinaryMessageWithSender { return nil }
+
+func (e *recordingEndpoint) Start() error { return nil }
+
+func (e *recordingEndpoint) Close() error { return nil }
+
+type recordingEndpointFactory struct {
+	endpoint *recordingEndpoint
+}
+
+func (f *recordingEndpointFactory) NewEndpoint(types.ConfigDigest, []string, []commontypes.BootstrapperLocator, int, types.BinaryNetworkEndpointLimits) (commontypes.BinaryNetworkEndpoint, error) {
+	return f.endpoint, nil
+}
+
+func (f *recordingEndpointFactory) PeerID() string {
+	return testPeerIDs[testSelf]
+}
+
+func newTestEndpoint(t *testing.T, behaviors ...Behavior) (commontypes.BinaryNetworkEndpoint, *recordingEndpoint) {
+	t.Helper()
+	inner := &recordingEndpoint{}
+	endpoint, err := NewEndpointFactory(&recordingEndpointFactory{inner}, behaviors...).NewEndpoint(types.ConfigDigest{}, testPeerIDs, nil, testF, types.BinaryNetworkEndpointLimits{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return endpoint, inner
+}
+
+func serialize(t *testing.T, msg message) []byte {
+	t.Helper()
+	b, _, err := serialization.Serialize[wireRI](msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+// decodeSent decodes the messages sent through e and clears them.
+func (e *recordingEndpoint) decodeSent(t *testing.T) map[commontypes.OracleID][]message {
+	t.Helper()
+	result := map[commontypes.OracleID][]message{}
+	for _, s := range e.sent {
+		msg, _, err := serialization.Deserialize[wireRI](s.payload)
+		if err != nil {
+			t.Fatalf("could not decode message to %v: %v", s.to, err)
+		}
+		result[s.to] = append(result[s.to], msg)
+	}
+	e.sent = nil
+	return result
+}
+
+func kindsOf(msgs []message) []MessageKind {
+	kinds := []MessageKind{}
+	for _, msg := range msgs {
+		kinds = append(kinds, kindOf(msg))
+	}
+	return kinds
+}
+
+func testObservation(observer commontypes.OracleID) protocol.AttributedSignedObservation {
+	return protocol.AttributedSignedObservation{
+		protocol.SignedObservation{types.Observation{byte(observer)}, []byte{0xa0, byte(observer)}},
+		observer,
+	}
+}
+
+var (
+	testNewEpochWish     = protocol.MessageNewEpochWish[wireRI]{2}
+	testObservationMsg   = protocol.MessageObservation[wireRI]{2, 5, protocol.SignedObservation{types.Observation{1}, []byte{0xa0, 0xa1}}}
+	testReportSignatures = protocol.MessageReportSignatures[wireRI]{5, [][]byte{{1}}}
+)
+
+func testPrepare(seqNr uint64) protocol.MessagePrepare[wireRI] {
+	return protocol.MessagePrepare[wireRI]{2, seqNr, protocol.PrepareSignature{byte(seqNr)}}
+}
+
+func testCommit(seqNr uint64) protocol.MessageCommit[wireRI] {
+	return protocol.MessageCommit[wireRI]{2, seqNr, protocol.CommitSignature{byte(seqNr)}}
+}
+
+func TestEndpointPassesThrough(t *testing.T) {
+	endpoint, inner := newTestEndpoint(t)
+
+	endpoint.Broadcast(serialize(t, testNewEpochWish))
+	sent := inner.decodeSent(t)
+	for i := 0; i < testN; i++ {
+		if msgs := sent[commontypes.OracleID(i)]; len(msgs) != 1 || !reflect.DeepEqual(msgs[0], testNewEpochWish) {
+			t.Errorf("oracle %v: expected broadcast message, got %v", i, msgs)
+		}
+	}
+
+	// Messages that cannot be decoded are passed through unchanged
+	endpoint.SendTo([]byte{0xff, 0xff}, 2)
+	if !reflect.DeepEqual(inner.sent, []sentMessage{{2, []byte{0xff, 0xff}}}) {
+		t.Errorf("expected garbage to be passed through, got %v", inner.sent)
+	}
+}
+
+func TestSilentAndWithhold(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		behavior Behavior
+		// expected kinds received by oracle 0, and oracle 2
+		expected0 []MessageKind
+		expected2 []MessageKind
+	}{
+		{
+			"Silent",
+			Silent(),
+			[]MessageKind{},
+			[]MessageKind{},
+		},
+		{
+			"WithholdReportSignatures",
+			WithholdReportSignatures(),
+			[]MessageKind{MessageKindNewEpochWish, MessageKindObservation},
+			[]MessageKind{MessageKindNewEpochWish, MessageKindObservation},
+		},
+		{
+			"Withhold",
+			Withhold(MessageKindNewEpochWish, MessageKindObservation),
+			[]MessageKind{MessageKindReportSignatures},
+			[]MessageKind{MessageKindReportSignatures},
+		},
+		{
+			"WithholdFrom",
+			WithholdFrom(2),
+			[]MessageKind{MessageKindNewEpochWish, MessageKindObservation, MessageKindReportSignatures},
+			[]MessageKind{},
+		},
+	} {
+		endpoint, inner := newTestEndpoint(t, test.behavior)
+		for _, msg := range []message{testNewEpochWish, testObservationMsg, testReportSignatures} {
+			endpoint.Broadcast(serialize(t, msg))
+		}
+		sent := inner.decodeSent(t)
+		if actual := kindsOf(sent[0]); !reflect.DeepEqual(actual, test.expected0) {
+			t.Errorf("%s: expected oracle 0 to receive %v, got %v", test.name, test.expected0, actual)
+		}
+		if actual := kindsOf(sent[2]); !reflect.DeepEqual(actual, test.expected2) {
+			t.Errorf("%s: expected oracle 2 to receive %v, got %v", test.name, test.expected2, actual)
+		}
+		// Messages to self are never modified
+		if actual := kindsOf(sent[testSelf]); len(actual) != 3 {
+			t.Errorf("%s: expected self to receive all messages, got %v", test.name, actual)
+		}
+	}
+}
+
+func TestEquivocateProposals(t *testing.T) {
+	for _, test := range []struct {
+		observations int
+		// number of observations odd followers receive
+		expectedOdd int
+	}{
+		{4, 3},
+		{3, 0},
+	} {
+		endpoint, inner := newTestEndpoint(t, EquivocateProposals())
+		asos := []protocol.AttributedSignedObservation{}
+		for i := 0; i < test.observations; i++ {
+			asos = append(asos, testObservation(commontypes.OracleID(i)))
+		}
+		endpoint.Broadcast(serialize(t, protocol.MessageProposal[wireRI]{2, 5, asos}))
+
+		name := fmt.Sprintf("%v observations", test.observations)
+		sent := inner.decodeSent(t)
+		for i := 0; i < testN; i++ {
+			expected := test.observations
+			if i%2 == 1 && commontypes.OracleID(i) != testSelf {
+				expected = test.expectedOdd
+			}
+			proposal, ok := sent[commontypes.OracleID(i)][0].(protocol.MessageProposal[wireRI])
+			if !ok || proposal.Epoch != 2 || proposal.SeqNr != 5 || len(proposal.AttributedSignedObservations) != expected {
+				t.Errorf("%s: expected oracle %v to receive proposal with %v observations, got %+v", name, i, expected, sent[commontypes.OracleID(i)])
+				continue
+			}
+			if expected != 0 && !reflect.DeepEqual(proposal.AttributedSignedObservations, asos[:expected]) {
+				t.Errorf("%s: oracle %v received modified observations %+v", name, i, proposal.AttributedSignedObservations)
+			}
+		}
+	}
+}
+
+func TestCorruptObservationSignatures(t *testing.T) {
+	endpoint, inner := newTestEndpoint(t, CorruptObservationSignatures())
+	endpoint.SendTo(serialize(t, testObservationMsg), 0)
+	endpoint.SendTo(serialize(t, testReportSignatures), 0)
+
+	sent := inner.decodeSent(t)[0]
+	expected := protocol.MessageObservation[wireRI]{2, 5, protocol.SignedObservation{types.Observation{1}, []byte{0xa1, 0xa1}}}
+	if len(sent) != 2 || !reflect.DeepEqual(sent[0], expected) || !reflect.DeepEqual(sent[1], testReportSignatures) {
+		t.Fatalf("expected corrupted observation and unmodified report signatures, got %+v", sent)
+	}
+	// The original signature is not modified in place
+	if testObservationMsg.SignedObservation.Signature[0] != 0xa0 {
+		t.Fatalf("original signature was modified")
+	}
+}
+
+func TestReplayStale(t *testing.T) {
+	if _, err := ReplayStale(0); err == nil {
+		t.Fatalf("expected error for zero lag")
+	}
+	behavior, err := ReplayStale(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	endpoint, inner := newTestEndpoint(t, behavior)
+
+	for seqNr := uint64(1); seqNr <= 4; seqNr++ {
+		endpoint.SendTo(serialize(t, testPrepare(seqNr)), 0)
+	}
+	endpoint.SendTo(serialize(t, testCommit(3)), 0)
+	endpoint.SendTo(serialize(t, testObservationMsg), 0)
+
+	expected := []message{
+		testPrepare(1),
+		testPrepare(2),
+		testPrepare(1), testPrepare(3),
+		testPrepare(2), testPrepare(4),
+		// No commit for seqNr 1 was sent before
+		testCommit(3),
+		testObservationMsg,
+	}
+	if sent := inner.decodeSent(t)[0]; !reflect.DeepEqual(sent, expected) {
+		t.Fatalf("expected %+v, got %+v", expected, sent)
+	}
+}
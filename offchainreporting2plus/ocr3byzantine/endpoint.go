@@ -0,0 +1,133 @@
+// Package ocr3byzantine helps test that an OCR3 committee tolerates up to f
+// faulty oracles. It provides a wrapper around a
+// types.BinaryNetworkEndpointFactory that makes an otherwise honest oracle
+// misbehave in scripted ways (see Behavior), and an OutcomeRecorder that
+// checks that the honest oracles still commit consistent outcomes.
+//
+// This package is intended for tests only.
+package ocr3byzantine
+
+import (
+	"github.com/smartcontractkit/libocr/commontypes"
+	"github.com/smartcontractkit/libocr/offchainreporting2plus/internal/ocr3/protocol"
+	"github.com/smartcontractkit/libocr/offchainreporting2plus/internal/ocr3/serialization"
+	"github.com/smartcontractkit/libocr/offchainreporting2plus/types"
+)
+
+// The wire format of OCR3 messages does not depend on the report info type,
+// so we can decode and re-encode all messages with a placeholder.
+type wireRI = struct{}
+
+type message = protocol.Message[wireRI]
+
+// NewEndpointFactory wraps factory so that every endpoint it creates passes
+// outgoing messages through behaviors, in order, before sending them.
+// Broadcasts are split into one message per recipient, so that behaviors can
+// treat recipients differently. Messages to self and incoming messages are
+// never modified.
+//
+// Use the returned factory as the BinaryNetworkEndpointFactory of the oracle
+// that should misbehave.
+func NewEndpointFactory(factory types.BinaryNetworkEndpointFactory, behaviors ...Behavior) types.BinaryNetworkEndpointFactory {
+	return &endpointFactory{factory, behaviors}
+}
+
+type endpointFactory struct {
+	inner     types.BinaryNetworkEndpointFactory
+	behaviors []Behavior
+}
+
+func (f *endpointFactory) NewEndpoint(
+	configDigest types.ConfigDigest,
+	peerIDs []string,
+	v2bootstrappers []commontypes.BootstrapperLocator,
+	failureThreshold int,
+	limits types.BinaryNetworkEndpointLimits,
+) (commontypes.BinaryNetworkEndpoint, error) {
+	inner, err := f.inner.NewEndpoint(configDigest, peerIDs, v2bootstrappers, failureThreshold, limits)
+	if err != nil {
+		return nil, err
+	}
+
+	self := commontypes.OracleID(0)
+	for i, peerID := range peerIDs {
+		if peerID == f.inner.PeerID() {
+			self = commontypes.OracleID(i)
+		}
+	}
+
+	env := Environment{len(peerIDs), failureThreshold, self}
+	interceptors := make([]interceptor, 0, len(f.behaviors))
+	for _, b := range f.behaviors {
+		interceptors = append(interceptors, b.newInterceptor(env))
+	}
+	return &endpoint{inner, env, interceptors}, nil
+}
+
+func (f *endpointFactory) PeerID() string {
+	return f.inner.PeerID()
+}
+
+// Environment describes the committee a Byzantine endpoint is part of.
+type Environment struct {
+	N    int
+	F    int
+	Self commontypes.OracleID
+}
+
+type endpoint struct {
+	inner        commontypes.BinaryNetworkEndpoint
+	env          Environment
+	interceptors []interceptor
+}
+
+var _ commontypes.BinaryNetworkEndpoint = (*endpoint)(nil)
+
+func (e *endpoint) SendTo(payload []byte, to commontypes.OracleID) {
+	if to == e.env.Self {
+		e.inner.SendTo(payload, to)
+		return
+	}
+
+	msg, _, err := serialization.Deserialize[wireRI](payload)
+	if err != nil {
+		// not something we understand, pass through unchanged
+		e.inner.SendTo(payload, to)
+		return
+	}
+
+	msgs := []message{msg}
+	for _, i := range e.interceptors {
+		var next []message
+		for _, m := range msgs {
+			next = append(next, i.intercept(m, to)...)
+		}
+		msgs = next
+	}
+
+	for _, m := range msgs {
+		b, _, err := serialization.Serialize[wireRI](m)
+		if err != nil {
+			continue
+		}
+		e.inner.SendTo(b, to)
+	}
+}
+
+func (e *endpoint) Broadcast(payload []byte) {
+	for i := 0; i < e.env.N; i++ {
+		e.SendTo(payload, commontypes.OracleID(i))
+	}
+}
+
+func (e *endpoint) Receive() <-chan commontypes.BinaryMessageWithSender {
+	return e.inner.Receive()
+}
+
+func (e *endpoint) Start() error {
+	return e.inner.Start()
+}
+
+func (e *endpoint) Close() error {
+	return e.inner.Close()
+}
@@ -0,0 +1,148 @@
+package ocr3byzantine
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/smartcontractkit/libocr/commontypes"
+	"github.com/smartcontractkit/libocr/offchainreporting2plus/ocr3types"
+	"github.com/smartcontractkit/libocr/offchainreporting2plus/types"
+)
+
+// OutcomeRecorder records the outcomes committed by the oracles of a
+// committee, so that a test can assert that honest oracles agree with each
+// other. An outcome is considered committed by an oracle when the protocol
+// calls ReportingPlugin.Reports on it.
+//
+// All its functions are thread-safe.
+type OutcomeRecorder[RI any] struct {
+	mu sync.Mutex
+	// outcomes[configDigest][seqNr][oracle]
+	outcomes map[types.ConfigDigest]map[uint64]map[commontypes.OracleID]ocr3types.Outcome
+	// first occasion on which an oracle committed two different outcomes for
+	// the same seqNr
+	selfConflict error
+}
+
+func NewOutcomeRecorder[RI any]() *OutcomeRecorder[RI] {
+	return &OutcomeRecorder[RI]{
+		outcomes: map[types.ConfigDigest]map[uint64]map[commontypes.OracleID]ocr3types.Outcome{},
+	}
+}
+
+// WrapFactory returns a ReportingPluginFactory whose plugins behave exactly
+// like those created by factory, but report committed outcomes to r. Wrap
+// the factory of every honest oracle.
+func (r *OutcomeRecorder[RI]) WrapFactory(factory ocr3types.ReportingPluginFactory[RI]) ocr3types.ReportingPluginFactory[RI] {
+	return &recordingFactory[RI]{factory, r}
+}
+
+func (r *OutcomeRecorder[RI]) record(configDigest types.ConfigDigest, oracle commontypes.OracleID, seqNr uint64, outcome ocr3types.Outcome) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	bySeqNr, ok := r.outcomes[configDigest]
+	if !ok {
+		bySeqNr = map[uint64]map[commontypes.OracleID]ocr3types.Outcome{}
+		r.outcomes[configDigest] = bySeqNr
+	}
+	byOracle, ok := bySeqNr[seqNr]
+	if !ok {
+		byOracle = map[commontypes.OracleID]ocr3types.Outcome{}
+		bySeqNr[seqNr] = byOracle
+	}
+	if previous, ok := byOracle[oracle]; ok && !bytes.Equal(previous, outcome) && r.selfConflict == nil {
+		r.selfConflict = fmt.Errorf("oracle %d committed two different outcomes for configDigest %s seqNr %d: %x and %x",
+			oracle, configDigest, seqNr, previous, outcome)
+	}
+	byOracle[oracle] = append(ocr3types.Outcome{}, outcome...)
+}
+
+// CheckConsistency returns an error if two oracles committed different
+// outcomes for the same seqNr, or if a single oracle committed two different
+// outcomes for the same seqNr.
+func (r *OutcomeRecorder[RI]) CheckConsistency() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.selfConflict != nil {
+		return r.selfConflict
+	}
+	for configDigest, bySeqNr := range r.outcomes {
+		for seqNr, byOracle := range bySeqNr {
+			var reference ocr3types.Outcome
+			var referenceOracle commontypes.OracleID
+			first := true
+			for _, oracle := range sortedOracles(byOracle) {
+				outcome := byOracle[oracle]
+				if first {
+					reference, referenceOracle, first = outcome, oracle, false
+					continue
+				}
+				if !bytes.Equal(reference, outcome) {
+					return fmt.Errorf("inconsistent outcomes for configDigest %s seqNr %d: oracle %d committed %x, oracle %d committed %x",
+						configDigest, seqNr, referenceOracle, reference, oracle, outcome)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// HighestCommittedSeqNr returns the highest seqNr for which oracle has
+// committed an outcome under configDigest, or 0 if there is none. Useful for
+// liveness assertions.
+func (r *OutcomeRecorder[RI]) HighestCommittedSeqNr(configDigest types.ConfigDigest, oracle commontypes.OracleID) uint64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	highest := uint64(0)
+	for seqNr, byOracle := range r.outcomes[configDigest] {
+		if _, ok := byOracle[oracle]; ok && seqNr > highest {
+			highest = seqNr
+		}
+	}
+	return highest
+}
+
+// Outcome returns the outcome committed by oracle for seqNr under
+// configDigest, if any.
+func (r *OutcomeRecorder[RI]) Outcome(configDigest types.ConfigDigest, oracle commontypes.OracleID, seqNr uint64) (ocr3types.Outcome, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	outcome, ok := r.outcomes[configDigest][seqNr][oracle]
+	return outcome, ok
+}
+
+func sortedOracles(m map[commontypes.OracleID]ocr3types.Outcome) []commontypes.OracleID {
+	oracles := make([]commontypes.OracleID, 0, len(m))
+	for o := range m {
+		oracles = append(oracles, o)
+	}
+	sort.Slice(oracles, func(i, j int) bool { return oracles[i] < oracles[j] })
+	return oracles
+}
+
+type recordingFactory[RI any] struct {
+	inner    ocr3types.ReportingPluginFactory[RI]
+	recorder *OutcomeRecorder[RI]
+}
+
+func (f *recordingFactory[RI]) NewReportingPlugin(config ocr3types.ReportingPluginConfig) (ocr3types.ReportingPlugin[RI], ocr3types.ReportingPluginInfo, error) {
+	plugin, info, err := f.inner.NewReportingPlugin(config)
+	if err != nil {
+		return nil, info, err
+	}
+	return &recordingPlugin[RI]{plugin, f.recorder, config.ConfigDigest, config.OracleID}, info, nil
+}
+
+type recordingPlugin[RI any] struct {
+	ocr3types.ReportingPlugin[RI]
+	recorder     *OutcomeRecorder[RI]
+	configDigest types.ConfigDigest
+	oracleID     commontypes.OracleID
+}
+
+func (p *recordingPlugin[RI]) Reports(seqNr uint64, outcome ocr3types.Outcome) ([]ocr3types.ReportWithInfo[RI], error) {
+	p.recorder.record(p.configDigest, p.oracleID, seqNr, outcome)
+	return p.ReportingPlugin.Reports(seqNr, outcome)
+}
@@ -0,0 +1,153 @@
+package ocr3byzantine
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/smartcontractkit/libocr/commontypes"
+	"github.com/smartcontractkit/libocr/offchainreporting2plus/ocr3types"
+	"github.com/smartcontractkit/libocr/offchainreporting2plus/types"
+)
+
+type testPlugin struct {
+	// not called by the tests
+	ocr3types.ReportingPlugin[struct{}]
+}
+
+func (p *testPlugin) Reports(seqNr uint64, outcome ocr3types.Outcome) ([]ocr3types.ReportWithInfo[struct{}], error) {
+	return []ocr3types.ReportWithInfo[struct{}]{{types.Report(outcome), struct{}{}}}, nil
+}
+
+type testPluginFactory struct {
+	plugin *testPlugin
+}
+
+func (f testPluginFactory) NewReportingPlugin(ocr3types.ReportingPluginConfig) (ocr3types.ReportingPlugin[struct{}], ocr3types.ReportingPluginInfo, error) {
+	return f.plugin, ocr3types.ReportingPluginInfo{Name: "test"}, nil
+}
+
+// newRecordingPlugins returns a recorder and a plugin for each oracle, all
+// sharing configDigest.
+func newRecordingPlugins(t *testing.T, configDigest types.ConfigDigest) (*OutcomeRecorder[struct{}], []ocr3types.ReportingPlugin[struct{}]) {
+	t.Helper()
+	recorder := NewOutcomeRecorder[struct{}]()
+	plugins := []ocr3types.ReportingPlugin[struct{}]{}
+	for i := 0; i < testN; i++ {
+		factory := recorder.WrapFactory(testPluginFactory{&testPlugin{}})
+		plugin, info, err := factory.NewReportingPlugin(ocr3types.ReportingPluginConfig{ConfigDigest: configDigest, OracleID: commontypes.OracleID(i), N: testN, F: testF})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Name != "test" {
+			t.Fatalf("expected info of inner plugin, got %+v", info)
+		}
+		plugins = append(plugins, plugin)
+	}
+	return recorder, plugins
+}
+
+func reports(t *testing.T, plugin ocr3types.ReportingPlugin[struct{}], seqNr uint64, outcome string) {
+	t.Helper()
+	rwis, err := plugin.Reports(seqNr, ocr3types.Outcome(outcome))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rwis) != 1 || string(rwis[0].Report) != outcome {
+		t.Fatalf("expected reports of inner plugin, got %v", rwis)
+	}
+}
+
+func TestOutcomeRecorderConsistent(t *testing.T) {
+	digest := types.ConfigDigest{1}
+	recorder, plugins := newRecordingPlugins(t, digest)
+
+	for i, plugin := range plugins {
+		reports(t, plugin, 1, "a")
+		// Oracle 3 lags behind
+		if i != 3 {
+			reports(t, plugin, 2, "b")
+		}
+		// Committing the same outcome twice is fine
+		reports(t, plugin, 1, "a")
+	}
+	if err := recorder.CheckConsistency(); err != nil {
+		t.Fatal(err)
+	}
+
+	for i, expected := range []uint64{2, 2, 2, 1} {
+		if highest := recorder.HighestCommittedSeqNr(digest, commontypes.OracleID(i)); highest != expected {
+			t.Errorf("oracle %v: expected highest seqNr %v, got %v", i, expected, highest)
+		}
+	}
+	if highest := recorder.HighestCommittedSeqNr(types.ConfigDigest{2}, 0); highest != 0 {
+		t.Errorf("expected highest seqNr 0 for unknown config digest, got %v", highest)
+	}
+
+	if outcome, ok := recorder.Outcome(digest, 0, 2); !ok || string(outcome) != "b" {
+		t.Errorf("expected outcome b, got %q, %v", outcome, ok)
+	}
+	if outcome, ok := recorder.Outcome(digest, 3, 2); ok {
+		t.Errorf("expected no outcome, got %q", outcome)
+	}
+}
+
+func TestOutcomeRecorderCopiesOutcomes(t *testing.T) {
+	digest := types.ConfigDigest{1}
+	recorder, plugins := newRecordingPlugins(t, digest)
+
+	outcome := ocr3types.Outcome("a")
+	if _, err := plugins[0].Reports(1, outcome); err != nil {
+		t.Fatal(err)
+	}
+	outcome[0] = 'z'
+	if recorded, _ := recorder.Outcome(digest, 0, 1); string(recorded) != "a" {
+		t.Fatalf("recorded outcome was modified to %q", recorded)
+	}
+}
+
+func TestOutcomeRecorderInconsistent(t *testing.T) {
+	for _, test := range []struct {
+		name          string
+		commit        func(t *testing.T, plugins []ocr3types.ReportingPlugin[struct{}])
+		expectedError string
+	}{
+		{
+			"different oracles",
+			func(t *testing.T, plugins []ocr3types.ReportingPlugin[struct{}]) {
+				reports(t, plugins[0], 1, "a")
+				reports(t, plugins[2], 1, "b")
+			},
+			"inconsistent outcomes",
+		},
+		{
+			"same oracle",
+			func(t *testing.T, plugins []ocr3types.ReportingPlugin[struct{}]) {
+				reports(t, plugins[1], 1, "a")
+				reports(t, plugins[1], 1, "b")
+				// Oracle 1's latest outcome agrees with the others, but the
+				// conflict is still reported
+				reports(t, plugins[0], 1, "b")
+			},
+			"oracle 1 committed two different outcomes",
+		},
+	} {
+		recorder, plugins := newRecordingPlugins(t, types.ConfigDigest{1})
+		test.commit(t, plugins)
+		if err := recorder.CheckConsistency(); err == nil || !strings.Contains(err.Error(), test.expectedError) {
+			t.Errorf("%s: expected error containing %q, got %v", test.name, test.expectedError, err)
+		}
+	}
+
+	// Outcomes under different config digests are independent
+	recorder := NewOutcomeRecorder[struct{}]()
+	for i, digest := range []types.ConfigDigest{{1}, {2}} {
+		plugin, _, err := recorder.WrapFactory(testPluginFactory{&testPlugin{}}).NewReportingPlugin(ocr3types.ReportingPluginConfig{ConfigDigest: digest, OracleID: commontypes.OracleID(i)})
+		if err != nil {
+			t.Fatal(err)
+		}
+		reports(t, plugin, 1, string(rune('a'+i)))
+	}
+	if err := recorder.CheckConsistency(); err != nil {
+		t.Errorf("different config digests: unexpected error %v", err)
+	}
+}
@@ -0,0 +1,65 @@
+package ocr3confighelper
+
+import (
+	"fmt"
+
+	"github.com/smartcontractkit/libocr/offchainreporting2plus/confighelper"
+	"github.com/smartcontractkit/libocr/offchainreporting2plus/internal/config/ocr3config"
+	"github.com/smartcontractkit/libocr/offchainreporting2plus/types"
+)
+
+// DiffPublicConfigs compares two OCR3 configs. See confighelper.ConfigDiff.
+func DiffPublicConfigs(old, new PublicConfig, opts confighelper.DiffOptions) confighelper.ConfigDiff {
+	return confighelper.Diff(diffInput(old), diffInput(new), opts)
+}
+
+// DiffContractConfigs compares two OCR3 contract configs. Unlike
+// PublicConfigFromContractConfig, it also accepts configs that oracles would
+// reject, as long as they can be decoded.
+func DiffContractConfigs(old, new types.ContractConfig, opts confighelper.DiffOptions) (confighelper.ConfigDiff, error) {
+	oldInternal, _, _, err := ocr3config.XXXPublicConfigAndErrorsFromContractConfig(old)
+	if err != nil {
+		return confighelper.ConfigDiff{}, fmt.Errorf("could not decode old config: %w", err)
+	}
+	newInternal, _, _, err := ocr3config.XXXPublicConfigAndErrorsFromContractConfig(new)
+	if err != nil {
+		return confighelper.ConfigDiff{}, fmt.Errorf("could not decode new config: %w", err)
+	}
+	oldOC, err := ocr3config.XXXDeserializeOffchainConfig(old.OffchainConfig)
+	if err != nil {
+		return confighelper.ConfigDiff{}, err
+	}
+	newOC, err := ocr3config.XXXDeserializeOffchainConfig(new.OffchainConfig)
+	if err != nil {
+		return confighelper.ConfigDiff{}, err
+	}
+
+	diff := DiffPublicConfigs(publicConfigFromInternal(oldInternal), publicConfigFromInternal(newInternal), opts)
+	sharedSecretChanged := oldOC.SharedSecretEncryptions.SharedSecretHash != newOC.SharedSecretEncryptions.SharedSecretHash
+	diff.SharedSecretChanged = &sharedSecretChanged
+	return diff, nil
+}
+
+func diffInput(pc PublicConfig) confighelper.DiffInput {
+	return confighelper.DiffInput{
+		pc.OracleIdentities,
+		[]confighelper.Parameter{
+			{"F", pc.F},
+			{"DeltaProgress", pc.DeltaProgress},
+			{"DeltaResend", pc.DeltaResend},
+			{"DeltaInitial", pc.DeltaInitial},
+			{"DeltaRound", pc.DeltaRound},
+			{"DeltaGrace", pc.DeltaGrace},
+			{"DeltaCertifiedCommitRequest", pc.DeltaCertifiedCommitRequest},
+			{"DeltaStage", pc.DeltaStage},
+			{"RMax", pc.RMax},
+			{"S", pc.S},
+			{"MaxDurationQuery", pc.MaxDurationQuery},
+			{"MaxDurationObservation", pc.MaxDurationObservation},
+			{"MaxDurationShouldAcceptAttestedReport", pc.MaxDurationShouldAcceptAttestedReport},
+			{"MaxDurationShouldTransmitAcceptedReport", pc.MaxDurationShouldTransmitAcceptedReport},
+		},
+		pc.ReportingPluginConfig,
+		pc.OnchainConfig,
+	}
+}
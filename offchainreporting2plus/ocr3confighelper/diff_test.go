@@ -0,0 +1,155 @@
+package ocr3confighelper_test
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/smartcontractkit/libocr/offchainreporting2plus/chains/evmutil"
+	"github.com/smartcontractkit/libocr/offchainreporting2plus/configbuilder"
+	"github.com/smartcontractkit/libocr/offchainreporting2plus/confighelper"
+	"github.com/smartcontractkit/libocr/offchainreporting2plus/ocr3confighelper"
+	"github.com/smartcontractkit/libocr/offchainreporting2plus/types"
+	ragetypes "github.com/smartcontractkit/libocr/ragep2p/types"
+)
+
+func testOracles(n int) []confighelper.OracleIdentityExtra {
+	oracles := []confighelper.OracleIdentityExtra{}
+	for i := 0; i < n; i++ {
+		seed := bytes.Repeat([]byte{byte(i + 1)}, ed25519.SeedSize)
+		var offchainPublicKey types.OffchainPublicKey
+		copy(offchainPublicKey[:], ed25519.NewKeyFromSeed(seed).Public().(ed25519.PublicKey))
+		peerID, err := ragetypes.PeerIDFromPrivateKey(ed25519.NewKeyFromSeed(seed))
+		if err != nil {
+			panic(err)
+		}
+		oracles = append(oracles, confighelper.OracleIdentityExtra{
+			confighelper.OracleIdentity{
+				offchainPublicKey,
+				bytes.Repeat([]byte{byte(0x10 + i)}, 20),
+				peerID.String(),
+				types.Account(common.BytesToAddress(bytes.Repeat([]byte{byte(0x20 + i)}, 20)).Hex()),
+			},
+			types.ConfigEncryptionPublicKey{byte(0x30 + i), 1},
+		})
+	}
+	return oracles
+}
+
+func testOptions(oracles []confighelper.OracleIdentityExtra) configbuilder.Options {
+	return configbuilder.Options{
+		Protocol:                                configbuilder.ProtocolOCR3,
+		ConfigCount:                             3,
+		Digester:                                evmutil.EVMOffchainConfigDigester{1, common.HexToAddress("0x1234")},
+		Oracles:                                 oracles,
+		F:                                       1,
+		DeltaProgress:                           8*time.Second + 1,
+		DeltaResend:                             30 * time.Second,
+		DeltaInitial:                            3 * time.Second,
+		DeltaRound:                              1500 * time.Millisecond,
+		DeltaGrace:                              500 * time.Millisecond,
+		DeltaCertifiedCommitRequest:             time.Second,
+		DeltaStage:                              20 * time.Second,
+		RMax:                                    5,
+		S:                                       []int{1, 1, 2},
+		ReportingPluginConfig:                   []byte{0xde, 0xad},
+		OnchainConfig:                           []byte{0xbe, 0xef},
+		MaxDurationQuery:                        0,
+		MaxDurationObservation:                  time.Second,
+		MaxDurationShouldAcceptAttestedReport:   time.Second,
+		MaxDurationShouldTransmitAcceptedReport: time.Second,
+	}
+}
+
+func TestDiffContractConfigs(t *testing.T) {
+	oracles := testOracles(6)
+	base := oracles[:5]
+
+	build := func(t *testing.T, opts configbuilder.Options, seed configbuilder.Seed) types.ContractConfig {
+		t.Helper()
+		cc, _, err := configbuilder.Build(opts, seed)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return cc
+	}
+	old := build(t, testOptions(base), configbuilder.Seed{1})
+
+	rotated := append([]confighelper.OracleIdentityExtra{}, base...)
+	rotated[1].OffchainPublicKey = oracles[5].OffchainPublicKey
+	slower := testOptions(base)
+	slower.DeltaInitial *= 2
+
+	for _, test := range []struct {
+		name                  string
+		opts                  configbuilder.Options
+		expectedOracles       []confighelper.OracleChange
+		expectedParameters    []confighelper.ParameterChange
+		forcesNewSharedSecret bool
+	}{
+		{
+			"oracle added",
+			testOptions(oracles),
+			[]confighelper.OracleChange{{confighelper.OracleAdded, -1, 5, confighelper.OracleIdentity{}, oracles[5].OracleIdentity, nil}},
+			nil,
+			false,
+		},
+		{
+			"oracle removed",
+			testOptions(base[:4]),
+			[]confighelper.OracleChange{{confighelper.OracleRemoved, 4, -1, base[4].OracleIdentity, confighelper.OracleIdentity{}, nil}},
+			nil,
+			true,
+		},
+		{
+			"offchain key rotated",
+			testOptions(rotated),
+			[]confighelper.OracleChange{{confighelper.OracleRotated, 1, 1, base[1].OracleIdentity, rotated[1].OracleIdentity, []string{"OffchainPublicKey"}}},
+			nil,
+			true,
+		},
+		{
+			"OCR3 parameter changed",
+			slower,
+			nil,
+			[]confighelper.ParameterChange{{"DeltaInitial", "3s", "6s"}},
+			false,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			diff, err := ocr3confighelper.DiffContractConfigs(old, build(t, test.opts, configbuilder.Seed{2}), confighelper.DiffOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(diff.Oracles, test.expectedOracles) {
+				t.Errorf("expected oracle changes %+v, got %+v", test.expectedOracles, diff.Oracles)
+			}
+			if !reflect.DeepEqual(diff.Parameters, test.expectedParameters) {
+				t.Errorf("expected parameter changes %+v, got %+v", test.expectedParameters, diff.Parameters)
+			}
+			if diff.ForcesNewSharedSecret != test.forcesNewSharedSecret {
+				t.Errorf("expected ForcesNewSharedSecret %v, got %v (%v)", test.forcesNewSharedSecret, diff.ForcesNewSharedSecret, diff.ForcesNewSharedSecretReasons)
+			}
+			if diff.SharedSecretChanged == nil || !*diff.SharedSecretChanged {
+				t.Errorf("expected SharedSecretChanged to be true")
+			}
+		})
+	}
+
+	// OCR2 configs can't be diffed as OCR3 configs
+	ocr2, _, err := configbuilder.Build(configbuilder.Options{
+		configbuilder.ProtocolOCR2, 3, evmutil.EVMOffchainConfigDigester{1, common.HexToAddress("0x1234")},
+		base, 1, 8*time.Second + 1, 30 * time.Second, 0, 1500 * time.Millisecond, 500 * time.Millisecond, 0, 20 * time.Second,
+		5, []int{1, 1, 2}, nil, nil, 0, time.Second, time.Second, time.Second, 0, time.Second,
+	}, configbuilder.Seed{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ocr3confighelper.DiffContractConfigs(old, ocr2, confighelper.DiffOptions{}); err == nil {
+		t.Fatalf("expected error when diffing OCR3 config with OCR2 config")
+	}
+}
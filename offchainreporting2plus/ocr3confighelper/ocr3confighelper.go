@@ -46,6 +46,10 @@ func PublicConfigFromContractConfig(skipResourceExhaustionChecks bool, change ty
 	if err != nil {
 		return PublicConfig{}, err
 	}
+	return publicConfigFromInternal(internalPublicConfig), nil
+}
+
+func publicConfigFromInternal(internalPublicConfig ocr3config.PublicConfig) PublicConfig {
 	identities := []confighelper.OracleIdentity{}
 	for _, internalIdentity := range internalPublicConfig.OracleIdentities {
 		identities = append(identities, confighelper.OracleIdentity{
@@ -74,7 +78,7 @@ func PublicConfigFromContractConfig(skipResourceExhaustionChecks bool, change ty
 		internalPublicConfig.F,
 		internalPublicConfig.OnchainConfig,
 		internalPublicConfig.ConfigDigest,
-	}, nil
+	}
 }
 
 // ContractSetConfigArgsForTestsWithAuxiliaryArgsMercuryV02 generates setConfig
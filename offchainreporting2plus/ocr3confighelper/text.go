@@ -0,0 +1,124 @@
+package ocr3confighelper
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/smartcontractkit/libocr/offchainreporting2plus/confighelper"
+	"github.com/smartcontractkit/libocr/offchainreporting2plus/internal/config/configtext"
+	"github.com/smartcontractkit/libocr/offchainreporting2plus/types"
+)
+
+// PublicConfigText is a PublicConfig with the same versioned text encoding as
+// confighelper.PublicConfigText, see there for details. The protocol field of
+// the encoding is "ocr3", so OCR2 and OCR3 configs can't be mixed up.
+type PublicConfigText PublicConfig
+
+var (
+	_ json.Marshaler   = PublicConfigText{}
+	_ json.Unmarshaler = &PublicConfigText{}
+)
+
+const publicConfigTextProtocol = "ocr3"
+
+type publicConfigText struct {
+	configtext.Header
+
+	DeltaProgress               configtext.Duration               `json:"deltaProgress"`
+	DeltaResend                 configtext.Duration               `json:"deltaResend"`
+	DeltaInitial                configtext.Duration               `json:"deltaInitial"`
+	DeltaRound                  configtext.Duration               `json:"deltaRound"`
+	DeltaGrace                  configtext.Duration               `json:"deltaGrace"`
+	DeltaCertifiedCommitRequest configtext.Duration               `json:"deltaCertifiedCommitRequest"`
+	DeltaStage                  configtext.Duration               `json:"deltaStage"`
+	RMax                        uint64                            `json:"rMax"`
+	S                           []int                             `json:"s"`
+	Oracles                     []confighelper.OracleIdentityText `json:"oracles"`
+
+	ReportingPluginConfig configtext.HexBytes `json:"reportingPluginConfig"`
+
+	MaxDurationQuery                        configtext.Duration `json:"maxDurationQuery"`
+	MaxDurationObservation                  configtext.Duration `json:"maxDurationObservation"`
+	MaxDurationShouldAcceptAttestedReport   configtext.Duration `json:"maxDurationShouldAcceptAttestedReport"`
+	MaxDurationShouldTransmitAcceptedReport configtext.Duration `json:"maxDurationShouldTransmitAcceptedReport"`
+
+	F             int                 `json:"f"`
+	OnchainConfig configtext.HexBytes `json:"onchainConfig"`
+	// Omitted if zero, which is the case for configs that haven't been
+	// deployed yet
+	ConfigDigest configtext.HexBytes `json:"configDigest,omitempty"`
+}
+
+func (pc PublicConfigText) MarshalJSON() ([]byte, error) {
+	var configDigest configtext.HexBytes
+	if pc.ConfigDigest != (types.ConfigDigest{}) {
+		configDigest = pc.ConfigDigest[:]
+	}
+	return json.Marshal(publicConfigText{
+		configtext.Header{configtext.Version, publicConfigTextProtocol},
+		configtext.Duration(pc.DeltaProgress),
+		configtext.Duration(pc.DeltaResend),
+		configtext.Duration(pc.DeltaInitial),
+		configtext.Duration(pc.DeltaRound),
+		configtext.Duration(pc.DeltaGrace),
+		configtext.Duration(pc.DeltaCertifiedCommitRequest),
+		configtext.Duration(pc.DeltaStage),
+		pc.RMax,
+		pc.S,
+		confighelper.OracleIdentitiesText(pc.OracleIdentities),
+		pc.ReportingPluginConfig,
+		configtext.Duration(pc.MaxDurationQuery),
+		configtext.Duration(pc.MaxDurationObservation),
+		configtext.Duration(pc.MaxDurationShouldAcceptAttestedReport),
+		configtext.Duration(pc.MaxDurationShouldTransmitAcceptedReport),
+		pc.F,
+		pc.OnchainConfig,
+		configDigest,
+	})
+}
+
+func (pc *PublicConfigText) UnmarshalJSON(data []byte) error {
+	var t publicConfigText
+	if err := configtext.UnmarshalStrict(data, &t); err != nil {
+		return fmt.Errorf("could not decode PublicConfig: %w", err)
+	}
+	if err := t.Header.Check(publicConfigTextProtocol); err != nil {
+		return err
+	}
+	var configDigest types.ConfigDigest
+	if t.ConfigDigest != nil {
+		if err := configtext.DecodeFixed("configDigest", t.ConfigDigest, configDigest[:]); err != nil {
+			return err
+		}
+	}
+	*pc = PublicConfigText{
+		time.Duration(t.DeltaProgress),
+		time.Duration(t.DeltaResend),
+		time.Duration(t.DeltaInitial),
+		time.Duration(t.DeltaRound),
+		time.Duration(t.DeltaGrace),
+		time.Duration(t.DeltaCertifiedCommitRequest),
+		time.Duration(t.DeltaStage),
+		t.RMax,
+		t.S,
+		confighelper.OracleIdentitiesFromText(t.Oracles),
+		t.ReportingPluginConfig,
+		time.Duration(t.MaxDurationQuery),
+		time.Duration(t.MaxDurationObservation),
+		time.Duration(t.MaxDurationShouldAcceptAttestedReport),
+		time.Duration(t.MaxDurationShouldTransmitAcceptedReport),
+		t.F,
+		t.OnchainConfig,
+		configDigest,
+	}
+	return nil
+}
+
+func (pc PublicConfigText) MarshalYAML() (interface{}, error) {
+	return configtext.MarshalYAML(pc)
+}
+
+func (pc *PublicConfigText) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	return configtext.UnmarshalYAML(unmarshal, pc)
+}
@@ -0,0 +1,122 @@
+package ocr3confighelper_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"gopkg.in/yaml.v2"
+
+	"github.com/smartcontractkit/libocr/offchainreporting2plus/chains/evmutil"
+	"github.com/smartcontractkit/libocr/offchainreporting2plus/configbuilder"
+	"github.com/smartcontractkit/libocr/offchainreporting2plus/confighelper"
+	"github.com/smartcontractkit/libocr/offchainreporting2plus/ocr3confighelper"
+)
+
+func TestPublicConfigTextRoundTrip(t *testing.T) {
+	oracles := testOracles(4)
+	seed := configbuilder.Seed{1}
+
+	cc, _, err := configbuilder.Build(testOptions(oracles), seed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pc, err := ocr3confighelper.PublicConfigFromContractConfig(false, cc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, format := range []struct {
+		name      string
+		marshal   func(interface{}) ([]byte, error)
+		unmarshal func([]byte, interface{}) error
+	}{
+		{"json", json.Marshal, json.Unmarshal},
+		{"yaml", yaml.Marshal, yaml.Unmarshal},
+	} {
+		encoded, err := format.marshal(ocr3confighelper.PublicConfigText(pc))
+		if err != nil {
+			t.Fatalf("%s: %v", format.name, err)
+		}
+		var decoded ocr3confighelper.PublicConfigText
+		if err := format.unmarshal(encoded, &decoded); err != nil {
+			t.Fatalf("%s: %v\n%s", format.name, err, encoded)
+		}
+		if !reflect.DeepEqual(pc, ocr3confighelper.PublicConfig(decoded)) {
+			t.Fatalf("%s: round-trip changed value\nbefore: %+v\nafter:  %+v\nencoding:\n%s", format.name, pc, decoded, encoded)
+		}
+
+		// Rebuild the config from the decoded text encoding and check that
+		// the result is identical byte-for-byte.
+		var identities []confighelper.OracleIdentityExtra
+		for i, identity := range decoded.OracleIdentities {
+			identities = append(identities, confighelper.OracleIdentityExtra{identity, oracles[i].ConfigEncryptionPublicKey})
+		}
+		rebuilt := configbuilder.Options{
+			configbuilder.ProtocolOCR3, 3, evmutil.EVMOffchainConfigDigester{1, common.HexToAddress("0x1234")},
+			identities, decoded.F,
+			decoded.DeltaProgress, decoded.DeltaResend, decoded.DeltaInitial, decoded.DeltaRound, decoded.DeltaGrace,
+			decoded.DeltaCertifiedCommitRequest, decoded.DeltaStage,
+			decoded.RMax, decoded.S, decoded.ReportingPluginConfig, decoded.OnchainConfig,
+			decoded.MaxDurationQuery, decoded.MaxDurationObservation, 0, 0,
+			decoded.MaxDurationShouldAcceptAttestedReport, decoded.MaxDurationShouldTransmitAcceptedReport,
+		}
+		rebuiltCC, _, err := configbuilder.Build(rebuilt, seed)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(cc, rebuiltCC) {
+			t.Fatalf("%s: rebuilt contract config differs\nbefore: %+v\nafter:  %+v", format.name, cc, rebuiltCC)
+		}
+	}
+}
+
+func TestPublicConfigTextRejectsMismatches(t *testing.T) {
+	cc, _, err := configbuilder.Build(testOptions(testOracles(4)), configbuilder.Seed{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	pc, err := ocr3confighelper.PublicConfigFromContractConfig(false, cc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	encoded, err := json.Marshal(ocr3confighelper.PublicConfigText(pc))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// OCR2 configs can't be decoded as OCR3 configs
+	ocr2, _, err := configbuilder.Build(configbuilder.Options{
+		configbuilder.ProtocolOCR2, 3, evmutil.EVMOffchainConfigDigester{1, common.HexToAddress("0x1234")},
+		testOracles(4), 1, 8*time.Second + 1, 30 * time.Second, 0, 1500 * time.Millisecond, 500 * time.Millisecond, 0, 20 * time.Second,
+		5, []int{1, 1, 2}, nil, nil, 0, time.Second, time.Second, time.Second, 0, time.Second,
+	}, configbuilder.Seed{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ocr2PC, err := confighelper.PublicConfigFromContractConfig(false, ocr2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ocr2Encoded, err := json.Marshal(confighelper.PublicConfigText(ocr2PC))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, text := range map[string]string{
+		"OCR2 config":   string(ocr2Encoded),
+		"wrong version": strings.Replace(string(encoded), `"version":1`, `"version":2`, 1),
+		"unknown field": strings.Replace(string(encoded), `"version":1`, `"version":1,"maxDurationReport":"1s"`, 1),
+	} {
+		if text == string(encoded) {
+			t.Fatalf("%s: replacement didn't apply", name)
+		}
+		var decoded ocr3confighelper.PublicConfigText
+		if err := json.Unmarshal([]byte(text), &decoded); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
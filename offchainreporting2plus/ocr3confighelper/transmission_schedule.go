@@ -0,0 +1,223 @@
+package ocr3confighelper
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"time"
+
+	"github.com/smartcontractkit/libocr/offchainreporting2plus/internal/config/ocr3config"
+	"github.com/smartcontractkit/libocr/offchainreporting2plus/types"
+)
+
+// maxTransmissionStages is the number of stages accepted by oracles, see the
+// checks on S in ocr3config.
+const maxTransmissionStages = 999
+
+// TransmissionScheduleParams are the inputs to RecommendTransmissionSchedule.
+type TransmissionScheduleParams struct {
+	N int
+	// Desired expected number of successful transmission attempts per stage.
+	// Higher values make it more likely that a report is transmitted in the
+	// first stage, at the cost of more redundant transmissions.
+	Redundancy int
+	// Transmission of a report should start no later than MaxLatency after
+	// the report has been attested, even if all but one oracle fail.
+	MaxLatency time.Duration
+	DeltaStage time.Duration
+	// FailureProbabilities[i] is the probability that oracle i does not
+	// transmit when it's scheduled to, e.g. because it's down or out of funds.
+	FailureProbabilities []float64
+}
+
+func (p TransmissionScheduleParams) check() error {
+	if p.N < 1 || p.N > types.MaxOracles {
+		return fmt.Errorf("N (%v) must be between 1 and %v", p.N, types.MaxOracles)
+	}
+	if p.Redundancy < 1 || p.Redundancy > p.N {
+		return fmt.Errorf("Redundancy (%v) must be between 1 and N (%v)", p.Redundancy, p.N)
+	}
+	if p.MaxLatency < 0 {
+		return fmt.Errorf("MaxLatency (%v) must not be negative", p.MaxLatency)
+	}
+	if p.DeltaStage <= 0 {
+		return fmt.Errorf("DeltaStage (%v) must be positive", p.DeltaStage)
+	}
+	return checkFailureProbabilities(p.N, p.FailureProbabilities)
+}
+
+func checkFailureProbabilities(n int, failureProbabilities []float64) error {
+	if len(failureProbabilities) != n {
+		return fmt.Errorf("expected %v failure probabilities, got %v", n, len(failureProbabilities))
+	}
+	for i, p := range failureProbabilities {
+		if !(0 <= p && p <= 1) {
+			return fmt.Errorf("failure probability of oracle %v (%v) must be between 0 and 1", i, p)
+		}
+	}
+	return nil
+}
+
+// RecommendTransmissionSchedule recommends a transmission schedule S.
+//
+// Oracles are assigned to stages in a random order that changes with every
+// report, so a stage of size k contains on average k times the mean success
+// probability working oracles. Every stage is sized so that this expectation
+// reaches Redundancy. The schedule contains all N oracles, and has as few
+// stages as possible, at most as many as fit into MaxLatency: if the stages
+// don't fit, the last stage takes all remaining oracles.
+//
+// Use SimulateTransmissionSchedule to check how a schedule performs.
+func RecommendTransmissionSchedule(params TransmissionScheduleParams) ([]int, error) {
+	if err := params.check(); err != nil {
+		return nil, err
+	}
+
+	successProbabilitySum := 0.0
+	for _, p := range params.FailureProbabilities {
+		successProbabilitySum += 1 - p
+	}
+	if successProbabilitySum == 0 {
+		return nil, fmt.Errorf("all oracles have failure probability 1")
+	}
+	meanSuccessProbability := successProbabilitySum / float64(params.N)
+	stageSize := int(math.Ceil(float64(params.Redundancy) / meanSuccessProbability))
+	if stageSize > params.N {
+		stageSize = params.N
+	}
+
+	// Stage i starts i*DeltaStage after the report has been attested
+	maxStages := int64(params.MaxLatency/params.DeltaStage) + 1
+	if maxStages > maxTransmissionStages {
+		maxStages = maxTransmissionStages
+	}
+
+	s := []int{}
+	remaining := params.N
+	for remaining > 0 {
+		size := stageSize
+		if int64(len(s)) == maxStages-1 || size > remaining {
+			size = remaining
+		}
+		s = append(s, size)
+		remaining -= size
+	}
+	return s, nil
+}
+
+// TransmissionSimulationParams are the inputs to
+// SimulateTransmissionSchedule.
+type TransmissionSimulationParams struct {
+	S                    []int
+	DeltaStage           time.Duration
+	FailureProbabilities []float64 // see TransmissionScheduleParams
+	GasPerTransmission   uint64
+	Reports              int
+	// Source of randomness for the transmission order key and oracle
+	// failures. If nil, a fixed seed is used, so results are reproducible.
+	Rand *rand.Rand
+}
+
+// TransmissionSimulation is the result of SimulateTransmissionSchedule.
+type TransmissionSimulation struct {
+	Reports int
+	// AttemptsHistogram[k] is the number of reports for which k oracles
+	// attempted transmission.
+	AttemptsHistogram []int
+	MeanAttempts      float64
+	// StageHistogram[i] is the number of reports transmitted in stage i.
+	StageHistogram []int
+	// Mean delay between attestation of a report and the start of its
+	// transmission, over all transmitted reports
+	MeanLatency time.Duration
+	// Number of reports that no oracle transmitted
+	Untransmitted int
+	// Expected gas spent on transmissions per report, including redundant
+	// transmissions
+	ExpectedGas float64
+}
+
+// SimulateTransmissionSchedule simulates the transmission of Reports reports
+// under schedule S. The order of oracles is computed exactly like the OCR3
+// transmission protocol does it, by applying permutation.Permutation keyed
+// with an HMAC of a (random) transmission order key, seqNr and index.
+//
+// The simulation assumes that a transmission started in a stage is visible to
+// all oracles before the next stage starts, i.e. that DeltaStage exceeds the
+// time it takes for a transmission to be included onchain. All oracles of the
+// first stage that contains a working oracle thus attempt transmission, and
+// no oracle of a later stage does. Each attempt costs GasPerTransmission.
+func SimulateTransmissionSchedule(params TransmissionSimulationParams) (TransmissionSimulation, error) {
+	n := len(params.FailureProbabilities)
+	if n < 1 || n > types.MaxOracles {
+		return TransmissionSimulation{}, fmt.Errorf("number of failure probabilities (%v) must be between 1 and %v", n, types.MaxOracles)
+	}
+	if err := checkFailureProbabilities(n, params.FailureProbabilities); err != nil {
+		return TransmissionSimulation{}, err
+	}
+	if len(params.S) < 1 || len(params.S) > maxTransmissionStages {
+		return TransmissionSimulation{}, fmt.Errorf("len(S) (%v) must be between 1 and %v", len(params.S), maxTransmissionStages)
+	}
+	for i, s := range params.S {
+		if s < 0 || s > types.MaxOracles {
+			return TransmissionSimulation{}, fmt.Errorf("S[%v] (%v) must be between 0 and %v", i, s, types.MaxOracles)
+		}
+	}
+	if params.Reports < 1 {
+		return TransmissionSimulation{}, fmt.Errorf("Reports (%v) must be positive", params.Reports)
+	}
+	rng := params.Rand
+	if rng == nil {
+		rng = rand.New(rand.NewSource(0))
+	}
+
+	var transmissionOrderKey [16]byte
+	_, _ = rng.Read(transmissionOrderKey[:])
+
+	result := TransmissionSimulation{
+		params.Reports,
+		make([]int, n+1),
+		0,
+		make([]int, len(params.S)),
+		0,
+		0,
+		0,
+	}
+	totalAttempts := 0
+	var totalLatency time.Duration
+	for seqNr := uint64(1); seqNr <= uint64(params.Reports); seqNr++ {
+		pi := ocr3config.TransmissionPermutation(transmissionOrderKey, n, seqNr, 0)
+
+		// Oracles attempting transmission, by stage
+		attemptsByStage := make([]int, len(params.S))
+		for oracle, p := range params.FailureProbabilities {
+			working := rng.Float64() >= p
+			stage, ok := ocr3config.TransmissionStage(params.S, pi[oracle])
+			if working && ok {
+				attemptsByStage[stage]++
+			}
+		}
+
+		attempts := 0
+		for stage, stageAttempts := range attemptsByStage {
+			if stageAttempts > 0 {
+				attempts = stageAttempts
+				result.StageHistogram[stage]++
+				totalLatency += time.Duration(stage) * params.DeltaStage
+				break
+			}
+		}
+		if attempts == 0 {
+			result.Untransmitted++
+		}
+		result.AttemptsHistogram[attempts]++
+		totalAttempts += attempts
+	}
+
+	result.MeanAttempts = float64(totalAttempts) / float64(params.Reports)
+	if transmitted := params.Reports - result.Untransmitted; transmitted > 0 {
+		result.MeanLatency = totalLatency / time.Duration(transmitted)
+	}
+	result.ExpectedGas = result.MeanAttempts * float64(params.GasPerTransmission)
+	return result, nil
+}
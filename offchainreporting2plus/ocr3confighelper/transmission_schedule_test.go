@@ -0,0 +1,62 @@
+package ocr3confighelper
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestRecommendTransmissionSchedule(t *testing.T) {
+	for _, tc := range []struct {
+		name               string
+		maxLatency         time.Duration
+		failureProbability float64
+		expected           []int
+	}{
+		{"reliable oracles", time.Minute, 0, []int{2, 2, 2, 2, 2, 2, 1}},
+		{"unreliable oracles", time.Minute, 0.5, []int{4, 4, 4, 1}},
+		{"tight latency", 20 * time.Second, 0, []int{2, 2, 9}},
+		{"no latency", 0, 0, []int{13}},
+	} {
+		failureProbabilities := make([]float64, 13)
+		for i := range failureProbabilities {
+			failureProbabilities[i] = tc.failureProbability
+		}
+		s, err := RecommendTransmissionSchedule(TransmissionScheduleParams{13, 2, tc.maxLatency, 10 * time.Second, failureProbabilities})
+		if err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		if !reflect.DeepEqual(s, tc.expected) {
+			t.Errorf("%s: expected S = %v, got %v", tc.name, tc.expected, s)
+		}
+	}
+}
+
+func TestSimulateTransmissionSchedule(t *testing.T) {
+	// Without failures, exactly the oracles of the first stage transmit.
+	result, err := SimulateTransmissionSchedule(TransmissionSimulationParams{
+		[]int{2, 3, 4}, time.Second, make([]float64, 9), 100_000, 1000, nil,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.AttemptsHistogram[2] != 1000 || result.StageHistogram[0] != 1000 || result.MeanLatency != 0 || result.ExpectedGas != 200_000 {
+		t.Fatalf("unexpected result %+v", result)
+	}
+
+	// Oracle 0 never works. Since every oracle is equally likely to be
+	// scheduled in the first stage, it's in the first stage in about 1/n of
+	// the reports, in which case transmission happens in the second stage.
+	failureProbabilities := make([]float64, 4)
+	failureProbabilities[0] = 1
+	result, err = SimulateTransmissionSchedule(TransmissionSimulationParams{
+		[]int{1, 1, 1, 1}, time.Second, failureProbabilities, 1, 10_000, nil,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Untransmitted != 0 || result.StageHistogram[0]+result.StageHistogram[1] != 10_000 ||
+		result.StageHistogram[1] < 2300 || result.StageHistogram[1] > 2700 {
+		t.Fatalf("unexpected result %+v", result)
+	}
+}
@@ -0,0 +1,361 @@
+package ocrdb
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/smartcontractkit/libocr/offchainreporting2plus/ocr3types"
+	"github.com/smartcontractkit/libocr/offchainreporting2plus/types"
+)
+
+// FileDatabase is a durable implementation of types.Database and
+// ocr3types.Database that stores all data in a single directory. Every
+// update is written to a temporary file which is fsynced and then atomically
+// renamed into place, so a crash never leaves a partially written record
+// behind.
+//
+// The directory layout is:
+//
+//	config.json
+//	state/<configDigest>.json
+//	pending-transmissions/<configDigest>-<epoch>-<round>.json
+//	protocol-state/<configDigest>/<hex(sha256(key))>
+//
+// Only a single FileDatabase (and process) may use a directory at a time.
+// All its functions are thread-safe.
+type FileDatabase struct {
+	dir string
+	mu  sync.Mutex
+}
+
+var _ types.Database = (*FileDatabase)(nil)
+var _ ocr3types.Database = (*FileDatabase)(nil)
+
+const (
+	fileDatabaseConfigFile                          = "config.json"
+	fileDatabaseStateDir                            = "state"
+	fileDatabasePendingTransmissionsDir             = "pending-transmissions"
+	fileDatabaseProtocolStateDir                    = "protocol-state"
+	fileDatabaseDirPerm                 fs.FileMode = 0o700
+	fileDatabaseFilePerm                fs.FileMode = 0o600
+	fileDatabaseTempPrefix                          = ".tmp-"
+)
+
+// NewFileDatabase opens the database in dir, creating the directory if it
+// does not exist.
+func NewFileDatabase(dir string) (*FileDatabase, error) {
+	for _, sub := range []string{fileDatabaseStateDir, fileDatabasePendingTransmissionsDir, fileDatabaseProtocolStateDir} {
+		if err := os.MkdirAll(filepath.Join(dir, sub), fileDatabaseDirPerm); err != nil {
+			return nil, fmt.Errorf("could not create database directory: %w", err)
+		}
+	}
+	return &FileDatabase{dir: dir}, nil
+}
+
+func (db *FileDatabase) ReadConfig(ctx context.Context) (*types.ContractConfig, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	var record fileContractConfig
+	found, err := readJSON(filepath.Join(db.dir, fileDatabaseConfigFile), &record)
+	if err != nil || !found {
+		return nil, err
+	}
+	configDigest, err := parseConfigDigestHex(record.ConfigDigest)
+	if err != nil {
+		return nil, fmt.Errorf("could not decode stored config: %w", err)
+	}
+	return &types.ContractConfig{
+		configDigest,
+		record.ConfigCount,
+		record.Signers,
+		record.Transmitters,
+		record.F,
+		record.OnchainConfig,
+		record.OffchainConfigVersion,
+		record.OffchainConfig,
+	}, nil
+}
+
+func (db *FileDatabase) WriteConfig(ctx context.Context, config types.ContractConfig) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	return writeJSON(filepath.Join(db.dir, fileDatabaseConfigFile), fileContractConfig{
+		config.ConfigDigest.Hex(),
+		config.ConfigCount,
+		config.Signers,
+		config.Transmitters,
+		config.F,
+		config.OnchainConfig,
+		config.OffchainConfigVersion,
+		config.OffchainConfig,
+	})
+}
+
+// fileContractConfig is the on-disk representation of types.ContractConfig.
+// types.ConfigDigest cannot be unmarshaled from JSON, so we store it as hex.
+type fileContractConfig struct {
+	ConfigDigest          string
+	ConfigCount           uint64
+	Signers               []types.OnchainPublicKey
+	Transmitters          []types.Account
+	F                     uint8
+	OnchainConfig         []byte
+	OffchainConfigVersion uint64
+	OffchainConfig        []byte
+}
+
+func (db *FileDatabase) ReadState(ctx context.Context, configDigest types.ConfigDigest) (*types.PersistentState, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	var state types.PersistentState
+	found, err := readJSON(db.statePath(configDigest), &state)
+	if err != nil || !found {
+		return nil, err
+	}
+	return &state, nil
+}
+
+func (db *FileDatabase) WriteState(ctx context.Context, configDigest types.ConfigDigest, state types.PersistentState) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	return writeJSON(db.statePath(configDigest), state)
+}
+
+func (db *FileDatabase) StorePendingTransmission(ctx context.Context, ts types.ReportTimestamp, pt types.PendingTransmission) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	return writeJSON(db.pendingTransmissionPath(ts), pt)
+}
+
+func (db *FileDatabase) PendingTransmissionsWithConfigDigest(ctx context.Context, configDigest types.ConfigDigest) (map[types.ReportTimestamp]types.PendingTransmission, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	all, err := db.pendingTransmissions()
+	if err != nil {
+		return nil, err
+	}
+	result := map[types.ReportTimestamp]types.PendingTransmission{}
+	for ts, pt := range all {
+		if ts.ConfigDigest == configDigest {
+			result[ts] = pt
+		}
+	}
+	return result, nil
+}
+
+func (db *FileDatabase) DeletePendingTransmission(ctx context.Context, ts types.ReportTimestamp) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	return removeFile(db.pendingTransmissionPath(ts))
+}
+
+func (db *FileDatabase) DeletePendingTransmissionsOlderThan(ctx context.Context, t time.Time) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	all, err := db.pendingTransmissions()
+	if err != nil {
+		return err
+	}
+	for ts, pt := range all {
+		if pt.Time.Before(t) {
+			if err := removeFile(db.pendingTransmissionPath(ts)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (db *FileDatabase) ReadProtocolState(ctx context.Context, configDigest types.ConfigDigest, key string) ([]byte, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	value, err := os.ReadFile(db.protocolStatePath(configDigest, key))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not read protocol state: %w", err)
+	}
+	if value == nil {
+		value = []byte{}
+	}
+	return value, nil
+}
+
+func (db *FileDatabase) WriteProtocolState(ctx context.Context, configDigest types.ConfigDigest, key string, value []byte) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	path := db.protocolStatePath(configDigest, key)
+	if value == nil {
+		return removeFile(path)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), fileDatabaseDirPerm); err != nil {
+		return fmt.Errorf("could not create protocol state directory: %w", err)
+	}
+	return writeFileAtomic(path, value)
+}
+
+func (db *FileDatabase) statePath(configDigest types.ConfigDigest) string {
+	return filepath.Join(db.dir, fileDatabaseStateDir, configDigest.Hex()+".json")
+}
+
+func (db *FileDatabase) pendingTransmissionPath(ts types.ReportTimestamp) string {
+	return filepath.Join(db.dir, fileDatabasePendingTransmissionsDir,
+		fmt.Sprintf("%s-%d-%d.json", ts.ConfigDigest.Hex(), ts.Epoch, ts.Round))
+}
+
+func (db *FileDatabase) protocolStatePath(configDigest types.ConfigDigest, key string) string {
+	// keys are arbitrary strings of arbitrary length. Hashing them yields safe
+	// file names that stay well below the usual 255 byte limit.
+	keyHash := sha256.Sum256([]byte(key))
+	return filepath.Join(db.dir, fileDatabaseProtocolStateDir, configDigest.Hex(), hex.EncodeToString(keyHash[:]))
+}
+
+// pendingTransmissions must be called with db.mu held.
+func (db *FileDatabase) pendingTransmissions() (map[types.ReportTimestamp]types.PendingTransmission, error) {
+	dir := filepath.Join(db.dir, fileDatabasePendingTransmissionsDir)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("could not list pending transmissions: %w", err)
+	}
+	result := map[types.ReportTimestamp]types.PendingTransmission{}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || strings.HasPrefix(name, fileDatabaseTempPrefix) || !strings.HasSuffix(name, ".json") {
+			continue
+		}
+		ts, err := parsePendingTransmissionFileName(strings.TrimSuffix(name, ".json"))
+		if err != nil {
+			return nil, err
+		}
+		var pt types.PendingTransmission
+		found, err := readJSON(filepath.Join(dir, name), &pt)
+		if err != nil {
+			return nil, err
+		}
+		if found {
+			result[ts] = pt
+		}
+	}
+	return result, nil
+}
+
+func parsePendingTransmissionFileName(name string) (types.ReportTimestamp, error) {
+	parts := strings.Split(name, "-")
+	if len(parts) != 3 {
+		return types.ReportTimestamp{}, fmt.Errorf("malformed pending transmission file name %q", name)
+	}
+	configDigest, err := parseConfigDigestHex(parts[0])
+	if err != nil {
+		return types.ReportTimestamp{}, fmt.Errorf("malformed config digest in pending transmission file name %q: %w", name, err)
+	}
+	epoch, err := strconv.ParseUint(parts[1], 10, 32)
+	if err != nil {
+		return types.ReportTimestamp{}, fmt.Errorf("malformed epoch in pending transmission file name %q: %w", name, err)
+	}
+	round, err := strconv.ParseUint(parts[2], 10, 8)
+	if err != nil {
+		return types.ReportTimestamp{}, fmt.Errorf("malformed round in pending transmission file name %q: %w", name, err)
+	}
+	return types.ReportTimestamp{configDigest, uint32(epoch), uint8(round)}, nil
+}
+
+func parseConfigDigestHex(s string) (types.ConfigDigest, error) {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		return types.ConfigDigest{}, err
+	}
+	return types.BytesToConfigDigest(b)
+}
+
+func readJSON(path string, v interface{}) (found bool, err error) {
+	b, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("could not read %s: %w", path, err)
+	}
+	if err := json.Unmarshal(b, v); err != nil {
+		return false, fmt.Errorf("could not decode %s: %w", path, err)
+	}
+	return true, nil
+}
+
+func writeJSON(path string, v interface{}) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("could not encode %s: %w", path, err)
+	}
+	return writeFileAtomic(path, b)
+}
+
+// writeFileAtomic writes data to a temporary file in the same directory as
+// path, fsyncs it, renames it to path, and finally fsyncs the directory so
+// that the rename itself is durable.
+func writeFileAtomic(path string, data []byte) error {
+	dir := filepath.Dir(path)
+	tmp, err := os.CreateTemp(dir, fileDatabaseTempPrefix+filepath.Base(path)+"-")
+	if err != nil {
+		return fmt.Errorf("could not create temporary file: %w", err)
+	}
+	succeeded := false
+	defer func() {
+		if !succeeded {
+			_ = tmp.Close()
+			_ = os.Remove(tmp.Name())
+		}
+	}()
+
+	if err := tmp.Chmod(fileDatabaseFilePerm); err != nil {
+		return fmt.Errorf("could not chmod temporary file: %w", err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		return fmt.Errorf("could not write temporary file: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		return fmt.Errorf("could not fsync temporary file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("could not close temporary file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("could not rename temporary file: %w", err)
+	}
+	succeeded = true
+	return syncDir(dir)
+}
+
+func removeFile(path string) error {
+	err := os.Remove(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("could not remove %s: %w", path, err)
+	}
+	return syncDir(filepath.Dir(path))
+}
+
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return fmt.Errorf("could not open directory for fsync: %w", err)
+	}
+	defer d.Close()
+	if err := d.Sync(); err != nil {
+		return fmt.Errorf("could not fsync directory: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,189 @@
+// Package ocrdb contains reference implementations of the databases required
+// to run an oracle: types.Database for OCR2 and ocr3types.Database for OCR3
+// and Mercury.
+//
+// MemoryDatabase keeps everything in memory and is intended for tests.
+// FileDatabase persists everything to a single directory and is suitable for
+// small deployments.
+package ocrdb
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/smartcontractkit/libocr/offchainreporting2plus/ocr3types"
+	"github.com/smartcontractkit/libocr/offchainreporting2plus/types"
+)
+
+// MemoryDatabase is a thread-safe, in-memory implementation of types.Database
+// and ocr3types.Database. Its contents are lost when the process exits.
+type MemoryDatabase struct {
+	mu                   sync.Mutex
+	config               *types.ContractConfig
+	states               map[types.ConfigDigest]types.PersistentState
+	pendingTransmissions map[types.ReportTimestamp]types.PendingTransmission
+	protocolStates       map[types.ConfigDigest]map[string][]byte
+}
+
+var _ types.Database = (*MemoryDatabase)(nil)
+var _ ocr3types.Database = (*MemoryDatabase)(nil)
+
+func NewMemoryDatabase() *MemoryDatabase {
+	return &MemoryDatabase{
+		sync.Mutex{},
+		nil,
+		map[types.ConfigDigest]types.PersistentState{},
+		map[types.ReportTimestamp]types.PendingTransmission{},
+		map[types.ConfigDigest]map[string][]byte{},
+	}
+}
+
+func (db *MemoryDatabase) ReadConfig(ctx context.Context) (*types.ContractConfig, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if db.config == nil {
+		return nil, nil
+	}
+	config := copyContractConfig(*db.config)
+	return &config, nil
+}
+
+func (db *MemoryDatabase) WriteConfig(ctx context.Context, config types.ContractConfig) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	copied := copyContractConfig(config)
+	db.config = &copied
+	return nil
+}
+
+func (db *MemoryDatabase) ReadState(ctx context.Context, configDigest types.ConfigDigest) (*types.PersistentState, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	state, ok := db.states[configDigest]
+	if !ok {
+		return nil, nil
+	}
+	state = copyPersistentState(state)
+	return &state, nil
+}
+
+func (db *MemoryDatabase) WriteState(ctx context.Context, configDigest types.ConfigDigest, state types.PersistentState) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.states[configDigest] = copyPersistentState(state)
+	return nil
+}
+
+func (db *MemoryDatabase) StorePendingTransmission(ctx context.Context, ts types.ReportTimestamp, pt types.PendingTransmission) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.pendingTransmissions[ts] = copyPendingTransmission(pt)
+	return nil
+}
+
+func (db *MemoryDatabase) PendingTransmissionsWithConfigDigest(ctx context.Context, configDigest types.ConfigDigest) (map[types.ReportTimestamp]types.PendingTransmission, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	result := map[types.ReportTimestamp]types.PendingTransmission{}
+	for ts, pt := range db.pendingTransmissions {
+		if ts.ConfigDigest == configDigest {
+			result[ts] = copyPendingTransmission(pt)
+		}
+	}
+	return result, nil
+}
+
+func (db *MemoryDatabase) DeletePendingTransmission(ctx context.Context, ts types.ReportTimestamp) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	delete(db.pendingTransmissions, ts)
+	return nil
+}
+
+func (db *MemoryDatabase) DeletePendingTransmissionsOlderThan(ctx context.Context, t time.Time) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	for ts, pt := range db.pendingTransmissions {
+		if pt.Time.Before(t) {
+			delete(db.pendingTransmissions, ts)
+		}
+	}
+	return nil
+}
+
+func (db *MemoryDatabase) ReadProtocolState(ctx context.Context, configDigest types.ConfigDigest, key string) ([]byte, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	value, ok := db.protocolStates[configDigest][key]
+	if !ok {
+		return nil, nil
+	}
+	return copyBytes(value), nil
+}
+
+func (db *MemoryDatabase) WriteProtocolState(ctx context.Context, configDigest types.ConfigDigest, key string, value []byte) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if value == nil {
+		delete(db.protocolStates[configDigest], key)
+		if len(db.protocolStates[configDigest]) == 0 {
+			delete(db.protocolStates, configDigest)
+		}
+		return nil
+	}
+	if db.protocolStates[configDigest] == nil {
+		db.protocolStates[configDigest] = map[string][]byte{}
+	}
+	db.protocolStates[configDigest][key] = copyBytes(value)
+	return nil
+}
+
+func copyBytes(b []byte) []byte {
+	if b == nil {
+		return nil
+	}
+	return append([]byte{}, b...)
+}
+
+func copyContractConfig(c types.ContractConfig) types.ContractConfig {
+	signers := make([]types.OnchainPublicKey, 0, len(c.Signers))
+	for _, s := range c.Signers {
+		signers = append(signers, copyBytes(s))
+	}
+	return types.ContractConfig{
+		c.ConfigDigest,
+		c.ConfigCount,
+		signers,
+		append([]types.Account{}, c.Transmitters...),
+		c.F,
+		copyBytes(c.OnchainConfig),
+		c.OffchainConfigVersion,
+		copyBytes(c.OffchainConfig),
+	}
+}
+
+func copyPersistentState(s types.PersistentState) types.PersistentState {
+	return types.PersistentState{
+		s.Epoch,
+		s.HighestSentEpoch,
+		append([]uint32{}, s.HighestReceivedEpoch...),
+	}
+}
+
+func copyPendingTransmission(pt types.PendingTransmission) types.PendingTransmission {
+	return types.PendingTransmission{
+		pt.Time,
+		pt.ExtraHash,
+		copyBytes(pt.Report),
+		copyAttributedOnchainSignatures(pt.AttributedSignatures),
+	}
+}
+
+func copyAttributedOnchainSignatures(sigs []types.AttributedOnchainSignature) []types.AttributedOnchainSignature {
+	result := make([]types.AttributedOnchainSignature, 0, len(sigs))
+	for _, sig := range sigs {
+		result = append(result, types.AttributedOnchainSignature{copyBytes(sig.Signature), sig.Signer})
+	}
+	return result
+}
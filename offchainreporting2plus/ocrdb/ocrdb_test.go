@@ -0,0 +1,257 @@
+package ocrdb_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/smartcontractkit/libocr/commontypes"
+	"github.com/smartcontractkit/libocr/offchainreporting2plus/ocr3types"
+	"github.com/smartcontractkit/libocr/offchainreporting2plus/ocrdb"
+	"github.com/smartcontractkit/libocr/offchainreporting2plus/types"
+)
+
+type database interface {
+	types.Database
+	ocr3types.Database
+}
+
+var (
+	digest1 = types.ConfigDigest{1}
+	digest2 = types.ConfigDigest{2}
+
+	testConfig = types.ContractConfig{
+		digest1,
+		3,
+		[]types.OnchainPublicKey{{1, 2}, {3, 4}},
+		[]types.Account{"a", "b"},
+		1,
+		[]byte{5},
+		2,
+		[]byte{6, 7},
+	}
+	testState = types.PersistentState{4, 5, []uint32{4, 5, 3}}
+
+	ts1      = types.ReportTimestamp{digest1, 1, 1}
+	ts2      = types.ReportTimestamp{digest1, 2, 1}
+	ts3      = types.ReportTimestamp{digest2, 1, 1}
+	baseTime = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+)
+
+func testPendingTransmission(i int) types.PendingTransmission {
+	return types.PendingTransmission{
+		baseTime.Add(time.Duration(i) * time.Minute),
+		[32]byte{byte(i)},
+		types.Report{byte(i), 1},
+		[]types.AttributedOnchainSignature{{[]byte{byte(i), 2}, commontypes.OracleID(i)}},
+	}
+}
+
+// longKey is longer than file names may be on common file systems.
+var longKey = strings.Repeat("k", 1000)
+
+// populate writes data that checkPopulated expects to read back.
+func populate(t *testing.T, db database) {
+	t.Helper()
+	ctx := context.Background()
+	if err := db.WriteConfig(ctx, testConfig); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.WriteState(ctx, digest1, testState); err != nil {
+		t.Fatal(err)
+	}
+	for i, ts := range []types.ReportTimestamp{ts1, ts2, ts3} {
+		if err := db.StorePendingTransmission(ctx, ts, testPendingTransmission(i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for key, value := range map[string][]byte{
+		"key":   {1, 2, 3},
+		"empty": {},
+		longKey: {4},
+		"gone":  {5},
+	} {
+		if err := db.WriteProtocolState(ctx, digest1, key, value); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := db.WriteProtocolState(ctx, digest2, "key", []byte{6}); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.WriteProtocolState(ctx, digest1, "gone", nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.DeletePendingTransmission(ctx, ts2); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func checkPopulated(t *testing.T, db database) {
+	t.Helper()
+	ctx := context.Background()
+
+	config, err := db.ReadConfig(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config == nil || !reflect.DeepEqual(*config, testConfig) {
+		t.Fatalf("expected config %+v, got %+v", testConfig, config)
+	}
+
+	state, err := db.ReadState(ctx, digest1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state == nil || !reflect.DeepEqual(*state, testState) {
+		t.Fatalf("expected state %+v, got %+v", testState, state)
+	}
+	state, err = db.ReadState(ctx, digest2)
+	if err != nil || state != nil {
+		t.Fatalf("expected no state for other config digest, got %+v, %v", state, err)
+	}
+
+	pts, err := db.PendingTransmissionsWithConfigDigest(ctx, digest1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := map[types.ReportTimestamp]types.PendingTransmission{ts1: testPendingTransmission(0)}; !reflect.DeepEqual(pts, expected) {
+		t.Fatalf("expected pending transmissions %+v, got %+v", expected, pts)
+	}
+
+	for _, test := range []struct {
+		configDigest types.ConfigDigest
+		key          string
+		expected     []byte
+	}{
+		{digest1, "key", []byte{1, 2, 3}},
+		{digest1, "empty", []byte{}},
+		{digest1, longKey, []byte{4}},
+		{digest1, "gone", nil},
+		{digest1, "missing", nil},
+		{digest2, "key", []byte{6}},
+		{digest2, "empty", nil},
+	} {
+		value, err := db.ReadProtocolState(ctx, test.configDigest, test.key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// nil means absent, an empty slice means present but empty
+		if (value == nil) != (test.expected == nil) || !reflect.DeepEqual(value, test.expected) {
+			t.Errorf("%v/%.10s: expected protocol state %#v, got %#v", test.configDigest, test.key, test.expected, value)
+		}
+	}
+}
+
+func testDatabase(t *testing.T, db database) {
+	ctx := context.Background()
+
+	config, err := db.ReadConfig(ctx)
+	if err != nil || config != nil {
+		t.Fatalf("expected no config in empty database, got %+v, %v", config, err)
+	}
+	state, err := db.ReadState(ctx, digest1)
+	if err != nil || state != nil {
+		t.Fatalf("expected no state in empty database, got %+v, %v", state, err)
+	}
+
+	populate(t, db)
+	checkPopulated(t, db)
+
+	// Mutating what was read or written must not affect what is stored
+	config, err = db.ReadConfig(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config.Signers[0][0]++
+	value, err := db.ReadProtocolState(ctx, digest1, "key")
+	if err != nil {
+		t.Fatal(err)
+	}
+	value[0]++
+	checkPopulated(t, db)
+
+	// Overwrite
+	if err := db.WriteProtocolState(ctx, digest1, "key", []byte{7}); err != nil {
+		t.Fatal(err)
+	}
+	if value, err := db.ReadProtocolState(ctx, digest1, "key"); err != nil || !reflect.DeepEqual(value, []byte{7}) {
+		t.Fatalf("expected overwritten protocol state, got %v, %v", value, err)
+	}
+	newState := types.PersistentState{6, 6, []uint32{6, 6, 6}}
+	if err := db.WriteState(ctx, digest1, newState); err != nil {
+		t.Fatal(err)
+	}
+	if state, err := db.ReadState(ctx, digest1); err != nil || !reflect.DeepEqual(*state, newState) {
+		t.Fatalf("expected overwritten state, got %+v, %v", state, err)
+	}
+
+	// Deleting what doesn't exist is not an error
+	if err := db.WriteProtocolState(ctx, digest2, "missing", nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.DeletePendingTransmission(ctx, ts2); err != nil {
+		t.Fatal(err)
+	}
+
+	// ts1 has time baseTime, ts3 has time baseTime+2min
+	if err := db.DeletePendingTransmissionsOlderThan(ctx, baseTime.Add(time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	for configDigest, expected := range map[types.ConfigDigest]map[types.ReportTimestamp]types.PendingTransmission{
+		digest1: {},
+		digest2: {ts3: testPendingTransmission(2)},
+	} {
+		pts, err := db.PendingTransmissionsWithConfigDigest(ctx, configDigest)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(pts, expected) {
+			t.Fatalf("expected pending transmissions %+v, got %+v", expected, pts)
+		}
+	}
+}
+
+func TestMemoryDatabase(t *testing.T) {
+	testDatabase(t, ocrdb.NewMemoryDatabase())
+}
+
+func TestFileDatabase(t *testing.T) {
+	db, err := ocrdb.NewFileDatabase(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	testDatabase(t, db)
+}
+
+func TestFileDatabasePersistsAcrossRestarts(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "db")
+	db, err := ocrdb.NewFileDatabase(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	populate(t, db)
+
+	reopened, err := ocrdb.NewFileDatabase(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkPopulated(t, reopened)
+
+	// No temporary files are left behind
+	err = filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if strings.HasPrefix(info.Name(), ".tmp-") {
+			t.Errorf("temporary file %v left behind", path)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,107 @@
+// Package offchainkeyring provides a reference implementation of
+// types.OffchainKeyring whose secret keys can be persisted to disk, encrypted
+// with a passphrase.
+package offchainkeyring
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"fmt"
+	"io"
+
+	"golang.org/x/crypto/curve25519"
+
+	"github.com/smartcontractkit/libocr/offchainreporting2plus/confighelper"
+	"github.com/smartcontractkit/libocr/offchainreporting2plus/types"
+)
+
+var _ types.OffchainKeyring = (*Keyring)(nil)
+
+// Keyring holds an Ed25519 keypair for signing offchain messages and an
+// X25519 keypair for decrypting the shared secret in the offchain config.
+//
+// All its functions are thread-safe.
+type Keyring struct {
+	signingKey    ed25519.PrivateKey
+	encryptionKey [curve25519.ScalarSize]byte
+}
+
+// New generates a new Keyring with randomness from rng. If rng is nil,
+// crypto/rand.Reader is used.
+func New(rng io.Reader) (*Keyring, error) {
+	if rng == nil {
+		rng = rand.Reader
+	}
+	_, signingKey, err := ed25519.GenerateKey(rng)
+	if err != nil {
+		return nil, fmt.Errorf("could not generate signing key: %w", err)
+	}
+	var encryptionKey [curve25519.ScalarSize]byte
+	if _, err := io.ReadFull(rng, encryptionKey[:]); err != nil {
+		return nil, fmt.Errorf("could not generate config encryption key: %w", err)
+	}
+	return &Keyring{signingKey, encryptionKey}, nil
+}
+
+// FromSeeds reconstructs a Keyring from its secret material: the 32 byte
+// Ed25519 seed and the 32 byte X25519 scalar.
+func FromSeeds(signingKeySeed []byte, encryptionKey []byte) (*Keyring, error) {
+	if len(signingKeySeed) != ed25519.SeedSize {
+		return nil, fmt.Errorf("signing key seed has wrong length, expected %v, got %v", ed25519.SeedSize, len(signingKeySeed))
+	}
+	if len(encryptionKey) != curve25519.ScalarSize {
+		return nil, fmt.Errorf("config encryption key has wrong length, expected %v, got %v", curve25519.ScalarSize, len(encryptionKey))
+	}
+	k := &Keyring{ed25519.NewKeyFromSeed(signingKeySeed), [curve25519.ScalarSize]byte{}}
+	copy(k.encryptionKey[:], encryptionKey)
+	return k, nil
+}
+
+func (k *Keyring) OffchainSign(msg []byte) (signature []byte, err error) {
+	return ed25519.Sign(k.signingKey, msg), nil
+}
+
+func (k *Keyring) ConfigDiffieHellman(point [curve25519.PointSize]byte) (sharedPoint [curve25519.PointSize]byte, err error) {
+	shared, err := curve25519.X25519(k.encryptionKey[:], point[:])
+	if err != nil {
+		return sharedPoint, err
+	}
+	copy(sharedPoint[:], shared)
+	return sharedPoint, nil
+}
+
+func (k *Keyring) OffchainPublicKey() types.OffchainPublicKey {
+	var pk types.OffchainPublicKey
+	copy(pk[:], k.signingKey.Public().(ed25519.PublicKey))
+	return pk
+}
+
+func (k *Keyring) ConfigEncryptionPublicKey() types.ConfigEncryptionPublicKey {
+	pk, err := curve25519.X25519(k.encryptionKey[:], curve25519.Basepoint)
+	if err != nil {
+		// assertion
+		panic(fmt.Sprintf("could not compute config encryption public key: %v", err))
+	}
+	var result types.ConfigEncryptionPublicKey
+	copy(result[:], pk)
+	return result
+}
+
+// OracleIdentityExtra combines the keyring's public keys with the given
+// onchain identity, peer ID and transmit account into the format expected by
+// the functions in confighelper.
+func (k *Keyring) OracleIdentityExtra(
+	onchainPublicKey types.OnchainPublicKey,
+	peerID string,
+	transmitAccount types.Account,
+) confighelper.OracleIdentityExtra {
+	return confighelper.OracleIdentityExtra{
+		confighelper.OracleIdentity{
+			k.OffchainPublicKey(),
+			onchainPublicKey,
+			peerID,
+			transmitAccount,
+		},
+		k.ConfigEncryptionPublicKey(),
+	}
+}
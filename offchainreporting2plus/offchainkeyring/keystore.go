@@ -0,0 +1,206 @@
+package offchainkeyring
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"golang.org/x/crypto/curve25519"
+	"golang.org/x/crypto/scrypt"
+)
+
+// ScryptParams are the parameters of the key derivation function used to
+// turn a passphrase into an encryption key.
+type ScryptParams struct {
+	N int
+	R int
+	P int
+}
+
+// DefaultScryptParams are suitable for keys used in production. Deriving a
+// key takes on the order of a second.
+var DefaultScryptParams = ScryptParams{1 << 18, 8, 1}
+
+// FastScryptParams are cheap to compute and only suitable for tests.
+var FastScryptParams = ScryptParams{1 << 10, 8, 1}
+
+// check rejects parameters above DefaultScryptParams. The parameters are read
+// from the keystore before it is authenticated, so without an upper bound a
+// crafted keystore could make Decrypt use arbitrary amounts of memory and CPU.
+func (p ScryptParams) check() error {
+	if p.N <= 0 || p.R <= 0 || p.P <= 0 {
+		return fmt.Errorf("scrypt parameters %+v must be positive", p)
+	}
+	if p.N > DefaultScryptParams.N || p.R > DefaultScryptParams.R || p.P > DefaultScryptParams.P {
+		return fmt.Errorf("scrypt parameters %+v exceed maximum %+v", p, DefaultScryptParams)
+	}
+	return nil
+}
+
+const (
+	keystoreVersion = 1
+	saltSize        = 32
+	derivedKeySize  = 32 // AES-256
+)
+
+// keystoreJSON is the on-disk format of an encrypted Keyring. The public keys
+// are stored in plain text so that they can be read without the passphrase;
+// they are authenticated as additional data of the AES-GCM ciphertext.
+type keystoreJSON struct {
+	Version                   int
+	OffchainPublicKey         string
+	ConfigEncryptionPublicKey string
+	Scrypt                    ScryptParams
+	Salt                      string
+	Nonce                     string
+	Ciphertext                string
+}
+
+// Encrypt serializes the keyring's secret keys, encrypted with AES-256-GCM
+// under a key derived from passphrase with scrypt. params must not exceed
+// DefaultScryptParams.
+func (k *Keyring) Encrypt(passphrase string, params ScryptParams) ([]byte, error) {
+	salt := make([]byte, saltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, fmt.Errorf("could not generate salt: %w", err)
+	}
+	aead, err := newAEAD(passphrase, salt, params)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("could not generate nonce: %w", err)
+	}
+
+	offchainPublicKey, configEncryptionPublicKey := k.OffchainPublicKey(), k.ConfigEncryptionPublicKey()
+	ks := keystoreJSON{
+		keystoreVersion,
+		hex.EncodeToString(offchainPublicKey[:]),
+		hex.EncodeToString(configEncryptionPublicKey[:]),
+		params,
+		hex.EncodeToString(salt),
+		hex.EncodeToString(nonce),
+		"",
+	}
+	plaintext := append(append([]byte{}, k.signingKey.Seed()...), k.encryptionKey[:]...)
+	ks.Ciphertext = hex.EncodeToString(aead.Seal(nil, nonce, plaintext, additionalData(ks)))
+	return json.MarshalIndent(ks, "", "  ")
+}
+
+// Decrypt is the inverse of Encrypt. It fails if the passphrase is wrong or
+// the data has been tampered with.
+func Decrypt(data []byte, passphrase string) (*Keyring, error) {
+	var ks keystoreJSON
+	if err := json.Unmarshal(data, &ks); err != nil {
+		return nil, fmt.Errorf("could not parse keystore: %w", err)
+	}
+	if ks.Version != keystoreVersion {
+		return nil, fmt.Errorf("unsupported keystore version %v", ks.Version)
+	}
+	salt, err := hex.DecodeString(ks.Salt)
+	if err != nil {
+		return nil, fmt.Errorf("could not decode salt: %w", err)
+	}
+	nonce, err := hex.DecodeString(ks.Nonce)
+	if err != nil {
+		return nil, fmt.Errorf("could not decode nonce: %w", err)
+	}
+	ciphertext, err := hex.DecodeString(ks.Ciphertext)
+	if err != nil {
+		return nil, fmt.Errorf("could not decode ciphertext: %w", err)
+	}
+	aead, err := newAEAD(passphrase, salt, ks.Scrypt)
+	if err != nil {
+		return nil, err
+	}
+	if len(nonce) != aead.NonceSize() {
+		return nil, fmt.Errorf("nonce has wrong length, expected %v, got %v", aead.NonceSize(), len(nonce))
+	}
+	plaintext, err := aead.Open(nil, nonce, ciphertext, additionalData(ks))
+	if err != nil {
+		return nil, fmt.Errorf("could not decrypt keystore, wrong passphrase or corrupted data")
+	}
+	if len(plaintext) != ed25519.SeedSize+curve25519.ScalarSize {
+		return nil, fmt.Errorf("decrypted keystore has wrong length")
+	}
+	k, err := FromSeeds(plaintext[:ed25519.SeedSize], plaintext[ed25519.SeedSize:])
+	if err != nil {
+		return nil, err
+	}
+	offchainPublicKey, configEncryptionPublicKey := k.OffchainPublicKey(), k.ConfigEncryptionPublicKey()
+	if hex.EncodeToString(offchainPublicKey[:]) != ks.OffchainPublicKey ||
+		hex.EncodeToString(configEncryptionPublicKey[:]) != ks.ConfigEncryptionPublicKey {
+		// assertion, the public keys are authenticated
+		return nil, fmt.Errorf("public keys in keystore do not match secret keys")
+	}
+	return k, nil
+}
+
+// WriteFile encrypts the keyring and writes it to path with permissions 0600.
+// The file is replaced atomically.
+func (k *Keyring) WriteFile(path string, passphrase string, params ScryptParams) error {
+	data, err := k.Encrypt(passphrase, params)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-"+filepath.Base(path)+"-")
+	if err != nil {
+		return fmt.Errorf("could not create temporary file: %w", err)
+	}
+	defer os.Remove(tmp.Name()) // no-op after successful rename
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("could not write keystore: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("could not fsync keystore: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("could not close keystore: %w", err)
+	}
+	// os.CreateTemp already creates the file with permissions 0600
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("could not rename keystore into place: %w", err)
+	}
+	return nil
+}
+
+// ReadFile reads and decrypts a keyring written by WriteFile.
+func ReadFile(path string, passphrase string) (*Keyring, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read keystore: %w", err)
+	}
+	return Decrypt(data, passphrase)
+}
+
+func newAEAD(passphrase string, salt []byte, params ScryptParams) (cipher.AEAD, error) {
+	if err := params.check(); err != nil {
+		return nil, err
+	}
+	key, err := scrypt.Key([]byte(passphrase), salt, params.N, params.R, params.P, derivedKeySize)
+	if err != nil {
+		return nil, fmt.Errorf("could not derive key from passphrase: %w", err)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// additionalData binds the unencrypted fields of the keystore to the
+// ciphertext.
+func additionalData(ks keystoreJSON) []byte {
+	return []byte(fmt.Sprintf("ocr2 offchain keyring v%d|%s|%s|%d,%d,%d|%s",
+		ks.Version, ks.OffchainPublicKey, ks.ConfigEncryptionPublicKey,
+		ks.Scrypt.N, ks.Scrypt.R, ks.Scrypt.P, ks.Salt))
+}
@@ -0,0 +1,175 @@
+package offchainkeyring_test
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/smartcontractkit/libocr/offchainreporting2plus/offchainkeyring"
+)
+
+// seededKeyring returns a keyring with fixed secret keys.
+func seededKeyring(t *testing.T) *offchainkeyring.Keyring {
+	t.Helper()
+	k, err := offchainkeyring.FromSeeds(bytes.Repeat([]byte{1}, 32), bytes.Repeat([]byte{2}, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return k
+}
+
+func requireSameKeys(t *testing.T, expected, actual *offchainkeyring.Keyring) {
+	t.Helper()
+	if expected.OffchainPublicKey() != actual.OffchainPublicKey() || expected.ConfigEncryptionPublicKey() != actual.ConfigEncryptionPublicKey() {
+		t.Fatalf("decrypted keyring has different keys")
+	}
+	msg := []byte("message")
+	expectedSig, err := expected.OffchainSign(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	actualSig, err := actual.OffchainSign(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Ed25519 signatures are deterministic
+	if !bytes.Equal(expectedSig, actualSig) {
+		t.Fatalf("decrypted keyring signs differently")
+	}
+}
+
+func TestKeystoreRoundTrip(t *testing.T) {
+	k := seededKeyring(t)
+	data, err := k.Encrypt("passphrase", offchainkeyring.FastScryptParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decrypted, err := offchainkeyring.Decrypt(data, "passphrase")
+	if err != nil {
+		t.Fatal(err)
+	}
+	requireSameKeys(t, k, decrypted)
+
+	path := filepath.Join(t.TempDir(), "keystore.json")
+	if err := k.WriteFile(path, "passphrase", offchainkeyring.FastScryptParams); err != nil {
+		t.Fatal(err)
+	}
+	read, err := offchainkeyring.ReadFile(path, "passphrase")
+	if err != nil {
+		t.Fatal(err)
+	}
+	requireSameKeys(t, k, read)
+
+	if _, err := offchainkeyring.Decrypt(data, "wrong passphrase"); err == nil {
+		t.Fatalf("expected error for wrong passphrase")
+	}
+}
+
+// modifyKeystore applies modify to the JSON fields of the keystore in data.
+func modifyKeystore(t *testing.T, data []byte, modify func(map[string]interface{})) []byte {
+	t.Helper()
+	var fields map[string]interface{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		t.Fatal(err)
+	}
+	modify(fields)
+	modified, err := json.Marshal(fields)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return modified
+}
+
+// flipHex flips the lowest bit of the first byte of a hex string.
+func flipHex(t *testing.T, s interface{}) string {
+	t.Helper()
+	b, err := hex.DecodeString(s.(string))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b[0] ^= 1
+	return hex.EncodeToString(b)
+}
+
+func TestKeystoreRejectsTampering(t *testing.T) {
+	k := seededKeyring(t)
+	other, err := offchainkeyring.FromSeeds(bytes.Repeat([]byte{3}, 32), bytes.Repeat([]byte{4}, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := k.Encrypt("passphrase", offchainkeyring.FastScryptParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherData, err := other.Encrypt("passphrase", offchainkeyring.FastScryptParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var otherFields map[string]interface{}
+	if err := json.Unmarshal(otherData, &otherFields); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, modify := range map[string]func(map[string]interface{}){
+		"ciphertext": func(f map[string]interface{}) { f["Ciphertext"] = flipHex(t, f["Ciphertext"]) },
+		"truncated ciphertext": func(f map[string]interface{}) {
+			f["Ciphertext"] = f["Ciphertext"].(string)[:len(f["Ciphertext"].(string))-2]
+		},
+		"nonce": func(f map[string]interface{}) { f["Nonce"] = flipHex(t, f["Nonce"]) },
+		// The remaining fields are authenticated as additional data
+		"offchain public key": func(f map[string]interface{}) { f["OffchainPublicKey"] = otherFields["OffchainPublicKey"] },
+		"config encryption public key": func(f map[string]interface{}) {
+			f["ConfigEncryptionPublicKey"] = otherFields["ConfigEncryptionPublicKey"]
+		},
+		"salt": func(f map[string]interface{}) { f["Salt"] = flipHex(t, f["Salt"]) },
+		"scrypt parameters": func(f map[string]interface{}) {
+			f["Scrypt"] = offchainkeyring.ScryptParams{offchainkeyring.FastScryptParams.N * 2, 8, 1}
+		},
+		"version":      func(f map[string]interface{}) { f["Version"] = 2 },
+		"invalid hex":  func(f map[string]interface{}) { f["Salt"] = "zz" },
+		"short nonce":  func(f map[string]interface{}) { f["Nonce"] = "00" },
+		"missing salt": func(f map[string]interface{}) { delete(f, "Salt") },
+	} {
+		if _, err := offchainkeyring.Decrypt(modifyKeystore(t, data, modify), "passphrase"); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+
+	if _, err := offchainkeyring.Decrypt([]byte("not json"), "passphrase"); err == nil {
+		t.Errorf("expected error for invalid JSON")
+	}
+}
+
+func TestKeystoreRejectsExpensiveScryptParams(t *testing.T) {
+	k := seededKeyring(t)
+	data, err := k.Encrypt("passphrase", offchainkeyring.FastScryptParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, params := range []offchainkeyring.ScryptParams{
+		// 128 * N * r bytes = 1 TiB
+		{1 << 30, 8, 1},
+		{offchainkeyring.DefaultScryptParams.N, 1 << 20, 1},
+		{offchainkeyring.DefaultScryptParams.N, 8, 1 << 20},
+		{0, 8, 1},
+		{1 << 10, -1, 1},
+	} {
+		start := time.Now()
+		modified := modifyKeystore(t, data, func(f map[string]interface{}) { f["Scrypt"] = params })
+		_, err := offchainkeyring.Decrypt(modified, "passphrase")
+		if err == nil || !strings.Contains(err.Error(), "scrypt parameters") {
+			t.Errorf("%+v: expected scrypt parameters error, got %v", params, err)
+		}
+		if elapsed := time.Since(start); elapsed > time.Second {
+			t.Errorf("%+v: rejecting parameters took %v", params, elapsed)
+		}
+		if _, err := k.Encrypt("passphrase", params); err == nil {
+			t.Errorf("%+v: expected Encrypt to reject parameters", params)
+		}
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"sync"
 
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/smartcontractkit/libocr/clock"
 	"github.com/smartcontractkit/libocr/commontypes"
 	"github.com/smartcontractkit/libocr/internal/loghelper"
 	"github.com/smartcontractkit/libocr/offchainreporting2plus/internal/managed"
@@ -142,6 +143,11 @@ type MercuryOracleArgs struct {
 	// ReportingPluginFactory creates ReportingPlugins that determine the
 	// "application logic" used in an OCR protocol instance.
 	MercuryPluginFactory ocr3types.MercuryPluginFactory
+
+	// Clock drives the protocol's timers. This may be nil, in which case the
+	// wall clock is used. Tests may pass a *clock.VirtualClock to simulate the
+	// protocol deterministically and faster than real time.
+	Clock clock.Clock
 }
 
 func (MercuryOracleArgs) oracleArgsMarker() {}
@@ -155,6 +161,7 @@ func (args MercuryOracleArgs) runManaged(ctx context.Context) {
 		ctx,
 
 		args.V2Bootstrappers,
+		clock.OrReal(args.Clock),
 		args.ContractConfigTracker,
 		args.ContractTransmitter,
 		args.Database,
@@ -215,6 +222,11 @@ type OCR3OracleArgs[RI any] struct {
 	// PluginFactory creates Plugins that determine the "application logic" used
 	// in a protocol instance.
 	ReportingPluginFactory ocr3types.ReportingPluginFactory[RI]
+
+	// Clock drives the protocol's timers. This may be nil, in which case the
+	// wall clock is used. Tests may pass a *clock.VirtualClock to simulate the
+	// protocol deterministically and faster than real time.
+	Clock clock.Clock
 }
 
 func (OCR3OracleArgs[RI]) oracleArgsMarker() {}
@@ -228,6 +240,7 @@ func (args OCR3OracleArgs[RI]) runManaged(ctx context.Context) {
 		ctx,
 
 		args.V2Bootstrappers,
+		clock.OrReal(args.Clock),
 		args.ContractConfigTracker,
 		args.ContractTransmitter,
 		args.Database,
@@ -0,0 +1,449 @@
+package remotesigner
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/curve25519"
+
+	"github.com/smartcontractkit/libocr/internal/mtls"
+	"github.com/smartcontractkit/libocr/offchainreporting2plus/ocr3types"
+	"github.com/smartcontractkit/libocr/offchainreporting2plus/types"
+)
+
+// Default number of idle connections a Client keeps open.
+const defaultMaxIdleConnections = 4
+
+// ClientConfig configures a Client.
+type ClientConfig struct {
+	// Dial opens a connection to the server. See DialUnix and
+	// DialLoopbackTCP.
+	Dial func(ctx context.Context) (net.Conn, error)
+
+	// Identity is the client's Ed25519 key. Its public key must be among the
+	// server's AuthorizedClients.
+	Identity ed25519.PrivateKey
+
+	// ServerPublicKey is the pinned identity public key of the server.
+	ServerPublicKey IdentityPublicKey
+
+	// Timeout bounds each operation, including dialing and the TLS handshake
+	// if no idle connection is available. The keyrings are called from
+	// within the protocol, so Timeout should be a fraction of the smallest
+	// MaxDuration* budget of the config; see TimeoutFromBudgets.
+	Timeout time.Duration
+
+	// MaxIdleConnections bounds the number of connections kept open between
+	// operations. Operations running concurrently use separate connections.
+	// Defaults to 4 if zero.
+	MaxIdleConnections int
+}
+
+// TimeoutFromBudgets returns a Timeout for ClientConfig that leaves ample
+// room in the smallest positive budget, e.g. the MaxDuration* values of a
+// PublicConfig. Budgets that are not positive are ignored. If no budget is
+// positive, TimeoutFromBudgets returns zero.
+func TimeoutFromBudgets(budgets ...time.Duration) time.Duration {
+	var min time.Duration
+	for _, b := range budgets {
+		if b > 0 && (min == 0 || b < min) {
+			min = b
+		}
+	}
+	return min / 4
+}
+
+// DialUnix returns a dial function for ClientConfig that connects to the
+// Unix socket at path.
+func DialUnix(path string) func(ctx context.Context) (net.Conn, error) {
+	return func(ctx context.Context) (net.Conn, error) {
+		var d net.Dialer
+		return d.DialContext(ctx, "unix", path)
+	}
+}
+
+// DialLoopbackTCP returns a dial function for ClientConfig that connects to
+// addr over TCP. addr must resolve to a loopback address.
+func DialLoopbackTCP(addr string) func(ctx context.Context) (net.Conn, error) {
+	return func(ctx context.Context) (net.Conn, error) {
+		tcpAddr, err := net.ResolveTCPAddr("tcp", addr)
+		if err != nil {
+			return nil, err
+		}
+		if err := checkLocalAddr(tcpAddr); err != nil {
+			return nil, err
+		}
+		var d net.Dialer
+		return d.DialContext(ctx, "tcp", tcpAddr.String())
+	}
+}
+
+// Client talks to a remote signer Server. Its keyrings forward every
+// operation involving private keys to the server. All its functions are
+// thread-safe.
+type Client struct {
+	dial      func(ctx context.Context) (net.Conn, error)
+	tlsConfig *tls.Config
+	timeout   time.Duration
+
+	idle chan *tls.Conn
+
+	closeOnce sync.Once
+	closed    chan struct{}
+
+	// public keys, fetched once by NewClient
+	offchain *offchainPublicKeys
+	onchain  *onchainPublicKey
+	ocr3     *onchainPublicKey
+}
+
+type offchainPublicKeys struct {
+	offchainPublicKey         types.OffchainPublicKey
+	configEncryptionPublicKey types.ConfigEncryptionPublicKey
+}
+
+type onchainPublicKey struct {
+	publicKey          types.OnchainPublicKey
+	maxSignatureLength int
+}
+
+// NewClient connects to the server and fetches the public keys of its
+// keyrings.
+func NewClient(ctx context.Context, config ClientConfig) (*Client, error) {
+	if config.Dial == nil {
+		return nil, fmt.Errorf("dial must not be nil")
+	}
+	if len(config.Identity) != ed25519.PrivateKeySize {
+		return nil, fmt.Errorf("identity has wrong length, expected %v, got %v", ed25519.PrivateKeySize, len(config.Identity))
+	}
+	if config.Timeout <= 0 {
+		return nil, fmt.Errorf("timeout must be positive, got %v", config.Timeout)
+	}
+	if config.MaxIdleConnections < 0 {
+		return nil, fmt.Errorf("max idle connections must not be negative, got %v", config.MaxIdleConnections)
+	}
+	maxIdleConnections := config.MaxIdleConnections
+	if maxIdleConnections == 0 {
+		maxIdleConnections = defaultMaxIdleConnections
+	}
+
+	c := &Client{
+		config.Dial,
+		newTLSConfig(config.Identity, mtls.VerifyCertMatchesPubKey(config.ServerPublicKey)),
+		config.Timeout,
+		make(chan *tls.Conn, maxIdleConnections),
+		sync.Once{},
+		make(chan struct{}),
+		nil,
+		nil,
+		nil,
+	}
+
+	var e encoder
+	e.uint8(uint8(methodPublicKeys))
+	callCtx, cancel := context.WithTimeout(ctx, c.timeout)
+	results, err := c.call(callCtx, e.buf)
+	cancel()
+	if err != nil {
+		c.Close()
+		return nil, fmt.Errorf("could not fetch public keys: %w", err)
+	}
+	d := decoder{results, nil}
+	if d.bool() {
+		c.offchain = &offchainPublicKeys{}
+		d.fixed(c.offchain.offchainPublicKey[:])
+		d.fixed(c.offchain.configEncryptionPublicKey[:])
+	}
+	if d.bool() {
+		c.onchain = &onchainPublicKey{d.bytes(), int(d.uint32())}
+	}
+	if d.bool() {
+		c.ocr3 = &onchainPublicKey{d.bytes(), int(d.uint32())}
+	}
+	if err := d.finish(); err != nil {
+		c.Close()
+		return nil, fmt.Errorf("could not decode public keys: %w", err)
+	}
+	return c, nil
+}
+
+// Close closes all idle connections. Operations started after Close fail.
+func (c *Client) Close() error {
+	c.closeOnce.Do(func() {
+		close(c.closed)
+		for {
+			select {
+			case conn := <-c.idle:
+				conn.Close()
+			default:
+				return
+			}
+		}
+	})
+	return nil
+}
+
+// OffchainKeyring returns a types.OffchainKeyring backed by the server, or an
+// error if the server has no offchain keyring.
+func (c *Client) OffchainKeyring() (types.OffchainKeyring, error) {
+	if c.offchain == nil {
+		return nil, fmt.Errorf("server has no offchain keyring")
+	}
+	return &offchainKeyring{c}, nil
+}
+
+// OnchainKeyring returns a types.OnchainKeyring backed by the server, or an
+// error if the server has no onchain keyring. Signing happens on the server,
+// verification happens locally with verify, which must match the server's
+// keyring, e.g. evmutil.VerifyReportSignature.
+func (c *Client) OnchainKeyring(
+	verify func(pk types.OnchainPublicKey, repctx types.ReportContext, report types.Report, signature []byte) bool,
+) (types.OnchainKeyring, error) {
+	if c.onchain == nil {
+		return nil, fmt.Errorf("server has no onchain keyring")
+	}
+	if verify == nil {
+		return nil, fmt.Errorf("verify must not be nil")
+	}
+	return &onchainKeyring{c, verify}, nil
+}
+
+// NewOCR3OnchainKeyring returns an ocr3types.OnchainKeyring backed by the
+// server, or an error if the server has no OCR3 onchain keyring. encodeInfo
+// serializes the report info for the server's keyring; if it is nil, the info
+// is not transmitted. Like for Client.OnchainKeyring, verification happens
+// locally with verify, e.g. evmutil.VerifyOCR3ReportSignature[RI].
+func NewOCR3OnchainKeyring[RI any](
+	c *Client,
+	encodeInfo func(RI) ([]byte, error),
+	verify func(pk types.OnchainPublicKey, configDigest types.ConfigDigest, seqNr uint64, rwi ocr3types.ReportWithInfo[RI], signature []byte) bool,
+) (ocr3types.OnchainKeyring[RI], error) {
+	if c.ocr3 == nil {
+		return nil, fmt.Errorf("server has no OCR3 onchain keyring")
+	}
+	if verify == nil {
+		return nil, fmt.Errorf("verify must not be nil")
+	}
+	if encodeInfo == nil {
+		encodeInfo = func(RI) ([]byte, error) { return nil, nil }
+	}
+	return &ocr3OnchainKeyring[RI]{c, encodeInfo, verify}, nil
+}
+
+// invoke performs a request with the client's timeout.
+func (c *Client) invoke(request []byte) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+	return c.call(ctx, request)
+}
+
+// call sends request to the server and returns the results of a successful
+// response. If anything goes wrong, the connection is discarded, so that a
+// connection never has an outstanding response when it is reused.
+func (c *Client) call(ctx context.Context, request []byte) ([]byte, error) {
+	select {
+	case <-c.closed:
+		return nil, fmt.Errorf("client is closed")
+	default:
+	}
+
+	conn, err := c.getConn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		if err := conn.SetDeadline(deadline); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+
+	response, err := func() ([]byte, error) {
+		if err := writeFrame(conn, request); err != nil {
+			return nil, err
+		}
+		return readFrame(conn)
+	}()
+	if !stop() || err != nil {
+		conn.Close()
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("remote signer call failed: %w", ctx.Err())
+		}
+		return nil, fmt.Errorf("remote signer call failed: %w", err)
+	}
+	c.putConn(conn)
+
+	d := decoder{response, nil}
+	switch status := d.uint8(); status {
+	case statusOK:
+		return d.buf, d.err
+	case statusError:
+		msg := d.bytes()
+		if err := d.finish(); err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("remote signer error: %s", msg)
+	default:
+		return nil, fmt.Errorf("unknown response status %v", status)
+	}
+}
+
+func (c *Client) getConn(ctx context.Context) (*tls.Conn, error) {
+	select {
+	case conn := <-c.idle:
+		return conn, nil
+	default:
+	}
+	rawConn, err := c.dial(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("could not dial remote signer: %w", err)
+	}
+	conn := tls.Client(rawConn, c.tlsConfig)
+	if err := conn.HandshakeContext(ctx); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("TLS handshake with remote signer failed: %w", err)
+	}
+	return conn, nil
+}
+
+func (c *Client) putConn(conn *tls.Conn) {
+	if err := conn.SetDeadline(time.Time{}); err != nil {
+		conn.Close()
+		return
+	}
+	select {
+	case <-c.closed:
+		conn.Close()
+	case c.idle <- conn:
+		// Close might have drained idle before we added conn
+		select {
+		case <-c.closed:
+			conn.Close()
+		default:
+		}
+	default:
+		conn.Close()
+	}
+}
+
+var _ types.OffchainKeyring = (*offchainKeyring)(nil)
+
+type offchainKeyring struct {
+	client *Client
+}
+
+func (k *offchainKeyring) OffchainSign(msg []byte) (signature []byte, err error) {
+	var e encoder
+	e.uint8(uint8(methodOffchainSign))
+	e.bytes(msg)
+	results, err := k.client.invoke(e.buf)
+	if err != nil {
+		return nil, err
+	}
+	d := decoder{results, nil}
+	signature = d.bytes()
+	return signature, d.finish()
+}
+
+func (k *offchainKeyring) ConfigDiffieHellman(point [curve25519.PointSize]byte) (sharedPoint [curve25519.PointSize]byte, err error) {
+	var e encoder
+	e.uint8(uint8(methodConfigDiffieHellman))
+	e.fixed(point[:])
+	results, err := k.client.invoke(e.buf)
+	if err != nil {
+		return sharedPoint, err
+	}
+	d := decoder{results, nil}
+	d.fixed(sharedPoint[:])
+	if err := d.finish(); err != nil {
+		return [curve25519.PointSize]byte{}, err
+	}
+	return sharedPoint, nil
+}
+
+func (k *offchainKeyring) OffchainPublicKey() types.OffchainPublicKey {
+	return k.client.offchain.offchainPublicKey
+}
+
+func (k *offchainKeyring) ConfigEncryptionPublicKey() types.ConfigEncryptionPublicKey {
+	return k.client.offchain.configEncryptionPublicKey
+}
+
+var _ types.OnchainKeyring = (*onchainKeyring)(nil)
+
+type onchainKeyring struct {
+	client *Client
+	verify func(pk types.OnchainPublicKey, repctx types.ReportContext, report types.Report, signature []byte) bool
+}
+
+func (k *onchainKeyring) PublicKey() types.OnchainPublicKey {
+	return append(types.OnchainPublicKey{}, k.client.onchain.publicKey...)
+}
+
+func (k *onchainKeyring) Sign(repctx types.ReportContext, report types.Report) (signature []byte, err error) {
+	var e encoder
+	e.uint8(uint8(methodOnchainSign))
+	encodeReportContext(&e, repctx)
+	e.bytes(report)
+	results, err := k.client.invoke(e.buf)
+	if err != nil {
+		return nil, err
+	}
+	d := decoder{results, nil}
+	signature = d.bytes()
+	return signature, d.finish()
+}
+
+func (k *onchainKeyring) Verify(pk types.OnchainPublicKey, repctx types.ReportContext, report types.Report, signature []byte) bool {
+	return k.verify(pk, repctx, report, signature)
+}
+
+func (k *onchainKeyring) MaxSignatureLength() int {
+	return k.client.onchain.maxSignatureLength
+}
+
+type ocr3OnchainKeyring[RI any] struct {
+	client     *Client
+	encodeInfo func(RI) ([]byte, error)
+	verify     func(pk types.OnchainPublicKey, configDigest types.ConfigDigest, seqNr uint64, rwi ocr3types.ReportWithInfo[RI], signature []byte) bool
+}
+
+var _ ocr3types.OnchainKeyring[struct{}] = (*ocr3OnchainKeyring[struct{}])(nil)
+
+func (k *ocr3OnchainKeyring[RI]) PublicKey() types.OnchainPublicKey {
+	return append(types.OnchainPublicKey{}, k.client.ocr3.publicKey...)
+}
+
+func (k *ocr3OnchainKeyring[RI]) Sign(configDigest types.ConfigDigest, seqNr uint64, rwi ocr3types.ReportWithInfo[RI]) (signature []byte, err error) {
+	info, err := k.encodeInfo(rwi.Info)
+	if err != nil {
+		return nil, fmt.Errorf("could not encode report info: %w", err)
+	}
+	var e encoder
+	e.uint8(uint8(methodOCR3OnchainSign))
+	e.fixed(configDigest[:])
+	e.uint64(seqNr)
+	e.bytes(rwi.Report)
+	e.bytes(info)
+	results, err := k.client.invoke(e.buf)
+	if err != nil {
+		return nil, err
+	}
+	d := decoder{results, nil}
+	signature = d.bytes()
+	return signature, d.finish()
+}
+
+func (k *ocr3OnchainKeyring[RI]) Verify(pk types.OnchainPublicKey, configDigest types.ConfigDigest, seqNr uint64, rwi ocr3types.ReportWithInfo[RI], signature []byte) bool {
+	return k.verify(pk, configDigest, seqNr, rwi, signature)
+}
+
+func (k *ocr3OnchainKeyring[RI]) MaxSignatureLength() int {
+	return k.client.ocr3.maxSignatureLength
+}
@@ -0,0 +1,315 @@
+package remotesigner
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"golang.org/x/crypto/curve25519"
+
+	"github.com/smartcontractkit/libocr/commontypes"
+	"github.com/smartcontractkit/libocr/internal/mtls"
+	"github.com/smartcontractkit/libocr/offchainreporting2plus/chains/evmutil"
+	"github.com/smartcontractkit/libocr/offchainreporting2plus/ocr3types"
+	"github.com/smartcontractkit/libocr/offchainreporting2plus/offchainkeyring"
+	"github.com/smartcontractkit/libocr/offchainreporting2plus/types"
+)
+
+type nopLogger struct{}
+
+func (nopLogger) Trace(string, commontypes.LogFields)    {}
+func (nopLogger) Debug(string, commontypes.LogFields)    {}
+func (nopLogger) Info(string, commontypes.LogFields)     {}
+func (nopLogger) Warn(string, commontypes.LogFields)     {}
+func (nopLogger) Error(string, commontypes.LogFields)    {}
+func (nopLogger) Critical(string, commontypes.LogFields) {}
+
+func newIdentity(t *testing.T) ed25519.PrivateKey {
+	t.Helper()
+	_, sk, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return sk
+}
+
+func identityPublicKey(sk ed25519.PrivateKey) IdentityPublicKey {
+	return mtls.MustStaticallySizedEd25519PublicKey(sk.Public())
+}
+
+// slowOnchainKeyring delays signing, to exercise timeouts.
+type slowOnchainKeyring struct {
+	types.OnchainKeyring
+	delay time.Duration
+}
+
+func (k slowOnchainKeyring) Sign(repctx types.ReportContext, report types.Report) ([]byte, error) {
+	time.Sleep(k.delay)
+	return k.OnchainKeyring.Sign(repctx, report)
+}
+
+// startServer serves config on a loopback TCP port until the test ends and
+// returns the port's address.
+func startServer(t *testing.T, config ServerConfig) string {
+	t.Helper()
+	server, err := NewServer(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	chDone := make(chan error)
+	go func() {
+		chDone <- server.Serve(ctx, listener)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		if err := <-chDone; err != nil {
+			t.Error(err)
+		}
+	})
+	return listener.Addr().String()
+}
+
+type testSetup struct {
+	serverIdentity  ed25519.PrivateKey
+	clientIdentity  ed25519.PrivateKey
+	offchainKeyring *offchainkeyring.Keyring
+	onchainKeyring  *evmutil.EVMOnchainKeyring
+	ocr3Keyring     *evmutil.EVMOCR3OnchainKeyring[[]byte]
+}
+
+func newTestSetup(t *testing.T) testSetup {
+	t.Helper()
+	offchain, err := offchainkeyring.New(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	onchainKey, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return testSetup{
+		newIdentity(t),
+		newIdentity(t),
+		offchain,
+		evmutil.NewEVMOnchainKeyring(onchainKey),
+		evmutil.NewEVMOCR3OnchainKeyring[[]byte](onchainKey),
+	}
+}
+
+func (s testSetup) serverConfig() ServerConfig {
+	return ServerConfig{
+		s.serverIdentity,
+		[]IdentityPublicKey{identityPublicKey(s.clientIdentity)},
+		s.offchainKeyring,
+		s.onchainKeyring,
+		s.ocr3Keyring,
+		nopLogger{},
+	}
+}
+
+func (s testSetup) clientConfig(addr string) ClientConfig {
+	return ClientConfig{
+		DialLoopbackTCP(addr),
+		s.clientIdentity,
+		identityPublicKey(s.serverIdentity),
+		5 * time.Second,
+		0,
+	}
+}
+
+func TestClientKeyrings(t *testing.T) {
+	setup := newTestSetup(t)
+	addr := startServer(t, setup.serverConfig())
+
+	client, err := NewClient(context.Background(), setup.clientConfig(addr))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	offchain, err := client.OffchainKeyring()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if offchain.OffchainPublicKey() != setup.offchainKeyring.OffchainPublicKey() || offchain.ConfigEncryptionPublicKey() != setup.offchainKeyring.ConfigEncryptionPublicKey() {
+		t.Fatalf("offchain public keys don't match server's")
+	}
+	msg := []byte("message")
+	sig, err := offchain.OffchainSign(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	offchainPublicKey := offchain.OffchainPublicKey()
+	if !ed25519.Verify(offchainPublicKey[:], msg, sig) {
+		t.Fatalf("invalid offchain signature")
+	}
+	var point [curve25519.PointSize]byte
+	copy(point[:], curve25519.Basepoint)
+	sharedPoint, err := offchain.ConfigDiffieHellman(point)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedSharedPoint, err := setup.offchainKeyring.ConfigDiffieHellman(point)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sharedPoint != expectedSharedPoint {
+		t.Fatalf("ConfigDiffieHellman returned %x, expected %x", sharedPoint, expectedSharedPoint)
+	}
+
+	onchain, err := client.OnchainKeyring(evmutil.VerifyReportSignature)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(onchain.PublicKey(), setup.onchainKeyring.PublicKey()) || onchain.MaxSignatureLength() != setup.onchainKeyring.MaxSignatureLength() {
+		t.Fatalf("onchain public key doesn't match server's")
+	}
+	repctx := types.ReportContext{types.ReportTimestamp{types.ConfigDigest{1}, 2, 3}, [32]byte{4}}
+	report := types.Report("report")
+	sig, err = onchain.Sign(repctx, report)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !onchain.Verify(onchain.PublicKey(), repctx, report, sig) || !setup.onchainKeyring.Verify(onchain.PublicKey(), repctx, report, sig) {
+		t.Fatalf("invalid onchain signature")
+	}
+	if onchain.Verify(onchain.PublicKey(), repctx, types.Report("other report"), sig) {
+		t.Fatalf("onchain signature verified for different report")
+	}
+
+	ocr3, err := NewOCR3OnchainKeyring(client, func(info string) ([]byte, error) { return []byte(info), nil }, evmutil.VerifyOCR3ReportSignature[string])
+	if err != nil {
+		t.Fatal(err)
+	}
+	rwi := ocr3types.ReportWithInfo[string]{types.Report("report"), "info"}
+	sig, err = ocr3.Sign(types.ConfigDigest{1}, 42, rwi)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ocr3.Verify(ocr3.PublicKey(), types.ConfigDigest{1}, 42, rwi, sig) || !setup.ocr3Keyring.Verify(ocr3.PublicKey(), types.ConfigDigest{1}, 42, ocr3types.ReportWithInfo[[]byte]{rwi.Report, nil}, sig) {
+		t.Fatalf("invalid OCR3 onchain signature")
+	}
+	if ocr3.Verify(ocr3.PublicKey(), types.ConfigDigest{1}, 43, rwi, sig) {
+		t.Fatalf("OCR3 onchain signature verified for different seqNr")
+	}
+
+	// Verification doesn't depend on the server
+	client.Close()
+	if _, err := ocr3.Sign(types.ConfigDigest{1}, 42, rwi); err == nil {
+		t.Fatalf("expected error signing with closed client")
+	}
+	if !ocr3.Verify(ocr3.PublicKey(), types.ConfigDigest{1}, 42, rwi, sig) {
+		t.Fatalf("verification failed after client was closed")
+	}
+
+	if _, err := client.OnchainKeyring(nil); err == nil {
+		t.Fatalf("expected error for nil verify function")
+	}
+}
+
+func TestClientAuthentication(t *testing.T) {
+	setup := newTestSetup(t)
+	addr := startServer(t, setup.serverConfig())
+
+	for name, modify := range map[string]func(*ClientConfig){
+		// The client pins a different key than the server's
+		"wrong server key": func(c *ClientConfig) { c.ServerPublicKey = identityPublicKey(newIdentity(t)) },
+		// The server doesn't know the client's key
+		"unauthorized client": func(c *ClientConfig) { c.Identity = newIdentity(t) },
+	} {
+		config := setup.clientConfig(addr)
+		modify(&config)
+		if _, err := NewClient(context.Background(), config); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+
+	// Sanity check that the unmodified config works
+	client, err := NewClient(context.Background(), setup.clientConfig(addr))
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.Close()
+}
+
+func TestClientTimeout(t *testing.T) {
+	setup := newTestSetup(t)
+	serverConfig := setup.serverConfig()
+	serverConfig.OnchainKeyring = slowOnchainKeyring{setup.onchainKeyring, time.Second}
+	addr := startServer(t, serverConfig)
+
+	clientConfig := setup.clientConfig(addr)
+	clientConfig.Timeout = 100 * time.Millisecond
+	client, err := NewClient(context.Background(), clientConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	onchain, err := client.OnchainKeyring(evmutil.VerifyReportSignature)
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Now()
+	if _, err := onchain.Sign(types.ReportContext{}, types.Report("report")); err == nil || !strings.Contains(err.Error(), "deadline exceeded") {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 900*time.Millisecond {
+		t.Fatalf("Sign returned after %v, expected about %v", elapsed, clientConfig.Timeout)
+	}
+
+	// The timed out connection was discarded, so its late response can't be
+	// mistaken for the response to a later request.
+	offchain, err := client.OffchainKeyring()
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := []byte("message")
+	sig, err := offchain.OffchainSign(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	offchainPublicKey := offchain.OffchainPublicKey()
+	if !ed25519.Verify(offchainPublicKey[:], msg, sig) {
+		t.Fatalf("invalid offchain signature")
+	}
+}
+
+func TestClientTimeoutUnresponsiveServer(t *testing.T) {
+	// Accepts connections but never completes a TLS handshake
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+		}
+	}()
+
+	setup := newTestSetup(t)
+	config := setup.clientConfig(listener.Addr().String())
+	config.Timeout = 100 * time.Millisecond
+	start := time.Now()
+	if _, err := NewClient(context.Background(), config); err == nil {
+		t.Fatalf("expected error")
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Fatalf("NewClient returned after %v, expected about %v", elapsed, config.Timeout)
+	}
+}
@@ -0,0 +1,199 @@
+// Package remotesigner lets an oracle use offchain and onchain keys that live
+// in a separate process, so that signing keys never become resident in the
+// oracle process.
+//
+// A Server holds the actual keyrings and listens on a Unix socket or a
+// loopback TCP port. A Client connects to it and exposes implementations of
+// types.OffchainKeyring, types.OnchainKeyring and ocr3types.OnchainKeyring
+// that forward every operation involving private keys to the server.
+// Signatures are verified locally, so that other oracles' signatures don't
+// look invalid while the server is unreachable.
+//
+// Connections are secured with TLS 1.3 and mutually authenticated: both
+// sides have an Ed25519 identity key, the client pins the server's public key
+// and the server only accepts clients whose public keys it has been
+// configured with.
+//
+// On top of TLS, the protocol is a sequence of request/response pairs. Each
+// message is a frame consisting of a 4 byte big-endian length followed by that
+// many bytes of payload. A request payload starts with a one byte method,
+// followed by the method's arguments. A response payload starts with a one
+// byte status. If the status is statusOK, the method's results follow;
+// otherwise an error message follows. Byte strings are encoded with a 4 byte
+// big-endian length prefix, integers are big-endian.
+package remotesigner
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+type method uint8
+
+const (
+	_ method = iota
+	methodPublicKeys
+	methodOffchainSign
+	methodConfigDiffieHellman
+	methodOnchainSign
+	methodOCR3OnchainSign
+)
+
+func (m method) String() string {
+	switch m {
+	case methodPublicKeys:
+		return "PublicKeys"
+	case methodOffchainSign:
+		return "OffchainSign"
+	case methodConfigDiffieHellman:
+		return "ConfigDiffieHellman"
+	case methodOnchainSign:
+		return "OnchainSign"
+	case methodOCR3OnchainSign:
+		return "OCR3OnchainSign"
+	}
+	return fmt.Sprintf("method(%d)", uint8(m))
+}
+
+const (
+	statusOK    uint8 = 0
+	statusError uint8 = 1
+)
+
+// Upper bound on the size of a frame. Reports can be large, but not this
+// large.
+const maxFrameLength = 16 * 1024 * 1024
+
+func writeFrame(w io.Writer, payload []byte) error {
+	if len(payload) > maxFrameLength {
+		return fmt.Errorf("frame of %d bytes exceeds maximum of %d bytes", len(payload), maxFrameLength)
+	}
+	buf := make([]byte, 4, 4+len(payload))
+	binary.BigEndian.PutUint32(buf, uint32(len(payload)))
+	_, err := w.Write(append(buf, payload...))
+	return err
+}
+
+func readFrame(r io.Reader) ([]byte, error) {
+	var header [4]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return nil, err
+	}
+	length := binary.BigEndian.Uint32(header[:])
+	if length > maxFrameLength {
+		return nil, fmt.Errorf("frame of %d bytes exceeds maximum of %d bytes", length, maxFrameLength)
+	}
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return nil, err
+	}
+	return payload, nil
+}
+
+// encoder builds a payload.
+type encoder struct {
+	buf []byte
+}
+
+func (e *encoder) uint8(v uint8) {
+	e.buf = append(e.buf, v)
+}
+
+func (e *encoder) uint32(v uint32) {
+	e.buf = binary.BigEndian.AppendUint32(e.buf, v)
+}
+
+func (e *encoder) uint64(v uint64) {
+	e.buf = binary.BigEndian.AppendUint64(e.buf, v)
+}
+
+func (e *encoder) bool(v bool) {
+	if v {
+		e.uint8(1)
+	} else {
+		e.uint8(0)
+	}
+}
+
+func (e *encoder) fixed(b []byte) {
+	e.buf = append(e.buf, b...)
+}
+
+func (e *encoder) bytes(b []byte) {
+	e.uint32(uint32(len(b)))
+	e.fixed(b)
+}
+
+// decoder parses a payload. After the first error, all further reads return
+// zero values and err remains set.
+type decoder struct {
+	buf []byte
+	err error
+}
+
+func (d *decoder) take(n int) []byte {
+	if d.err != nil {
+		return make([]byte, n)
+	}
+	if len(d.buf) < n {
+		d.err = fmt.Errorf("payload too short")
+		return make([]byte, n)
+	}
+	b := d.buf[:n]
+	d.buf = d.buf[n:]
+	return b
+}
+
+func (d *decoder) uint8() uint8 {
+	return d.take(1)[0]
+}
+
+func (d *decoder) uint32() uint32 {
+	return binary.BigEndian.Uint32(d.take(4))
+}
+
+func (d *decoder) uint64() uint64 {
+	return binary.BigEndian.Uint64(d.take(8))
+}
+
+func (d *decoder) bool() bool {
+	switch d.uint8() {
+	case 0:
+		return false
+	case 1:
+		return true
+	default:
+		if d.err == nil {
+			d.err = fmt.Errorf("invalid bool")
+		}
+		return false
+	}
+}
+
+func (d *decoder) fixed(dst []byte) {
+	copy(dst, d.take(len(dst)))
+}
+
+func (d *decoder) bytes() []byte {
+	n := d.uint32()
+	if d.err != nil {
+		return nil
+	}
+	if uint64(n) > uint64(len(d.buf)) {
+		d.err = fmt.Errorf("payload too short")
+		return nil
+	}
+	return append([]byte{}, d.take(int(n))...)
+}
+
+// finish returns an error if decoding failed or there are trailing bytes.
+func (d *decoder) finish() error {
+	if d.err != nil {
+		return d.err
+	}
+	if len(d.buf) != 0 {
+		return fmt.Errorf("%d trailing bytes in payload", len(d.buf))
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+package remotesigner
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/binary"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/smartcontractkit/libocr/internal/mtls"
+)
+
+func TestFrames(t *testing.T) {
+	var buf bytes.Buffer
+	for _, payload := range [][]byte{{}, []byte("payload")} {
+		if err := writeFrame(&buf, payload); err != nil {
+			t.Fatal(err)
+		}
+		decoded, err := readFrame(&buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(decoded, payload) {
+			t.Fatalf("expected %x, got %x", payload, decoded)
+		}
+	}
+
+	if err := writeFrame(&buf, make([]byte, maxFrameLength+1)); err == nil {
+		t.Fatalf("expected error writing oversized frame")
+	}
+
+	oversized := binary.BigEndian.AppendUint32(nil, maxFrameLength+1)
+	for name, data := range map[string][]byte{
+		"short header":    {0, 0},
+		"oversized frame": oversized,
+		"short payload":   append(binary.BigEndian.AppendUint32(nil, 10), 1, 2, 3),
+	} {
+		if _, err := readFrame(bytes.NewReader(data)); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+func TestDecoder(t *testing.T) {
+	var e encoder
+	e.uint8(1)
+	e.bool(true)
+	e.bytes([]byte("bytes"))
+	e.uint64(2)
+
+	d := decoder{e.buf, nil}
+	if d.uint8() != 1 || !d.bool() || !bytes.Equal(d.bytes(), []byte("bytes")) || d.uint64() != 2 {
+		t.Fatalf("round-trip failed")
+	}
+	if err := d.finish(); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, decode := range map[string]func(d *decoder){
+		"trailing bytes": func(d *decoder) { d.uint8() },
+		"too short":      func(d *decoder) { d.uint8(); d.bool(); d.bytes(); d.uint64(); d.uint8() },
+		"invalid bool":   func(d *decoder) { d.uint8(); d.uint8(); d.bool() },
+		"bytes too long": func(d *decoder) { d.uint32(); d.bytes() },
+	} {
+		d := decoder{e.buf, nil}
+		decode(&d)
+		if err := d.finish(); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+func TestServerRejectsMalformedRequests(t *testing.T) {
+	setup := newTestSetup(t)
+	server, err := NewServer(setup.serverConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	valid := []byte{uint8(methodOffchainSign), 0, 0, 0, 1, 0xff}
+	for name, request := range map[string][]byte{
+		"empty":          {},
+		"unknown method": {0xff},
+		"truncated":      valid[:len(valid)-1],
+		"trailing bytes": append(append([]byte{}, valid...), 0),
+		"short point":    {uint8(methodConfigDiffieHellman), 1, 2, 3},
+	} {
+		d := decoder{server.handleRequest(request), nil}
+		if status := d.uint8(); status != statusError {
+			t.Errorf("%s: expected error status, got %v", name, status)
+		}
+		d.bytes()
+		if err := d.finish(); err != nil {
+			t.Errorf("%s: malformed error response: %v", name, err)
+		}
+	}
+	if d := (decoder{server.handleRequest(valid), nil}); d.uint8() != statusOK {
+		t.Fatalf("valid request failed")
+	}
+
+	// Over the wire, the server answers malformed requests and keeps the
+	// connection open, but drops connections that send oversized frames.
+	addr := startServer(t, setup.serverConfig())
+	rawConn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn := tls.Client(rawConn, newTLSConfig(setup.clientIdentity, mtls.VerifyCertMatchesPubKey(identityPublicKey(setup.serverIdentity))))
+	defer conn.Close()
+	if err := conn.SetDeadline(time.Now().Add(5 * time.Second)); err != nil {
+		t.Fatal(err)
+	}
+	for _, request := range [][]byte{{0xff}, valid} {
+		if err := writeFrame(conn, request); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := readFrame(conn); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := conn.Write(binary.BigEndian.AppendUint32(nil, maxFrameLength+1)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := readFrame(conn); err != io.EOF {
+		t.Fatalf("expected connection to be closed, got %v", err)
+	}
+}
@@ -0,0 +1,302 @@
+package remotesigner
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/curve25519"
+
+	"github.com/smartcontractkit/libocr/commontypes"
+	"github.com/smartcontractkit/libocr/internal/loghelper"
+	"github.com/smartcontractkit/libocr/internal/mtls"
+	"github.com/smartcontractkit/libocr/offchainreporting2plus/ocr3types"
+	"github.com/smartcontractkit/libocr/offchainreporting2plus/types"
+	"github.com/smartcontractkit/libocr/subprocesses"
+)
+
+// Maximum duration of the TLS handshake on an incoming connection.
+const serverHandshakeTimeout = 5 * time.Second
+
+// ServerConfig configures a Server.
+type ServerConfig struct {
+	// Identity is the server's Ed25519 key. Clients pin its public key.
+	Identity ed25519.PrivateKey
+
+	// AuthorizedClients lists the identity public keys of clients that may
+	// connect. All other clients are rejected during the TLS handshake.
+	AuthorizedClients []IdentityPublicKey
+
+	// Keyrings the server signs with. Any of them may be nil, in which case
+	// the corresponding requests fail.
+	OffchainKeyring types.OffchainKeyring
+	OnchainKeyring  types.OnchainKeyring
+	// The OCR3 keyring receives the report info exactly as encoded by the
+	// client, see NewOCR3OnchainKeyring.
+	OCR3OnchainKeyring ocr3types.OnchainKeyring[[]byte]
+
+	Logger commontypes.Logger
+}
+
+// Server is a reference implementation of a remote signer. It serves the
+// keyrings in its ServerConfig to authorized clients.
+type Server struct {
+	identity           ed25519.PrivateKey
+	authorizedClients  map[IdentityPublicKey]struct{}
+	offchainKeyring    types.OffchainKeyring
+	onchainKeyring     types.OnchainKeyring
+	ocr3OnchainKeyring ocr3types.OnchainKeyring[[]byte]
+	logger             loghelper.LoggerWithContext
+}
+
+func NewServer(config ServerConfig) (*Server, error) {
+	if len(config.Identity) != ed25519.PrivateKeySize {
+		return nil, fmt.Errorf("identity has wrong length, expected %v, got %v", ed25519.PrivateKeySize, len(config.Identity))
+	}
+	if len(config.AuthorizedClients) == 0 {
+		return nil, fmt.Errorf("no authorized clients")
+	}
+	if config.OffchainKeyring == nil && config.OnchainKeyring == nil && config.OCR3OnchainKeyring == nil {
+		return nil, fmt.Errorf("no keyrings")
+	}
+	if config.Logger == nil {
+		return nil, fmt.Errorf("logger must not be nil")
+	}
+	authorizedClients := make(map[IdentityPublicKey]struct{}, len(config.AuthorizedClients))
+	for _, pk := range config.AuthorizedClients {
+		authorizedClients[pk] = struct{}{}
+	}
+	return &Server{
+		config.Identity,
+		authorizedClients,
+		config.OffchainKeyring,
+		config.OnchainKeyring,
+		config.OCR3OnchainKeyring,
+		loghelper.MakeRootLoggerWithContext(config.Logger).MakeChild(commontypes.LogFields{
+			"id": "RemoteSignerServer",
+		}),
+	}, nil
+}
+
+// PublicKey returns the public key clients must pin to connect to this server.
+func (s *Server) PublicKey() IdentityPublicKey {
+	return mtls.MustStaticallySizedEd25519PublicKey(s.identity.Public())
+}
+
+// Serve accepts connections on listener until ctx is canceled or accepting
+// fails. Since the protocol is meant for communication between processes on
+// the same host, listener must be a Unix socket or a loopback TCP listener.
+// Serve closes listener and all connections before returning.
+func (s *Server) Serve(ctx context.Context, listener net.Listener) error {
+	if err := checkLocalAddr(listener.Addr()); err != nil {
+		listener.Close()
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var subs subprocesses.Subprocesses
+	defer subs.Wait()
+
+	subs.Go(func() {
+		<-ctx.Done()
+		loghelper.CloseLogError(listener, s.logger, "failed to close listener")
+	})
+
+	tlsConfig := newTLSConfig(s.identity, verifyCertInAllowList(s.authorizedClients))
+	s.logger.Info("serving", commontypes.LogFields{"addr": listener.Addr().String()})
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("could not accept connection: %w", err)
+		}
+		subs.Go(func() {
+			s.handleConn(ctx, tls.Server(conn, tlsConfig))
+		})
+	}
+}
+
+func (s *Server) handleConn(ctx context.Context, conn *tls.Conn) {
+	logger := s.logger.MakeChild(commontypes.LogFields{"remoteAddr": conn.RemoteAddr().String()})
+
+	var closeOnce sync.Once
+	closeConn := func() { closeOnce.Do(func() { conn.Close() }) }
+	defer closeConn()
+	stop := context.AfterFunc(ctx, closeConn)
+	defer stop()
+
+	handshakeCtx, cancel := context.WithTimeout(ctx, serverHandshakeTimeout)
+	err := conn.HandshakeContext(handshakeCtx)
+	cancel()
+	if err != nil {
+		logger.Warn("TLS handshake failed", commontypes.LogFields{"error": err})
+		return
+	}
+	logger.Debug("client connected", nil)
+
+	for {
+		request, err := readFrame(conn)
+		if err != nil {
+			if ctx.Err() == nil && !errors.Is(err, net.ErrClosed) {
+				logger.Debug("connection closed", commontypes.LogFields{"error": err})
+			}
+			return
+		}
+		response := s.handleRequest(request)
+		if err := writeFrame(conn, response); err != nil {
+			logger.Warn("could not write response", commontypes.LogFields{"error": err})
+			return
+		}
+	}
+}
+
+// handleRequest never returns an error: failures are reported to the client
+// in the response.
+func (s *Server) handleRequest(request []byte) []byte {
+	d := decoder{request, nil}
+	m := method(d.uint8())
+	results, err := s.dispatch(m, &d)
+	var e encoder
+	if err != nil {
+		s.logger.Debug("request failed", commontypes.LogFields{"method": m.String(), "error": err})
+		e.uint8(statusError)
+		e.bytes([]byte(err.Error()))
+		return e.buf
+	}
+	e.uint8(statusOK)
+	e.fixed(results)
+	return e.buf
+}
+
+func (s *Server) dispatch(m method, d *decoder) ([]byte, error) {
+	var e encoder
+	switch m {
+	case methodPublicKeys:
+		if err := d.finish(); err != nil {
+			return nil, err
+		}
+		e.bool(s.offchainKeyring != nil)
+		if s.offchainKeyring != nil {
+			offchainPublicKey := s.offchainKeyring.OffchainPublicKey()
+			configEncryptionPublicKey := s.offchainKeyring.ConfigEncryptionPublicKey()
+			e.fixed(offchainPublicKey[:])
+			e.fixed(configEncryptionPublicKey[:])
+		}
+		e.bool(s.onchainKeyring != nil)
+		if s.onchainKeyring != nil {
+			e.bytes(s.onchainKeyring.PublicKey())
+			e.uint32(uint32(s.onchainKeyring.MaxSignatureLength()))
+		}
+		e.bool(s.ocr3OnchainKeyring != nil)
+		if s.ocr3OnchainKeyring != nil {
+			e.bytes(s.ocr3OnchainKeyring.PublicKey())
+			e.uint32(uint32(s.ocr3OnchainKeyring.MaxSignatureLength()))
+		}
+
+	case methodOffchainSign:
+		msg := d.bytes()
+		if err := d.finish(); err != nil {
+			return nil, err
+		}
+		if s.offchainKeyring == nil {
+			return nil, fmt.Errorf("no offchain keyring")
+		}
+		sig, err := s.offchainKeyring.OffchainSign(msg)
+		if err != nil {
+			return nil, err
+		}
+		e.bytes(sig)
+
+	case methodConfigDiffieHellman:
+		var point [curve25519.PointSize]byte
+		d.fixed(point[:])
+		if err := d.finish(); err != nil {
+			return nil, err
+		}
+		if s.offchainKeyring == nil {
+			return nil, fmt.Errorf("no offchain keyring")
+		}
+		sharedPoint, err := s.offchainKeyring.ConfigDiffieHellman(point)
+		if err != nil {
+			return nil, err
+		}
+		e.fixed(sharedPoint[:])
+
+	case methodOnchainSign:
+		repctx := decodeReportContext(d)
+		report := d.bytes()
+		if err := d.finish(); err != nil {
+			return nil, err
+		}
+		if s.onchainKeyring == nil {
+			return nil, fmt.Errorf("no onchain keyring")
+		}
+		sig, err := s.onchainKeyring.Sign(repctx, report)
+		if err != nil {
+			return nil, err
+		}
+		e.bytes(sig)
+
+	case methodOCR3OnchainSign:
+		var configDigest types.ConfigDigest
+		d.fixed(configDigest[:])
+		seqNr := d.uint64()
+		rwi := ocr3types.ReportWithInfo[[]byte]{d.bytes(), d.bytes()}
+		if err := d.finish(); err != nil {
+			return nil, err
+		}
+		if s.ocr3OnchainKeyring == nil {
+			return nil, fmt.Errorf("no OCR3 onchain keyring")
+		}
+		sig, err := s.ocr3OnchainKeyring.Sign(configDigest, seqNr, rwi)
+		if err != nil {
+			return nil, err
+		}
+		e.bytes(sig)
+
+	default:
+		return nil, fmt.Errorf("unknown method %v", m)
+	}
+	return e.buf, nil
+}
+
+func encodeReportContext(e *encoder, repctx types.ReportContext) {
+	e.fixed(repctx.ConfigDigest[:])
+	e.uint32(repctx.Epoch)
+	e.uint8(repctx.Round)
+	e.fixed(repctx.ExtraHash[:])
+}
+
+func decodeReportContext(d *decoder) types.ReportContext {
+	var repctx types.ReportContext
+	d.fixed(repctx.ConfigDigest[:])
+	repctx.Epoch = d.uint32()
+	repctx.Round = d.uint8()
+	d.fixed(repctx.ExtraHash[:])
+	return repctx
+}
+
+// checkLocalAddr returns an error unless addr is a Unix socket or a loopback
+// TCP address.
+func checkLocalAddr(addr net.Addr) error {
+	switch a := addr.(type) {
+	case *net.UnixAddr:
+		return nil
+	case *net.TCPAddr:
+		if a.IP.IsLoopback() {
+			return nil
+		}
+		return fmt.Errorf("refusing non-loopback TCP address %v", a)
+	default:
+		return fmt.Errorf("unsupported address %v of type %T, expected Unix socket or loopback TCP", addr, addr)
+	}
+}
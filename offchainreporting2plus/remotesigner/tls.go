@@ -0,0 +1,52 @@
+package remotesigner
+
+import (
+	"crypto/ed25519"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+
+	"github.com/smartcontractkit/libocr/internal/mtls"
+)
+
+// IdentityPublicKey is the Ed25519 public key a client or server
+// authenticates itself with.
+type IdentityPublicKey [ed25519.PublicKeySize]byte
+
+func newTLSConfig(identity ed25519.PrivateKey, verifyPeerCertificate func(rawCerts [][]byte, verifiedChains [][]*x509.Certificate) error) *tls.Config {
+	return &tls.Config{
+		Certificates: []tls.Certificate{mtls.NewMinimalX509CertFromPrivateKey(identity)},
+		ClientAuth:   tls.RequireAnyClientCert,
+
+		// Both sides use self-signed certs, so we skip verification here.
+		// Instead, we use VerifyPeerCertificate to pin public keys.
+		InsecureSkipVerify: true,
+
+		MaxVersion: tls.VersionTLS13,
+		MinVersion: tls.VersionTLS13,
+
+		VerifyPeerCertificate: verifyPeerCertificate,
+	}
+}
+
+// verifyCertInAllowList accepts a peer iff its certificate's public key is in
+// allowed.
+func verifyCertInAllowList(allowed map[IdentityPublicKey]struct{}) func(rawCerts [][]byte, verifiedChains [][]*x509.Certificate) error {
+	return func(rawCerts [][]byte, verifiedChains [][]*x509.Certificate) error {
+		if len(rawCerts) != 1 {
+			return fmt.Errorf("expected exactly one certificate, got %d", len(rawCerts))
+		}
+		cert, err := x509.ParseCertificate(rawCerts[0])
+		if err != nil {
+			return err
+		}
+		pk, err := mtls.PubKeyFromCert(cert)
+		if err != nil {
+			return err
+		}
+		if _, ok := allowed[pk]; !ok {
+			return fmt.Errorf("client public key %x is not authorized", pk)
+		}
+		return nil
+	}
+}
@@ -12,8 +12,8 @@ import (
 type ConfigDigestPrefix uint16
 
 // This acts as the canonical "registry" of ConfigDigestPrefixes. Pick an unused
-// prefix and add it to this list before you build an OffchainConfigDigester for
-// whatever chain you're targeting.
+// prefix and add it to this list (and to configDigestPrefixNames) before you
+// build an OffchainConfigDigester for whatever chain you're targeting.
 const (
 	_                                        ConfigDigestPrefix = 0 // reserved to prevent errors where a zero-default creeps through somewhere
 	ConfigDigestPrefixEVM                    ConfigDigestPrefix = 1 // TODO: rename to ConfigDigestPrefixEVMSimple in the future
@@ -30,6 +30,35 @@ const (
 	_                      ConfigDigestPrefix = 0xFFFF // reserved for future use
 )
 
+var configDigestPrefixNames = map[ConfigDigestPrefix]string{
+	ConfigDigestPrefixEVM:                    "EVM",
+	ConfigDigestPrefixTerra:                  "Terra",
+	ConfigDigestPrefixSolana:                 "Solana",
+	ConfigDigestPrefixStarknet:               "Starknet",
+	ConfigDigestPrefixMercuryV02:             "MercuryV02",
+	ConfigDigestPrefixEVMThresholdDecryption: "EVMThresholdDecryption",
+	ConfigDigestPrefixEVMS4:                  "EVMS4",
+	ConfigDigestPrefixLLO:                    "LLO",
+	ConfigDigestPrefixOCR1:                   "OCR1",
+}
+
+// Name returns the name of the prefix in the registry above, e.g. "Solana" for
+// ConfigDigestPrefixSolana. ok is false if the prefix is not registered.
+func (prefix ConfigDigestPrefix) Name() (name string, ok bool) {
+	name, ok = configDigestPrefixNames[prefix]
+	return name, ok
+}
+
+// Describe returns the prefix in hex together with its name, for use in error
+// messages, e.g. "0003 (Solana)" or "0005 (unregistered)".
+func (prefix ConfigDigestPrefix) Describe() string {
+	name, ok := prefix.Name()
+	if !ok {
+		name = "unregistered"
+	}
+	return fmt.Sprintf("%s (%s)", prefix, name)
+}
+
 func ConfigDigestPrefixFromConfigDigest(configDigest ConfigDigest) ConfigDigestPrefix {
 	return ConfigDigestPrefix(binary.BigEndian.Uint16(configDigest[:2]))
 }
@@ -0,0 +1,112 @@
+package ragep2p
+
+import (
+	"bytes"
+	"compress/flate"
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// StreamCompression selects whether messages sent on a stream are compressed.
+// Compression is negotiated when the stream is opened: messages are only
+// compressed if both sides of the stream have enabled the same compression,
+// so enabling compression on one side is always safe.
+//
+// Limits on received messages (maxMessageLength and the bytes token bucket
+// passed to NewStream) apply to the decompressed size of messages, and a
+// message is only decompressed after its decompressed size has been checked
+// against them. Compressed messages thus can't be used as decompression bombs.
+type StreamCompression int
+
+const (
+	StreamCompressionNone StreamCompression = iota
+	// DEFLATE as implemented by compress/flate. Messages that don't get
+	// smaller by compression are sent uncompressed.
+	StreamCompressionDeflate
+)
+
+func (c StreamCompression) String() string {
+	switch c {
+	case StreamCompressionNone:
+		return "none"
+	case StreamCompressionDeflate:
+		return "deflate"
+	}
+	return fmt.Sprintf("StreamCompression(%d)", int(c))
+}
+
+func (c StreamCompression) MarshalText() ([]byte, error) {
+	return []byte(c.String()), nil
+}
+
+func (c StreamCompression) check() error {
+	switch c {
+	case StreamCompressionNone, StreamCompressionDeflate:
+		return nil
+	}
+	return fmt.Errorf("invalid stream compression %v", c)
+}
+
+// Size of the uncompressed length prefix of frameTypeCompressedData payloads
+const compressedDataLengthSize = 4
+
+// compressor is used by a single authenticatedConnectionWriteLoop. It reuses
+// its buffers across messages.
+type compressor struct {
+	buf    bytes.Buffer
+	writer *flate.Writer
+}
+
+func newCompressor() *compressor {
+	// BestSpeed since we compress on the connection's write path
+	writer, err := flate.NewWriter(nil, flate.BestSpeed)
+	if err != nil {
+		// can only happen for invalid compression levels
+		panic(err)
+	}
+	return &compressor{bytes.Buffer{}, writer}
+}
+
+// Compress returns the payload of a frameTypeCompressedData frame for data.
+// It returns false if compression doesn't make data smaller, in which case
+// data should be sent in a frameTypeData frame instead. The returned slice is
+// only valid until the next call.
+func (c *compressor) Compress(data []byte) ([]byte, bool) {
+	c.buf.Reset()
+	_ = binary.Write(&c.buf, binary.BigEndian, uint32(len(data)))
+	c.writer.Reset(&c.buf)
+	// writes to a bytes.Buffer can't fail
+	_, _ = c.writer.Write(data)
+	_ = c.writer.Close()
+	if c.buf.Len() >= len(data) {
+		return nil, false
+	}
+	return c.buf.Bytes(), true
+}
+
+// decompressor is used by a single authenticatedConnectionReadLoop.
+type decompressor struct {
+	reader io.ReadCloser
+}
+
+func newDecompressor() *decompressor {
+	return &decompressor{flate.NewReader(bytes.NewReader(nil))}
+}
+
+// Decompress decompresses compressed, which must decompress to exactly
+// uncompressedLength bytes. At most uncompressedLength bytes are allocated.
+func (d *decompressor) Decompress(compressed []byte, uncompressedLength int) ([]byte, error) {
+	if err := d.reader.(flate.Resetter).Reset(bytes.NewReader(compressed), nil); err != nil {
+		return nil, err
+	}
+	data := make([]byte, uncompressedLength)
+	if _, err := io.ReadFull(d.reader, data); err != nil {
+		return nil, fmt.Errorf("could not decompress message: %w", err)
+	}
+	var extra [1]byte
+	if n, err := d.reader.Read(extra[:]); n != 0 || err != io.EOF {
+		return nil, fmt.Errorf("message decompresses to more than the announced %v bytes", uncompressedLength)
+	}
+	return data, nil
+}
@@ -0,0 +1,86 @@
+package ragep2p
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestCompressorRoundTrip(t *testing.T) {
+	c := newCompressor()
+	d := newDecompressor()
+
+	compressible := bytes.Repeat([]byte("observation"), 1000)
+	payload, ok := c.Compress(compressible)
+	if !ok {
+		t.Fatalf("expected compressible data to be compressed")
+	}
+	if binary.BigEndian.Uint32(payload) != uint32(len(compressible)) {
+		t.Fatalf("wrong length prefix")
+	}
+	data, err := d.Decompress(payload[compressedDataLengthSize:], len(compressible))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data, compressible) {
+		t.Fatalf("round trip changed data")
+	}
+
+	// A message that decompresses to more or fewer bytes than announced must
+	// be rejected
+	for _, announced := range []int{len(compressible) - 1, len(compressible) + 1} {
+		if _, err := d.Decompress(payload[compressedDataLengthSize:], announced); err == nil {
+			t.Fatalf("expected error for announced length %v", announced)
+		}
+	}
+
+	if _, ok := c.Compress([]byte{0x42}); ok {
+		t.Fatalf("expected incompressible data not to be compressed")
+	}
+}
+
+func TestOpenPayload(t *testing.T) {
+	for _, options := range []streamOptions{0, streamOptionCompressionDeflate} {
+		name, decodedOptions := decodeOpenPayload(encodeOpenPayload("ocr/abc", options))
+		if name != "ocr/abc" || decodedOptions != options {
+			t.Fatalf("round trip of options %v yielded %q, %v", options, name, decodedOptions)
+		}
+	}
+}
+
+func TestStreamCompression(t *testing.T) {
+	network := NewMemoryNetwork()
+	hosts := testHosts(t, network.Transport(), []string{"a", "b"})
+	limit := TokenBucketParams{1e6, 1e6}
+	msg := bytes.Repeat([]byte("compress me "), 10_000)
+
+	for _, compressions := range [][2]StreamCompression{
+		{StreamCompressionDeflate, StreamCompressionDeflate},
+		{StreamCompressionDeflate, StreamCompressionNone},
+	} {
+		streamName := fmt.Sprintf("test-%v-%v", compressions[0], compressions[1])
+		sa, err := hosts[0].NewStreamWithOptions(hosts[1].ID(), streamName, 10, 10, len(msg), limit, limit, StreamOptions{StreamPriority{StreamPriorityClassNormal, 1}, compressions[0]})
+		if err != nil {
+			t.Fatal(err)
+		}
+		sb, err := hosts[1].NewStreamWithOptions(hosts[0].ID(), streamName, 10, 10, len(msg), limit, limit, StreamOptions{StreamPriority{StreamPriorityClassNormal, 1}, compressions[1]})
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, pair := range [][2]*Stream{{sa, sb}, {sb, sa}} {
+			pair[0].SendMessage(msg)
+			select {
+			case received := <-pair[1].ReceiveMessages():
+				if !bytes.Equal(received, msg) {
+					t.Fatalf("%s: received message differs from sent message", streamName)
+				}
+			case <-time.After(10 * time.Second):
+				t.Fatalf("%s: timed out waiting for message", streamName)
+			}
+		}
+		sa.Close()
+		sb.Close()
+	}
+}
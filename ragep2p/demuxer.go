@@ -17,6 +17,7 @@ const (
 	shouldPushResultMessagesLimitExceeded
 	shouldPushResultBytesLimitExceeded
 	shouldPushResultUnknownStream
+	shouldPushResultUnexpectedCompression
 )
 
 type pushResult int
@@ -34,6 +35,11 @@ type demuxerStream struct {
 	maxMessageSize  int
 	messagesLimiter ratelimit.TokenBucket
 	bytesLimiter    ratelimit.TokenBucket
+	compression     StreamCompression
+
+	// Counters for snapshots
+	droppedOverflow uint64
+	droppedLimits   uint64
 }
 
 type demuxer struct {
@@ -62,6 +68,7 @@ func (d *demuxer) AddStream(
 	maxMessageSize int,
 	messagesLimit TokenBucketParams,
 	bytesLimit TokenBucketParams,
+	compression StreamCompression,
 ) bool {
 	d.mutex.Lock()
 	defer d.mutex.Unlock()
@@ -76,6 +83,9 @@ func (d *demuxer) AddStream(
 		maxMessageSize,
 		makeRateLimiter(messagesLimit),
 		makeRateLimiter(bytesLimit),
+		compression,
+		0,
+		0,
 	}
 	return true
 }
@@ -87,7 +97,11 @@ func (d *demuxer) RemoveStream(sid streamID) {
 	delete(d.streams, sid)
 }
 
-func (d *demuxer) ShouldPush(sid streamID, size int) shouldPushResult {
+// ShouldPush checks whether a message should be pushed. size is the
+// (decompressed) size of the message. compressed indicates whether the
+// message was sent compressed, which is only allowed if compression is
+// enabled for the stream.
+func (d *demuxer) ShouldPush(sid streamID, size int, compressed bool) shouldPushResult {
 	d.mutex.Lock()
 	defer d.mutex.Unlock()
 
@@ -96,6 +110,10 @@ func (d *demuxer) ShouldPush(sid streamID, size int) shouldPushResult {
 		return shouldPushResultUnknownStream
 	}
 
+	if compressed && s.compression == StreamCompressionNone {
+		return shouldPushResultUnexpectedCompression
+	}
+
 	if size > s.maxMessageSize {
 		return shouldPushResultMessageTooBig
 	}
@@ -104,10 +122,12 @@ func (d *demuxer) ShouldPush(sid streamID, size int) shouldPushResult {
 	bytesLimiterAllow := s.bytesLimiter.RemoveTokens(uint32(size))
 
 	if !messagesLimiterAllow {
+		s.droppedLimits++
 		return shouldPushResultMessagesLimitExceeded
 	}
 
 	if !bytesLimiterAllow {
+		s.droppedLimits++
 		return shouldPushResultBytesLimitExceeded
 	}
 
@@ -128,6 +148,7 @@ func (d *demuxer) PushMessage(sid streamID, msg []byte) pushResult {
 		result = pushResultSuccess
 	} else {
 		result = pushResultDropped
+		s.droppedOverflow++
 	}
 
 	select {
@@ -170,3 +191,26 @@ func (d *demuxer) SignalPending(sid streamID) <-chan struct{} {
 
 	return s.chSignal
 }
+
+type demuxerStreamSnapshot struct {
+	buffered        int
+	bufferSize      int
+	droppedOverflow uint64
+	droppedLimits   uint64
+}
+
+func (d *demuxer) Snapshot(sid streamID) (demuxerStreamSnapshot, bool) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	s, ok := d.streams[sid]
+	if !ok {
+		return demuxerStreamSnapshot{}, false
+	}
+	return demuxerStreamSnapshot{
+		s.buffer.Len(),
+		s.buffer.Cap(),
+		s.droppedOverflow,
+		s.droppedLimits,
+	}, true
+}
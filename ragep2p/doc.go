@@ -24,6 +24,11 @@
 // guarantee that messages that are delivered are delivered in FIFO order and
 // without modifications.
 //
+// Streams created with Host.NewReliableStream instead deliver every message
+// at least once, in order, as long as both sides keep the Stream open. Messages
+// are acknowledged by the receiver and retransmitted by the sender if needed,
+// also after the connection has been re-established.
+//
 // # Peer discovery
 //
 // ragep2p will handle peer discovery (i.e. associating network addresses like
@@ -34,6 +39,29 @@
 // sequentially dialing all of them until a connection is successfully
 // established.
 //
+// # Stream priorities
+//
+// All Streams with a peer share a single connection. Each Stream has a
+// StreamPriority that determines the order in which outgoing messages of
+// different Streams are written: Streams of a higher priority class always
+// go first, and Streams of the same class share the connection in proportion
+// to their weights. A Stream with lots of traffic thus cannot starve
+// latency-sensitive Streams of the same or a higher class.
+//
+// # Compression
+//
+// Streams can opt into compression of their messages, see StreamCompression.
+// Compression is negotiated when a Stream is opened and only used if both
+// sides enable it.
+//
+// # Keepalive
+//
+// If the remote peer supports it, ragep2p sends a ping frame on the connection
+// every few seconds and measures the round-trip time until the pong frame
+// answering it arrives. A connection whose pings go unanswered for too long is
+// considered dead, e.g. half-open after the remote host crashed, and is
+// closed, so that a new connection can be dialed.
+//
 // # Thread Safety
 //
 // All public functions on Host and Stream are thread-safe.
@@ -46,7 +74,9 @@
 // We allocate a buffer for each message received. In principle, this could allo
 // an adversary to force a recipient to run out of memory. To defend against
 // this, we put limits on the length of messages and rate limit messages,
-// thereby also limiting adversarially-controlled allocations.
+// thereby also limiting adversarially-controlled allocations. For compressed
+// messages, these limits apply to the decompressed size, which is checked
+// before decompressing.
 //
 // # Security
 //
@@ -56,7 +86,9 @@
 // ragep2p's security model assumes that all Streams on the local Host behave
 // honestly and cooperatively. Since many Streams are multiplexed over a single
 // connection, a single "bad" Stream could completely exhaust the entire
-// connection preventing other Streams from delivering messages as well. Other
+// connection preventing other Streams from delivering messages as well.
+// (Stream priorities limit the damage a Stream can do to Streams of its own
+// or higher priority classes, but not to lower ones.) Other
 // network participants, however, are not assumed to behave honestly and we
 // attempt to defend against fingerprinting, impersonation, MitM, resource
 // exhaustion, tarpitting, etc.
@@ -92,6 +124,14 @@
 // The audience for these metrics are operators of software using ragep2p and
 // developers building on top of ragep2p.
 //
+// # Introspection
+//
+// Host.Snapshot() returns the current state of a Host's peers, connections,
+// and Streams, including buffer occupancy and counts of dropped messages.
+// NewSnapshotHandler() serves the same information as JSON over HTTP. Since
+// the snapshot reveals the host's peers and their network addresses, the
+// handler should only be exposed on internal debug endpoints.
+//
 // # Suggested Health Checks
 //
 // The following example health checks in PromQL enable operators to monitor
@@ -124,4 +164,20 @@
 // to a misconfiguration in my infrastructure.
 //
 //	rate(ragep2p_host_inbound_dials_total[48h]) > 0
+//
+// Are messages sent on a stream written to the connection quickly? If not,
+// the stream is blocked by streams with a higher priority, by streams of the
+// same priority class with higher weight, or by a slow connection.
+//
+//	histogram_quantile(0.99, rate(ragep2p_stream_send_queue_delay_seconds_bucket[5m])) < 1
+//
+// Is the round-trip time to the remote peer reasonable? If not, this may
+// explain slow protocol rounds involving that peer.
+//
+//	histogram_quantile(0.9, rate(ragep2p_peer_rtt_seconds_bucket[10m])) < 0.5
+//
+// Are connections to the remote peer closed because pings go unanswered? If
+// so, this points to an unreliable network path or an overloaded remote host.
+//
+//	increase(ragep2p_peer_conn_pong_timeouts_total[1h]) == 0
 package ragep2p
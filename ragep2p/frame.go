@@ -19,6 +19,15 @@ const (
 	frameTypeOpen
 	frameTypeClose
 	frameTypeData
+	// Like frameTypeData, but the payload consists of the uncompressed length
+	// of the message as uint32 followed by the DEFLATE-compressed message. Only
+	// sent on streams for which both sides have enabled compression.
+	frameTypeCompressedData
+	// Ping and pong frames carry an 8 byte nonce and no stream id. A pong
+	// echoes the nonce of the ping it answers. Only sent on connections for
+	// which both sides support them, see tlsALPNProtocolPing.
+	frameTypePing
+	frameTypePong
 )
 
 type frameHeader struct {
@@ -46,6 +55,9 @@ func decodeFrameHeader(encoded []byte) (frameHeader, error) {
 	case frameTypeOpen:
 	case frameTypeClose:
 	case frameTypeData:
+	case frameTypeCompressedData:
+	case frameTypePing:
+	case frameTypePong:
 	default:
 		return frameHeader{}, errUnknownFrameType
 	}
@@ -76,3 +88,54 @@ func getStreamID(self types.PeerID, other types.PeerID, name string) streamID {
 	copy(result[:], h.Sum(nil))
 	return result
 }
+
+// Open frames carry the stream name, optionally followed by a zero byte and a
+// byte of stream options. Peers that predate stream options treat the whole
+// payload as the stream name, which only affects logging. Options are thus
+// only appended if there are any.
+type streamOptions uint8
+
+const (
+	streamOptionCompressionDeflate streamOptions = 1 << iota
+	streamOptionReliable
+)
+
+const streamOptionsEncodedSize = 2
+
+func makeStreamOptions(compression StreamCompression, reliable bool) streamOptions {
+	var options streamOptions
+	if compression == StreamCompressionDeflate {
+		options |= streamOptionCompressionDeflate
+	}
+	if reliable {
+		options |= streamOptionReliable
+	}
+	return options
+}
+
+func (o streamOptions) compression() StreamCompression {
+	if o&streamOptionCompressionDeflate != 0 {
+		return StreamCompressionDeflate
+	}
+	return StreamCompressionNone
+}
+
+func (o streamOptions) reliable() bool {
+	return o&streamOptionReliable != 0
+}
+
+func encodeOpenPayload(streamName string, options streamOptions) []byte {
+	payload := []byte(streamName)
+	if options != 0 {
+		payload = append(payload, 0, byte(options))
+	}
+	return payload
+}
+
+// Unknown options are ignored, so that options can be added in the future.
+func decodeOpenPayload(payload []byte) (string, streamOptions) {
+	if i := bytes.IndexByte(payload, 0); i >= 0 && i == len(payload)-streamOptionsEncodedSize {
+		return string(payload[:i]), streamOptions(payload[i+1])
+	}
+	return string(payload), 0
+}
@@ -16,6 +16,16 @@ func NewMessageBuffer(cap int) *MessageBuffer {
 	}
 }
 
+// Len returns the number of items in the buffer
+func (rb *MessageBuffer) Len() int {
+	return rb.length
+}
+
+// Cap returns the capacity of the buffer
+func (rb *MessageBuffer) Cap() int {
+	return len(rb.buffer)
+}
+
 // Peek at the front item
 func (rb *MessageBuffer) Peek() []byte {
 	if rb.length == 0 {
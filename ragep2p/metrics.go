@@ -1,6 +1,8 @@
 package ragep2p
 
 import (
+	"sync"
+
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/smartcontractkit/libocr/commontypes"
 	"github.com/smartcontractkit/libocr/internal/metricshelper"
@@ -45,6 +47,14 @@ type peerMetrics struct {
 	rawconnRateLimitRate        prometheus.Gauge
 	rawconnRateLimitCapacity    prometheus.Gauge
 	messageBytes                prometheus.Histogram
+
+	compressionSentBytesTotal                 prometheus.Counter
+	compressionSentUncompressedBytesTotal     prometheus.Counter
+	compressionReceivedBytesTotal             prometheus.Counter
+	compressionReceivedUncompressedBytesTotal prometheus.Counter
+
+	rttSeconds            prometheus.Histogram
+	connPongTimeoutsTotal prometheus.Counter
 }
 
 func newPeerMetrics(registerer prometheus.Registerer, logger commontypes.Logger, self types.PeerID, other types.PeerID) *peerMetrics {
@@ -135,6 +145,55 @@ func newPeerMetrics(registerer prometheus.Registerer, logger commontypes.Logger,
 
 	metricshelper.RegisterOrLogError(logger, registerer, messageBytes, "ragep2p_experimental_peer_message_bytes")
 
+	compressionSentBytesTotal := prometheus.NewCounter(prometheus.CounterOpts{
+		Name:        "ragep2p_peer_compression_sent_bytes_total",
+		Help:        "The number of bytes of messages sent to the remote peer on streams with compression, after compression. Divide ragep2p_peer_compression_sent_uncompressed_bytes_total by this to get the compression ratio",
+		ConstLabels: labels,
+	})
+
+	metricshelper.RegisterOrLogError(logger, registerer, compressionSentBytesTotal, "ragep2p_peer_compression_sent_bytes_total")
+
+	compressionSentUncompressedBytesTotal := prometheus.NewCounter(prometheus.CounterOpts{
+		Name:        "ragep2p_peer_compression_sent_uncompressed_bytes_total",
+		Help:        "The number of bytes of messages sent to the remote peer on streams with compression, before compression",
+		ConstLabels: labels,
+	})
+
+	metricshelper.RegisterOrLogError(logger, registerer, compressionSentUncompressedBytesTotal, "ragep2p_peer_compression_sent_uncompressed_bytes_total")
+
+	compressionReceivedBytesTotal := prometheus.NewCounter(prometheus.CounterOpts{
+		Name:        "ragep2p_peer_compression_received_bytes_total",
+		Help:        "The number of bytes of compressed messages received from the remote peer, before decompression. Divide ragep2p_peer_compression_received_uncompressed_bytes_total by this to get the compression ratio",
+		ConstLabels: labels,
+	})
+
+	metricshelper.RegisterOrLogError(logger, registerer, compressionReceivedBytesTotal, "ragep2p_peer_compression_received_bytes_total")
+
+	compressionReceivedUncompressedBytesTotal := prometheus.NewCounter(prometheus.CounterOpts{
+		Name:        "ragep2p_peer_compression_received_uncompressed_bytes_total",
+		Help:        "The number of bytes of compressed messages received from the remote peer, after decompression",
+		ConstLabels: labels,
+	})
+
+	metricshelper.RegisterOrLogError(logger, registerer, compressionReceivedUncompressedBytesTotal, "ragep2p_peer_compression_received_uncompressed_bytes_total")
+
+	rttSeconds := prometheus.NewHistogram(prometheus.HistogramOpts{
+		Name:        "ragep2p_peer_rtt_seconds",
+		Help:        "The round-trip time to the remote peer, measured with ping frames. This includes time the ping and pong frames spend waiting behind other frames on the connection. Only measured if the remote peer supports ping frames",
+		ConstLabels: labels,
+		Buckets:     prometheus.ExponentialBuckets(0.001, 2, 15), // 1ms, 2ms, ..., ~16s
+	})
+
+	metricshelper.RegisterOrLogError(logger, registerer, rttSeconds, "ragep2p_peer_rtt_seconds")
+
+	connPongTimeoutsTotal := prometheus.NewCounter(prometheus.CounterOpts{
+		Name:        "ragep2p_peer_conn_pong_timeouts_total",
+		Help:        "The number of secure connections with the remote peer that were closed because the remote peer didn't answer a ping frame in time, e.g. because the connection was half-open",
+		ConstLabels: labels,
+	})
+
+	metricshelper.RegisterOrLogError(logger, registerer, connPongTimeoutsTotal, "ragep2p_peer_conn_pong_timeouts_total")
+
 	return &peerMetrics{
 		registerer,
 		connEstablishedTotal,
@@ -147,6 +206,14 @@ func newPeerMetrics(registerer prometheus.Registerer, logger commontypes.Logger,
 		rawconnRateLimitRate,
 		rawconnRateLimitCapacity,
 		messageBytes,
+
+		compressionSentBytesTotal,
+		compressionSentUncompressedBytesTotal,
+		compressionReceivedBytesTotal,
+		compressionReceivedUncompressedBytesTotal,
+
+		rttSeconds,
+		connPongTimeoutsTotal,
 	}
 }
 
@@ -161,9 +228,104 @@ func (m *peerMetrics) Close() {
 	m.registerer.Unregister(m.rawconnRateLimitRate)
 	m.registerer.Unregister(m.rawconnRateLimitCapacity)
 	m.registerer.Unregister(m.messageBytes)
+	m.registerer.Unregister(m.compressionSentBytesTotal)
+	m.registerer.Unregister(m.compressionSentUncompressedBytesTotal)
+	m.registerer.Unregister(m.compressionReceivedBytesTotal)
+	m.registerer.Unregister(m.compressionReceivedUncompressedBytesTotal)
+	m.registerer.Unregister(m.rttSeconds)
+	m.registerer.Unregister(m.connPongTimeoutsTotal)
 }
 
 func (m *peerMetrics) SetConnRateLimit(tokenBucketParams TokenBucketParams) {
 	m.rawconnRateLimitRate.Set(tokenBucketParams.Rate)
 	m.rawconnRateLimitCapacity.Set(float64(tokenBucketParams.Capacity))
 }
+
+// streamMetricsSet hands out streamMetrics and shares them between all
+// streams with the same labels. A stream may be reopened under the same name
+// before the send loop of the old stream has exited and closed its metrics.
+// Since prometheus unregisters collectors by descriptor, closing the old
+// metrics would unregister the new stream's metrics, too. We thus only
+// unregister once the last stream using the metrics has closed them.
+type streamMetricsSet struct {
+	registerer prometheus.Registerer
+
+	mu      sync.Mutex
+	metrics map[streamMetricsKey]*streamMetrics
+}
+
+type streamMetricsKey struct {
+	self          types.PeerID
+	other         types.PeerID
+	streamName    string
+	priorityClass StreamPriorityClass
+}
+
+func newStreamMetricsSet(registerer prometheus.Registerer) *streamMetricsSet {
+	return &streamMetricsSet{
+		registerer,
+		sync.Mutex{},
+		map[streamMetricsKey]*streamMetrics{},
+	}
+}
+
+// Acquire returns the metrics for a stream. Every call must be matched by a
+// call to Close on the returned streamMetrics.
+func (s *streamMetricsSet) Acquire(logger commontypes.Logger, self types.PeerID, other types.PeerID, streamName string, priority StreamPriority) *streamMetrics {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := streamMetricsKey{self, other, streamName, priority.Class}
+	if m, ok := s.metrics[key]; ok {
+		m.refs++
+		return m
+	}
+	m := newStreamMetrics(s, key, logger)
+	s.metrics[key] = m
+	return m
+}
+
+type streamMetrics struct {
+	set  *streamMetricsSet
+	key  streamMetricsKey
+	refs int // protected by set.mu
+
+	sendQueueDelaySeconds prometheus.Histogram
+}
+
+func newStreamMetrics(set *streamMetricsSet, key streamMetricsKey, logger commontypes.Logger) *streamMetrics {
+	labels := map[string]string{
+		"peer_id":        key.self.String(),
+		"remote_peer_id": key.other.String(),
+		"stream_name":    key.streamName,
+		"priority_class": key.priorityClass.String(),
+	}
+
+	sendQueueDelaySeconds := prometheus.NewHistogram(prometheus.HistogramOpts{
+		Name:        "ragep2p_stream_send_queue_delay_seconds",
+		Help:        "The time messages sent on the stream spend queued before they are written to the connection. High values indicate that the stream is blocked by other streams with the remote peer, or that there is no connection.",
+		ConstLabels: labels,
+		Buckets:     prometheus.ExponentialBuckets(0.001, 4, 9), // 1ms, 4ms, ..., ~65s
+	})
+
+	metricshelper.RegisterOrLogError(logger, set.registerer, sendQueueDelaySeconds, "ragep2p_stream_send_queue_delay_seconds")
+
+	return &streamMetrics{
+		set,
+		key,
+		1,
+		sendQueueDelaySeconds,
+	}
+}
+
+func (m *streamMetrics) Close() {
+	m.set.mu.Lock()
+	defer m.set.mu.Unlock()
+
+	m.refs--
+	if m.refs > 0 {
+		return
+	}
+	delete(m.set.metrics, m.key)
+	m.set.registerer.Unregister(m.sendQueueDelaySeconds)
+}
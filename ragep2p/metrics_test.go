@@ -0,0 +1,59 @@
+package ragep2p
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/smartcontractkit/libocr/ragep2p/types"
+)
+
+// sendQueueDelaySeries returns the number of ragep2p_stream_send_queue_delay_seconds
+// series registered with registry.
+func sendQueueDelaySeries(t *testing.T, registry *prometheus.Registry) int {
+	t.Helper()
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, family := range families {
+		if family.GetName() == "ragep2p_stream_send_queue_delay_seconds" {
+			return len(family.GetMetric())
+		}
+	}
+	return 0
+}
+
+func TestStreamMetricsSurviveReopen(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	set := newStreamMetricsSet(registry)
+	normal := StreamPriority{StreamPriorityClassNormal, 1}
+
+	old := set.Acquire(nopLogger{}, types.PeerID{1}, types.PeerID{2}, "stream", normal)
+	// The stream is reopened before the old stream's send loop has closed
+	// its metrics
+	reopened := set.Acquire(nopLogger{}, types.PeerID{1}, types.PeerID{2}, "stream", normal)
+	if reopened != old {
+		t.Fatalf("expected streams with the same labels to share metrics")
+	}
+	high := set.Acquire(nopLogger{}, types.PeerID{1}, types.PeerID{2}, "stream", StreamPriority{StreamPriorityClassHigh, 1})
+	if n := sendQueueDelaySeries(t, registry); n != 2 {
+		t.Fatalf("expected 2 series, got %v", n)
+	}
+
+	old.Close()
+	if n := sendQueueDelaySeries(t, registry); n != 2 {
+		t.Fatalf("closing the old stream's metrics unregistered the reopened stream's, %v series left", n)
+	}
+	reopened.Close()
+	high.Close()
+	if n := sendQueueDelaySeries(t, registry); n != 0 {
+		t.Fatalf("expected all series to be unregistered, got %v", n)
+	}
+
+	// Metrics can be registered again after all streams closed them
+	again := set.Acquire(nopLogger{}, types.PeerID{1}, types.PeerID{2}, "stream", normal)
+	defer again.Close()
+	if again == old || sendQueueDelaySeries(t, registry) != 1 {
+		t.Fatalf("expected fresh metrics to be registered")
+	}
+}
@@ -0,0 +1,67 @@
+package ragep2p
+
+import (
+	"encoding/binary"
+	"sync"
+	"time"
+)
+
+// We send a ping frame every pingInterval and close the connection if it isn't
+// answered within pongTimeout. Without this, we wouldn't notice that a
+// connection is half-open, e.g. because the remote host crashed, until a write
+// to it times out, which may never happen if we have nothing to send.
+const (
+	pingInterval = 5 * time.Second
+	// Pongs may be delayed by frames written before them and by rate limiting,
+	// so we are generous here.
+	pongTimeout = 4 * netTimeout
+)
+
+const pingPayloadSize = 8
+
+func encodePingPayload(nonce uint64) []byte {
+	payload := make([]byte, pingPayloadSize)
+	binary.BigEndian.PutUint64(payload, nonce)
+	return payload
+}
+
+func decodePingPayload(payload []byte) uint64 {
+	return binary.BigEndian.Uint64(payload)
+}
+
+// pinger keeps track of the ping frame sent on a connection that hasn't been
+// answered yet. At most one ping is outstanding at any time. Thread-safe, the
+// write loop sends pings and the read loop receives pongs.
+type pinger struct {
+	mu     sync.Mutex
+	nonce  uint64
+	sentAt time.Time // zero if no ping is outstanding
+}
+
+// Tick is called every pingInterval. It returns the nonce of a ping frame that
+// should be sent, if any, and whether the outstanding ping has timed out.
+func (p *pinger) Tick(now time.Time) (nonce uint64, send bool, timedOut bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if !p.sentAt.IsZero() {
+		return 0, false, now.Sub(p.sentAt) > pongTimeout
+	}
+	p.nonce++
+	p.sentAt = now
+	return p.nonce, true, false
+}
+
+// Pong returns the round-trip time if the pong with the given nonce answers
+// the outstanding ping. Other pongs are ignored.
+func (p *pinger) Pong(nonce uint64, now time.Time) (time.Duration, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.sentAt.IsZero() || nonce != p.nonce {
+		return 0, false
+	}
+	rtt := now.Sub(p.sentAt)
+	p.sentAt = time.Time{}
+	return rtt, true
+}
@@ -0,0 +1,37 @@
+package ragep2p
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPinger(t *testing.T) {
+	var p pinger
+	start := time.Now()
+
+	nonce, send, timedOut := p.Tick(start)
+	if !send || timedOut {
+		t.Fatalf("expected first tick to send a ping")
+	}
+	// At most one ping is outstanding
+	if _, send, timedOut := p.Tick(start.Add(pingInterval)); send || timedOut {
+		t.Fatalf("unexpected tick result with outstanding ping")
+	}
+	if _, ok := p.Pong(nonce+1, start.Add(time.Second)); ok {
+		t.Fatalf("pong with wrong nonce was accepted")
+	}
+	if rtt, ok := p.Pong(nonce, start.Add(time.Second)); !ok || rtt != time.Second {
+		t.Fatalf("unexpected pong result %v, %v", rtt, ok)
+	}
+	if _, ok := p.Pong(nonce, start.Add(2*time.Second)); ok {
+		t.Fatalf("duplicate pong was accepted")
+	}
+
+	nonce2, send, _ := p.Tick(start.Add(pingInterval))
+	if !send || nonce2 == nonce {
+		t.Fatalf("expected new ping with fresh nonce")
+	}
+	if _, _, timedOut := p.Tick(start.Add(pingInterval + pongTimeout + time.Nanosecond)); !timedOut {
+		t.Fatalf("expected unanswered ping to time out")
+	}
+}
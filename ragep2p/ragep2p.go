@@ -4,11 +4,13 @@ import (
 	"context"
 	"crypto/ed25519"
 	"crypto/tls"
+	"encoding/binary"
 	"encoding/hex"
 	"fmt"
 	"io"
 	"math/rand"
 	"net"
+	"strings"
 	"sync"
 	"time"
 
@@ -17,9 +19,8 @@ import (
 
 	"github.com/smartcontractkit/libocr/commontypes"
 	"github.com/smartcontractkit/libocr/internal/loghelper"
+	"github.com/smartcontractkit/libocr/internal/mtls"
 	"github.com/smartcontractkit/libocr/ragep2p/internal/knock"
-	"github.com/smartcontractkit/libocr/ragep2p/internal/msgbuf"
-	"github.com/smartcontractkit/libocr/ragep2p/internal/mtls"
 	"github.com/smartcontractkit/libocr/ragep2p/internal/ratelimit"
 	"github.com/smartcontractkit/libocr/ragep2p/internal/ratelimitedconn"
 	"github.com/smartcontractkit/libocr/ragep2p/types"
@@ -68,10 +69,12 @@ type peerStreamOpenRequest struct {
 	maxMessageLength   int
 	messagesLimit      TokenBucketParams
 	bytesLimit         TokenBucketParams
+	compression        StreamCompression
+	reliable           bool
 }
 
 type peerStreamOpenResponse struct {
-	chSendOnOff <-chan bool
+	chSendOnOff <-chan streamOnOff
 	demux       *demuxer
 	err         error
 }
@@ -92,9 +95,17 @@ type newConnNotification struct {
 type streamStateNotification struct {
 	streamID   streamID
 	streamName string // Used for sanity check, populated only on stream open and empty on stream close
+	options    streamOptions
 	open       bool
 }
 
+// streamOnOff turns a stream on or off. compression is the compression
+// negotiated with the other side, it is only meaningful if on is true.
+type streamOnOff struct {
+	on          bool
+	compression StreamCompression
+}
+
 type streamIDAndData struct {
 	StreamID streamID
 	Data     []byte
@@ -106,6 +117,16 @@ type peerConnLifeCycle struct {
 	chConnTerminated <-chan struct{}
 }
 
+// peerConnInfo describes the active connection with a peer for snapshots.
+type peerConnInfo struct {
+	connected   bool
+	remoteAddr  string
+	inbound     bool
+	established time.Time
+	// identifies the connection
+	chConnTerminated <-chan struct{}
+}
+
 type peer struct {
 	chDone <-chan struct{}
 
@@ -122,8 +143,11 @@ type peer struct {
 	connLifeCycleMu sync.Mutex
 	connLifeCycle   peerConnLifeCycle
 
-	chStreamToConn chan streamIDAndData
-	demuxer        *demuxer
+	connInfoMu sync.Mutex
+	connInfo   peerConnInfo
+
+	scheduler *scheduler
+	demuxer   *demuxer
 
 	chNewConnNotification chan<- newConnNotification
 
@@ -141,6 +165,9 @@ type HostConfig struct {
 	// DurationBetweenDials is the minimum duration between two dials. It is
 	// not the exact duration because of jitter.
 	DurationBetweenDials time.Duration
+	// Transport over which connections are established. If nil,
+	// TCPTransport is used.
+	Transport Transport
 }
 
 // A Host allows users to establish Streams with other peers identified by their
@@ -156,7 +183,8 @@ type Host struct {
 	logger            loghelper.LoggerWithContext
 	metricsRegisterer prometheus.Registerer
 
-	hostMetrics *hostMetrics
+	hostMetrics   *hostMetrics
+	streamMetrics *streamMetricsSet
 
 	// Derived from secretKey
 	id      types.PeerID
@@ -190,6 +218,15 @@ func NewHost(
 	if len(listenAddresses) == 0 {
 		return nil, fmt.Errorf("no listen addresses provided")
 	}
+	transport := config.Transport
+	if transport == nil {
+		transport = TCPTransport{}
+	}
+	for _, addr := range listenAddresses {
+		if _, err := transport.ParseAddress(addr); err != nil {
+			return nil, fmt.Errorf("invalid listen address %q: %w", addr, err)
+		}
+	}
 
 	id, err := mtls.StaticallySizedEd25519PublicKey(secretKey.Public())
 	if err != nil {
@@ -207,6 +244,7 @@ func NewHost(
 		metricsRegisterer,
 
 		newHostMetrics(metricsRegisterer, logger, types.PeerID(id)),
+		newStreamMetricsSet(metricsRegisterer),
 
 		id,
 		mtls.NewMinimalX509CertFromPrivateKey(secretKey),
@@ -244,9 +282,9 @@ func (ho *Host) Start() error {
 		ho.dialLoop()
 	})
 	for _, addr := range ho.listenAddresses {
-		ln, err := net.Listen("tcp", addr)
+		ln, err := ho.transport().Listen(addr)
 		if err != nil {
-			return fmt.Errorf("net.Listen(%q) failed: %w", addr, err)
+			return fmt.Errorf("Listen(%q) failed: %w", addr, err)
 		}
 		ho.subprocesses.Go(func() {
 			ho.listenLoop(ln)
@@ -320,7 +358,10 @@ func (ho *Host) findOrCreatePeer(other types.PeerID) *peer {
 				chConnTerminated,
 			},
 
-			make(chan streamIDAndData),
+			sync.Mutex{},
+			peerConnInfo{},
+
+			newScheduler(),
 			demuxer,
 
 			chNewConnNotification,
@@ -379,11 +420,32 @@ func peerLoop(
 
 	type stream struct {
 		name                      string
-		chOnOff                   chan<- bool
+		chOnOff                   chan<- streamOnOff
 		messagesLimit, bytesLimit TokenBucketParams
+		compression               StreamCompression
+		reliable                  bool
 	}
 	streams := map[streamID]stream{}
-	otherStreams := map[streamID]struct{}{}
+	// options announced by the other side for each stream it has opened
+	otherStreams := map[streamID]streamOptions{}
+
+	// Compression is only used if both sides have enabled it. Reliable and
+	// best-effort streams use different message formats, so a stream is only
+	// turned on if both sides agree on reliability.
+	turnOn := func(s stream, otherOptions streamOptions) streamOnOff {
+		if s.reliable != otherOptions.reliable() {
+			logger.Warn("Not turning on stream, only one side requested reliable delivery", commontypes.LogFields{
+				"streamName":    s.name,
+				"localReliable": s.reliable,
+			})
+			return streamOnOff{false, StreamCompressionNone}
+		}
+		compression := StreamCompressionNone
+		if s.compression == otherOptions.compression() {
+			compression = s.compression
+		}
+		return streamOnOff{true, compression}
+	}
 
 	var chConnTerminated <-chan struct{}
 
@@ -399,6 +461,7 @@ func peerLoop(
 			selfStreamStateNotification = streamStateNotification{
 				streamID,
 				streams[streamID].name,
+				makeStreamOptions(streams[streamID].compression, streams[streamID].reliable),
 				state,
 			}
 			break
@@ -410,9 +473,10 @@ func peerLoop(
 			delete(pendingSelfStreamStateNotifications, selfStreamStateNotification.streamID)
 
 			// if the stream has been opened by the other end already, switch it on right away
-			if _, other := otherStreams[selfStreamStateNotification.streamID]; other && selfStreamStateNotification.open {
+			if otherOptions, other := otherStreams[selfStreamStateNotification.streamID]; other && selfStreamStateNotification.open {
+				s := streams[selfStreamStateNotification.streamID]
 				select {
-				case streams[selfStreamStateNotification.streamID].chOnOff <- true:
+				case s.chOnOff <- turnOn(s, otherOptions):
 				case <-ctx.Done():
 				}
 			}
@@ -436,12 +500,12 @@ func peerLoop(
 			pendingSelfStreamStateNotifications = map[streamID]bool{}
 
 			// Reset streams on other side
-			otherStreams = map[streamID]struct{}{}
+			otherStreams = map[streamID]streamOptions{}
 
 			// Pause all streams on our side
 			for _, stream := range streams {
 				select {
-				case stream.chOnOff <- false:
+				case stream.chOnOff <- streamOnOff{false, StreamCompressionNone}:
 				case <-ctx.Done():
 				}
 			}
@@ -458,7 +522,7 @@ func peerLoop(
 				break
 			}
 			if notification.open {
-				otherStreams[notification.streamID] = struct{}{}
+				otherStreams[notification.streamID] = notification.options
 			} else {
 				delete(otherStreams, notification.streamID)
 			}
@@ -470,8 +534,12 @@ func peerLoop(
 						"remoteStreamName": notification.streamName,
 					})
 				}
+				onOff := streamOnOff{false, StreamCompressionNone}
+				if notification.open {
+					onOff = turnOn(s, notification.options)
+				}
 				select {
-				case s.chOnOff <- notification.open:
+				case s.chOnOff <- onOff:
 				case <-ctx.Done():
 				}
 			}
@@ -492,20 +560,22 @@ func peerLoop(
 			} else {
 				connRateLimiter.AddStream(req.messagesLimit, req.bytesLimit)
 				metrics.SetConnRateLimit(connRateLimiter.TokenBucketParams())
-				if !demux.AddStream(req.streamID, req.incomingBufferSize, req.maxMessageLength, req.messagesLimit, req.bytesLimit) {
+				if !demux.AddStream(req.streamID, req.incomingBufferSize, req.maxMessageLength, req.messagesLimit, req.bytesLimit, req.compression) {
 					logger.Warn("Assumption violation. Failed to add already existing stream to demuxer", commontypes.LogFields{
 						"streamOpenRequest": req,
 					})
 					// let's try to fix the problem by removing and adding the stream again
 					demux.RemoveStream(req.streamID)
-					demux.AddStream(req.streamID, req.incomingBufferSize, req.maxMessageLength, req.messagesLimit, req.bytesLimit)
+					demux.AddStream(req.streamID, req.incomingBufferSize, req.maxMessageLength, req.messagesLimit, req.bytesLimit, req.compression)
 				}
-				chOnOff := make(chan bool)
+				chOnOff := make(chan streamOnOff)
 				streams[req.streamID] = stream{
 					req.streamName,
 					chOnOff,
 					req.messagesLimit,
 					req.bytesLimit,
+					req.compression,
+					req.reliable,
 				}
 				if chConnTerminated != nil {
 					pendingSelfStreamStateNotifications[req.streamID] = true
@@ -631,10 +701,14 @@ func (ho *Host) dialLoop() {
 
 				logger := p.logger.MakeChild(commontypes.LogFields{"direction": "out", "remoteAddr": address})
 
-				dialer := net.Dialer{
-					Timeout: ho.config.DurationBetweenDials,
+				if _, err := ho.transport().ParseAddress(address); err != nil {
+					logger.Warn("Discoverer returned invalid address", commontypes.LogFields{"error": err})
+					return
 				}
-				conn, err := dialer.DialContext(ho.ctx, "tcp", address)
+
+				dialCtx, dialCancel := context.WithTimeout(ho.ctx, ho.config.DurationBetweenDials)
+				defer dialCancel()
+				conn, err := ho.transport().Dial(dialCtx, address)
 				if err != nil {
 					logger.Warn("Dial error", commontypes.LogFields{"error": err})
 					return
@@ -831,7 +905,9 @@ func (ho *Host) handleConnection(incoming bool, rlConn *ratelimitedconn.RateLimi
 
 	rlConn.EnableRateLimiting()
 
-	logger.Info("Connection established", nil)
+	pingsEnabled := tlsConn.ConnectionState().NegotiatedProtocol == tlsALPNProtocolPing
+
+	logger.Info("Connection established", commontypes.LogFields{"pingsEnabled": pingsEnabled})
 	peer.metrics.connEstablishedTotal.Inc()
 	if incoming {
 		peer.metrics.connEstablishedInboundTotal.Inc()
@@ -846,15 +922,33 @@ func (ho *Host) handleConnection(incoming bool, rlConn *ratelimitedconn.RateLimi
 	chConnTerminated := make(chan struct{})
 	peer.connLifeCycle.connCancel = connCancel
 	peer.connLifeCycle.chConnTerminated = chConnTerminated
+	peer.connInfoMu.Lock()
+	peer.connInfo = peerConnInfo{
+		true,
+		tlsConn.RemoteAddr().String(),
+		incoming,
+		time.Now(),
+		chConnTerminated,
+	}
+	peer.connInfoMu.Unlock()
 	peer.connLifeCycle.connSubs.Go(func() {
 		defer connCancel()
+		defer func() {
+			peer.connInfoMu.Lock()
+			defer peer.connInfoMu.Unlock()
+			// a newer connection may have replaced ours already
+			if peer.connInfo.chConnTerminated == chConnTerminated {
+				peer.connInfo = peerConnInfo{}
+			}
+		}()
 		authenticatedConnectionLoop(
 			connCtx,
 			tlsConn,
 			peer.chOtherStreamStateNotification,
 			peer.chSelfStreamStateNotification,
 			peer.demuxer,
-			peer.chStreamToConn,
+			peer.scheduler,
+			pingsEnabled,
 			chConnTerminated,
 			logger,
 			peer.metrics,
@@ -878,6 +972,19 @@ type TokenBucketParams struct {
 	Capacity uint32
 }
 
+// StreamOptions contains the optional parameters of a stream. The zero value
+// gives the behavior of NewStream.
+type StreamOptions struct {
+	// The priority of the stream's outgoing messages relative to other
+	// streams with the same peer. The zero value selects
+	// StreamPriority{StreamPriorityClassNormal, 1}.
+	Priority StreamPriority
+	// The compression to use if the other side supports it.
+	Compression StreamCompression
+}
+
+var defaultStreamPriority = StreamPriority{StreamPriorityClassNormal, 1}
+
 // NewStream creates a new bidirectional stream with peer other for streamName.
 // It is parameterized with a maxMessageLength, the maximum size of a message in
 // bytes and two parameters for rate limiting.
@@ -890,15 +997,103 @@ func (ho *Host) NewStream(
 	messagesLimit TokenBucketParams,
 	bytesLimit TokenBucketParams,
 ) (*Stream, error) {
+	return ho.newStream(other, streamName, outgoingBufferSize, incomingBufferSize, maxMessageLength, messagesLimit, bytesLimit, StreamOptions{}, false)
+}
+
+// NewStreamWithOptions is like NewStream, but additionally takes options.
+func (ho *Host) NewStreamWithOptions(
+	other types.PeerID,
+	streamName string,
+	outgoingBufferSize int,
+	incomingBufferSize int,
+	maxMessageLength int,
+	messagesLimit TokenBucketParams,
+	bytesLimit TokenBucketParams,
+	options StreamOptions,
+) (*Stream, error) {
+	return ho.newStream(other, streamName, outgoingBufferSize, incomingBufferSize, maxMessageLength, messagesLimit, bytesLimit, options, false)
+}
+
+// NewReliableStream is like NewStreamWithOptions, but creates a reliable
+// stream: as long as both sides keep the stream open, messages are delivered
+// at least once and in order, even across reconnects. Both sides must create
+// the stream with NewReliableStream; if only one side does, the stream remains
+// turned off and no messages are exchanged.
+//
+// outgoingBufferSize is the window, the maximum number of messages that have
+// been sent but not yet acknowledged by the other side. SendMessage blocks
+// while the window is full. To avoid needless retransmissions,
+// incomingBufferSize and the limits of the other side should accommodate a
+// full window.
+//
+// Every message, including retransmissions and acknowledgements, counts
+// towards the limits of the receiving side just like on regular streams.
+// Messages dropped due to limits are retransmitted with exponential backoff.
+// Messages that haven't been acknowledged when the stream is closed are lost.
+func (ho *Host) NewReliableStream(
+	other types.PeerID,
+	streamName string,
+	outgoingBufferSize int,
+	incomingBufferSize int,
+	maxMessageLength int,
+	messagesLimit TokenBucketParams,
+	bytesLimit TokenBucketParams,
+	options StreamOptions,
+) (*Stream, error) {
+	return ho.newStream(other, streamName, outgoingBufferSize, incomingBufferSize, maxMessageLength, messagesLimit, bytesLimit, options, true)
+}
+
+func (ho *Host) newStream(
+	other types.PeerID,
+	streamName string,
+	outgoingBufferSize int,
+	incomingBufferSize int,
+	maxMessageLength int,
+	messagesLimit TokenBucketParams,
+	bytesLimit TokenBucketParams,
+	options StreamOptions,
+	reliable bool,
+) (*Stream, error) {
+	priority, compression := options.Priority, options.Compression
+	if priority == (StreamPriority{}) {
+		priority = defaultStreamPriority
+	}
+
 	if other == ho.id {
 		return nil, fmt.Errorf("stream with self is forbidden")
 	}
 
+	if outgoingBufferSize < 1 {
+		return nil, fmt.Errorf("outgoingBufferSize %v must be positive", outgoingBufferSize)
+	}
+
+	if err := priority.check(); err != nil {
+		return nil, err
+	}
+
+	if err := compression.check(); err != nil {
+		return nil, err
+	}
+
+	if strings.IndexByte(streamName, 0) >= 0 {
+		return nil, fmt.Errorf("streamName '%v' must not contain zero bytes", streamName)
+	}
+
+	if options := makeStreamOptions(compression, reliable); MaxStreamNameLength < len(encodeOpenPayload(streamName, options)) {
+		return nil, fmt.Errorf("streamName '%v' is too long to enable compression or reliable delivery, maximum length is %v", streamName, MaxStreamNameLength-streamOptionsEncodedSize)
+	}
+
 	if MaxStreamNameLength < len(streamName) {
 		return nil, fmt.Errorf("streamName '%v' is longer than maximum length %v", streamName, MaxStreamNameLength)
 	}
 
-	if MaxMessageLength < maxMessageLength {
+	// Reliable streams add a header to every message
+	wireMaxMessageLength := maxMessageLength
+	if reliable {
+		wireMaxMessageLength += reliableDataHeaderSize
+	}
+
+	if MaxMessageLength < wireMaxMessageLength {
 		return nil, fmt.Errorf("maxMessageLength %v is greater than global MaxMessageLength %v", maxMessageLength, MaxMessageLength)
 	}
 
@@ -916,9 +1111,11 @@ func (ho *Host) NewStream(
 		sid,
 		streamName,
 		incomingBufferSize,
-		maxMessageLength,
+		wireMaxMessageLength,
 		messagesLimit,
 		bytesLimit,
+		compression,
+		reliable,
 	}:
 		response = <-p.chStreamOpenResponse
 		if response.err != nil {
@@ -934,6 +1131,22 @@ func (ho *Host) NewStream(
 		"streamID":   streamID,
 		"streamName": streamName,
 	})
+
+	// For reliable streams, outgoingBufferSize bounds the number of
+	// unacknowledged messages. The queue needs additional room for acks.
+	queueSize := outgoingBufferSize
+	if reliable {
+		queueSize = 2 * outgoingBufferSize
+	}
+
+	metrics := ho.streamMetrics.Acquire(streamLogger, ho.id, other, streamName, priority)
+	schedulerStream, ok := p.scheduler.AddStream(streamID, streamName, priority, queueSize, metrics)
+	if !ok {
+		streamLogger.Warn("Assumption violation. Failed to add already existing stream to scheduler", nil)
+		// let's try to fix the problem by removing and adding the stream again
+		p.scheduler.RemoveStream(schedulerStream)
+		schedulerStream, _ = p.scheduler.AddStream(streamID, streamName, priority, queueSize, metrics)
+	}
 	s := Stream{
 		sync.Mutex{},
 		false,
@@ -941,8 +1154,9 @@ func (ho *Host) NewStream(
 		streamName,
 		other,
 		streamID,
-
+		reliable,
 		outgoingBufferSize,
+
 		ho,
 
 		subprocesses.Subprocesses{},
@@ -951,8 +1165,11 @@ func (ho *Host) NewStream(
 		streamLogger,
 		make(chan []byte),
 		make(chan []byte, 5),
+		make(chan reliableMessage),
+		metrics,
 
-		p.chStreamToConn,
+		p.scheduler,
+		schedulerStream,
 		response.demux,
 		response.chSendOnOff,
 
@@ -960,18 +1177,30 @@ func (ho *Host) NewStream(
 		p.chStreamCloseResponse,
 	}
 
-	s.subprocesses.Go(func() {
-		s.receiveLoop()
-	})
-	s.subprocesses.Go(func() {
-		s.sendLoop()
-	})
+	if reliable {
+		s.subprocesses.Go(func() {
+			s.reliableReceiveLoop()
+		})
+		s.subprocesses.Go(func() {
+			s.reliableSendLoop()
+		})
+	} else {
+		s.subprocesses.Go(func() {
+			s.receiveLoop()
+		})
+		s.subprocesses.Go(func() {
+			s.sendLoop()
+		})
+	}
 
 	streamLogger.Info("NewStream succeeded", commontypes.LogFields{
 		"incomingBufferSize": incomingBufferSize,
 		"maxMessageLength":   maxMessageLength,
 		"messagesLimit":      messagesLimit,
 		"bytesLimit":         bytesLimit,
+		"priority":           priority,
+		"compression":        compression,
+		"reliable":           reliable,
 	})
 
 	return &s, nil
@@ -988,7 +1217,8 @@ type Stream struct {
 	name     string
 	other    types.PeerID
 	streamID streamID
-
+	reliable bool
+	// for reliable streams, the window size
 	outgoingBufferSize int
 
 	host *Host
@@ -999,10 +1229,13 @@ type Stream struct {
 	logger       loghelper.LoggerWithContext
 	chSend       chan []byte
 	chReceive    chan []byte
+	chAck        chan reliableMessage // only used by reliable streams
+	metrics      *streamMetrics
 
-	chStreamToConn chan<- streamIDAndData
-	demux          *demuxer
-	chStreamOnOff  <-chan bool
+	scheduler       *scheduler
+	schedulerStream *schedulerStream
+	demux           *demuxer
+	chStreamOnOff   <-chan streamOnOff
 
 	chStreamCloseRequest  chan<- peerStreamCloseRequest
 	chStreamCloseResponse <-chan peerStreamCloseResponse
@@ -1019,6 +1252,9 @@ func (st *Stream) Name() string {
 }
 
 // Best effort sending of messages. May fail without returning an error.
+//
+// For reliable streams, SendMessage blocks until there is room for the
+// message in the window, or the stream is closed.
 func (st *Stream) SendMessage(data []byte) {
 	select {
 	case st.chSend <- data:
@@ -1112,43 +1348,23 @@ func (st *Stream) receiveLoop() {
 }
 
 func (st *Stream) sendLoop() {
-	var chStreamToPeerOrNil chan<- streamIDAndData
-	var pending streamIDAndData
-	var onOff bool
-	pendingFilled := false
-
-	ringBuffer := msgbuf.NewMessageBuffer(st.outgoingBufferSize)
+	defer st.metrics.Close()
+	defer st.scheduler.RemoveStream(st.schedulerStream)
 
 	for {
 		select {
-		case onOff = <-st.chStreamOnOff:
-			if onOff {
-				if pendingFilled {
-					chStreamToPeerOrNil = st.chStreamToConn
-				}
-				st.logger.Info("Turned on stream", nil)
+		case onOff := <-st.chStreamOnOff:
+			st.scheduler.SetEnabled(st.streamID, onOff.on, onOff.compression)
+			if onOff.on {
+				st.logger.Info("Turned on stream", commontypes.LogFields{
+					"compression": onOff.compression,
+				})
 			} else {
-				chStreamToPeerOrNil = nil
 				st.logger.Info("Turned off stream", nil)
 			}
 
 		case msg := <-st.chSend:
-			if ringBuffer.Push(msg) != nil || !pendingFilled {
-				pending = streamIDAndData{st.streamID, ringBuffer.Peek()}
-				pendingFilled = true
-				if onOff {
-					chStreamToPeerOrNil = st.chStreamToConn
-				}
-			}
-
-		case chStreamToPeerOrNil <- pending:
-			ringBuffer.Pop()
-			if p := ringBuffer.Peek(); p != nil {
-				pending = streamIDAndData{st.streamID, p}
-			} else {
-				pendingFilled = false
-				chStreamToPeerOrNil = nil
-			}
+			st.scheduler.Push(st.streamID, msg)
 
 		case <-st.ctx.Done():
 			return
@@ -1166,7 +1382,8 @@ func authenticatedConnectionLoop(
 	chOtherStreamStateNotification chan<- streamStateNotification,
 	chSelfStreamStateNotification <-chan streamStateNotification,
 	demux *demuxer,
-	chWriteData <-chan streamIDAndData,
+	scheduler *scheduler,
+	pingsEnabled bool,
 	chTerminated chan<- struct{},
 	logger loghelper.LoggerWithContext,
 	metrics *peerMetrics,
@@ -1188,6 +1405,11 @@ func authenticatedConnectionLoop(
 	childCtx, childCancel := context.WithCancel(ctx)
 	defer childCancel()
 
+	pinger := &pinger{}
+	// Nonces of pings the write loop should answer. If a pong is pending
+	// already, the read loop drops further pings.
+	chPong := make(chan uint64, 1)
+
 	chReadTerminated := make(chan struct{})
 	subs.Go(func() {
 		authenticatedConnectionReadLoop(
@@ -1195,6 +1417,8 @@ func authenticatedConnectionLoop(
 			conn,
 			chOtherStreamStateNotification,
 			demux,
+			pinger,
+			chPong,
 			chReadTerminated,
 			logger,
 			metrics,
@@ -1207,7 +1431,10 @@ func authenticatedConnectionLoop(
 			childCtx,
 			conn,
 			chSelfStreamStateNotification,
-			chWriteData,
+			scheduler,
+			pingsEnabled,
+			pinger,
+			chPong,
 			chWriteTerminated,
 			logger,
 			metrics,
@@ -1228,6 +1455,8 @@ func authenticatedConnectionReadLoop(
 	conn net.Conn,
 	chOtherStreamStateNotification chan<- streamStateNotification,
 	demux *demuxer,
+	pinger *pinger,
+	chPong chan<- uint64,
 	chReadTerminated chan<- struct{},
 	logger loghelper.LoggerWithContext,
 	metrics *peerMetrics,
@@ -1259,6 +1488,10 @@ func authenticatedConnectionReadLoop(
 	// Note that we never reset this taper. There shouldn't be many messages
 	// with unknown stream id.
 	unknownStreamIDTaper := loghelper.LogarithmicTaper{}
+	// Note that we never reset this taper either.
+	unexpectedCompressionTaper := loghelper.LogarithmicTaper{}
+
+	decompressor := newDecompressor()
 
 	// We keep track of stream names for logging.
 	// Note that entries in this map are not checked for truthfulness, the remote
@@ -1278,6 +1511,7 @@ func authenticatedConnectionReadLoop(
 	const maxOpenCloseFramesReceived = 2 * MaxStreamsPerPeer
 
 	rawHeader := make([]byte, frameHeaderEncodedSize)
+	pingPayload := make([]byte, pingPayloadSize)
 
 	for {
 		if !readInternal(rawHeader) {
@@ -1296,15 +1530,17 @@ func authenticatedConnectionReadLoop(
 			if header.PayloadLength == 0 || header.PayloadLength > MaxStreamNameLength {
 				return
 			}
-			streamName := make([]byte, header.PayloadLength)
-			if !readInternal(streamName) {
+			payload := make([]byte, header.PayloadLength)
+			if !readInternal(payload) {
 				return
 			}
-			remoteStreamNameByID[header.StreamID] = string(streamName)
+			streamName, options := decodeOpenPayload(payload)
+			remoteStreamNameByID[header.StreamID] = streamName
 			select {
 			case chOtherStreamStateNotification <- streamStateNotification{
 				header.StreamID,
-				string(streamName),
+				streamName,
+				options,
 				true,
 			}:
 			case <-ctx.Done():
@@ -1321,24 +1557,70 @@ func authenticatedConnectionReadLoop(
 			case chOtherStreamStateNotification <- streamStateNotification{
 				header.StreamID,
 				"",
+				0,
 				false,
 			}:
 			case <-ctx.Done():
 				return
 			}
-		case frameTypeData:
-			if MaxMessageLength < header.PayloadLength {
+		case frameTypePing, frameTypePong:
+			if header.PayloadLength != pingPayloadSize {
+				logWithHeader(header).Warn("authenticatedConnectionReadLoop: ping/pong frame has wrong payload length, closing connection", nil)
+				return
+			}
+			if !readInternal(pingPayload) {
+				return
+			}
+			nonce := decodePingPayload(pingPayload)
+			if header.Type == frameTypePing {
+				select {
+				case chPong <- nonce:
+				default:
+					// a pong is pending already, the remote is pinging
+					// more often than it should
+				}
+			} else if rtt, ok := pinger.Pong(nonce, time.Now()); ok {
+				metrics.rttSeconds.Observe(rtt.Seconds())
+			}
+		case frameTypeData, frameTypeCompressedData:
+			compressed := header.Type == frameTypeCompressedData
+			// Length of the data that remains to be read for this frame
+			payloadLength := header.PayloadLength
+			// Length of the message after decompression
+			messageLength := header.PayloadLength
+			if compressed {
+				if payloadLength < compressedDataLengthSize {
+					logWithHeader(header).Warn("authenticatedConnectionReadLoop: compressed frame is too short, closing connection", nil)
+					return
+				}
+				rawMessageLength := make([]byte, compressedDataLengthSize)
+				if !readInternal(rawMessageLength) {
+					return
+				}
+				payloadLength -= compressedDataLengthSize
+				messageLength = binary.BigEndian.Uint32(rawMessageLength)
+				// Senders only compress messages if that makes them smaller.
+				// This ensures that limits on the decompressed size of
+				// messages also limit the bytes we read.
+				if messageLength < payloadLength {
+					logWithHeader(header).Warn("authenticatedConnectionReadLoop: compressed message is larger than uncompressed message, closing connection", commontypes.LogFields{
+						"messageLength": messageLength,
+					})
+					return
+				}
+			}
+			if MaxMessageLength < messageLength {
 				logWithHeader(header).Warn("authenticatedConnectionReadLoop: message exceeds ragep2p message length limit, closing connection", commontypes.LogFields{
-					"payloadLength":           header.PayloadLength,
+					"messageLength":           messageLength,
 					"ragep2pMaxMessageLength": MaxMessageLength,
 				})
 				return
 			}
-			// Cast to int is safe since header.PayloadLength <= MaxMessageLength <= INT_MAX
-			switch demux.ShouldPush(header.StreamID, int(header.PayloadLength)) {
+			// Cast to int is safe since messageLength <= MaxMessageLength <= INT_MAX
+			switch demux.ShouldPush(header.StreamID, int(messageLength), compressed) {
 			case shouldPushResultMessageTooBig:
 				logWithHeader(header).Warn("authenticatedConnectionReadLoop: message too big, closing connection", commontypes.LogFields{
-					"payloadLength": header.PayloadLength,
+					"messageLength": messageLength,
 				})
 				return
 			case shouldPushResultMessagesLimitExceeded:
@@ -1347,7 +1629,7 @@ func authenticatedConnectionReadLoop(
 						"limitsExceededDroppedCount": count,
 					})
 				})
-				if !skipInternal(header.PayloadLength) {
+				if !skipInternal(payloadLength) {
 					return
 				}
 			case shouldPushResultBytesLimitExceeded:
@@ -1356,7 +1638,7 @@ func authenticatedConnectionReadLoop(
 						"limitsExceededDroppedCount": count,
 					})
 				})
-				if !skipInternal(header.PayloadLength) {
+				if !skipInternal(payloadLength) {
 					return
 				}
 			case shouldPushResultUnknownStream:
@@ -1365,7 +1647,19 @@ func authenticatedConnectionReadLoop(
 						"unknownStreamIDDroppedCount": count,
 					})
 				})
-				if !skipInternal(header.PayloadLength) {
+				if !skipInternal(payloadLength) {
+					return
+				}
+			case shouldPushResultUnexpectedCompression:
+				// This can happen if we reopened the stream without
+				// compression while the other side was still sending
+				// compressed messages.
+				unexpectedCompressionTaper.Trigger(func(count uint64) {
+					logWithHeader(header).Warn("authenticatedConnectionReadLoop: compressed message on stream without compression, dropping message", commontypes.LogFields{
+						"unexpectedCompressionDroppedCount": count,
+					})
+				})
+				if !skipInternal(payloadLength) {
 					return
 				}
 			case shouldPushResultYes:
@@ -1374,10 +1668,22 @@ func authenticatedConnectionReadLoop(
 						"droppedCount": oldCount,
 					})
 				})
-				data := make([]byte, header.PayloadLength)
+				data := make([]byte, payloadLength)
 				if !readInternal(data) {
 					return
 				}
+				if compressed {
+					var err error
+					data, err = decompressor.Decompress(data, int(messageLength))
+					if err != nil {
+						logWithHeader(header).Warn("authenticatedConnectionReadLoop: error decompressing message, closing connection", commontypes.LogFields{
+							"error": err,
+						})
+						return
+					}
+					metrics.compressionReceivedBytesTotal.Add(float64(payloadLength + compressedDataLengthSize))
+					metrics.compressionReceivedUncompressedBytesTotal.Add(float64(messageLength))
+				}
 				switch demux.PushMessage(header.StreamID, data) {
 				case pushResultSuccess:
 				case pushResultDropped:
@@ -1406,42 +1712,83 @@ func authenticatedConnectionWriteLoop(
 	ctx context.Context,
 	conn net.Conn,
 	chSelfStreamStateNotification <-chan streamStateNotification,
-	chWriteData <-chan streamIDAndData,
+	scheduler *scheduler,
+	pingsEnabled bool,
+	pinger *pinger,
+	chPong <-chan uint64,
 	chWriteTerminated chan<- struct{},
 	logger loghelper.LoggerWithContext,
 	metrics *peerMetrics,
 ) {
+	shutdown := func() {
+		if err := safeClose(conn); err != nil {
+			logger.Warn("Failed to close connection", commontypes.LogFields{"error": err})
+		}
+		close(chWriteTerminated)
+	}
+
 	writeInternal := func(buf []byte) bool {
 		_, err := conn.Write(buf)
 		if err != nil {
 			logger.Warn("Error writing to connection", commontypes.LogFields{"error": err})
-			// shut everything down
-			if err := safeClose(conn); err != nil {
-				logger.Warn("Failed to close connection", commontypes.LogFields{"error": err})
-			}
-			close(chWriteTerminated)
+			shutdown()
 			return false
 		}
 		metrics.connWrittenBytesTotal.Add(float64(len(buf)))
 		return true
 	}
 
+	writePingFrame := func(typ frameType, nonce uint64) bool {
+		if err := conn.SetWriteDeadline(time.Now().Add(netTimeout)); err != nil {
+			logger.Warn("Closing connection, error during SetWriteDeadline", commontypes.LogFields{"error": err})
+			return false
+		}
+		header := frameHeader{
+			typ,
+			streamID{},
+			pingPayloadSize,
+		}
+		return writeInternal(header.Encode()) && writeInternal(encodePingPayload(nonce))
+	}
+
+	compressor := newCompressor()
+
+	// nil if the remote doesn't support pings
+	var chPingTick <-chan time.Time
+	if pingsEnabled {
+		ticker := time.NewTicker(pingInterval)
+		defer ticker.Stop()
+		chPingTick = ticker.C
+	}
+
 	for {
 		select {
-		case data := <-chWriteData:
+		case <-scheduler.SignalPending():
+			data, compression, ok := scheduler.Pop()
+			if !ok {
+				break
+			}
 			if err := conn.SetWriteDeadline(time.Now().Add(netTimeout)); err != nil {
 				logger.Warn("Closing connection, error during SetWriteDeadline", commontypes.LogFields{"error": err})
 				return
 			}
+			typ, payload := frameTypeData, data.Data
+			if compression == StreamCompressionDeflate {
+				if compressed, ok := compressor.Compress(data.Data); ok {
+					typ, payload = frameTypeCompressedData, compressed
+				}
+				metrics.compressionSentBytesTotal.Add(float64(len(payload)))
+				metrics.compressionSentUncompressedBytesTotal.Add(float64(len(data.Data)))
+			}
 			header := frameHeader{
-				frameTypeData,
+				typ,
 				data.StreamID,
-				uint32(len(data.Data)),
+				uint32(len(payload)),
 			}
 			if !writeInternal(header.Encode()) {
 				return
 			}
-			if !writeInternal(data.Data) {
+			if !writeInternal(payload) {
 				return
 			}
 			metrics.messageBytes.Observe(float64(len(data.Data)))
@@ -1451,12 +1798,12 @@ func authenticatedConnectionWriteLoop(
 				return
 			}
 			var header frameHeader
-			streamName := []byte(notification.streamName)
+			payload := encodeOpenPayload(notification.streamName, notification.options)
 			if notification.open {
 				header = frameHeader{
 					frameTypeOpen,
 					notification.streamID,
-					uint32(len(streamName)),
+					uint32(len(payload)),
 				}
 			} else {
 				header = frameHeader{
@@ -1468,7 +1815,26 @@ func authenticatedConnectionWriteLoop(
 			if !writeInternal(header.Encode()) {
 				return
 			}
-			if notification.open && !writeInternal(streamName) {
+			if notification.open && !writeInternal(payload) {
+				return
+			}
+
+		case <-chPingTick:
+			nonce, send, timedOut := pinger.Tick(time.Now())
+			if timedOut {
+				logger.Warn("Closing connection, remote didn't answer ping in time", commontypes.LogFields{
+					"pongTimeout": pongTimeout.String(),
+				})
+				metrics.connPongTimeoutsTotal.Inc()
+				shutdown()
+				return
+			}
+			if send && !writePingFrame(frameTypePing, nonce) {
+				return
+			}
+
+		case nonce := <-chPong:
+			if !writePingFrame(frameTypePong, nonce) {
 				return
 			}
 
@@ -0,0 +1,361 @@
+package ragep2p
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/smartcontractkit/libocr/commontypes"
+	"github.com/smartcontractkit/libocr/internal/loghelper"
+)
+
+// Reliable streams wrap every message sent over the connection in one of two
+// message types:
+//
+//	data: type (1 byte) || session (8 bytes) || seqNr (8 bytes) || base (8 bytes) || payload
+//	ack:  type (1 byte) || session (8 bytes) || seqNr (8 bytes)
+//
+// Every Stream has a random session that identifies it for as long as it's
+// open. The sender numbers its messages with consecutive seqNrs starting at 1
+// and keeps up to a window of unacknowledged messages. base is the lowest
+// seqNr that has not been acknowledged yet. Acks are cumulative: they
+// acknowledge all messages of the session up to and including seqNr. The
+// receiver only delivers messages in order; all other messages are dropped
+// and retransmitted by the sender later (go-back-N).
+//
+// When the receiver sees a new session or a base beyond the next seqNr it
+// expects, it continues at base. This happens when either side has reopened
+// the stream: messages below base have been acknowledged, i.e. delivered, by
+// a previous incarnation of the receiving Stream.
+
+type reliableMessageType uint8
+
+const (
+	_ reliableMessageType = iota
+	reliableMessageTypeData
+	reliableMessageTypeAck
+)
+
+const reliableDataHeaderSize = 1 + 8 + 8 + 8
+const reliableAckSize = 1 + 8 + 8
+
+// Retransmission timeouts double with every retransmission that doesn't lead
+// to progress.
+const (
+	reliableMinRetransmitTimeout = 1 * time.Second
+	reliableMaxRetransmitTimeout = 32 * time.Second
+)
+
+type reliableMessage struct {
+	typ     reliableMessageType
+	session uint64
+	seqNr   uint64
+	base    uint64 // only for data
+	payload []byte // only for data
+}
+
+func (m reliableMessage) Encode() []byte {
+	var buf []byte
+	switch m.typ {
+	case reliableMessageTypeData:
+		buf = make([]byte, reliableDataHeaderSize, reliableDataHeaderSize+len(m.payload))
+		binary.BigEndian.PutUint64(buf[17:25], m.base)
+	case reliableMessageTypeAck:
+		buf = make([]byte, reliableAckSize)
+	}
+	buf[0] = byte(m.typ)
+	binary.BigEndian.PutUint64(buf[1:9], m.session)
+	binary.BigEndian.PutUint64(buf[9:17], m.seqNr)
+	return append(buf, m.payload...)
+}
+
+func decodeReliableMessage(encoded []byte) (reliableMessage, error) {
+	if len(encoded) == 0 {
+		return reliableMessage{}, fmt.Errorf("empty reliable message")
+	}
+	switch typ := reliableMessageType(encoded[0]); typ {
+	case reliableMessageTypeData:
+		if len(encoded) < reliableDataHeaderSize {
+			return reliableMessage{}, fmt.Errorf("reliable data message too short")
+		}
+		return reliableMessage{
+			typ,
+			binary.BigEndian.Uint64(encoded[1:9]),
+			binary.BigEndian.Uint64(encoded[9:17]),
+			binary.BigEndian.Uint64(encoded[17:25]),
+			encoded[reliableDataHeaderSize:],
+		}, nil
+	case reliableMessageTypeAck:
+		if len(encoded) != reliableAckSize {
+			return reliableMessage{}, fmt.Errorf("reliable ack message has wrong length")
+		}
+		return reliableMessage{
+			typ,
+			binary.BigEndian.Uint64(encoded[1:9]),
+			binary.BigEndian.Uint64(encoded[9:17]),
+			0,
+			nil,
+		}, nil
+	default:
+		return reliableMessage{}, fmt.Errorf("unknown reliable message type %v", typ)
+	}
+}
+
+func newReliableSession() uint64 {
+	for {
+		// zero is reserved for "no session"
+		if session := rand.Uint64(); session != 0 {
+			return session
+		}
+	}
+}
+
+// reliableSender is the sending half of a reliable stream. NOT thread-safe.
+type reliableSender struct {
+	session uint64
+	window  int
+	base    uint64
+	// unacked[i] is the payload of the message with seqNr base+i
+	unacked [][]byte
+
+	retransmitTimeout time.Duration
+}
+
+func newReliableSender(session uint64, window int) *reliableSender {
+	return &reliableSender{
+		session,
+		window,
+		1,
+		nil,
+		reliableMinRetransmitTimeout,
+	}
+}
+
+// CanSend returns whether there is room in the window for another message.
+func (s *reliableSender) CanSend() bool {
+	return len(s.unacked) < s.window
+}
+
+func (s *reliableSender) encode(i int) []byte {
+	return reliableMessage{
+		reliableMessageTypeData,
+		s.session,
+		s.base + uint64(i),
+		s.base,
+		s.unacked[i],
+	}.Encode()
+}
+
+// Send adds payload to the window and returns the encoded message. Must only
+// be called if CanSend returns true.
+func (s *reliableSender) Send(payload []byte) []byte {
+	s.unacked = append(s.unacked, payload)
+	return s.encode(len(s.unacked) - 1)
+}
+
+// Ack processes an ack and returns whether it acknowledged any message that
+// hadn't been acknowledged before.
+func (s *reliableSender) Ack(session uint64, seqNr uint64) bool {
+	if session != s.session || seqNr < s.base {
+		return false
+	}
+	acked := seqNr - s.base + 1
+	if acked > uint64(len(s.unacked)) {
+		// bogus ack for messages we haven't sent
+		return false
+	}
+	for i := uint64(0); i < acked; i++ {
+		s.unacked[i] = nil
+	}
+	s.unacked = s.unacked[acked:]
+	s.base += acked
+	s.retransmitTimeout = reliableMinRetransmitTimeout
+	return true
+}
+
+// Unacked returns freshly encoded messages for all unacknowledged payloads.
+func (s *reliableSender) Unacked() [][]byte {
+	encoded := make([][]byte, 0, len(s.unacked))
+	for i := range s.unacked {
+		encoded = append(encoded, s.encode(i))
+	}
+	return encoded
+}
+
+// Backoff doubles the retransmission timeout and returns the previous one.
+func (s *reliableSender) Backoff() time.Duration {
+	timeout := s.retransmitTimeout
+	s.retransmitTimeout *= 2
+	if s.retransmitTimeout > reliableMaxRetransmitTimeout {
+		s.retransmitTimeout = reliableMaxRetransmitTimeout
+	}
+	return timeout
+}
+
+// reliableReceiver is the receiving half of a reliable stream. NOT
+// thread-safe.
+type reliableReceiver struct {
+	// session of the sender, zero if we haven't received any message yet
+	session   uint64
+	nextSeqNr uint64
+}
+
+// Receive processes a data message and returns whether its payload should be
+// delivered.
+func (r *reliableReceiver) Receive(msg reliableMessage) bool {
+	if msg.session != r.session || r.nextSeqNr < msg.base {
+		r.session = msg.session
+		r.nextSeqNr = msg.base
+	}
+	if msg.seqNr != r.nextSeqNr {
+		// duplicate or out of order
+		return false
+	}
+	r.nextSeqNr++
+	return true
+}
+
+// Ack returns an encoded ack for all messages delivered so far, or nil if
+// there is nothing to acknowledge.
+func (r *reliableReceiver) Ack() []byte {
+	if r.session == 0 || r.nextSeqNr == 0 {
+		return nil
+	}
+	return reliableMessage{
+		reliableMessageTypeAck,
+		r.session,
+		r.nextSeqNr - 1,
+		0,
+		nil,
+	}.Encode()
+}
+
+func (st *Stream) reliableSendLoop() {
+	defer st.metrics.Close()
+	defer st.scheduler.RemoveStream(st.schedulerStream)
+
+	sender := newReliableSender(newReliableSession(), st.outgoingBufferSize)
+	on := false
+	// nil unless a retransmission is scheduled
+	var chRetransmit <-chan time.Time
+
+	// Go-back-N: (re)send all unacknowledged messages. Messages still queued
+	// from earlier are replaced, so that we don't send them twice.
+	retransmit := func(timeout time.Duration) {
+		chRetransmit = nil
+		if len(sender.unacked) != 0 {
+			st.scheduler.Replace(st.streamID, sender.Unacked())
+			chRetransmit = time.After(timeout)
+		}
+	}
+
+	for {
+		var chSendOrNil <-chan []byte
+		if sender.CanSend() {
+			chSendOrNil = st.chSend
+		}
+
+		select {
+		case onOff := <-st.chStreamOnOff:
+			st.scheduler.SetEnabled(st.streamID, onOff.on, onOff.compression)
+			on = onOff.on
+			if on {
+				st.logger.Info("Turned on stream", commontypes.LogFields{
+					"compression": onOff.compression,
+				})
+				// Messages sent on the previous connection may have been lost
+				retransmit(sender.retransmitTimeout)
+			} else {
+				st.logger.Info("Turned off stream", nil)
+				chRetransmit = nil
+			}
+
+		case msg := <-chSendOrNil:
+			hadUnacked := len(sender.unacked) != 0
+			st.scheduler.Push(st.streamID, sender.Send(msg))
+			if on && !hadUnacked {
+				chRetransmit = time.After(sender.retransmitTimeout)
+			}
+
+		case ack := <-st.chAck:
+			if sender.Ack(ack.session, ack.seqNr) {
+				chRetransmit = nil
+				if on && len(sender.unacked) != 0 {
+					chRetransmit = time.After(sender.retransmitTimeout)
+				}
+			}
+
+		case <-chRetransmit:
+			st.logger.Debug("Retransmitting unacknowledged messages", commontypes.LogFields{
+				"unacked": len(sender.unacked),
+				"base":    sender.base,
+			})
+			retransmit(sender.Backoff())
+
+		case <-st.ctx.Done():
+			return
+		}
+	}
+}
+
+func (st *Stream) reliableReceiveLoop() {
+	chSignalPending := st.demux.SignalPending(st.streamID)
+	chDone := st.ctx.Done()
+	var receiver reliableReceiver
+	ackPending := false
+	// We taper logs to prevent the other side from spamming our logs
+	invalidMessageTaper := loghelper.LogarithmicTaper{}
+	for {
+		select {
+		case <-chSignalPending:
+			encoded := st.demux.PopMessage(st.streamID)
+			if encoded == nil {
+				st.logger.Error("Received nil msg, this should not happen", nil)
+				break
+			}
+			msg, err := decodeReliableMessage(encoded)
+			if err != nil {
+				invalidMessageTaper.Trigger(func(count uint64) {
+					st.logger.Warn("Dropping invalid message on reliable stream", commontypes.LogFields{
+						"error":                      err,
+						"invalidMessageDroppedCount": count,
+					})
+				})
+				break
+			}
+			switch msg.typ {
+			case reliableMessageTypeAck:
+				select {
+				case st.chAck <- msg:
+				case <-chDone:
+					return
+				}
+			case reliableMessageTypeData:
+				if receiver.Receive(msg) {
+					select {
+					case st.chReceive <- msg.payload:
+					case <-chDone:
+						return
+					}
+				}
+				// acknowledge duplicates, too, the sender may have missed
+				// our previous ack
+				ackPending = true
+			}
+			// Only ack once we've processed all pending messages, acks
+			// are cumulative
+			if ackPending && len(chSignalPending) == 0 {
+				if ack := receiver.Ack(); ack != nil {
+					// Acks bypass the queue, so that neither
+					// retransmissions (which replace the queue) nor
+					// a full queue can drop them.
+					st.scheduler.SetControl(st.streamID, ack)
+				}
+				ackPending = false
+			}
+		case <-chDone:
+			return
+		}
+	}
+}
@@ -0,0 +1,272 @@
+package ragep2p
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"math/rand"
+	"net"
+	"sync"
+	"testing"
+	"time"
+)
+
+// simulateReliable sends n messages from sender to receiver over a channel
+// that drops messages with probability loss and returns the delivered
+// payloads.
+func simulateReliable(t *testing.T, rng *rand.Rand, sender *reliableSender, receiver *reliableReceiver, n int, loss float64) []uint64 {
+	t.Helper()
+	var delivered []uint64
+	var wire [][]byte
+	next := 0
+	for rounds := 0; len(delivered) < n; rounds++ {
+		if rounds > 100*n {
+			t.Fatalf("no progress, delivered %v of %v messages", len(delivered), n)
+		}
+		for next < n && sender.CanSend() {
+			payload := make([]byte, 8)
+			binary.BigEndian.PutUint64(payload, uint64(next))
+			wire = append(wire, sender.Send(payload))
+			next++
+		}
+		if len(wire) == 0 {
+			// everything was lost, time out
+			wire = sender.Unacked()
+		}
+		for _, encoded := range wire {
+			if rng.Float64() < loss {
+				continue
+			}
+			msg, err := decodeReliableMessage(encoded)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if receiver.Receive(msg) {
+				delivered = append(delivered, binary.BigEndian.Uint64(msg.payload))
+			}
+		}
+		wire = nil
+		if ack := receiver.Ack(); ack != nil && rng.Float64() >= loss {
+			msg, err := decodeReliableMessage(ack)
+			if err != nil {
+				t.Fatal(err)
+			}
+			sender.Ack(msg.session, msg.seqNr)
+		}
+	}
+	return delivered
+}
+
+func TestReliableInOrderDelivery(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	for _, loss := range []float64{0, 0.1, 0.5} {
+		delivered := simulateReliable(t, rng, newReliableSender(1, 8), &reliableReceiver{}, 200, loss)
+		for i, d := range delivered {
+			if d != uint64(i) {
+				t.Fatalf("loss %v: message %v has payload %v", loss, i, d)
+			}
+		}
+	}
+}
+
+func TestReliableReopen(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	sender := newReliableSender(1, 4)
+	simulateReliable(t, rng, sender, &reliableReceiver{}, 10, 0)
+
+	// A reopened receiver continues where the old one left off
+	receiver := &reliableReceiver{}
+	delivered := simulateReliable(t, rng, sender, receiver, 5, 0.2)
+	if delivered[0] != 0 || len(delivered) != 5 {
+		t.Fatalf("unexpected delivery after receiver reopened: %v", delivered)
+	}
+
+	// A reopened sender starts a new session that the receiver follows
+	delivered = simulateReliable(t, rng, newReliableSender(2, 4), receiver, 5, 0.2)
+	if delivered[0] != 0 || len(delivered) != 5 {
+		t.Fatalf("unexpected delivery after sender reopened: %v", delivered)
+	}
+
+	// Acks for other sessions are ignored
+	s := newReliableSender(3, 4)
+	s.Send([]byte{1})
+	if s.Ack(4, 1) || !s.Ack(3, 1) || !s.CanSend() {
+		t.Fatalf("unexpected ack handling")
+	}
+}
+
+func TestReliableStream(t *testing.T) {
+	network := NewMemoryNetwork()
+	hosts := testHosts(t, network.Transport(), []string{"a", "b"})
+	limit := TokenBucketParams{1e6, 1e6}
+
+	sa, err := hosts[0].NewReliableStream(hosts[1].ID(), "reliable", 4, 8, 8, limit, limit, StreamOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sa.Close()
+	sb, err := hosts[1].NewReliableStream(hosts[0].ID(), "reliable", 4, 8, 8, limit, limit, StreamOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sb.Close()
+
+	const n = 100
+	go func() {
+		for i := 0; i < n; i++ {
+			msg := make([]byte, 8)
+			binary.BigEndian.PutUint64(msg, uint64(i))
+			sa.SendMessage(msg)
+		}
+	}()
+	for i := 0; i < n; i++ {
+		select {
+		case msg := <-sb.ReceiveMessages():
+			if got := binary.BigEndian.Uint64(msg); got != uint64(i) {
+				t.Fatalf("received message %v, expected %v", got, i)
+			}
+		case <-time.After(10 * time.Second):
+			t.Fatalf("timed out waiting for message %v", i)
+		}
+	}
+}
+
+// droppingTransport wraps a Transport and can close all connections it has
+// created so far.
+type droppingTransport struct {
+	Transport
+	mu    sync.Mutex
+	conns []net.Conn
+}
+
+type droppingListener struct {
+	net.Listener
+	transport *droppingTransport
+}
+
+func (t *droppingTransport) track(conn net.Conn) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.conns = append(t.conns, conn)
+}
+
+func (t *droppingTransport) Listen(address string) (net.Listener, error) {
+	ln, err := t.Transport.Listen(address)
+	if err != nil {
+		return nil, err
+	}
+	return droppingListener{ln, t}, nil
+}
+
+func (t *droppingTransport) Dial(ctx context.Context, address string) (net.Conn, error) {
+	conn, err := t.Transport.Dial(ctx, address)
+	if err == nil {
+		t.track(conn)
+	}
+	return conn, err
+}
+
+func (ln droppingListener) Accept() (net.Conn, error) {
+	conn, err := ln.Listener.Accept()
+	if err == nil {
+		ln.transport.track(conn)
+	}
+	return conn, err
+}
+
+// DropAll closes all connections, the hosts will reconnect.
+func (t *droppingTransport) DropAll() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, conn := range t.conns {
+		conn.Close()
+	}
+	t.conns = nil
+}
+
+func TestReliableStreamSurvivesReconnect(t *testing.T) {
+	transport := &droppingTransport{Transport: NewMemoryNetwork().Transport()}
+	hosts := testHosts(t, transport, []string{"a", "b"})
+	limit := TokenBucketParams{1e6, 1e6}
+
+	streams := []*Stream{}
+	for i, host := range hosts {
+		st, err := host.NewReliableStream(hosts[1-i].ID(), "reliable", 4, 8, 8, limit, limit, StreamOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer st.Close()
+		streams = append(streams, st)
+	}
+
+	// Traffic flows in both directions, so that acks share the connection
+	// with data and retransmissions.
+	const n = 300
+	for _, st := range streams {
+		go func(st *Stream) {
+			for i := 0; i < n; i++ {
+				msg := make([]byte, 8)
+				binary.BigEndian.PutUint64(msg, uint64(i))
+				st.SendMessage(msg)
+			}
+		}(st)
+	}
+
+	start := time.Now()
+	chErr := make(chan error, len(streams))
+	var received [2]uint64
+	var receivedMu sync.Mutex
+	for i, st := range streams {
+		go func(i int, st *Stream) {
+			// Messages may be delivered more than once across a
+			// reconnect, but never out of order or with gaps.
+			next := uint64(0)
+			for next < n {
+				select {
+				case msg := <-st.ReceiveMessages():
+					got := binary.BigEndian.Uint64(msg)
+					if got > next {
+						chErr <- fmt.Errorf("stream %v: received message %v, expected %v", i, got, next)
+						return
+					}
+					if got == next {
+						next++
+						receivedMu.Lock()
+						received[i] = next
+						receivedMu.Unlock()
+					}
+				case <-time.After(20 * time.Second):
+					chErr <- fmt.Errorf("stream %v: timed out waiting for message %v", i, next)
+					return
+				}
+			}
+			chErr <- nil
+		}(i, st)
+	}
+
+	// Drop the connection mid-stream, twice
+	for _, threshold := range []uint64{n / 3, 2 * n / 3} {
+		for {
+			receivedMu.Lock()
+			progress := received[0] >= threshold && received[1] >= threshold
+			receivedMu.Unlock()
+			if progress {
+				break
+			}
+			time.Sleep(time.Millisecond)
+		}
+		transport.DropAll()
+	}
+
+	for range streams {
+		if err := <-chErr; err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Streams retransmit as soon as they reconnect, which takes much less
+	// than the initial retransmission timeout. Waiting for a timeout means
+	// that an ack was lost.
+	if elapsed := time.Since(start); elapsed > reliableMinRetransmitTimeout {
+		t.Fatalf("delivery took %v", elapsed)
+	}
+}
@@ -0,0 +1,376 @@
+package ragep2p
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// StreamPriorityClass determines which streams' messages are written to the
+// connection first. As long as a stream of a higher class has messages
+// queued, no messages of streams of a lower class are written. Higher classes
+// should thus only be used for streams with low bandwidth requirements.
+type StreamPriorityClass int
+
+const (
+	_ StreamPriorityClass = iota
+	StreamPriorityClassLow
+	StreamPriorityClassNormal
+	StreamPriorityClassHigh
+)
+
+func (c StreamPriorityClass) String() string {
+	switch c {
+	case StreamPriorityClassLow:
+		return "low"
+	case StreamPriorityClassNormal:
+		return "normal"
+	case StreamPriorityClassHigh:
+		return "high"
+	}
+	return fmt.Sprintf("StreamPriorityClass(%d)", int(c))
+}
+
+func (c StreamPriorityClass) MarshalText() ([]byte, error) {
+	return []byte(c.String()), nil
+}
+
+// MaxStreamPriorityWeight is the largest weight a stream may have.
+const MaxStreamPriorityWeight = 1000
+
+// StreamPriority controls how a stream shares the connection with the other
+// streams to the same peer. Streams are scheduled by Class first. Within a
+// class, streams with queued messages get a share of the bytes written that is
+// proportional to their Weight (weighted fair queuing), so that a stream with
+// lots of traffic cannot starve other streams of the same class.
+//
+// Scheduling happens per message: a message that is being written is never
+// interrupted, so a large message still delays all messages queued behind it.
+type StreamPriority struct {
+	Class StreamPriorityClass `json:"class"`
+	// Must be between 1 and MaxStreamPriorityWeight.
+	Weight int `json:"weight"`
+}
+
+func (p StreamPriority) check() error {
+	if p.Class < StreamPriorityClassLow || StreamPriorityClassHigh < p.Class {
+		return fmt.Errorf("invalid stream priority class %v", p.Class)
+	}
+	if p.Weight < 1 || MaxStreamPriorityWeight < p.Weight {
+		return fmt.Errorf("stream priority weight %v is not between 1 and %v", p.Weight, MaxStreamPriorityWeight)
+	}
+	return nil
+}
+
+// Number of bytes a stream of weight 1 may send per round of deficit round
+// robin.
+const schedulerQuantum = 16 * 1024
+
+type scheduledMessage struct {
+	data     []byte
+	enqueued time.Time
+}
+
+type schedulerStream struct {
+	sid       streamID
+	name      string
+	priority  StreamPriority
+	queue     []scheduledMessage
+	queueSize int
+	// Pending control message (e.g. a cumulative ack), nil data if there is
+	// none. It is written before the queued messages and is neither affected
+	// by Replace nor dropped when the queue is full.
+	control scheduledMessage
+	metrics *streamMetrics
+
+	// Messages are only written while the stream is enabled, i.e. while both
+	// sides have opened it on the current connection.
+	enabled bool
+	// Compression negotiated for the current connection
+	compression StreamCompression
+	// Whether the stream is in its class' active list
+	active bool
+	// Deficit round robin state
+	deficit int
+	visited bool
+
+	// Number of messages dropped because the queue was full
+	dropped uint64
+}
+
+// scheduler holds the outgoing messages of all streams to a peer and decides
+// in which order they are written to the connection.
+type scheduler struct {
+	mutex   sync.Mutex
+	streams map[streamID]*schedulerStream
+	// active[class] contains the enabled streams of class that have queued
+	// messages, in round robin order.
+	active   [StreamPriorityClassHigh + 1][]*schedulerStream
+	chSignal chan struct{}
+}
+
+func newScheduler() *scheduler {
+	return &scheduler{
+		sync.Mutex{},
+		map[streamID]*schedulerStream{},
+		[StreamPriorityClassHigh + 1][]*schedulerStream{},
+		make(chan struct{}, 1),
+	}
+}
+
+// Caller must hold mutex.
+func (s *scheduler) signal() {
+	select {
+	case s.chSignal <- struct{}{}:
+	default:
+	}
+}
+
+// Caller must hold mutex.
+func (ss *schedulerStream) hasQueued() bool {
+	return ss.control.data != nil || len(ss.queue) > 0
+}
+
+// Caller must hold mutex.
+func (s *scheduler) updateActive(ss *schedulerStream) {
+	shouldBeActive := ss.enabled && ss.hasQueued()
+	if shouldBeActive == ss.active {
+		return
+	}
+	ss.active = shouldBeActive
+	ss.deficit = 0
+	ss.visited = false
+	class := ss.priority.Class
+	if shouldBeActive {
+		s.active[class] = append(s.active[class], ss)
+		s.signal()
+		return
+	}
+	for i, other := range s.active[class] {
+		if other == ss {
+			s.active[class] = append(s.active[class][:i], s.active[class][i+1:]...)
+			break
+		}
+	}
+}
+
+// AddStream adds a stream and returns its entry, which must be passed to
+// RemoveStream. If there already is a stream with the same sid, AddStream
+// returns the existing entry and false.
+func (s *scheduler) AddStream(sid streamID, name string, priority StreamPriority, queueSize int, metrics *streamMetrics) (*schedulerStream, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if ss, ok := s.streams[sid]; ok {
+		return ss, false
+	}
+	ss := &schedulerStream{
+		sid,
+		name,
+		priority,
+		nil,
+		queueSize,
+		scheduledMessage{},
+		metrics,
+		false,
+		StreamCompressionNone,
+		false,
+		0,
+		false,
+		0,
+	}
+	s.streams[sid] = ss
+	return ss, true
+}
+
+// RemoveStream removes the entry returned by AddStream. Streams are removed
+// asynchronously after they have been closed, at which point a new stream with
+// the same sid may have been added already. The new stream's entry is left
+// alone.
+func (s *scheduler) RemoveStream(ss *schedulerStream) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.streams[ss.sid] != ss {
+		return
+	}
+	ss.enabled = false
+	s.updateActive(ss)
+	delete(s.streams, ss.sid)
+}
+
+// Push appends a message to the stream's queue. If the queue is full, the
+// oldest message is dropped.
+func (s *scheduler) Push(sid streamID, data []byte) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	ss, ok := s.streams[sid]
+	if !ok {
+		return
+	}
+	if len(ss.queue) >= ss.queueSize {
+		ss.queue[0] = scheduledMessage{}
+		ss.queue = ss.queue[1:]
+		ss.dropped++
+	}
+	ss.queue = append(ss.queue, scheduledMessage{data, time.Now()})
+	s.updateActive(ss)
+}
+
+// Replace replaces all messages queued for the stream with messages. The
+// pending control message is kept.
+func (s *scheduler) Replace(sid streamID, messages [][]byte) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	ss, ok := s.streams[sid]
+	if !ok {
+		return
+	}
+	now := time.Now()
+	ss.queue = ss.queue[:0:0]
+	for _, data := range messages {
+		if len(ss.queue) >= ss.queueSize {
+			ss.queue = ss.queue[1:]
+		}
+		ss.queue = append(ss.queue, scheduledMessage{data, now})
+	}
+	s.updateActive(ss)
+}
+
+// SetControl sets the stream's pending control message, replacing any
+// previous one that hasn't been written yet. This suits messages like
+// cumulative acks, where only the latest one matters.
+func (s *scheduler) SetControl(sid streamID, data []byte) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	ss, ok := s.streams[sid]
+	if !ok {
+		return
+	}
+	ss.control = scheduledMessage{data, time.Now()}
+	s.updateActive(ss)
+}
+
+// SetEnabled enables or disables writing the stream's messages. If the stream
+// is enabled, its messages are written with the given compression.
+func (s *scheduler) SetEnabled(sid streamID, enabled bool, compression StreamCompression) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	ss, ok := s.streams[sid]
+	if !ok {
+		return
+	}
+	ss.enabled = enabled
+	ss.compression = compression
+	s.updateActive(ss)
+}
+
+// SignalPending receives a value whenever Pop may return a message.
+func (s *scheduler) SignalPending() <-chan struct{} {
+	return s.chSignal
+}
+
+// Pop removes and returns the next message to be written, together with the
+// compression to write it with. Streams are served in strict order of priority
+// class, and by deficit round robin within a class.
+func (s *scheduler) Pop() (streamIDAndData, StreamCompression, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for class := StreamPriorityClassHigh; class >= StreamPriorityClassLow; class-- {
+		if len(s.active[class]) == 0 {
+			continue
+		}
+		// Terminates since every visit increases the deficit of the visited
+		// stream.
+		for {
+			ss := s.active[class][0]
+			if !ss.visited {
+				ss.deficit += ss.priority.Weight * schedulerQuantum
+				ss.visited = true
+			}
+			isControl := ss.control.data != nil
+			var msg scheduledMessage
+			if isControl {
+				msg = ss.control
+			} else {
+				msg = ss.queue[0]
+			}
+			if len(msg.data) <= ss.deficit {
+				ss.deficit -= len(msg.data)
+				if isControl {
+					ss.control = scheduledMessage{}
+				} else {
+					ss.queue[0] = scheduledMessage{}
+					ss.queue = ss.queue[1:]
+				}
+				// updateActive resets the deficit if the stream has become
+				// inactive
+				s.updateActive(ss)
+				ss.metrics.sendQueueDelaySeconds.Observe(time.Since(msg.enqueued).Seconds())
+				if s.hasActive() {
+					s.signal()
+				}
+				return streamIDAndData{ss.sid, msg.data}, ss.compression, true
+			}
+			// Move on to the next stream in round robin order
+			ss.visited = false
+			s.active[class] = append(s.active[class][1:], ss)
+		}
+	}
+	return streamIDAndData{}, StreamCompressionNone, false
+}
+
+// Caller must hold mutex.
+func (s *scheduler) hasActive() bool {
+	for _, streams := range s.active {
+		if len(streams) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+type schedulerStreamSnapshot struct {
+	sid         streamID
+	name        string
+	priority    StreamPriority
+	enabled     bool
+	compression StreamCompression
+	queued      int
+	queueSize   int
+	dropped     uint64
+	// Time the oldest queued message has been waiting, zero if the queue is
+	// empty
+	oldestQueued time.Duration
+}
+
+func (s *scheduler) Snapshot() []schedulerStreamSnapshot {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	now := time.Now()
+	snapshots := make([]schedulerStreamSnapshot, 0, len(s.streams))
+	for _, ss := range s.streams {
+		var oldestQueued time.Duration
+		if len(ss.queue) > 0 {
+			oldestQueued = now.Sub(ss.queue[0].enqueued)
+		}
+		snapshots = append(snapshots, schedulerStreamSnapshot{
+			ss.sid,
+			ss.name,
+			ss.priority,
+			ss.enabled,
+			ss.compression,
+			len(ss.queue),
+			ss.queueSize,
+			ss.dropped,
+			oldestQueued,
+		})
+	}
+	return snapshots
+}
@@ -0,0 +1,155 @@
+package ragep2p
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/smartcontractkit/libocr/ragep2p/types"
+)
+
+func addTestStream(t *testing.T, s *scheduler, sid streamID, priority StreamPriority, queueSize int) *schedulerStream {
+	t.Helper()
+	metrics := newStreamMetricsSet(prometheus.NewRegistry()).Acquire(nopLogger{}, types.PeerID{}, types.PeerID{}, "test", priority)
+	ss, ok := s.AddStream(sid, "test", priority, queueSize, metrics)
+	if !ok {
+		t.Fatalf("could not add stream %v", sid)
+	}
+	s.SetEnabled(sid, true, StreamCompressionNone)
+	return ss
+}
+
+func popAll(s *scheduler) []streamIDAndData {
+	var result []streamIDAndData
+	for {
+		data, _, ok := s.Pop()
+		if !ok {
+			return result
+		}
+		result = append(result, data)
+	}
+}
+
+func TestSchedulerStrictPriority(t *testing.T) {
+	s := newScheduler()
+	low, high := streamID{1}, streamID{2}
+	addTestStream(t, s, low, StreamPriority{StreamPriorityClassLow, MaxStreamPriorityWeight}, 10)
+	addTestStream(t, s, high, StreamPriority{StreamPriorityClassHigh, 1}, 10)
+
+	for i := 0; i < 3; i++ {
+		s.Push(low, []byte{byte(i)})
+		s.Push(high, []byte{byte(i)})
+	}
+
+	popped := popAll(s)
+	if len(popped) != 6 {
+		t.Fatalf("expected 6 messages, got %v", len(popped))
+	}
+	for i, data := range popped {
+		expected := high
+		if i >= 3 {
+			expected = low
+		}
+		if data.StreamID != expected {
+			t.Fatalf("message %v: expected stream %v, got %v", i, expected, data.StreamID)
+		}
+	}
+}
+
+func TestSchedulerWeightedFairness(t *testing.T) {
+	s := newScheduler()
+	light, heavy := streamID{1}, streamID{2}
+	addTestStream(t, s, light, StreamPriority{StreamPriorityClassNormal, 1}, 1000)
+	addTestStream(t, s, heavy, StreamPriority{StreamPriorityClassNormal, 3}, 1000)
+
+	msg := make([]byte, schedulerQuantum/4)
+	for i := 0; i < 1000; i++ {
+		s.Push(light, msg)
+		s.Push(heavy, msg)
+	}
+
+	bytes := map[streamID]int{}
+	for i := 0; i < 800; i++ {
+		data, _, ok := s.Pop()
+		if !ok {
+			t.Fatalf("scheduler ran out of messages")
+		}
+		bytes[data.StreamID] += len(data.Data)
+	}
+	if bytes[heavy] != 3*bytes[light] {
+		t.Fatalf("expected heavy stream to get three times the bytes of light stream, got %v and %v", bytes[heavy], bytes[light])
+	}
+}
+
+func TestSchedulerDisabledAndOverflow(t *testing.T) {
+	s := newScheduler()
+	sid := streamID{1}
+	ss := addTestStream(t, s, sid, StreamPriority{StreamPriorityClassNormal, 1}, 2)
+	s.SetEnabled(sid, false, StreamCompressionNone)
+
+	for i := 0; i < 3; i++ {
+		s.Push(sid, []byte{byte(i)})
+	}
+	if _, _, ok := s.Pop(); ok {
+		t.Fatalf("popped message of disabled stream")
+	}
+
+	s.SetEnabled(sid, true, StreamCompressionNone)
+	popped := popAll(s)
+	if len(popped) != 2 || popped[0].Data[0] != 1 || popped[1].Data[0] != 2 {
+		t.Fatalf("expected the two newest messages, got %v", popped)
+	}
+
+	s.Push(sid, []byte{3})
+	s.RemoveStream(ss)
+	if _, _, ok := s.Pop(); ok {
+		t.Fatalf("popped message of removed stream")
+	}
+}
+
+func TestSchedulerRemoveStaleStream(t *testing.T) {
+	s := newScheduler()
+	sid := streamID{1}
+	old := addTestStream(t, s, sid, StreamPriority{StreamPriorityClassNormal, 1}, 2)
+	s.RemoveStream(old)
+
+	// A stream with the same sid is added before the old stream's loops have
+	// exited. Their deferred removal must not affect the new stream.
+	addTestStream(t, s, sid, StreamPriority{StreamPriorityClassNormal, 1}, 2)
+	s.RemoveStream(old)
+	s.Push(sid, []byte{1})
+	if popped := popAll(s); len(popped) != 1 || popped[0].StreamID != sid {
+		t.Fatalf("expected message of new stream, got %v", popped)
+	}
+}
+
+func TestSchedulerControlMessage(t *testing.T) {
+	s := newScheduler()
+	sid := streamID{1}
+	addTestStream(t, s, sid, StreamPriority{StreamPriorityClassNormal, 1}, 2)
+
+	s.Push(sid, []byte("data1"))
+	s.SetControl(sid, []byte("ack1"))
+	// Only the latest control message is written
+	s.SetControl(sid, []byte("ack2"))
+	// Neither a full queue nor Replace drops the control message
+	s.Push(sid, []byte("data2"))
+	s.Push(sid, []byte("data3"))
+	s.Replace(sid, [][]byte{[]byte("data4")})
+
+	popped := popAll(s)
+	expected := []string{"ack2", "data4"}
+	if len(popped) != len(expected) {
+		t.Fatalf("expected %v messages, got %v", len(expected), len(popped))
+	}
+	for i, data := range popped {
+		if string(data.Data) != expected[i] {
+			t.Fatalf("message %v: expected %q, got %q", i, expected[i], data.Data)
+		}
+	}
+
+	// A control message alone makes the stream active
+	s.SetControl(sid, []byte("ack3"))
+	if popped := popAll(s); len(popped) != 1 || string(popped[0].Data) != "ack3" {
+		t.Fatalf("expected only ack3, got %v", popped)
+	}
+}
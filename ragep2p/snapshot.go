@@ -0,0 +1,173 @@
+package ragep2p
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/smartcontractkit/libocr/ragep2p/types"
+)
+
+// HostSnapshot describes the state of a Host at a point in time, see
+// Host.Snapshot. It is meant for diagnostics; its contents may change in
+// future versions.
+type HostSnapshot struct {
+	PeerID          types.PeerID   `json:"peerID"`
+	ListenAddresses []string       `json:"listenAddresses"`
+	Time            time.Time      `json:"time"`
+	Peers           []PeerSnapshot `json:"peers"`
+}
+
+// PeerSnapshot describes the connection with a peer and the streams with it.
+// In JSON, Uptime is rendered like "1m30s".
+type PeerSnapshot struct {
+	PeerID    types.PeerID `json:"peerID"`
+	Connected bool         `json:"connected"`
+	// The following four fields are only set if Connected is true.
+	RemoteAddress string `json:"remoteAddress,omitempty"`
+	// Whether the connection was dialed by the peer
+	Inbound               bool             `json:"inbound"`
+	ConnectionEstablished time.Time        `json:"connectionEstablished"`
+	Uptime                time.Duration    `json:"-"`
+	Streams               []StreamSnapshot `json:"streams"`
+}
+
+// StreamSnapshot describes a stream. In JSON, OldestOutgoingQueued is rendered
+// like "1m30s".
+type StreamSnapshot struct {
+	Name     string         `json:"name"`
+	ID       string         `json:"id"`
+	Priority StreamPriority `json:"priority"`
+	// Whether both sides have opened the stream on the current connection,
+	// i.e. whether outgoing messages are being written
+	Active bool `json:"active"`
+	// Compression negotiated for the current connection
+	Compression StreamCompression `json:"compression"`
+
+	OutgoingQueued    int `json:"outgoingQueued"`
+	OutgoingQueueSize int `json:"outgoingQueueSize"`
+	// Time the oldest queued outgoing message has been waiting to be
+	// written. Large values indicate that the stream is stuck.
+	OldestOutgoingQueued time.Duration `json:"-"`
+	// Outgoing messages dropped because the queue was full
+	OutgoingDropped uint64 `json:"outgoingDropped"`
+
+	IncomingBuffered   int `json:"incomingBuffered"`
+	IncomingBufferSize int `json:"incomingBufferSize"`
+	// Incoming messages dropped because the buffer was full, i.e. because
+	// messages weren't received from the stream quickly enough
+	IncomingDroppedOverflow uint64 `json:"incomingDroppedOverflow"`
+	// Incoming messages dropped because they exceeded the stream's rate limits
+	IncomingDroppedLimits uint64 `json:"incomingDroppedLimits"`
+}
+
+func (ps PeerSnapshot) MarshalJSON() ([]byte, error) {
+	type peerSnapshot PeerSnapshot
+	return json.Marshal(struct {
+		peerSnapshot
+		Uptime string `json:"uptime"`
+	}{peerSnapshot(ps), ps.Uptime.String()})
+}
+
+func (ss StreamSnapshot) MarshalJSON() ([]byte, error) {
+	type streamSnapshot StreamSnapshot
+	return json.Marshal(struct {
+		streamSnapshot
+		OldestOutgoingQueued string `json:"oldestOutgoingQueued"`
+	}{streamSnapshot(ss), ss.OldestOutgoingQueued.String()})
+}
+
+// Snapshot returns the state of the host's peers, their connections and
+// streams. Peers are sorted by PeerID, streams by name. Only peers with which
+// the host has open streams are included.
+func (ho *Host) Snapshot() HostSnapshot {
+	now := time.Now()
+
+	ho.peersMu.Lock()
+	peers := make([]*peer, 0, len(ho.peers))
+	for _, p := range ho.peers {
+		peers = append(peers, p)
+	}
+	ho.peersMu.Unlock()
+
+	peerSnapshots := make([]PeerSnapshot, 0, len(peers))
+	for _, p := range peers {
+		peerSnapshots = append(peerSnapshots, p.snapshot(now))
+	}
+	sort.Slice(peerSnapshots, func(i, j int) bool {
+		return peerSnapshots[i].PeerID.String() < peerSnapshots[j].PeerID.String()
+	})
+
+	return HostSnapshot{
+		ho.id,
+		append([]string{}, ho.listenAddresses...),
+		now,
+		peerSnapshots,
+	}
+}
+
+func (p *peer) snapshot(now time.Time) PeerSnapshot {
+	p.connInfoMu.Lock()
+	connInfo := p.connInfo
+	p.connInfoMu.Unlock()
+
+	var uptime time.Duration
+	if connInfo.connected {
+		uptime = now.Sub(connInfo.established)
+	}
+
+	streams := []StreamSnapshot{}
+	for _, ss := range p.scheduler.Snapshot() {
+		// zero if the stream has just been removed from the demuxer
+		ds, _ := p.demuxer.Snapshot(ss.sid)
+		streams = append(streams, StreamSnapshot{
+			ss.name,
+			ss.sid.String(),
+			ss.priority,
+			ss.enabled,
+			ss.compression,
+			ss.queued,
+			ss.queueSize,
+			ss.oldestQueued,
+			ss.dropped,
+			ds.buffered,
+			ds.bufferSize,
+			ds.droppedOverflow,
+			ds.droppedLimits,
+		})
+	}
+	sort.Slice(streams, func(i, j int) bool {
+		return streams[i].Name < streams[j].Name
+	})
+
+	return PeerSnapshot{
+		p.other,
+		connInfo.connected,
+		connInfo.remoteAddr,
+		connInfo.inbound,
+		connInfo.established,
+		uptime,
+		streams,
+	}
+}
+
+// NewSnapshotHandler returns an http.Handler that renders host.Snapshot() as
+// JSON. The snapshot reveals the host's peers and their network addresses, so
+// the handler should only be served on an internal debug endpoint.
+func NewSnapshotHandler(host *Host) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		encoded, err := json.MarshalIndent(host.Snapshot(), "", "  ")
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(encoded)
+	})
+}
@@ -0,0 +1,105 @@
+package ragep2p
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestSnapshot(t *testing.T) {
+	network := NewMemoryNetwork()
+	hosts := testHosts(t, network.Transport(), []string{"a", "b"})
+	limit := TokenBucketParams{1e6, 1e6}
+	priority := StreamPriority{StreamPriorityClassHigh, 3}
+
+	sa, err := hosts[0].NewStreamWithOptions(hosts[1].ID(), "snapshot", 10, 10, 1024, limit, limit, StreamOptions{priority, StreamCompressionDeflate})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sa.Close()
+	sb, err := hosts[1].NewStreamWithOptions(hosts[0].ID(), "snapshot", 10, 10, 1024, limit, limit, StreamOptions{priority, StreamCompressionDeflate})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sb.Close()
+
+	sa.SendMessage([]byte("hello"))
+	select {
+	case <-sb.ReceiveMessages():
+	case <-time.After(10 * time.Second):
+		t.Fatalf("timed out waiting for message")
+	}
+	// Leave messages buffered on b's side. Since nobody receives them, they
+	// pile up in the demuxer once the stream's receive channel is full.
+	for i := 0; i < 10; i++ {
+		sa.SendMessage([]byte("hello again"))
+	}
+
+	var peer PeerSnapshot
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		snapshot := hosts[1].Snapshot()
+		if snapshot.PeerID != hosts[1].ID() || len(snapshot.Peers) != 1 {
+			t.Fatalf("unexpected snapshot: %+v", snapshot)
+		}
+		peer = snapshot.Peers[0]
+		if len(peer.Streams) == 1 && peer.Streams[0].IncomingBuffered > 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("message never showed up in snapshot: %+v", peer)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if peer.PeerID != hosts[0].ID() || !peer.Connected || peer.RemoteAddress == "" || peer.ConnectionEstablished.IsZero() {
+		t.Fatalf("unexpected peer snapshot: %+v", peer)
+	}
+	stream := peer.Streams[0]
+	if stream.Name != "snapshot" || stream.Priority != priority || !stream.Active || stream.Compression != StreamCompressionDeflate || stream.IncomingBufferSize != 10 {
+		t.Fatalf("unexpected stream snapshot: %+v", stream)
+	}
+
+	server := httptest.NewServer(NewSnapshotHandler(hosts[1]))
+	defer server.Close()
+	resp, err := http.Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.Header.Get("Content-Type") != "application/json" {
+		t.Fatalf("unexpected content type %q", resp.Header.Get("Content-Type"))
+	}
+	var decoded struct {
+		Peers []struct {
+			Connected bool   `json:"connected"`
+			Uptime    string `json:"uptime"`
+			Streams   []struct {
+				Name     string `json:"name"`
+				Priority struct {
+					Class string `json:"class"`
+				} `json:"priority"`
+				Compression string `json:"compression"`
+			} `json:"streams"`
+		} `json:"peers"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&decoded); err != nil {
+		t.Fatal(err)
+	}
+	if len(decoded.Peers) != 1 || !decoded.Peers[0].Connected || decoded.Peers[0].Uptime == "" || len(decoded.Peers[0].Streams) != 1 {
+		t.Fatalf("unexpected JSON snapshot: %+v", decoded)
+	}
+	if s := decoded.Peers[0].Streams[0]; s.Name != "snapshot" || s.Priority.Class != "high" || s.Compression != "deflate" {
+		t.Fatalf("unexpected JSON stream snapshot: %+v", s)
+	}
+
+	resp, err = http.Post(server.URL, "application/json", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Fatalf("unexpected status %v for POST", resp.StatusCode)
+	}
+}
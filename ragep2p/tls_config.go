@@ -5,6 +5,11 @@ import (
 	"crypto/x509"
 )
 
+// Peers that support ping and pong frames offer this protocol via ALPN.
+// Older peers don't offer or accept any protocol, in which case the
+// negotiated protocol is empty and we don't send them ping frames.
+const tlsALPNProtocolPing = "ragep2p-ping"
+
 func newTLSConfig(cert tls.Certificate, verifyPeerCertificate func(rawCerts [][]byte, verifiedChains [][]*x509.Certificate) error) *tls.Config {
 	return &tls.Config{
 		Certificates: []tls.Certificate{cert},
@@ -18,5 +23,7 @@ func newTLSConfig(cert tls.Certificate, verifyPeerCertificate func(rawCerts [][]
 		MinVersion: tls.VersionTLS13,
 
 		VerifyPeerCertificate: verifyPeerCertificate,
+
+		NextProtos: []string{tlsALPNProtocolPing},
 	}
 }
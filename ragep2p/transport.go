@@ -0,0 +1,97 @@
+package ragep2p
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+)
+
+// Transport abstracts the network over which a Host establishes connections.
+// Everything on top of the raw connection (knock, mTLS, rate limiting,
+// framing) is handled by the Host and is the same for all transports.
+//
+// All its functions should be thread-safe.
+type Transport interface {
+	// ParseAddress checks that address is valid for this transport. The Host
+	// calls it for its listen addresses in NewHost and for every address
+	// returned by the Discoverer before dialing it. It must not block, e.g.
+	// on DNS lookups; resolution is left to Listen and Dial.
+	ParseAddress(address string) (net.Addr, error)
+	// Listen returns a listener on address. Closing the listener must make
+	// pending Accept calls return.
+	Listen(address string) (net.Listener, error)
+	// Dial connects to address. It must return when ctx is done. Returned
+	// connections must support deadlines.
+	Dial(ctx context.Context, address string) (net.Conn, error)
+}
+
+// TCPTransport is the default Transport. Addresses are of the form
+// "host:port".
+type TCPTransport struct{}
+
+var _ Transport = TCPTransport{}
+
+// ParseAddress only checks the syntax of address. Host names are resolved
+// when dialing.
+func (TCPTransport) ParseAddress(address string) (net.Addr, error) {
+	_, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := strconv.ParseUint(port, 10, 16); err != nil {
+		return nil, fmt.Errorf("invalid port %q in address %q", port, address)
+	}
+	return unresolvedTCPAddr(address), nil
+}
+
+// unresolvedTCPAddr is a syntactically valid "host:port" address whose host
+// may not have been resolved yet.
+type unresolvedTCPAddr string
+
+func (a unresolvedTCPAddr) Network() string { return "tcp" }
+func (a unresolvedTCPAddr) String() string  { return string(a) }
+
+func (TCPTransport) Listen(address string) (net.Listener, error) {
+	return net.Listen("tcp", address)
+}
+
+func (TCPTransport) Dial(ctx context.Context, address string) (net.Conn, error) {
+	var dialer net.Dialer
+	return dialer.DialContext(ctx, "tcp", address)
+}
+
+// UnixTransport connects hosts via Unix domain sockets, e.g. an oracle and a
+// co-located sidecar. Addresses are socket paths. Like with net.Listen, the
+// socket file must not exist when the Host starts; it is removed when the
+// Host is closed.
+//
+// Note that the ragedisco Discoverer only announces IP addresses, so
+// UnixTransport is meant to be used with a Discoverer that knows the socket
+// paths of peers upfront.
+type UnixTransport struct{}
+
+var _ Transport = UnixTransport{}
+
+func (UnixTransport) ParseAddress(address string) (net.Addr, error) {
+	if address == "" {
+		return nil, fmt.Errorf("unix socket path must not be empty")
+	}
+	return net.ResolveUnixAddr("unix", address)
+}
+
+func (UnixTransport) Listen(address string) (net.Listener, error) {
+	return net.Listen("unix", address)
+}
+
+func (UnixTransport) Dial(ctx context.Context, address string) (net.Conn, error) {
+	var dialer net.Dialer
+	return dialer.DialContext(ctx, "unix", address)
+}
+
+func (ho *Host) transport() Transport {
+	if ho.config.Transport == nil {
+		return TCPTransport{}
+	}
+	return ho.config.Transport
+}
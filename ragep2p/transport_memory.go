@@ -0,0 +1,340 @@
+package ragep2p
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"sync"
+	"time"
+)
+
+// MemoryNetwork is an in-memory network that connects Hosts running in the
+// same process, e.g. in tests. Obtain a Transport for each Host with
+// Transport(). Hosts on the same MemoryNetwork can reach each other under
+// their listen addresses, which are arbitrary non-empty strings. Knock, mTLS,
+// and rate limiting work exactly like with TCP.
+//
+// Connections are buffered in both directions, so that, like with TCP,
+// writes don't block on the other side reading unless the buffer is full.
+type MemoryNetwork struct {
+	mu        sync.Mutex
+	listeners map[string]*memoryListener
+	dialCount uint64
+}
+
+func NewMemoryNetwork() *MemoryNetwork {
+	return &MemoryNetwork{listeners: map[string]*memoryListener{}}
+}
+
+// Transport returns a Transport for a Host on this network.
+func (n *MemoryNetwork) Transport() Transport {
+	return memoryTransport{n}
+}
+
+// Size of the buffer in each direction of a connection
+const memoryConnBufferSize = 1024 * 1024
+
+// Maximum number of connections waiting to be accepted per listener
+const memoryListenerBacklog = 64
+
+type memoryAddr string
+
+func (a memoryAddr) Network() string { return "memory" }
+func (a memoryAddr) String() string  { return string(a) }
+
+type memoryTransport struct {
+	network *MemoryNetwork
+}
+
+var _ Transport = memoryTransport{}
+
+func (t memoryTransport) ParseAddress(address string) (net.Addr, error) {
+	if address == "" {
+		return nil, fmt.Errorf("memory address must not be empty")
+	}
+	return memoryAddr(address), nil
+}
+
+func (t memoryTransport) Listen(address string) (net.Listener, error) {
+	if _, err := t.ParseAddress(address); err != nil {
+		return nil, err
+	}
+	t.network.mu.Lock()
+	defer t.network.mu.Unlock()
+	if _, ok := t.network.listeners[address]; ok {
+		return nil, fmt.Errorf("memory address %q already in use", address)
+	}
+	ln := &memoryListener{
+		t.network,
+		memoryAddr(address),
+		make(chan net.Conn, memoryListenerBacklog),
+		make(chan struct{}),
+		sync.Once{},
+	}
+	t.network.listeners[address] = ln
+	return ln, nil
+}
+
+func (t memoryTransport) Dial(ctx context.Context, address string) (net.Conn, error) {
+	t.network.mu.Lock()
+	ln, ok := t.network.listeners[address]
+	t.network.dialCount++
+	localAddr := memoryAddr(fmt.Sprintf("memory-dialer-%d", t.network.dialCount))
+	t.network.mu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("dial %v: connection refused", address)
+	}
+
+	clientToServer, serverToClient := newMemoryBuffer(), newMemoryBuffer()
+	client := newMemoryConn(localAddr, ln.addr, serverToClient, clientToServer)
+	server := newMemoryConn(ln.addr, localAddr, clientToServer, serverToClient)
+	select {
+	case ln.chConns <- server:
+		return client, nil
+	case <-ln.chClosed:
+		return nil, fmt.Errorf("dial %v: connection refused", address)
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+type memoryListener struct {
+	network   *MemoryNetwork
+	addr      memoryAddr
+	chConns   chan net.Conn
+	chClosed  chan struct{}
+	closeOnce sync.Once
+}
+
+var _ net.Listener = (*memoryListener)(nil)
+
+func (ln *memoryListener) Accept() (net.Conn, error) {
+	select {
+	case conn := <-ln.chConns:
+		return conn, nil
+	case <-ln.chClosed:
+		return nil, net.ErrClosed
+	}
+}
+
+func (ln *memoryListener) Close() error {
+	ln.closeOnce.Do(func() {
+		close(ln.chClosed)
+		ln.network.mu.Lock()
+		delete(ln.network.listeners, string(ln.addr))
+		ln.network.mu.Unlock()
+	})
+	return nil
+}
+
+func (ln *memoryListener) Addr() net.Addr {
+	return ln.addr
+}
+
+// memoryBuffer holds the bytes in flight in one direction of a memoryConn.
+type memoryBuffer struct {
+	mu     sync.Mutex
+	data   []byte
+	closed bool
+	// closed and replaced whenever data or closed change
+	chChanged chan struct{}
+}
+
+func newMemoryBuffer() *memoryBuffer {
+	return &memoryBuffer{chChanged: make(chan struct{})}
+}
+
+// Caller must hold mu.
+func (b *memoryBuffer) signal() {
+	close(b.chChanged)
+	b.chChanged = make(chan struct{})
+}
+
+func (b *memoryBuffer) close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !b.closed {
+		b.closed = true
+		b.signal()
+	}
+}
+
+type memoryConn struct {
+	localAddr, remoteAddr memoryAddr
+	in, out               *memoryBuffer
+
+	deadlineMu        sync.Mutex
+	readDeadline      time.Time
+	writeDeadline     time.Time
+	chDeadlineChanged chan struct{}
+
+	closeOnce sync.Once
+	chClosed  chan struct{}
+}
+
+var _ net.Conn = (*memoryConn)(nil)
+
+func newMemoryConn(localAddr, remoteAddr memoryAddr, in, out *memoryBuffer) *memoryConn {
+	return &memoryConn{
+		localAddr,
+		remoteAddr,
+		in,
+		out,
+		sync.Mutex{},
+		time.Time{},
+		time.Time{},
+		make(chan struct{}),
+		sync.Once{},
+		make(chan struct{}),
+	}
+}
+
+func (c *memoryConn) deadline(write bool) (time.Time, <-chan struct{}) {
+	c.deadlineMu.Lock()
+	defer c.deadlineMu.Unlock()
+	if write {
+		return c.writeDeadline, c.chDeadlineChanged
+	}
+	return c.readDeadline, c.chDeadlineChanged
+}
+
+// wait blocks until chChanged is closed, the deadline passes, or the conn is
+// closed. It returns early if the deadline is changed, so that the caller
+// re-evaluates it.
+func (c *memoryConn) wait(chChanged <-chan struct{}, write bool) error {
+	deadline, chDeadlineChanged := c.deadline(write)
+	var chTimeout <-chan time.Time
+	if !deadline.IsZero() {
+		d := time.Until(deadline)
+		if d <= 0 {
+			return os.ErrDeadlineExceeded
+		}
+		timer := time.NewTimer(d)
+		defer timer.Stop()
+		chTimeout = timer.C
+	}
+	select {
+	case <-chChanged:
+		return nil
+	case <-chDeadlineChanged:
+		return nil
+	case <-chTimeout:
+		return os.ErrDeadlineExceeded
+	case <-c.chClosed:
+		return net.ErrClosed
+	}
+}
+
+func (c *memoryConn) checkOpenAndDeadline(write bool) error {
+	select {
+	case <-c.chClosed:
+		return net.ErrClosed
+	default:
+	}
+	deadline, _ := c.deadline(write)
+	if !deadline.IsZero() && !time.Now().Before(deadline) {
+		return os.ErrDeadlineExceeded
+	}
+	return nil
+}
+
+func (c *memoryConn) Read(p []byte) (int, error) {
+	for {
+		if err := c.checkOpenAndDeadline(false); err != nil {
+			return 0, err
+		}
+		c.in.mu.Lock()
+		if len(c.in.data) > 0 {
+			n := copy(p, c.in.data)
+			c.in.data = c.in.data[n:]
+			c.in.signal()
+			c.in.mu.Unlock()
+			return n, nil
+		}
+		if c.in.closed {
+			c.in.mu.Unlock()
+			return 0, io.EOF
+		}
+		chChanged := c.in.chChanged
+		c.in.mu.Unlock()
+		if err := c.wait(chChanged, false); err != nil {
+			return 0, err
+		}
+	}
+}
+
+func (c *memoryConn) Write(p []byte) (int, error) {
+	written := 0
+	for len(p) > 0 {
+		if err := c.checkOpenAndDeadline(true); err != nil {
+			return written, err
+		}
+		c.out.mu.Lock()
+		if c.out.closed {
+			c.out.mu.Unlock()
+			return written, io.ErrClosedPipe
+		}
+		if space := memoryConnBufferSize - len(c.out.data); space > 0 {
+			n := len(p)
+			if n > space {
+				n = space
+			}
+			c.out.data = append(c.out.data, p[:n]...)
+			c.out.signal()
+			c.out.mu.Unlock()
+			p = p[n:]
+			written += n
+			continue
+		}
+		chChanged := c.out.chChanged
+		c.out.mu.Unlock()
+		if err := c.wait(chChanged, true); err != nil {
+			return written, err
+		}
+	}
+	return written, nil
+}
+
+// Close closes both directions. The other side reads the remaining buffered
+// data followed by io.EOF.
+func (c *memoryConn) Close() error {
+	c.closeOnce.Do(func() {
+		close(c.chClosed)
+		c.in.close()
+		c.out.close()
+	})
+	return nil
+}
+
+func (c *memoryConn) LocalAddr() net.Addr  { return c.localAddr }
+func (c *memoryConn) RemoteAddr() net.Addr { return c.remoteAddr }
+
+func (c *memoryConn) SetDeadline(t time.Time) error {
+	c.setDeadline(&t, &t)
+	return nil
+}
+
+func (c *memoryConn) SetReadDeadline(t time.Time) error {
+	c.setDeadline(&t, nil)
+	return nil
+}
+
+func (c *memoryConn) SetWriteDeadline(t time.Time) error {
+	c.setDeadline(nil, &t)
+	return nil
+}
+
+func (c *memoryConn) setDeadline(read, write *time.Time) {
+	c.deadlineMu.Lock()
+	defer c.deadlineMu.Unlock()
+	if read != nil {
+		c.readDeadline = *read
+	}
+	if write != nil {
+		c.writeDeadline = *write
+	}
+	close(c.chDeadlineChanged)
+	c.chDeadlineChanged = make(chan struct{})
+}
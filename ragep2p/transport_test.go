@@ -0,0 +1,139 @@
+package ragep2p
+
+import (
+	"crypto/ed25519"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/smartcontractkit/libocr/commontypes"
+	"github.com/smartcontractkit/libocr/internal/loghelper"
+	"github.com/smartcontractkit/libocr/ragep2p/types"
+)
+
+type nopLogger struct{}
+
+func (nopLogger) Trace(string, commontypes.LogFields)    {}
+func (nopLogger) Debug(string, commontypes.LogFields)    {}
+func (nopLogger) Info(string, commontypes.LogFields)     {}
+func (nopLogger) Warn(string, commontypes.LogFields)     {}
+func (nopLogger) Error(string, commontypes.LogFields)    {}
+func (nopLogger) Critical(string, commontypes.LogFields) {}
+
+// staticDiscoverer knows the addresses of all peers upfront.
+type staticDiscoverer map[types.PeerID][]types.Address
+
+func (d staticDiscoverer) Start(*Host, ed25519.PrivateKey, loghelper.LoggerWithContext) error {
+	return nil
+}
+
+func (d staticDiscoverer) Close() error { return nil }
+
+func (d staticDiscoverer) FindPeer(peer types.PeerID) ([]types.Address, error) {
+	return d[peer], nil
+}
+
+// testHosts starts a host for each address and returns them once started.
+func testHosts(t *testing.T, transport Transport, addresses []string) []*Host {
+	t.Helper()
+	discoverer := staticDiscoverer{}
+	keys := []ed25519.PrivateKey{}
+	for _, address := range addresses {
+		_, sk, err := ed25519.GenerateKey(nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		keys = append(keys, sk)
+		id, err := types.PeerIDFromPrivateKey(sk)
+		if err != nil {
+			t.Fatal(err)
+		}
+		discoverer[id] = []types.Address{types.Address(address)}
+	}
+
+	hosts := []*Host{}
+	for i, address := range addresses {
+		host, err := NewHost(
+			HostConfig{100 * time.Millisecond, transport},
+			keys[i],
+			[]string{address},
+			discoverer,
+			nopLogger{},
+			prometheus.NewRegistry(),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := host.Start(); err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { host.Close() })
+		hosts = append(hosts, host)
+	}
+	return hosts
+}
+
+// checkAllPairsCommunicate opens a stream between every pair of hosts and
+// sends a message in each direction.
+func checkAllPairsCommunicate(t *testing.T, hosts []*Host) {
+	t.Helper()
+	limit := TokenBucketParams{1e6, 1e6}
+	for i, a := range hosts {
+		for j, b := range hosts {
+			if i >= j {
+				continue
+			}
+			streamName := fmt.Sprintf("test-%d-%d", i, j)
+			sa, err := a.NewStream(b.ID(), streamName, 10, 10, 1024, limit, limit)
+			if err != nil {
+				t.Fatal(err)
+			}
+			sb, err := b.NewStream(a.ID(), streamName, 10, 10, 1024, limit, limit)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, pair := range [][2]*Stream{{sa, sb}, {sb, sa}} {
+				pair[0].SendMessage([]byte(streamName))
+				select {
+				case msg := <-pair[1].ReceiveMessages():
+					if string(msg) != streamName {
+						t.Fatalf("received %q, expected %q", msg, streamName)
+					}
+				case <-time.After(10 * time.Second):
+					t.Fatalf("timed out waiting for message on %s", streamName)
+				}
+			}
+			sa.Close()
+			sb.Close()
+		}
+	}
+}
+
+func TestMemoryTransport(t *testing.T) {
+	network := NewMemoryNetwork()
+	hosts := testHosts(t, network.Transport(), []string{"a", "b", "c", "d"})
+	checkAllPairsCommunicate(t, hosts)
+}
+
+func TestUnixTransport(t *testing.T) {
+	dir := t.TempDir()
+	hosts := testHosts(t, UnixTransport{}, []string{filepath.Join(dir, "a.sock"), filepath.Join(dir, "b.sock")})
+	checkAllPairsCommunicate(t, hosts)
+}
+
+func TestTCPTransportParseAddress(t *testing.T) {
+	// Host names are not resolved, ".invalid" never resolves
+	for _, address := range []string{"127.0.0.1:1234", "[::1]:80", "localhost:0", "peer.invalid:1234", ":1234"} {
+		if _, err := (TCPTransport{}).ParseAddress(address); err != nil {
+			t.Errorf("%q: unexpected error: %v", address, err)
+		}
+	}
+	for _, address := range []string{"", "localhost", "::1:80", "localhost:http", "localhost:65536", "localhost:-1"} {
+		if _, err := (TCPTransport{}).ParseAddress(address); err == nil {
+			t.Errorf("%q: expected error", address)
+		}
+	}
+}
@@ -7,7 +7,7 @@ import (
 	"fmt"
 
 	"github.com/mr-tron/base58"
-	"github.com/smartcontractkit/libocr/ragep2p/internal/mtls"
+	"github.com/smartcontractkit/libocr/internal/mtls"
 )
 
 // Address represents a network address & port such as "192.168.1.2:8080". It